@@ -0,0 +1,93 @@
+package domainapiclient
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Endpoints a client can be granted. Wildcard grants every endpoint, or every
+// app in AllowedApps.
+const (
+	Wildcard            = "*"
+	EndpointEmailSend   = "email.send"
+	EndpointResetMail   = "reset.email"
+	EndpointOTPSend     = "otp.send"
+	EndpointOTPVerify   = "otp.verify"
+	EndpointInboxCreate = "inbox.create"
+	EndpointNotifySend  = "notify.send"
+)
+
+func (ApiClient) TableName() string {
+	return "api_clients"
+}
+
+// ApiClient is a service allowed to call the sender APIs. Only a SHA-256 hash
+// of its key is stored; KeyPrefix is the public part used to find the row.
+// Requests are signed with SigningSecret, which is stored as is since the
+// signatures are checked against it, and never sent with a request. After a
+// rotation the previous key and signing secret keep working until
+// PreviousKeyExpiresAt.
+type ApiClient struct {
+	Id                    string         `json:"id" gorm:"column:id;primaryKey"`
+	Name                  string         `json:"name" gorm:"column:name"`
+	Description           string         `json:"description,omitempty" gorm:"column:description"`
+	KeyPrefix             string         `json:"key_prefix" gorm:"column:key_prefix"`
+	KeyHash               string         `json:"-" gorm:"column:key_hash"`
+	PreviousKeyPrefix     *string        `json:"previous_key_prefix,omitempty" gorm:"column:previous_key_prefix"`
+	PreviousKeyHash       *string        `json:"-" gorm:"column:previous_key_hash"`
+	PreviousKeyExpiresAt  *time.Time     `json:"previous_key_expires_at,omitempty" gorm:"column:previous_key_expires_at"`
+	SigningSecret         *string        `json:"-" gorm:"column:signing_secret"`
+	PreviousSigningSecret *string        `json:"-" gorm:"column:previous_signing_secret"`
+	AllowedEndpoints      []string       `json:"allowed_endpoints" gorm:"column:allowed_endpoints;serializer:json"`
+	AllowedApps           []string       `json:"allowed_apps" gorm:"column:allowed_apps;serializer:json"`
+	RequireSignature      bool           `json:"require_signature" gorm:"column:require_signature"`
+	BurstPerMinute        int            `json:"burst_per_minute" gorm:"column:burst_per_minute"`
+	DailyQuota            int            `json:"daily_quota" gorm:"column:daily_quota"`
+	MonthlyQuota          int            `json:"monthly_quota" gorm:"column:monthly_quota"`
+	IsActive              bool           `json:"is_active" gorm:"column:is_active"`
+	LastUsedAt            *time.Time     `json:"last_used_at,omitempty" gorm:"column:last_used_at"`
+	CreatedBy             *string        `json:"created_by,omitempty" gorm:"column:created_by"`
+	CreatedAt             time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt             *time.Time     `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// AllowsEndpoint reports whether the client may call endpoint.
+func (c ApiClient) AllowsEndpoint(endpoint string) bool {
+	for _, e := range c.AllowedEndpoints {
+		if e == Wildcard || e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// SigningSecrets returns the secrets a request signature may be made with at
+// now: the current one and, during a rotation grace period, the previous one.
+func (c ApiClient) SigningSecrets(now time.Time) []string {
+	var secrets []string
+	if c.SigningSecret != nil && *c.SigningSecret != "" {
+		secrets = append(secrets, *c.SigningSecret)
+	}
+	if c.PreviousSigningSecret != nil && *c.PreviousSigningSecret != "" &&
+		c.PreviousKeyExpiresAt != nil && now.Before(*c.PreviousKeyExpiresAt) {
+		secrets = append(secrets, *c.PreviousSigningSecret)
+	}
+	return secrets
+}
+
+// AllowsApp reports whether the client may send as appName, the X-App-Name
+// header. A client without an app list is not restricted.
+func (c ApiClient) AllowsApp(appName string) bool {
+	if len(c.AllowedApps) == 0 {
+		return true
+	}
+	for _, app := range c.AllowedApps {
+		if app == Wildcard || strings.EqualFold(app, strings.TrimSpace(appName)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package domainapiclient
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	QuotaWindowMinute = "minute"
+	QuotaWindowDay    = "day"
+	QuotaWindowMonth  = "month"
+)
+
+// QuotaWindow is one fixed counting window. Bucket names the window instance
+// (e.g. "day:20240131") so counters roll over on their own; a Limit of 0 is
+// metered only.
+type QuotaWindow struct {
+	Name     string
+	Bucket   string
+	Limit    int
+	ResetsAt time.Time
+}
+
+// QuotaWindows returns the client's minute, day and month windows containing
+// now. Day and month boundaries follow the service's local time.
+func (c ApiClient) QuotaWindows(now time.Time) []QuotaWindow {
+	minute := now.Truncate(time.Minute)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	return []QuotaWindow{
+		{Name: QuotaWindowMinute, Bucket: fmt.Sprintf("minute:%d", minute.Unix()/60), Limit: c.BurstPerMinute, ResetsAt: minute.Add(time.Minute)},
+		{Name: QuotaWindowDay, Bucket: "day:" + day.Format("20060102"), Limit: c.DailyQuota, ResetsAt: day.AddDate(0, 0, 1)},
+		{Name: QuotaWindowMonth, Bucket: "month:" + month.Format("200601"), Limit: c.MonthlyQuota, ResetsAt: month.AddDate(0, 1, 0)},
+	}
+}
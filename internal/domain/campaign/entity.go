@@ -0,0 +1,63 @@
+package domaincampaign
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+// Segment selects campaign recipients from the users table. Every set field
+// narrows the audience; an empty segment targets every user with an email.
+type Segment struct {
+	Roles       []string               `json:"roles,omitempty"`
+	CreatedFrom *time.Time             `json:"created_from,omitempty"`
+	CreatedTo   *time.Time             `json:"created_to,omitempty"`
+	Verified    *bool                  `json:"verified,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Campaign is one email sent to every user of a segment. The segment is
+// expanded in batches by the campaign worker; CursorId is the last user
+// processed, so a paused or interrupted campaign resumes where it stopped.
+type Campaign struct {
+	Id           string                 `json:"id" gorm:"column:id;primaryKey"`
+	Name         string                 `json:"name" gorm:"column:name"`
+	AppName      string                 `json:"app_name,omitempty" gorm:"column:app_name"`
+	Sender       string                 `json:"sender,omitempty" gorm:"column:sender"`
+	Subject      string                 `json:"subject,omitempty" gorm:"column:subject"`
+	TemplateKey  string                 `json:"template_key,omitempty" gorm:"column:template_key"`
+	TemplateData map[string]interface{} `json:"template_data,omitempty" gorm:"column:template_data;serializer:json"`
+	TextBody     string                 `json:"text_body,omitempty" gorm:"column:text_body"`
+	HTMLBody     string                 `json:"html_body,omitempty" gorm:"column:html_body"`
+	MarkdownBody string                 `json:"markdown_body,omitempty" gorm:"column:markdown_body"`
+	Segment      Segment                `json:"segment" gorm:"column:segment;serializer:json"`
+	Status       string                 `json:"status" gorm:"column:status"`
+	RatePerMin   int                    `json:"rate_per_minute" gorm:"column:rate_per_minute"`
+	TotalCount   int                    `json:"total_count" gorm:"column:total_count"`
+	QueuedCount  int                    `json:"queued_count" gorm:"-"`
+	SentCount    int                    `json:"sent_count" gorm:"column:sent_count"`
+	FailedCount  int                    `json:"failed_count" gorm:"column:failed_count"`
+	SkippedCount int                    `json:"skipped_count" gorm:"column:skipped_count"`
+	CursorId     *string                `json:"-" gorm:"column:cursor_id"`
+	LastError    string                 `json:"last_error,omitempty" gorm:"column:last_error"`
+	LeaseUntil   *time.Time             `json:"-" gorm:"column:lease_until"`
+	StartedAt    *time.Time             `json:"started_at,omitempty" gorm:"column:started_at"`
+	FinishedAt   *time.Time             `json:"finished_at,omitempty" gorm:"column:finished_at"`
+	CreatedBy    *string                `json:"created_by,omitempty" gorm:"column:created_by"`
+	CreatedAt    time.Time              `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    *time.Time             `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	DeletedAt    gorm.DeletedAt         `json:"-" gorm:"index"`
+}
+
+// WithProgress fills QueuedCount, the recipients not yet processed.
+func (c Campaign) WithProgress() Campaign {
+	c.QueuedCount = c.TotalCount - c.SentCount - c.FailedCount - c.SkippedCount
+	if c.QueuedCount < 0 || c.Status == StatusCancelled || c.Status == StatusCompleted {
+		c.QueuedCount = 0
+	}
+	return c
+}
@@ -0,0 +1,30 @@
+package domaincampaign
+
+const (
+	StatusDraft     = "draft"
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+	StatusCancelled = "cancelled"
+)
+
+// transitions lists the statuses each status may move to through the API or
+// the worker.
+var transitions = map[string][]string{
+	StatusDraft:   {StatusRunning, StatusCancelled},
+	StatusRunning: {StatusPaused, StatusCancelled, StatusCompleted},
+	StatusPaused:  {StatusRunning, StatusCancelled},
+}
+
+// SourcesOf returns the statuses that may move to next.
+func SourcesOf(next string) []string {
+	var from []string
+	for current, targets := range transitions {
+		for _, s := range targets {
+			if s == next {
+				from = append(from, current)
+			}
+		}
+	}
+	return from
+}
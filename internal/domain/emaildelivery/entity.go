@@ -0,0 +1,45 @@
+package domainemaildelivery
+
+import (
+	"time"
+)
+
+func (EmailDelivery) TableName() string {
+	return "email_deliveries"
+}
+
+// EmailDelivery is one recipient of one sent message. Provider webhooks
+// address it by MessageID and Recipient.
+type EmailDelivery struct {
+	Id           string     `json:"id" gorm:"column:id;primaryKey"`
+	MessageId    string     `json:"message_id" gorm:"column:message_id"`
+	EmailType    string     `json:"email_type" gorm:"column:email_type"`
+	AppName      string     `json:"app_name" gorm:"column:app_name"`
+	Recipient    string     `json:"recipient" gorm:"column:recipient"`
+	Subject      string     `json:"subject" gorm:"column:subject"`
+	Status       string     `json:"status" gorm:"column:status"`
+	StatusDetail string     `json:"status_detail,omitempty" gorm:"column:status_detail"`
+	LastEventAt  *time.Time `json:"last_event_at,omitempty" gorm:"column:last_event_at"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+	OpenedAt     *time.Time `json:"opened_at,omitempty" gorm:"column:opened_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty" gorm:"column:updated_at"`
+}
+
+func (Suppression) TableName() string {
+	return "email_suppressions"
+}
+
+// Suppression blocks future sends to Email until it is removed. Unsubscribes
+// only block campaign sends.
+type Suppression struct {
+	Id        string     `json:"id" gorm:"column:id;primaryKey"`
+	Email     string     `json:"email" gorm:"column:email"`
+	Reason    string     `json:"reason" gorm:"column:reason"`
+	Source    string     `json:"source" gorm:"column:source"`
+	Detail    string     `json:"detail,omitempty" gorm:"column:detail"`
+	MessageId string     `json:"message_id,omitempty" gorm:"column:message_id"`
+	CreatedBy *string    `json:"created_by,omitempty" gorm:"column:created_by"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" gorm:"column:updated_at"`
+}
@@ -0,0 +1,52 @@
+package domainemaildelivery
+
+const (
+	StatusSent         = "sent"
+	StatusDeferred     = "deferred"
+	StatusSoftBounced  = "soft_bounced"
+	StatusDelivered    = "delivered"
+	StatusOpened       = "opened"
+	StatusClicked      = "clicked"
+	StatusUnsubscribed = "unsubscribed"
+	StatusBounced      = "bounced"
+	StatusBlocked      = "blocked"
+	StatusFailed       = "failed"
+	StatusComplained   = "complained"
+)
+
+const (
+	SuppressionHardBounce = "hard_bounce"
+	SuppressionComplaint  = "complaint"
+	SuppressionManual     = "manual"
+	// SuppressionUnsubscribe only blocks campaign sends; transactional mail
+	// still reaches the address.
+	SuppressionUnsubscribe = "unsubscribe"
+)
+
+// EmailTypeCampaign is the only send type unsubscribes apply to.
+const EmailTypeCampaign = "campaign"
+
+// statusRank orders statuses so late or replayed webhook events never move a
+// delivery backwards, e.g. a delayed "delivered" after "opened".
+var statusRank = map[string]int{
+	StatusSent:         1,
+	StatusDeferred:     2,
+	StatusSoftBounced:  2,
+	StatusDelivered:    3,
+	StatusOpened:       4,
+	StatusClicked:      5,
+	StatusUnsubscribed: 6,
+	StatusBounced:      7,
+	StatusBlocked:      7,
+	StatusFailed:       7,
+	StatusComplained:   8,
+}
+
+// CanTransition reports whether a delivery in status current may move to next.
+func CanTransition(current, next string) bool {
+	nextRank, ok := statusRank[next]
+	if !ok {
+		return false
+	}
+	return nextRank >= statusRank[current]
+}
@@ -0,0 +1,27 @@
+package domainemailtemplate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (EmailTemplate) TableName() string {
+	return "email_templates"
+}
+
+type EmailTemplate struct {
+	Id        string          `json:"id" gorm:"column:id;primaryKey"`
+	Key       string          `json:"key" gorm:"column:template_key"`
+	Locale    string          `json:"locale" gorm:"column:locale"`
+	Subject   string          `json:"subject" gorm:"column:subject"`
+	TextBody  string          `json:"text_body,omitempty" gorm:"column:text_body"`
+	HTMLBody  string          `json:"html_body,omitempty" gorm:"column:html_body"`
+	Version   int             `json:"version" gorm:"column:version"`
+	Schema    *VariableSchema `json:"variable_schema,omitempty" gorm:"column:variable_schema;serializer:json"`
+	IsActive  bool            `json:"is_active" gorm:"column:is_active"`
+	CreatedBy *string         `json:"created_by,omitempty" gorm:"column:created_by"`
+	CreatedAt time.Time       `json:"created_at,omitempty" gorm:"column:created_at"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt  `json:"-" gorm:"index"`
+}
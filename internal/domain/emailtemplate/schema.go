@@ -0,0 +1,33 @@
+package domainemailtemplate
+
+const (
+	VariableTypeString  = "string"
+	VariableTypeNumber  = "number"
+	VariableTypeBoolean = "boolean"
+	VariableTypeURL     = "url"
+	VariableTypeEmail   = "email"
+)
+
+// VariableSchema declares the template_data a template accepts. In strict
+// mode variables that are not declared are rejected.
+type VariableSchema struct {
+	Strict    bool           `json:"strict"`
+	Variables []VariableRule `json:"variables"`
+}
+
+// VariableRule constrains one variable. MaxLength counts characters, or for
+// numbers the characters of the number as printed.
+type VariableRule struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Required  bool   `json:"required"`
+	MaxLength int    `json:"max_length,omitempty"`
+}
+
+func IsValidVariableType(t string) bool {
+	switch t {
+	case VariableTypeString, VariableTypeNumber, VariableTypeBoolean, VariableTypeURL, VariableTypeEmail:
+		return true
+	}
+	return false
+}
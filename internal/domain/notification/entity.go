@@ -0,0 +1,39 @@
+package domainnotification
+
+import "time"
+
+// Event types pushed to a user's live stream.
+const (
+	EventCreated     = "notification"
+	EventRead        = "read"
+	EventUnread      = "unread"
+	EventReadAll     = "read_all"
+	EventUnreadCount = "unread_count"
+)
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// Notification is one entry of a user's in-app inbox. It is unread until
+// ReadAt is set.
+type Notification struct {
+	Id        string     `json:"id" gorm:"column:id;primaryKey"`
+	UserId    string     `json:"user_id" gorm:"column:user_id"`
+	Title     string     `json:"title" gorm:"column:title"`
+	Body      string     `json:"body,omitempty" gorm:"column:body"`
+	Link      string     `json:"link,omitempty" gorm:"column:link"`
+	Category  string     `json:"category" gorm:"column:category"`
+	ReadAt    *time.Time `json:"read_at" gorm:"column:read_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// Event is a change to a user's inbox, delivered to every open stream of that
+// user.
+type Event struct {
+	Type         string        `json:"type"`
+	UserId       string        `json:"user_id"`
+	Notification *Notification `json:"notification,omitempty"`
+	Id           string        `json:"id,omitempty"`
+	Unread       *int64        `json:"unread,omitempty"`
+}
@@ -0,0 +1,70 @@
+package domainnotify
+
+// Channels a notification can be routed to.
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelInApp   = "in_app"
+	ChannelPush    = "push"
+	ChannelWebhook = "webhook"
+)
+
+// Rule modes. ModeAll delivers on every channel of the rule; ModeFirst walks
+// the channels in order and stops at the first one that delivers.
+const (
+	ModeAll   = "all"
+	ModeFirst = "first"
+)
+
+// Per-channel outcomes reported to the caller.
+const (
+	StatusSent    = "sent"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+	// StatusNotAttempted marks channels left alone because an earlier one
+	// delivered in ModeFirst.
+	StatusNotAttempted = "not_attempted"
+)
+
+// Rule says how one notification key is delivered. Channel templates are Go
+// text/templates rendered with the request data plus .Recipient.
+type Rule struct {
+	Key      string       `json:"key"`
+	Category string       `json:"category"`
+	Mode     string       `json:"mode"`
+	Channels []string     `json:"channels"`
+	Email    *EmailRule   `json:"email,omitempty"`
+	SMS      *SMSRule     `json:"sms,omitempty"`
+	InApp    *InAppRule   `json:"in_app,omitempty"`
+	Push     *PushRule    `json:"push,omitempty"`
+	Webhook  *WebhookRule `json:"webhook,omitempty"`
+}
+
+// EmailRule points at a managed email template, which is localized and
+// validated like any other template send.
+type EmailRule struct {
+	TemplateKey string `json:"template_key"`
+	Sender      string `json:"sender,omitempty"`
+}
+
+type SMSRule struct {
+	Text string `json:"text"`
+}
+
+type InAppRule struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	Link  string `json:"link,omitempty"`
+}
+
+// PushRule is the Web Push message shown by the browser.
+type PushRule struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	Link  string `json:"link,omitempty"`
+}
+
+// WebhookRule names the event delivered to the app's webhook subscribers.
+type WebhookRule struct {
+	Event string `json:"event"`
+}
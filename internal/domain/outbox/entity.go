@@ -0,0 +1,64 @@
+package domainoutbox
+
+import "time"
+
+func (Event) TableName() string {
+	return "outbox"
+}
+
+// Event is a domain event written in the same transaction as the change it
+// describes. The relay hands it to every consumer once the transaction has
+// committed, so an event is never published for a rolled back change nor
+// lost when the process dies after the commit.
+type Event struct {
+	Id            string                 `json:"id" gorm:"column:id;primaryKey"`
+	Type          string                 `json:"type" gorm:"column:event_type"`
+	AggregateType string                 `json:"aggregate_type" gorm:"column:aggregate_type"`
+	AggregateId   string                 `json:"aggregate_id" gorm:"column:aggregate_id"`
+	Payload       map[string]interface{} `json:"payload" gorm:"column:payload;serializer:json"`
+	CreatedAt     time.Time              `json:"created_at" gorm:"column:created_at"`
+}
+
+// NewEvent describes a change to an aggregate. The id is assigned when the
+// event is written.
+func NewEvent(eventType, aggregateType, aggregateId string, payload map[string]interface{}) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateId:   aggregateId,
+		Payload:       payload,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (Consumer) TableName() string {
+	return "outbox_consumers"
+}
+
+// Consumer is a registered relay target. It only receives events created
+// after it was first registered, so adding one does not replay the backlog.
+// ClaimedBy and ClaimedUntil name the relay working through its events.
+type Consumer struct {
+	Name         string     `gorm:"column:name;primaryKey"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	ClaimedBy    *string    `gorm:"column:claimed_by"`
+	ClaimedUntil *time.Time `gorm:"column:claimed_until"`
+}
+
+func (Processed) TableName() string {
+	return "outbox_processed"
+}
+
+// Processed marks an event as handled by one consumer.
+type Processed struct {
+	Consumer    string    `gorm:"column:consumer;primaryKey"`
+	EventId     string    `gorm:"column:event_id;primaryKey"`
+	ProcessedAt time.Time `gorm:"column:processed_at"`
+}
+
+// Aggregate types of the events written to the outbox.
+const (
+	AggregateUser       = "user"
+	AggregateRole       = "role"
+	AggregatePermission = "permission"
+)
@@ -0,0 +1,16 @@
+package domainpasswordhistory
+
+import "time"
+
+func (Entry) TableName() string {
+	return "password_histories"
+}
+
+// Entry is the bcrypt hash of a password a user has set, kept so it cannot
+// be set again while it is among their most recent ones.
+type Entry struct {
+	Id           string    `json:"id" gorm:"column:id;primaryKey"`
+	UserId       string    `json:"user_id" gorm:"column:user_id"`
+	PasswordHash string    `json:"-" gorm:"column:password_hash"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at"`
+}
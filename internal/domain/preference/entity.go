@@ -0,0 +1,42 @@
+package domainpreference
+
+import "time"
+
+// Notification categories. Security notices such as OTP codes, password
+// resets and sign-in alerts are mandatory and always delivered.
+const (
+	CategorySecurity  = "security"
+	CategoryProduct   = "product"
+	CategoryMarketing = "marketing"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+var (
+	Categories = []string{CategorySecurity, CategoryProduct, CategoryMarketing}
+	Channels   = []string{ChannelEmail, ChannelSMS, ChannelPush}
+)
+
+// Locked reports whether users cannot opt out of category.
+func Locked(category string) bool {
+	return category == CategorySecurity
+}
+
+func (Preference) TableName() string {
+	return "notification_preferences"
+}
+
+// Preference is a user's choice for one category on one channel. Users are
+// opted in to everything until they store a preference saying otherwise.
+type Preference struct {
+	UserId    string     `json:"user_id" gorm:"column:user_id;primaryKey"`
+	Category  string     `json:"category" gorm:"column:category;primaryKey"`
+	Channel   string     `json:"channel" gorm:"column:channel;primaryKey"`
+	Enabled   bool       `json:"enabled" gorm:"column:enabled"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" gorm:"column:updated_at"`
+}
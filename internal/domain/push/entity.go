@@ -0,0 +1,24 @@
+package domainpush
+
+import "time"
+
+func (Subscription) TableName() string {
+	return "push_subscriptions"
+}
+
+// Subscription is one browser's Web Push registration. The endpoint is unique
+// to a browser profile, so registering it again moves it to the current user
+// and login. TokenId is the JWT ID of the login that registered it.
+type Subscription struct {
+	Id            string     `json:"id" gorm:"column:id;primaryKey"`
+	UserId        string     `json:"user_id" gorm:"column:user_id"`
+	Endpoint      string     `json:"endpoint" gorm:"column:endpoint"`
+	P256dh        string     `json:"-" gorm:"column:p256dh"`
+	Auth          string     `json:"-" gorm:"column:auth"`
+	TokenId       string     `json:"-" gorm:"column:token_id"`
+	UserAgent     string     `json:"user_agent,omitempty" gorm:"column:user_agent"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty" gorm:"column:last_success_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty" gorm:"column:updated_at"`
+}
@@ -0,0 +1,52 @@
+package domainsecurityalert
+
+import "time"
+
+// Alert types, one per account change users are told about.
+const (
+	TypePasswordChanged = "password_changed"
+	TypePasswordReset   = "password_reset"
+	TypeEmailChanged    = "email_changed"
+	TypeRoleChanged     = "role_changed"
+	TypeAccountDeleted  = "account_deleted"
+	TypeNewDevice       = "new_device"
+)
+
+func (Alert) TableName() string {
+	return "security_alerts"
+}
+
+// Alert is a security notice sent to Email, the address the user had when
+// the change happened. When it carries a lock token, following the "it
+// wasn't me" link with it locks the account once, before LockExpiresAt.
+// EventId is the outbox event the alert was raised for, if any.
+type Alert struct {
+	Id            string                 `json:"id" gorm:"column:id;primaryKey"`
+	UserId        string                 `json:"user_id" gorm:"column:user_id"`
+	EventId       *string                `json:"event_id,omitempty" gorm:"column:event_id"`
+	Type          string                 `json:"type" gorm:"column:type"`
+	Email         string                 `json:"email" gorm:"column:email"`
+	AppName       string                 `json:"app_name,omitempty" gorm:"column:app_name"`
+	LockTokenHash *string                `json:"-" gorm:"column:lock_token_hash"`
+	LockExpiresAt *time.Time             `json:"lock_expires_at,omitempty" gorm:"column:lock_expires_at"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty" gorm:"column:metadata;serializer:json"`
+	SentAt        *time.Time             `json:"sent_at,omitempty" gorm:"column:sent_at"`
+	UsedAt        *time.Time             `json:"used_at,omitempty" gorm:"column:used_at"`
+	CreatedAt     time.Time              `json:"created_at" gorm:"column:created_at"`
+}
+
+func (Device) TableName() string {
+	return "user_devices"
+}
+
+// Device is a browser or app a user has logged in from, told apart by a
+// fingerprint of its User-Agent.
+type Device struct {
+	Id          string    `json:"id" gorm:"column:id;primaryKey"`
+	UserId      string    `json:"user_id" gorm:"column:user_id"`
+	Fingerprint string    `json:"-" gorm:"column:fingerprint"`
+	UserAgent   string    `json:"user_agent,omitempty" gorm:"column:user_agent"`
+	IP          string    `json:"ip,omitempty" gorm:"column:ip"`
+	FirstSeenAt time.Time `json:"first_seen_at" gorm:"column:first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at" gorm:"column:last_seen_at"`
+}
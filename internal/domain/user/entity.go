@@ -11,14 +11,20 @@ func (Users) TableName() string {
 }
 
 type Users struct {
-	Id        string         `json:"id" gorm:"column:id;primaryKey"`
-	Name      string         `json:"name" gorm:"column:name"`
-	Email     string         `json:"email,omitempty" gorm:"column:email"`
-	Phone     string         `json:"phone,omitempty" gorm:"column:phone"`
-	Password  string         `json:"-" gorm:"column:password"`
-	Role      string         `json:"role,omitempty" gorm:"column:role"`
-	RoleId    *string        `json:"role_id,omitempty" gorm:"column:role_id"`
-	CreatedAt time.Time      `json:"created_at,omitempty" gorm:"column:created_at"`
-	UpdatedAt *time.Time     `json:"updated_at,omitempty" gorm:"column:updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Id              string                 `json:"id" gorm:"column:id;primaryKey"`
+	Name            string                 `json:"name" gorm:"column:name"`
+	Email           string                 `json:"email,omitempty" gorm:"column:email"`
+	Phone           string                 `json:"phone,omitempty" gorm:"column:phone"`
+	Password        string                 `json:"-" gorm:"column:password"`
+	Role            string                 `json:"role,omitempty" gorm:"column:role"`
+	RoleId          *string                `json:"role_id,omitempty" gorm:"column:role_id"`
+	Locale          string                 `json:"locale,omitempty" gorm:"column:locale"`
+	EmailVerifiedAt *time.Time             `json:"email_verified_at,omitempty" gorm:"column:email_verified_at"`
+	Attributes      map[string]interface{} `json:"attributes,omitempty" gorm:"column:attributes;serializer:json"`
+	LockedAt        *time.Time             `json:"locked_at,omitempty" gorm:"column:locked_at"`
+	// PasswordChangedAt is when the password was last set.
+	PasswordChangedAt *time.Time     `json:"password_changed_at,omitempty" gorm:"column:password_changed_at"`
+	CreatedAt         time.Time      `json:"created_at,omitempty" gorm:"column:created_at"`
+	UpdatedAt         *time.Time     `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 }
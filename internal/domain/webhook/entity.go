@@ -0,0 +1,92 @@
+package domainwebhook
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (Endpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// Endpoint is a subscriber URL. Events lists the event types it receives;
+// Wildcard subscribes it to all of them. Secret signs every delivery and is
+// only shown when the endpoint is created or the secret is rotated.
+type Endpoint struct {
+	Id          string         `json:"id" gorm:"column:id;primaryKey"`
+	Name        string         `json:"name" gorm:"column:name"`
+	URL         string         `json:"url" gorm:"column:url"`
+	Secret      string         `json:"-" gorm:"column:secret"`
+	Events      []string       `json:"events" gorm:"column:events;serializer:json"`
+	Description string         `json:"description,omitempty" gorm:"column:description"`
+	IsActive    bool           `json:"is_active" gorm:"column:is_active"`
+	CreatedBy   *string        `json:"created_by,omitempty" gorm:"column:created_by"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Subscribes reports whether the endpoint receives eventType.
+func (e Endpoint) Subscribes(eventType string) bool {
+	for _, ev := range e.Events {
+		if ev == Wildcard || ev == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Delivery is one event queued for one endpoint. While it is pending or
+// retrying, NextAttemptAt is when the worker may pick it up; a worker holding
+// it pushes NextAttemptAt forward as its lease.
+type Delivery struct {
+	Id             string                 `json:"id" gorm:"column:id;primaryKey"`
+	EndpointId     string                 `json:"endpoint_id" gorm:"column:endpoint_id"`
+	EventId        string                 `json:"event_id" gorm:"column:event_id"`
+	EventType      string                 `json:"event_type" gorm:"column:event_type"`
+	OccurredAt     time.Time              `json:"occurred_at" gorm:"column:occurred_at"`
+	Data           map[string]interface{} `json:"data" gorm:"column:data;serializer:json"`
+	Status         string                 `json:"status" gorm:"column:status"`
+	Attempts       int                    `json:"attempts" gorm:"column:attempts"`
+	NextAttemptAt  *time.Time             `json:"next_attempt_at,omitempty" gorm:"column:next_attempt_at"`
+	LastStatusCode *int                   `json:"last_status_code,omitempty" gorm:"column:last_status_code"`
+	LastError      string                 `json:"last_error,omitempty" gorm:"column:last_error"`
+	DeliveredAt    *time.Time             `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+	CreatedAt      time.Time              `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt      *time.Time             `json:"updated_at,omitempty" gorm:"column:updated_at"`
+	AttemptLog     []Attempt              `json:"attempt_log,omitempty" gorm:"-"`
+}
+
+// Envelope is the JSON body posted to the endpoint.
+func (d Delivery) Envelope() Envelope {
+	return Envelope{Id: d.EventId, Type: d.EventType, OccurredAt: d.OccurredAt.UTC(), Data: d.Data}
+}
+
+func (Attempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
+
+// Attempt records one HTTP request made for a delivery. StatusCode is nil
+// when no response was received.
+type Attempt struct {
+	Id           string    `json:"id" gorm:"column:id;primaryKey"`
+	DeliveryId   string    `json:"delivery_id" gorm:"column:delivery_id"`
+	Attempt      int       `json:"attempt" gorm:"column:attempt"`
+	StatusCode   *int      `json:"status_code,omitempty" gorm:"column:status_code"`
+	Error        string    `json:"error,omitempty" gorm:"column:error"`
+	ResponseBody string    `json:"response_body,omitempty" gorm:"column:response_body"`
+	DurationMs   int64     `json:"duration_ms" gorm:"column:duration_ms"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+type Envelope struct {
+	Id         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
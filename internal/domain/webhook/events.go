@@ -0,0 +1,79 @@
+package domainwebhook
+
+const (
+	StatusPending   = "pending"
+	StatusRetrying  = "retrying"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Wildcard in an endpoint's event list subscribes it to every event.
+const Wildcard = "*"
+
+// NotifyEventPrefix starts the events raised by notification rules with a
+// webhook channel. Their names come from the rules, so any event with this
+// prefix may be subscribed to.
+const NotifyEventPrefix = "notify."
+
+const (
+	EventUserRegistered      = "user.registered"
+	EventUserCreated         = "user.created"
+	EventUserRoleChanged     = "user.role_changed"
+	EventUserPasswordChanged = "user.password_changed"
+	EventUserEmailChanged    = "user.email_changed"
+	EventUserLocked          = "user.locked"
+	EventUserDeleted         = "user.deleted"
+
+	EventOTPSent     = "otp.sent"
+	EventOTPVerified = "otp.verified"
+
+	EventResetRequested = "password.reset.requested"
+	EventResetVerified  = "password.reset.verified"
+	EventResetCompleted = "password.reset.completed"
+
+	EventSessionRevoked = "session.revoked"
+
+	EventRoleCreated            = "role.created"
+	EventRoleUpdated            = "role.updated"
+	EventRoleDeleted            = "role.deleted"
+	EventRolePermissionsChanged = "role.permissions_changed"
+	EventRoleMenusChanged       = "role.menus_changed"
+
+	EventPermissionCreated = "permission.created"
+	EventPermissionUpdated = "permission.updated"
+	EventPermissionDeleted = "permission.deleted"
+)
+
+// Events lists every event type an endpoint can subscribe to.
+var Events = []string{
+	EventUserRegistered,
+	EventUserCreated,
+	EventUserRoleChanged,
+	EventUserPasswordChanged,
+	EventUserEmailChanged,
+	EventUserLocked,
+	EventUserDeleted,
+	EventOTPSent,
+	EventOTPVerified,
+	EventResetRequested,
+	EventResetVerified,
+	EventResetCompleted,
+	EventSessionRevoked,
+	EventRoleCreated,
+	EventRoleUpdated,
+	EventRoleDeleted,
+	EventRolePermissionsChanged,
+	EventRoleMenusChanged,
+	EventPermissionCreated,
+	EventPermissionUpdated,
+	EventPermissionDeleted,
+}
+
+// LegacyEvents maps the names reset events had before they moved under
+// "password.reset." to their current names. Endpoints may still be created
+// with them; they are stored under the current name.
+var LegacyEvents = map[string]string{
+	"password_reset.requested": EventResetRequested,
+	"password_reset.verified":  EventResetVerified,
+	"user.password_reset":      EventResetCompleted,
+}
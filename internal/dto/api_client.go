@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+)
+
+type ApiClientCreate struct {
+	Name             string   `json:"name" binding:"required,min=3,max=100"`
+	Description      string   `json:"description" binding:"omitempty,max=500"`
+	AllowedEndpoints []string `json:"allowed_endpoints" binding:"required,min=1,dive,oneof=* email.send reset.email otp.send otp.verify inbox.create notify.send"`
+	AllowedApps      []string `json:"allowed_apps" binding:"omitempty,dive,required,max=100"`
+	RequireSignature bool     `json:"require_signature"`
+	BurstPerMinute   int      `json:"burst_per_minute" binding:"omitempty,min=0"`
+	DailyQuota       int      `json:"daily_quota" binding:"omitempty,min=0"`
+	MonthlyQuota     int      `json:"monthly_quota" binding:"omitempty,min=0"`
+}
+
+type ApiClientUpdate struct {
+	Name             string   `json:"name" binding:"omitempty,min=3,max=100"`
+	Description      *string  `json:"description" binding:"omitempty,max=500"`
+	AllowedEndpoints []string `json:"allowed_endpoints" binding:"omitempty,min=1,dive,oneof=* email.send reset.email otp.send otp.verify inbox.create notify.send"`
+	AllowedApps      []string `json:"allowed_apps" binding:"omitempty,dive,required,max=100"`
+	RequireSignature *bool    `json:"require_signature"`
+	IsActive         *bool    `json:"is_active"`
+	BurstPerMinute   *int     `json:"burst_per_minute" binding:"omitempty,min=0"`
+	DailyQuota       *int     `json:"daily_quota" binding:"omitempty,min=0"`
+	MonthlyQuota     *int     `json:"monthly_quota" binding:"omitempty,min=0"`
+}
+
+type ApiClientRotate struct {
+	// GraceSeconds keeps the current key valid for this long after rotation.
+	// Zero revokes it immediately; nil uses the configured default.
+	GraceSeconds *int `json:"grace_seconds" binding:"omitempty,min=0,max=2592000"`
+}
+
+// ApiClientKey is returned once when a key is issued or rotated. The key is
+// not stored and cannot be retrieved again; neither can the signing secret,
+// which signs requests and must never be sent with one.
+type ApiClientKey struct {
+	Client        domainapiclient.ApiClient `json:"client"`
+	ApiKey        string                    `json:"api_key"`
+	SigningSecret string                    `json:"signing_secret"`
+}
+
+// QuotaUsage is a client's consumption in one quota window. A Limit of 0 means
+// the window is metered but not limited.
+type QuotaUsage struct {
+	Window    string    `json:"window"`
+	Used      int       `json:"used"`
+	Limit     int       `json:"limit"`
+	Remaining *int      `json:"remaining,omitempty"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+type ApiClientUsage struct {
+	ClientId string       `json:"client_id"`
+	Windows  []QuotaUsage `json:"windows"`
+}
+
+// QuotaCheck is the outcome of reserving quota for one request. Tightest is
+// the window that refused it or, when allowed, the limited window with the
+// least room left; it is nil when no window is limited.
+type QuotaCheck struct {
+	Allowed   bool
+	Tightest  *QuotaUsage
+	CheckedAt time.Time
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+)
+
+type CampaignSegment struct {
+	Roles       []string               `json:"roles" binding:"omitempty,max=20,dive,required,max=50"`
+	CreatedFrom *time.Time             `json:"created_from"`
+	CreatedTo   *time.Time             `json:"created_to"`
+	Verified    *bool                  `json:"verified"`
+	Attributes  map[string]interface{} `json:"attributes" binding:"omitempty,max=20"`
+}
+
+type CampaignCreate struct {
+	Name          string                 `json:"name" binding:"required,min=3,max=150"`
+	AppName       string                 `json:"app_name" binding:"omitempty,max=100"`
+	Sender        string                 `json:"sender" binding:"omitempty,max=100"`
+	Subject       string                 `json:"subject" binding:"omitempty,max=200"`
+	TemplateKey   string                 `json:"template_key" binding:"omitempty,max=100"`
+	TemplateData  map[string]interface{} `json:"template_data" binding:"omitempty"`
+	TextBody      string                 `json:"text_body" binding:"omitempty,max=10000"`
+	HTMLBody      string                 `json:"html_body" binding:"omitempty,max=50000"`
+	MarkdownBody  string                 `json:"markdown_body" binding:"omitempty,max=50000"`
+	Segment       CampaignSegment        `json:"segment"`
+	RatePerMinute int                    `json:"rate_per_minute" binding:"omitempty,min=1"`
+}
+
+// CampaignUpdate edits a draft campaign. Nil fields are left unchanged.
+type CampaignUpdate struct {
+	Name          string                 `json:"name" binding:"omitempty,min=3,max=150"`
+	AppName       *string                `json:"app_name" binding:"omitempty,max=100"`
+	Sender        *string                `json:"sender" binding:"omitempty,max=100"`
+	Subject       *string                `json:"subject" binding:"omitempty,max=200"`
+	TemplateKey   *string                `json:"template_key" binding:"omitempty,max=100"`
+	TemplateData  map[string]interface{} `json:"template_data" binding:"omitempty"`
+	TextBody      *string                `json:"text_body" binding:"omitempty,max=10000"`
+	HTMLBody      *string                `json:"html_body" binding:"omitempty,max=50000"`
+	MarkdownBody  *string                `json:"markdown_body" binding:"omitempty,max=50000"`
+	Segment       *CampaignSegment       `json:"segment"`
+	RatePerMinute *int                   `json:"rate_per_minute" binding:"omitempty,min=1"`
+}
+
+// SegmentPreview is the size of a segment at the time it was counted.
+type SegmentPreview struct {
+	Count int64 `json:"count"`
+}
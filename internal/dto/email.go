@@ -1,13 +1,53 @@
 package dto
 
+import "time"
+
 type SendEmailRequest struct {
-	Type           string                 `json:"type" binding:"required,oneof=campaign info notification"`
+	Type           string                 `json:"type" binding:"required,oneof=campaign info notification calendar"`
+	Category       string                 `json:"category" binding:"omitempty,oneof=product marketing"`
 	To             []string               `json:"to" binding:"required,min=1,dive,email"`
+	Cc             []string               `json:"cc" binding:"omitempty,dive,email"`
+	Bcc            []string               `json:"bcc" binding:"omitempty,dive,email"`
+	Sender         string                 `json:"sender" binding:"omitempty,max=100"`
 	Subject        string                 `json:"subject" binding:"omitempty,max=200"`
 	TextBody       string                 `json:"text_body" binding:"omitempty,max=10000"`
 	HTMLBody       string                 `json:"html_body" binding:"omitempty,max=50000"`
+	MarkdownBody   string                 `json:"markdown_body" binding:"omitempty,max=50000"`
 	ReplyTo        string                 `json:"reply_to" binding:"omitempty,email"`
 	IdempotencyKey string                 `json:"idempotency_key" binding:"omitempty,max=100"`
+	PrivateTo      bool                   `json:"private_to"`
 	TemplateKey    string                 `json:"template_key" binding:"omitempty,max=100"`
 	TemplateData   map[string]interface{} `json:"template_data" binding:"omitempty"`
+	Locale         string                 `json:"locale" binding:"omitempty,min=2,max=10"`
+	Event          *CalendarEvent         `json:"event" binding:"required_if=Type calendar,omitempty"`
+	// AcceptLocale comes from the Accept-Language header and is only used
+	// when neither Locale nor the recipient's profile sets one.
+	AcceptLocale string `json:"-"`
+}
+
+type CalendarEvent struct {
+	UID         string             `json:"uid" binding:"required,max=255"`
+	Method      string             `json:"method" binding:"omitempty,oneof=REQUEST CANCEL"`
+	Sequence    int                `json:"sequence" binding:"omitempty,gte=0"`
+	Summary     string             `json:"summary" binding:"required,max=255"`
+	Description string             `json:"description" binding:"omitempty,max=5000"`
+	Location    string             `json:"location" binding:"omitempty,max=500"`
+	URL         string             `json:"url" binding:"omitempty,url,max=2048"`
+	Start       time.Time          `json:"start" binding:"required"`
+	End         time.Time          `json:"end" binding:"required,gtfield=Start"`
+	Timezone    string             `json:"timezone" binding:"omitempty,max=64"`
+	Organizer   *CalendarParty     `json:"organizer" binding:"omitempty"`
+	Attendees   []CalendarAttendee `json:"attendees" binding:"omitempty,max=100,dive"`
+}
+
+type CalendarParty struct {
+	Name  string `json:"name" binding:"omitempty,max=100"`
+	Email string `json:"email" binding:"required,email"`
+}
+
+type CalendarAttendee struct {
+	Name  string `json:"name" binding:"omitempty,max=100"`
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=CHAIR REQ-PARTICIPANT OPT-PARTICIPANT NON-PARTICIPANT"`
+	RSVP  *bool  `json:"rsvp"`
 }
@@ -0,0 +1,13 @@
+package dto
+
+type SuppressionCreate struct {
+	Email  string `json:"email" binding:"required,email"`
+	Reason string `json:"reason" binding:"omitempty,oneof=manual unsubscribe"`
+	Detail string `json:"detail" binding:"omitempty,max=500"`
+}
+
+type WebhookResult struct {
+	Received   int `json:"received"`
+	Updated    int `json:"updated"`
+	Suppressed int `json:"suppressed"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+type EmailTemplateCreate struct {
+	Key            string                       `json:"key" binding:"required,min=3,max=100"`
+	Locale         string                       `json:"locale" binding:"omitempty,min=2,max=10"`
+	Subject        string                       `json:"subject" binding:"required,max=200"`
+	TextBody       string                       `json:"text_body" binding:"omitempty,max=10000"`
+	HTMLBody       string                       `json:"html_body" binding:"omitempty,max=50000"`
+	VariableSchema *EmailTemplateVariableSchema `json:"variable_schema" binding:"omitempty"`
+}
+
+type EmailTemplateUpdate struct {
+	Locale         string                       `json:"locale" binding:"omitempty,min=2,max=10"`
+	Subject        string                       `json:"subject" binding:"omitempty,max=200"`
+	TextBody       string                       `json:"text_body" binding:"omitempty,max=10000"`
+	HTMLBody       string                       `json:"html_body" binding:"omitempty,max=50000"`
+	VariableSchema *EmailTemplateVariableSchema `json:"variable_schema" binding:"omitempty"`
+}
+
+type EmailTemplateVariableSchema struct {
+	Strict    bool                    `json:"strict"`
+	Variables []EmailTemplateVariable `json:"variables" binding:"omitempty,max=100,dive"`
+}
+
+type EmailTemplateVariable struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Type      string `json:"type" binding:"required,oneof=string number boolean url email"`
+	Required  bool   `json:"required"`
+	MaxLength int    `json:"max_length" binding:"omitempty,gte=1"`
+}
+
+type EmailTemplateRollback struct {
+	Locale  string `json:"locale" binding:"omitempty,min=2,max=10"`
+	Version int    `json:"version" binding:"required,gte=1"`
+}
+
+type EmailTemplatePreviewRequest struct {
+	Locale       string                 `json:"locale" binding:"omitempty,min=2,max=10"`
+	Subject      string                 `json:"subject" binding:"omitempty,max=200"`
+	TemplateData map[string]interface{} `json:"template_data" binding:"omitempty"`
+	// AcceptLocale comes from the Accept-Language header and is only used
+	// when neither Locale nor the recipient's profile sets one.
+	AcceptLocale string `json:"-"`
+}
+
+type EmailTemplatePreview struct {
+	Subject  string   `json:"subject"`
+	TextBody string   `json:"text_body"`
+	HTMLBody string   `json:"html_body"`
+	Warnings []string `json:"warnings"`
+}
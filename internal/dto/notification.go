@@ -0,0 +1,19 @@
+package dto
+
+// NotificationCreate targets either one user or every user holding a role.
+type NotificationCreate struct {
+	UserId   string `json:"user_id" binding:"required_without=Role,excluded_with=Role,omitempty,uuid"`
+	Role     string `json:"role" binding:"required_without=UserId,omitempty,max=50"`
+	Title    string `json:"title" binding:"required,max=200"`
+	Body     string `json:"body" binding:"omitempty,max=5000"`
+	Link     string `json:"link" binding:"omitempty,url,max=2048"`
+	Category string `json:"category" binding:"omitempty,oneof=security product marketing"`
+}
+
+type NotificationCreated struct {
+	Created int64 `json:"created"`
+}
+
+type UnreadCount struct {
+	Unread int64 `json:"unread"`
+}
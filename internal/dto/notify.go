@@ -0,0 +1,30 @@
+package dto
+
+// NotifyRequest targets a registered user, whose contact details, locale and
+// preferences are looked up, or a bare contact.
+type NotifyRequest struct {
+	UserId   string                 `json:"user_id" binding:"required_without=Contact,excluded_with=Contact,omitempty,uuid"`
+	Contact  *NotifyContact         `json:"contact" binding:"required_without=UserId,omitempty"`
+	Key      string                 `json:"key" binding:"required,max=100"`
+	Data     map[string]interface{} `json:"data" binding:"omitempty"`
+	Channels []string               `json:"channels" binding:"omitempty,max=5,dive,oneof=email sms in_app push webhook"`
+	Locale   string                 `json:"locale" binding:"omitempty,min=2,max=10"`
+}
+
+type NotifyContact struct {
+	Name  string `json:"name" binding:"omitempty,max=100"`
+	Email string `json:"email" binding:"required_without=Phone,omitempty,email"`
+	Phone string `json:"phone" binding:"required_without=Email,omitempty,e164"`
+}
+
+type NotifyResult struct {
+	Key       string                `json:"key"`
+	Delivered bool                  `json:"delivered"`
+	Channels  []NotifyChannelResult `json:"channels"`
+}
+
+type NotifyChannelResult struct {
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+}
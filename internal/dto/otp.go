@@ -1,7 +1,8 @@
 package dto
 
 type OTPSendRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email  string `json:"email" binding:"required,email"`
+	Locale string `json:"locale" binding:"omitempty,min=2,max=10"`
 }
 
 type OTPVerifyRequest struct {
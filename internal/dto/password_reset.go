@@ -1,16 +1,30 @@
 package dto
 
 type PasswordResetRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email  string `json:"email" binding:"required,email"`
+	Locale string `json:"locale" binding:"omitempty,min=2,max=10"`
+	Mode   string `json:"mode" binding:"omitempty,oneof=link code"`
 }
 
 type PasswordResetVerifyRequest struct {
 	Token string `json:"token" binding:"required"`
 }
 
+type PasswordResetVerifyCodeRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type PasswordResetCompleteRequest struct {
+	Token       string `json:"token" binding:"required_without=Ticket"`
+	Ticket      string `json:"ticket" binding:"required_without=Token"`
+	NewPassword string `json:"new_password" binding:"required,max=256"`
+}
+
 type PasswordResetEmailRequest struct {
 	Email            string `json:"email" binding:"required,email"`
 	Token            string `json:"token" binding:"required"`
 	ResetURL         string `json:"reset_url" binding:"omitempty,url"`
 	ExpiresInMinutes int    `json:"expires_in_minutes" binding:"omitempty,gte=1,lte=1440"`
+	Locale           string `json:"locale" binding:"omitempty,min=2,max=10"`
 }
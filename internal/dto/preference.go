@@ -0,0 +1,20 @@
+package dto
+
+type PreferenceUpdate struct {
+	Preferences []PreferenceItem `json:"preferences" binding:"required,min=1,max=9,dive"`
+}
+
+type PreferenceItem struct {
+	Category string `json:"category" binding:"required,oneof=security product marketing"`
+	Channel  string `json:"channel" binding:"required,oneof=email sms push"`
+	Enabled  *bool  `json:"enabled" binding:"required"`
+}
+
+// PreferenceView is one cell of a user's category by channel matrix. Locked
+// cells cannot be turned off.
+type PreferenceView struct {
+	Category string `json:"category"`
+	Channel  string `json:"channel"`
+	Enabled  bool   `json:"enabled"`
+	Locked   bool   `json:"locked"`
+}
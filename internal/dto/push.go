@@ -0,0 +1,30 @@
+package dto
+
+// PushSubscriptionCreate is the browser's PushSubscription.toJSON().
+type PushSubscriptionCreate struct {
+	Endpoint       string   `json:"endpoint" binding:"required,url,max=2048"`
+	ExpirationTime *int64   `json:"expirationTime" binding:"omitempty"`
+	Keys           PushKeys `json:"keys" binding:"required"`
+}
+
+type PushKeys struct {
+	P256dh string `json:"p256dh" binding:"required,max=255"`
+	Auth   string `json:"auth" binding:"required,max=255"`
+}
+
+type PushSubscriptionDelete struct {
+	Endpoint string `json:"endpoint" binding:"required,url,max=2048"`
+}
+
+type VapidPublicKey struct {
+	PublicKey string `json:"public_key"`
+}
+
+// PushMessage is the JSON payload handed to the service worker's push event.
+type PushMessage struct {
+	Title string                 `json:"title"`
+	Body  string                 `json:"body,omitempty"`
+	URL   string                 `json:"url,omitempty"`
+	Tag   string                 `json:"tag,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
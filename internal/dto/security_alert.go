@@ -0,0 +1,5 @@
+package dto
+
+type SecurityLockRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -4,39 +4,50 @@ type UserRegister struct {
 	Name     string `json:"name" binding:"required,min=3,max=100"`
 	Email    string `json:"email" binding:"required,email"`
 	Phone    string `json:"phone" binding:"required,min=9,max=15"`
-	Password string `json:"password" binding:"required,min=8,max=64"`
+	Password string `json:"password" binding:"required,max=256"`
+	Locale   string `json:"locale" binding:"omitempty,min=2,max=10"`
 }
 
 type AdminCreateUser struct {
 	Name     string `json:"name" binding:"required,min=3,max=100"`
 	Email    string `json:"email" binding:"required,email"`
 	Phone    string `json:"phone" binding:"omitempty,min=9,max=15"`
-	Password string `json:"password" binding:"required,min=8,max=64"`
+	Password string `json:"password" binding:"required,max=256"`
 	Role     string `json:"role" binding:"required"`
 }
 
 type Login struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8,max=64"`
+	Password string `json:"password" binding:"required,max=256"`
 }
 
 type UserUpdate struct {
-	Name  string `json:"name" binding:"omitempty,min=3,max=100"`
-	Email string `json:"email" binding:"omitempty,email"`
-	Phone string `json:"phone" binding:"omitempty,min=9,max=15"`
-	Role  string `json:"role" binding:"omitempty"`
+	Name          string                 `json:"name" binding:"omitempty,min=3,max=100"`
+	Email         string                 `json:"email" binding:"omitempty,email"`
+	Phone         string                 `json:"phone" binding:"omitempty,min=9,max=15"`
+	Role          string                 `json:"role" binding:"omitempty"`
+	Locale        string                 `json:"locale" binding:"omitempty,min=2,max=10"`
+	EmailVerified *bool                  `json:"email_verified"`
+	Attributes    map[string]interface{} `json:"attributes" binding:"omitempty,max=50"`
 }
 
 type ChangePassword struct {
-	CurrentPassword string `json:"current_password" binding:"required,min=8,max=64"`
-	NewPassword     string `json:"new_password" binding:"required,min=8,max=64"`
+	CurrentPassword string `json:"current_password" binding:"required,max=256"`
+	NewPassword     string `json:"new_password" binding:"required,max=256"`
 }
 
-type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required,email"`
+// ChangeExpiredPassword sets a new password with the ticket returned by a
+// login whose password had expired.
+type ChangeExpiredPassword struct {
+	Ticket      string `json:"ticket" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,max=256"`
 }
 
-type ResetPasswordRequest struct {
-	Token       string `json:"token" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=8,max=64"`
+// PasswordCheck asks how a password fares against the policy of Role. Email
+// and Name, when given, are checked for in the password.
+type PasswordCheck struct {
+	Password string `json:"password" binding:"required,max=256"`
+	Email    string `json:"email" binding:"omitempty,max=255"`
+	Name     string `json:"name" binding:"omitempty,max=100"`
+	Role     string `json:"role" binding:"omitempty,max=50"`
 }
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	domainwebhook "service-sender/internal/domain/webhook"
+)
+
+type WebhookEndpointCreate struct {
+	Name        string   `json:"name" binding:"required,min=3,max=150"`
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	Events      []string `json:"events" binding:"required,min=1,dive,required,max=100"`
+	Description string   `json:"description" binding:"omitempty,max=500"`
+}
+
+// WebhookEndpointUpdate edits an endpoint. Nil fields are left unchanged.
+type WebhookEndpointUpdate struct {
+	Name        string   `json:"name" binding:"omitempty,min=3,max=150"`
+	URL         string   `json:"url" binding:"omitempty,url,max=2048"`
+	Events      []string `json:"events" binding:"omitempty,min=1,dive,required,max=100"`
+	Description *string  `json:"description" binding:"omitempty,max=500"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// WebhookEndpointSecret is returned once when an endpoint is created or its
+// secret is rotated. Subscribers verify X-Webhook-Signature with it.
+type WebhookEndpointSecret struct {
+	Endpoint domainwebhook.Endpoint `json:"endpoint"`
+	Secret   string                 `json:"secret"`
+}
@@ -0,0 +1,197 @@
+package handlerapiclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"service-sender/internal/dto"
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+	serviceapiclient "service-sender/internal/services/apiclient"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerApiClient struct {
+	Service interfaceapiclient.ServiceApiClientInterface
+}
+
+func NewApiClientHandler(s interfaceapiclient.ServiceApiClientInterface) *HandlerApiClient {
+	return &HandlerApiClient{Service: s}
+}
+
+func (h *HandlerApiClient) Create(ctx *gin.Context) {
+	var req dto.ApiClientCreate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][Create]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	authData := utils.GetAuthData(ctx)
+	data, err := h.Service.Create(req, utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "API client created successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data.Client)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerApiClient) GetByID(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][GetByID]"
+
+	data, err := h.Service.GetByID(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetByID; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get API client successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerApiClient) GetAll(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][GetAll]"
+
+	params, err := filter.GetBaseParams(ctx, "name", "asc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"is_active"})
+
+	data, total, err := h.Service.GetAll(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetAll; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerApiClient) Update(ctx *gin.Context) {
+	var req dto.ApiClientUpdate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][Update]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.Update(ctx.Param("id"), req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "API client updated successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerApiClient) Rotate(ctx *gin.Context) {
+	var req dto.ApiClientRotate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][Rotate]"
+
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	data, err := h.Service.Rotate(ctx.Param("id"), req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Rotate; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "API client key rotated successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerApiClient) GetUsage(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][GetUsage]"
+
+	data, err := h.Service.GetUsage(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetUsage; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get API client usage successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerApiClient) Delete(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ApiClientHandler][Delete]"
+
+	if err := h.Service.Delete(ctx.Param("id")); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Delete; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "API client deleted successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerApiClient) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "api client not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, serviceapiclient.ErrApiClientExists):
+		res := response.Response(http.StatusBadRequest, messages.MsgExists, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceapiclient.ErrUsageUnavailable):
+		res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: err.Error()}
+		ctx.JSON(http.StatusServiceUnavailable, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
@@ -0,0 +1,218 @@
+package handlercampaign
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	domaincampaign "service-sender/internal/domain/campaign"
+	"service-sender/internal/dto"
+	interfacecampaign "service-sender/internal/interfaces/campaign"
+	servicecampaign "service-sender/internal/services/campaign"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerCampaign struct {
+	Service interfacecampaign.ServiceCampaignInterface
+}
+
+func NewCampaignHandler(s interfacecampaign.ServiceCampaignInterface) *HandlerCampaign {
+	return &HandlerCampaign{Service: s}
+}
+
+func (h *HandlerCampaign) Create(ctx *gin.Context) {
+	var req dto.CampaignCreate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][Create]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	authData := utils.GetAuthData(ctx)
+	data, err := h.Service.Create(req, utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Campaign created successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerCampaign) GetByID(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][GetByID]"
+
+	data, err := h.Service.GetByID(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetByID; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get campaign successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerCampaign) GetAll(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][GetAll]"
+
+	params, err := filter.GetBaseParams(ctx, "created_at", "desc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"status", "app_name"})
+
+	data, total, err := h.Service.GetAll(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetAll; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerCampaign) Update(ctx *gin.Context) {
+	var req dto.CampaignUpdate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][Update]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.Update(ctx.Param("id"), req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Campaign updated successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerCampaign) Delete(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][Delete]"
+
+	if err := h.Service.Delete(ctx.Param("id")); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Delete; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Campaign deleted successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerCampaign) PreviewSegment(ctx *gin.Context) {
+	var req dto.CampaignSegment
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][PreviewSegment]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.PreviewSegment(req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.PreviewSegment; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Segment counted successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerCampaign) Start(ctx *gin.Context) {
+	h.control(ctx, "Start", h.Service.Start, "Campaign started successfully")
+}
+
+func (h *HandlerCampaign) Pause(ctx *gin.Context) {
+	h.control(ctx, "Pause", h.Service.Pause, "Campaign paused successfully")
+}
+
+func (h *HandlerCampaign) Resume(ctx *gin.Context) {
+	h.control(ctx, "Resume", h.Service.Resume, "Campaign resumed successfully")
+}
+
+func (h *HandlerCampaign) Cancel(ctx *gin.Context) {
+	h.control(ctx, "Cancel", h.Service.Cancel, "Campaign cancelled successfully")
+}
+
+// control runs one of the status actions, which share their request and
+// response shape.
+func (h *HandlerCampaign) control(ctx *gin.Context, action string, fn func(id string) (domaincampaign.Campaign, error), message string) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[CampaignHandler][" + action + "]"
+
+	data, err := fn(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.%s; Error: %+v", logPrefix, action, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, message, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerCampaign) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "campaign not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, servicecampaign.ErrCampaignContentRequired),
+		errors.Is(err, servicecampaign.ErrCampaignSubjectRequired),
+		errors.Is(err, servicecampaign.ErrCampaignRateTooHigh):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, servicecampaign.ErrCampaignNotEditable),
+		errors.Is(err, servicecampaign.ErrCampaignActive),
+		errors.Is(err, servicecampaign.ErrCampaignTransition):
+		res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+		ctx.JSON(http.StatusConflict, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
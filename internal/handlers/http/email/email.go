@@ -8,10 +8,12 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"service-sender/internal/dto"
 	interfaceemail "service-sender/internal/interfaces/email"
 	serviceemail "service-sender/internal/services/email"
+	"service-sender/pkg/i18n"
 	"service-sender/pkg/logger"
 	"service-sender/pkg/messages"
 	"service-sender/pkg/response"
@@ -39,41 +41,30 @@ func (h *HandlerEmail) SendEmail(ctx *gin.Context) {
 		return
 	}
 
-	appName := strings.TrimSpace(ctx.GetHeader("X-App-Name"))
-	if appName == "" {
-		appName = strings.TrimSpace(utils.GetEnv("EMAIL_APP_NAME", utils.GetEnv("OTP_APP_NAME", "Account Verification").(string)).(string))
-	}
+	appName := resolveAppName(ctx)
+	req.Locale = i18n.Normalize(req.Locale)
+	req.AcceptLocale = utils.HeaderLocale(ctx)
 
 	total, subject, err := h.Service.Send(ctx.Request.Context(), req, appName)
+	ctx.Set(utils.CtxKeyQuotaUsed, total)
+	if partial := new(serviceemail.PartialSendError); errors.As(err, &partial) {
+		ctx.Set(utils.CtxKeyQuotaUsed, partial.Sent)
+		// Some copies went out, so a retry would repeat them; say who
+		// was reached and who was not.
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Send partial: %v", logPrefix, err))
+		res := response.Response(http.StatusMultiStatus, "Email sent to some recipients", logId, gin.H{
+			"type":       req.Type,
+			"subject":    subject,
+			"total_sent": partial.Sent,
+			"failed":     partial.Failed,
+		})
+		ctx.JSON(http.StatusMultiStatus, res)
+		return
+	}
 	if err != nil {
 		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Send error: %v", logPrefix, err))
-		switch {
-		case errors.Is(err, serviceemail.ErrEmailNotConfigured):
-			res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
-			res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Email sender is not available"}
-			ctx.JSON(http.StatusServiceUnavailable, res)
-			return
-		case errors.Is(err, serviceemail.ErrSubjectRequired):
-			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
-			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "subject is required when template_key is empty"}
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		case errors.Is(err, serviceemail.ErrEmailBodyRequired):
-			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
-			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "text_body or html_body is required"}
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		case errors.Is(err, serviceemail.ErrTemplateNotFound):
-			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
-			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "template_key is not registered"}
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		default:
-			res := response.Response(http.StatusBadGateway, messages.MsgFail, logId, nil)
-			res.Error = response.Errors{Code: http.StatusBadGateway, Message: "Failed to send email"}
-			ctx.JSON(http.StatusBadGateway, res)
-			return
-		}
+		h.respondError(ctx, logId, err)
+		return
 	}
 
 	res := response.Response(http.StatusOK, messages.MsgSuccess, logId, gin.H{
@@ -83,3 +74,136 @@ func (h *HandlerEmail) SendEmail(ctx *gin.Context) {
 	})
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (h *HandlerEmail) PreviewTemplate(ctx *gin.Context) {
+	var req dto.EmailTemplatePreviewRequest
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailHandler][PreviewTemplate]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, logPrefix+"; BindJSON ERROR: "+err.Error())
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	req.Locale = i18n.Normalize(req.Locale)
+	req.AcceptLocale = utils.HeaderLocale(ctx)
+	data, err := h.Service.Preview(ctx.Request.Context(), ctx.Param("key"), req, resolveAppName(ctx))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Preview error: %v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, messages.MsgSuccess, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmail) TestSendTemplate(ctx *gin.Context) {
+	var req dto.EmailTemplatePreviewRequest
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailHandler][TestSendTemplate]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, logPrefix+"; BindJSON ERROR: "+err.Error())
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	req.Locale = i18n.Normalize(req.Locale)
+	req.AcceptLocale = utils.HeaderLocale(ctx)
+	authData := utils.GetAuthData(ctx)
+	data, recipient, err := h.Service.SendTest(ctx.Request.Context(), ctx.Param("key"), req, resolveAppName(ctx), utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.SendTest error: %v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, messages.MsgSuccess, logId, gin.H{
+		"to":        recipient,
+		"subject":   data.Subject,
+		"text_body": data.TextBody,
+		"html_body": data.HTMLBody,
+		"warnings":  data.Warnings,
+	})
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmail) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	var dataErr *serviceemail.TemplateDataError
+	switch {
+	case errors.As(err, &dataErr):
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = dataErr.Fields
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrEmailNotConfigured):
+		res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Email sender is not available"}
+		ctx.JSON(http.StatusServiceUnavailable, res)
+	case errors.Is(err, serviceemail.ErrSubjectRequired):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "subject is required when template_key is empty"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrEmailBodyRequired):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "text_body, html_body or markdown_body is required"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrCalendarEventRequired):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "event is required for calendar emails"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrCalendarTimezoneInvalid):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "event.timezone is not a valid IANA timezone"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrAllRecipientsSuppressed):
+		res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusUnprocessableEntity, Message: "all recipients are on the suppression list"}
+		ctx.JSON(http.StatusUnprocessableEntity, res)
+	case errors.Is(err, serviceemail.ErrAllRecipientsOptedOut):
+		res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusUnprocessableEntity, Message: "all recipients opted out of this category"}
+		ctx.JSON(http.StatusUnprocessableEntity, res)
+	case errors.Is(err, serviceemail.ErrPrivateToWithCopies):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "private_to cannot be combined with cc or bcc"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrCategoryNotAllowed):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "campaigns cannot be sent in the security category"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrTemplateNotFound):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "template_key is not registered"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrTestRecipientNotFound):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "your account has no email address to send the test to"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrSenderIdentityNotFound):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "sender is not registered"}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemail.ErrSenderIdentityNotAllowed):
+		res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+		res.Error = response.Errors{Code: http.StatusForbidden, Message: "sender is not allowed for this type or app"}
+		ctx.JSON(http.StatusForbidden, res)
+	default:
+		res := response.Response(http.StatusBadGateway, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadGateway, Message: "Failed to send email"}
+		ctx.JSON(http.StatusBadGateway, res)
+	}
+}
+
+func resolveAppName(ctx *gin.Context) string {
+	appName := strings.TrimSpace(ctx.GetHeader("X-App-Name"))
+	if appName == "" {
+		appName = strings.TrimSpace(utils.GetEnv("EMAIL_APP_NAME", utils.GetEnv("OTP_APP_NAME", "Account Verification").(string)).(string))
+	}
+	return appName
+}
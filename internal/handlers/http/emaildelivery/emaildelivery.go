@@ -0,0 +1,198 @@
+package handleremaildelivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"service-sender/internal/dto"
+	interfaceemaildelivery "service-sender/internal/interfaces/emaildelivery"
+	"service-sender/pkg/config"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/mailer"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/pkg/security"
+	"service-sender/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerEmailDelivery struct {
+	Service interfaceemaildelivery.ServiceEmailDeliveryInterface
+	Config  config.EmailWebhookConfig
+}
+
+func NewEmailDeliveryHandler(s interfaceemaildelivery.ServiceEmailDeliveryInterface, webhook config.EmailWebhookConfig) *HandlerEmailDelivery {
+	return &HandlerEmailDelivery{Service: s, Config: webhook}
+}
+
+// Webhook ingests delivery events posted by the email provider. The request
+// is authenticated against EMAIL_WEBHOOK_SECRET before the body is parsed.
+func (h *HandlerEmailDelivery) Webhook(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailDeliveryHandler][Webhook]"
+
+	if h.Config.Secret == "" {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; EMAIL_WEBHOOK_SECRET is not set", logPrefix))
+		res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "email webhooks are not configured"}
+		ctx.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetRawData ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := security.VerifyWebhook(ctx.Request.Header, body, h.Config.Secret, h.Config.Tolerance, time.Now()); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; VerifyWebhook; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusUnauthorized, messages.MsgDenied, logId, nil)
+		res.Error = response.Errors{Code: http.StatusUnauthorized, Message: security.ErrWebhookUnauthorized.Error()}
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	events, err := mailer.ParseWebhook(ctx.Param("provider"), body)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; ParseWebhook; Error: %+v", logPrefix, err))
+		if errors.Is(err, mailer.ErrUnknownWebhookProvider) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: err.Error()}
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.HandleEvents(events)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.HandleEvents; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Webhook processed successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailDelivery) GetDeliveries(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailDeliveryHandler][GetDeliveries]"
+
+	params, err := filter.GetBaseParams(ctx, "created_at", "desc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"status", "email_type", "app_name", "recipient", "message_id"})
+
+	data, total, err := h.Service.GetDeliveries(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetDeliveries; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailDelivery) GetSuppressions(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailDeliveryHandler][GetSuppressions]"
+
+	params, err := filter.GetBaseParams(ctx, "created_at", "desc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"reason", "source"})
+
+	data, total, err := h.Service.GetSuppressions(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetSuppressions; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailDelivery) CreateSuppression(ctx *gin.Context) {
+	var req dto.SuppressionCreate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailDeliveryHandler][CreateSuppression]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	authData := utils.GetAuthData(ctx)
+	data, err := h.Service.CreateSuppression(req, utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.CreateSuppression; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Email suppression created successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerEmailDelivery) DeleteSuppression(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailDeliveryHandler][DeleteSuppression]"
+
+	if err := h.Service.DeleteSuppression(ctx.Param("email")); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.DeleteSuppression; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Email suppression deleted successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailDelivery) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "email suppression not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
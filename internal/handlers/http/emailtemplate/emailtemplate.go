@@ -0,0 +1,197 @@
+package handleremailtemplate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"service-sender/internal/dto"
+	interfaceemailtemplate "service-sender/internal/interfaces/emailtemplate"
+	serviceemailtemplate "service-sender/internal/services/emailtemplate"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerEmailTemplate struct {
+	Service interfaceemailtemplate.ServiceEmailTemplateInterface
+}
+
+func NewEmailTemplateHandler(s interfaceemailtemplate.ServiceEmailTemplateInterface) *HandlerEmailTemplate {
+	return &HandlerEmailTemplate{Service: s}
+}
+
+func (h *HandlerEmailTemplate) Create(ctx *gin.Context) {
+	var req dto.EmailTemplateCreate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][Create]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	authData := utils.GetAuthData(ctx)
+	data, err := h.Service.Create(req, utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Email template created successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerEmailTemplate) GetByKey(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][GetByKey]"
+
+	data, err := h.Service.GetActive(ctx.Param("key"), ctx.Query("locale"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetActive; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get email template successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailTemplate) GetAll(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][GetAll]"
+
+	params, err := filter.GetBaseParams(ctx, "key", "asc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"key", "locale"})
+
+	data, total, err := h.Service.GetAll(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetAll; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailTemplate) GetVersions(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][GetVersions]"
+
+	data, err := h.Service.GetVersions(ctx.Param("key"), ctx.Query("locale"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetVersions; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get email template versions successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailTemplate) Update(ctx *gin.Context) {
+	var req dto.EmailTemplateUpdate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][Update]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	authData := utils.GetAuthData(ctx)
+	data, err := h.Service.Update(ctx.Param("key"), req, utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Email template updated successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailTemplate) Rollback(ctx *gin.Context) {
+	var req dto.EmailTemplateRollback
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][Rollback]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.Rollback(ctx.Param("key"), req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Rollback; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Email template rolled back successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailTemplate) Delete(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[EmailTemplateHandler][Delete]"
+
+	if err := h.Service.Delete(ctx.Param("key"), ctx.Query("locale")); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Delete; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Email template deleted successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerEmailTemplate) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "email template not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, serviceemailtemplate.ErrTemplateExists):
+		res := response.Response(http.StatusBadRequest, messages.MsgExists, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, serviceemailtemplate.ErrTemplateInvalid), errors.Is(err, serviceemailtemplate.ErrTemplateBodyMissing),
+		errors.Is(err, serviceemailtemplate.ErrTemplateSchemaInvalid):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
@@ -0,0 +1,250 @@
+package handlernotification
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+
+	domainnotification "service-sender/internal/domain/notification"
+	domainuser "service-sender/internal/domain/user"
+	"service-sender/internal/dto"
+	interfacenotification "service-sender/internal/interfaces/notification"
+	"service-sender/pkg/config"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerNotification struct {
+	Service interfacenotification.ServiceNotificationInterface
+	Config  config.NotificationConfig
+}
+
+func NewNotificationHandler(s interfacenotification.ServiceNotificationInterface, cfg config.NotificationConfig) *HandlerNotification {
+	return &HandlerNotification{Service: s, Config: cfg}
+}
+
+func (h *HandlerNotification) Create(ctx *gin.Context) {
+	var req dto.NotificationCreate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][Create]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.Create(req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: "user not found"}
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Notification created successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerNotification) GetAll(ctx *gin.Context) {
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][GetAll]"
+
+	params, err := filter.GetBaseParams(ctx, "created_at", "desc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"category", "unread"})
+
+	data, total, err := h.Service.GetAll(userId, params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetAll; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerNotification) UnreadCount(ctx *gin.Context) {
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][UnreadCount]"
+
+	data, err := h.Service.UnreadCount(userId)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.UnreadCount; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get unread count successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerNotification) MarkRead(ctx *gin.Context) {
+	h.mark(ctx, "MarkRead", h.Service.MarkRead, "Notification marked as read")
+}
+
+func (h *HandlerNotification) MarkUnread(ctx *gin.Context) {
+	h.mark(ctx, "MarkUnread", h.Service.MarkUnread, "Notification marked as unread")
+}
+
+func (h *HandlerNotification) MarkAllRead(ctx *gin.Context) {
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][MarkAllRead]"
+
+	if err := h.Service.MarkAllRead(userId); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.MarkAllRead; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "All notifications marked as read", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// StreamToken issues a short-lived token for Stream. Browsers cannot set an
+// Authorization header on an EventSource, so they pass it as the token query
+// parameter or the notification_stream cookie instead.
+func (h *HandlerNotification) StreamToken(ctx *gin.Context) {
+	authData := utils.GetAuthData(ctx)
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][StreamToken]"
+
+	user := domainuser.Users{
+		Id:   utils.InterfaceString(authData["user_id"]),
+		Name: utils.InterfaceString(authData["username"]),
+		Role: utils.InterfaceString(authData["role"]),
+	}
+	token, err := utils.GenerateScopedJwt(&user, logId.String(), utils.ScopeNotificationStream, h.Config.StreamTokenTTL)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GenerateScopedJwt; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Stream token issued successfully", logId, gin.H{
+		"token":      token,
+		"expires_in": int(h.Config.StreamTokenTTL.Seconds()),
+	})
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Stream pushes inbox events as Server-Sent Events until the client goes
+// away. The first event is the current unread count, so a reconnecting
+// client never needs a separate request to resync.
+func (h *HandlerNotification) Stream(ctx *gin.Context) {
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][Stream]"
+
+	// Subscribe before counting so no change falls between the two.
+	events, unsubscribe := h.Service.Subscribe(userId)
+	defer unsubscribe()
+
+	count, err := h.Service.UnreadCount(userId)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.UnreadCount; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	ctx.SSEvent(domainnotification.EventUnreadCount, domainnotification.Event{Type: domainnotification.EventUnreadCount, UserId: userId, Unread: &count.Unread})
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.Config.StreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event := <-events:
+			ctx.SSEvent(event.Type, event)
+		case <-heartbeat.C:
+			if _, err := io.WriteString(ctx.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+// mark runs one of the per-notification read state changes, which share
+// their request and response shape.
+func (h *HandlerNotification) mark(ctx *gin.Context, action string, fn func(userId, id string) error, message string) {
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotificationHandler][" + action + "]"
+
+	id, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	if err := fn(userId, id); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.%s; Error: %+v", logPrefix, action, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, message, logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerNotification) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "notification not found"}
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+	res.Error = err.Error()
+	ctx.JSON(http.StatusInternalServerError, res)
+}
@@ -0,0 +1,77 @@
+package handlernotify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"service-sender/internal/dto"
+	interfacenotify "service-sender/internal/interfaces/notify"
+	servicenotify "service-sender/internal/services/notify"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type HandlerNotify struct {
+	Service interfacenotify.ServiceNotifyInterface
+}
+
+func NewNotifyHandler(s interfacenotify.ServiceNotifyInterface) *HandlerNotify {
+	return &HandlerNotify{Service: s}
+}
+
+// Notify answers 200 whenever the request itself was valid; the caller reads
+// the per-channel results to see where the notification went.
+func (h *HandlerNotify) Notify(ctx *gin.Context) {
+	var req dto.NotifyRequest
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[NotifyHandler][Notify]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	appName := strings.TrimSpace(ctx.GetHeader("X-App-Name"))
+	if appName == "" {
+		appName = strings.TrimSpace(utils.GetEnv("EMAIL_APP_NAME", utils.GetEnv("OTP_APP_NAME", "Account Verification").(string)).(string))
+	}
+
+	data, err := h.Service.Notify(ctx.Request.Context(), req, appName)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Notify; Error: %+v", logPrefix, err))
+		switch {
+		case errors.Is(err, servicenotify.ErrNotifyRuleNotFound):
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: err.Error()}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: "user not found"}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, servicenotify.ErrNotifyNoDatabase):
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	res := response.Response(http.StatusOK, "Notification processed", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -45,7 +45,7 @@ func (h *HandlerOTP) SendRegisterOTP(ctx *gin.Context) {
 	}
 	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Request email: %s; AppName: %s;", logPrefix, req.Email, appName))
 
-	err := h.Service.SendRegisterOTP(ctx.Request.Context(), req.Email, appName)
+	err := h.Service.SendRegisterOTP(ctx.Request.Context(), req.Email, appName, utils.RequestLocale(ctx, req.Locale))
 	if err != nil {
 		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.SendRegisterOTP error: %v", logPrefix, err))
 		if throttle := new(serviceotp.ThrottleError); errors.As(err, &throttle) {
@@ -0,0 +1,80 @@
+package handlerpreference
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"service-sender/internal/dto"
+	interfacepreference "service-sender/internal/interfaces/preference"
+	servicepreference "service-sender/internal/services/preference"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HandlerPreference struct {
+	Service interfacepreference.ServicePreferenceInterface
+}
+
+func NewPreferenceHandler(s interfacepreference.ServicePreferenceInterface) *HandlerPreference {
+	return &HandlerPreference{Service: s}
+}
+
+func (h *HandlerPreference) GetPreferences(ctx *gin.Context) {
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[PreferenceHandler][GetPreferences]"
+
+	data, err := h.Service.GetPreferences(userId)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetPreferences; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get notification preferences successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerPreference) UpdatePreferences(ctx *gin.Context) {
+	var req dto.PreferenceUpdate
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[PreferenceHandler][UpdatePreferences]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.UpdatePreferences(userId, req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.UpdatePreferences; Error: %+v", logPrefix, err))
+		if errors.Is(err, servicepreference.ErrPreferenceLocked) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Notification preferences updated successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,129 @@
+package handlerpush
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"service-sender/internal/dto"
+	interfacepush "service-sender/internal/interfaces/push"
+	servicepush "service-sender/internal/services/push"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerPush struct {
+	Service interfacepush.ServicePushInterface
+}
+
+func NewPushHandler(s interfacepush.ServicePushInterface) *HandlerPush {
+	return &HandlerPush{Service: s}
+}
+
+func (h *HandlerPush) PublicKey(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[PushHandler][PublicKey]"
+
+	data, err := h.Service.PublicKey()
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.PublicKey; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get VAPID public key successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerPush) GetSubscriptions(ctx *gin.Context) {
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[PushHandler][GetSubscriptions]"
+
+	data, err := h.Service.GetSubscriptions(userId)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetSubscriptions; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get push subscriptions successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerPush) Subscribe(ctx *gin.Context) {
+	var req dto.PushSubscriptionCreate
+	authData := utils.GetAuthData(ctx)
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[PushHandler][Subscribe]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.Subscribe(utils.InterfaceString(authData["user_id"]), utils.InterfaceString(authData["jti"]), ctx.GetHeader("User-Agent"), req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Subscribe; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Push subscription registered successfully", logId, data)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerPush) Unsubscribe(ctx *gin.Context) {
+	var req dto.PushSubscriptionDelete
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[PushHandler][Unsubscribe]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := h.Service.Unsubscribe(userId, req.Endpoint); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Unsubscribe; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Push subscription removed successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerPush) respondError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "push subscription not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, servicepush.ErrPushNotConfigured):
+		res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: err.Error()}
+		ctx.JSON(http.StatusServiceUnavailable, res)
+	case errors.Is(err, servicepush.ErrPushEndpointNotAllowed), errors.Is(err, servicepush.ErrPushInvalidKeys):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
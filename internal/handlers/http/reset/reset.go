@@ -16,6 +16,7 @@ import (
 	"service-sender/pkg/logger"
 	"service-sender/pkg/mailer"
 	"service-sender/pkg/messages"
+	"service-sender/pkg/passwordpolicy"
 	"service-sender/pkg/response"
 	"service-sender/utils"
 
@@ -52,12 +53,9 @@ func (h *HandlerReset) RequestReset(ctx *gin.Context) {
 		return
 	}
 
-	appName := strings.TrimSpace(ctx.GetHeader("X-App-Name"))
-	if appName == "" {
-		appName = strings.TrimSpace(utils.GetEnv("RESET_APP_NAME", utils.GetEnv("OTP_APP_NAME", "Account Verification").(string)).(string))
-	}
+	appName := resetAppName(ctx)
 
-	err := h.Service.RequestReset(ctx.Request.Context(), req.Email, appName)
+	mode, err := h.Service.RequestReset(ctx.Request.Context(), req.Email, appName, utils.RequestLocale(ctx, req.Locale), req.Mode)
 	if err != nil {
 		if throttle := new(servicereset.ThrottleError); errors.As(err, &throttle) {
 			retryAfter := int(throttle.RetryAfter.Seconds())
@@ -70,6 +68,13 @@ func (h *HandlerReset) RequestReset(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, servicereset.ErrResetModeNotAllowed) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "Reset mode is not allowed for this app"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
 		if errors.Is(err, servicereset.ErrResetNotConfigured) || errors.Is(err, servicereset.ErrResetDeliveryFailed) {
 			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
 			res.Error = response.Errors{Code: http.StatusInternalServerError, Message: "Password reset service is not available"}
@@ -83,7 +88,7 @@ func (h *HandlerReset) RequestReset(ctx *gin.Context) {
 		return
 	}
 
-	res := response.Response(http.StatusOK, messages.MsgSuccess, logId, map[string]string{"email": req.Email})
+	res := response.Response(http.StatusOK, messages.MsgSuccess, logId, map[string]string{"email": req.Email, "mode": mode})
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -127,6 +132,138 @@ func (h *HandlerReset) VerifyReset(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// VerifyResetCode exchanges a code sent in code mode for a ticket, which is
+// then passed to CompleteReset.
+func (h *HandlerReset) VerifyResetCode(ctx *gin.Context) {
+	var req dto.PasswordResetVerifyCodeRequest
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ResetHandler][VerifyResetCode]"
+
+	if h.Service == nil {
+		res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Password reset service is not available"}
+		ctx.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, logPrefix+"; BindJSON ERROR: "+err.Error())
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	ticket, err := h.Service.VerifyResetCode(ctx.Request.Context(), req.Email, req.Code, resetAppName(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, servicereset.ErrResetInvalid):
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "Invalid or expired code"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		case errors.Is(err, servicereset.ErrResetTooManyAttempt):
+			res := response.Response(http.StatusTooManyRequests, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusTooManyRequests, Message: "Too many attempts, please request a new code"}
+			ctx.JSON(http.StatusTooManyRequests, res)
+			return
+		case errors.Is(err, servicereset.ErrResetModeNotAllowed):
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "Reset mode is not allowed for this app"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		case errors.Is(err, servicereset.ErrResetNotConfigured):
+			res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Password reset service is not available"}
+			ctx.JSON(http.StatusServiceUnavailable, res)
+			return
+		}
+
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.VerifyResetCode error: %v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusInternalServerError, Message: "Unable to verify code"}
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, messages.MsgSuccess, logId, map[string]interface{}{
+		"ticket":     ticket,
+		"expires_in": int(h.Config.TicketTTL.Seconds()),
+	})
+	ctx.JSON(http.StatusOK, res)
+}
+
+// CompleteReset sets a new password with a link token or a ticket from
+// VerifyResetCode. It is used up and every session of the user is revoked.
+func (h *HandlerReset) CompleteReset(ctx *gin.Context) {
+	var req dto.PasswordResetCompleteRequest
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[ResetHandler][CompleteReset]"
+
+	if h.Service == nil {
+		res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Password reset service is not available"}
+		ctx.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, logPrefix+"; BindJSON ERROR: "+err.Error())
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	token := req.Token
+	if token == "" {
+		token = req.Ticket
+	}
+
+	err := h.Service.CompleteReset(ctx.Request.Context(), token, req.NewPassword)
+	if err != nil {
+		if weak := new(servicereset.WeakPasswordError); errors.As(err, &weak) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: weak.Error()}
+			if perr := new(passwordpolicy.Error); errors.As(err, &perr) {
+				errs := make([]response.Errors, 0, len(perr.Reasons))
+				for _, reason := range perr.Reasons {
+					errs = append(errs, response.Errors{Code: http.StatusBadRequest, Message: reason.Message})
+				}
+				res.Error = errs
+			}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		if errors.Is(err, servicereset.ErrResetInvalid) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "Invalid or expired token"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		if errors.Is(err, servicereset.ErrResetNotConfigured) {
+			res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Password reset service is not available"}
+			ctx.JSON(http.StatusServiceUnavailable, res)
+			return
+		}
+
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.CompleteReset error: %v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusInternalServerError, Message: "Unable to reset password"}
+		if errors.Is(err, servicereset.ErrSessionsNotRevoked) {
+			res.Error = response.Errors{Code: http.StatusInternalServerError, Message: "Password was changed but existing sessions could not be signed out"}
+		}
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Password reset successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
 func (h *HandlerReset) SendResetEmail(ctx *gin.Context) {
 	var req dto.PasswordResetEmailRequest
 	logId := utils.GenerateLogId(ctx)
@@ -147,10 +284,7 @@ func (h *HandlerReset) SendResetEmail(ctx *gin.Context) {
 		return
 	}
 
-	appName := strings.TrimSpace(ctx.GetHeader("X-App-Name"))
-	if appName == "" {
-		appName = strings.TrimSpace(utils.GetEnv("RESET_APP_NAME", utils.GetEnv("OTP_APP_NAME", "Account Verification").(string)).(string))
-	}
+	appName := resetAppName(ctx)
 
 	ttl := h.Config.TTL
 	if req.ExpiresInMinutes > 0 {
@@ -162,7 +296,7 @@ func (h *HandlerReset) SendResetEmail(ctx *gin.Context) {
 		resetURL = buildResetURL(h.Config.URLTemplate, req.Token)
 	}
 
-	if err := h.Sender.SendPasswordReset(req.Email, req.Token, appName, resetURL, ttl); err != nil {
+	if err := h.Sender.SendPasswordReset(req.Email, req.Token, appName, utils.RequestLocale(ctx, req.Locale), resetURL, ttl); err != nil {
 		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; SendPasswordReset error: %v", logPrefix, err))
 		res := response.Response(http.StatusBadGateway, messages.MsgFail, logId, nil)
 		res.Error = response.Errors{Code: http.StatusBadGateway, Message: "Failed to send reset email"}
@@ -186,3 +320,12 @@ func buildResetURL(template, token string) string {
 	}
 	return template + "?token=" + token
 }
+
+// resetAppName is the app a reset is for, which picks its email branding and
+// reset mode.
+func resetAppName(ctx *gin.Context) string {
+	if appName := strings.TrimSpace(ctx.GetHeader("X-App-Name")); appName != "" {
+		return appName
+	}
+	return strings.TrimSpace(utils.GetEnv("RESET_APP_NAME", utils.GetEnv("OTP_APP_NAME", "Account Verification").(string)).(string))
+}
@@ -0,0 +1,82 @@
+package handlersecurityalert
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"service-sender/internal/dto"
+	interfacesecurityalert "service-sender/internal/interfaces/securityalert"
+	servicesecurityalert "service-sender/internal/services/securityalert"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HandlerSecurityAlert struct {
+	Service interfacesecurityalert.ServiceSecurityAlertInterface
+}
+
+func NewSecurityAlertHandler(s interfacesecurityalert.ServiceSecurityAlertInterface) *HandlerSecurityAlert {
+	return &HandlerSecurityAlert{Service: s}
+}
+
+// Lock is called by the page behind the "it wasn't me" link of a security
+// alert. The link works once.
+func (h *HandlerSecurityAlert) Lock(ctx *gin.Context) {
+	var req dto.SecurityLockRequest
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[SecurityAlertHandler][Lock]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, logPrefix+"; BindJSON ERROR: "+err.Error())
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := h.Service.Lock(ctx.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, servicesecurityalert.ErrLockInvalid) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "Invalid or expired link"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		if errors.Is(err, servicesecurityalert.ErrLockEmailTaken) {
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: "Your previous email address now belongs to another account. Contact support"}
+			ctx.JSON(http.StatusConflict, res)
+			return
+		}
+
+		// The account is locked either way; the user needs another reset.
+		if errors.Is(err, servicesecurityalert.ErrLockResetNotSent) {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Lock error: %v", logPrefix, err))
+			res := response.Response(http.StatusOK, "Account locked. Request a password reset to unlock it", logId, nil)
+			ctx.JSON(http.StatusOK, res)
+			return
+		}
+
+		if errors.Is(err, servicesecurityalert.ErrAlertNotConfigured) {
+			res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "Security alerts are not available"}
+			ctx.JSON(http.StatusServiceUnavailable, res)
+			return
+		}
+
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Lock error: %v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusInternalServerError, Message: "Unable to lock account"}
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Account locked and password reset sent", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
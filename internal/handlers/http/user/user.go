@@ -8,12 +8,17 @@ import (
 	"reflect"
 	"service-sender/infrastructure/database"
 	"service-sender/internal/dto"
+	interfacesecurityalert "service-sender/internal/interfaces/securityalert"
 	interfaceuser "service-sender/internal/interfaces/user"
 	sessionRepo "service-sender/internal/repositories/session"
+	servicepasswordhistory "service-sender/internal/services/passwordhistory"
 	sessionSvc "service-sender/internal/services/session"
+	serviceuser "service-sender/internal/services/user"
 	"service-sender/pkg/filter"
+	"service-sender/pkg/i18n"
 	"service-sender/pkg/logger"
 	"service-sender/pkg/messages"
+	"service-sender/pkg/passwordpolicy"
 	"service-sender/pkg/response"
 	"service-sender/pkg/security"
 	"service-sender/utils"
@@ -29,12 +34,15 @@ import (
 type HandlerUser struct {
 	Service      interfaceuser.ServiceUserInterface
 	LoginLimiter security.LoginLimiter
+	// Alerts is told of every login to spot new devices. It may be nil.
+	Alerts interfacesecurityalert.ServiceSecurityAlertInterface
 }
 
-func NewUserHandler(s interfaceuser.ServiceUserInterface, limiter security.LoginLimiter) *HandlerUser {
+func NewUserHandler(s interfaceuser.ServiceUserInterface, limiter security.LoginLimiter, alerts interfacesecurityalert.ServiceSecurityAlertInterface) *HandlerUser {
 	return &HandlerUser{
 		Service:      s,
 		LoginLimiter: limiter,
+		Alerts:       alerts,
 	}
 }
 
@@ -53,9 +61,15 @@ func (h *HandlerUser) Register(ctx *gin.Context) {
 	}
 	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
+	// Remember the language the user signed up in for later emails.
+	req.Locale = utils.RequestLocale(ctx, req.Locale)
 	data, err := h.Service.RegisterUser(req)
 	if err != nil {
 		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.RegisterUser; Error: %+v", logPrefix, err))
+		if perr := new(passwordpolicy.Error); errors.As(err, &perr) {
+			h.respondWeakPassword(ctx, logId, perr)
+			return
+		}
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Error: email or phone already exists", logPrefix))
 			res := response.Response(http.StatusBadRequest, messages.MsgExists, logId, nil)
@@ -101,6 +115,10 @@ func (h *HandlerUser) AdminCreateUser(ctx *gin.Context) {
 	data, err := h.Service.AdminCreateUser(req, creatorRole)
 	if err != nil {
 		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.AdminCreateUser; Error: %+v", logPrefix, err))
+		if perr := new(passwordpolicy.Error); errors.As(err, &perr) {
+			h.respondWeakPassword(ctx, logId, perr)
+			return
+		}
 		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "already exists") {
 			res := response.Response(http.StatusBadRequest, messages.MsgExists, logId, nil)
 			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
@@ -168,6 +186,30 @@ func (h *HandlerUser) Login(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, serviceuser.ErrAccountLocked) {
+			res := response.Response(http.StatusForbidden, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: "Account locked. Check your email to reset your password"}
+			ctx.JSON(http.StatusForbidden, res)
+			return
+		}
+
+		// token is a ticket for /change/expired-password, not a login.
+		if errors.Is(err, serviceuser.ErrPasswordExpired) {
+			if h.LoginLimiter != nil {
+				if err := h.LoginLimiter.Reset(ctx.Request.Context(), loginIdentifier); err != nil {
+					logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; LoginLimiter.Reset error: %v", logPrefix, err))
+				}
+			}
+
+			res := response.Response(http.StatusForbidden, messages.MsgFail, logId, map[string]interface{}{
+				"reason":        "password_expired",
+				"change_ticket": token,
+			})
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: "Password expired. Change it to continue"}
+			ctx.JSON(http.StatusForbidden, res)
+			return
+		}
+
 		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
 		res.Error = err.Error()
 		ctx.JSON(http.StatusInternalServerError, res)
@@ -185,7 +227,7 @@ func (h *HandlerUser) Login(ctx *gin.Context) {
 		user, errUser := h.Service.GetUserByEmail(req.Email)
 		if errUser == nil {
 			sRepo := sessionRepo.NewSessionRepository(redisClient)
-			sSvc := sessionSvc.NewSessionService(sRepo)
+			sSvc := sessionSvc.NewSessionService(sRepo, nil, nil)
 
 			session, errSession := sSvc.CreateSession(context.Background(), &user, token, ctx)
 			if errSession != nil {
@@ -196,6 +238,18 @@ func (h *HandlerUser) Login(ctx *gin.Context) {
 		}
 	}
 
+	// Look for a new device without holding up the login.
+	if h.Alerts != nil {
+		if user, errUser := h.Service.GetUserByEmail(req.Email); errUser == nil {
+			ip, userAgent, appName := ctx.ClientIP(), ctx.GetHeader("User-Agent"), strings.TrimSpace(ctx.GetHeader("X-App-Name"))
+			go func() {
+				if err := h.Alerts.CheckLogin(user, ip, userAgent, appName); err != nil {
+					logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Alerts.CheckLogin error: %v", logPrefix, err))
+				}
+			}()
+		}
+	}
+
 	res := response.Response(http.StatusOK, "success", logId, map[string]interface{}{"token": token})
 	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(token)))
 	ctx.JSON(http.StatusOK, res)
@@ -216,6 +270,47 @@ func (h *HandlerUser) respondTooManyLoginAttempts(ctx *gin.Context, logId uuid.U
 	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, res)
 }
 
+// respondWeakPassword lists every rule of the password policy the password
+// breaks.
+func (h *HandlerUser) respondWeakPassword(ctx *gin.Context, logId uuid.UUID, perr *passwordpolicy.Error) {
+	errs := make([]response.Errors, 0, len(perr.Reasons))
+	for _, reason := range perr.Reasons {
+		errs = append(errs, response.Errors{Code: http.StatusBadRequest, Message: reason.Message})
+	}
+
+	res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+	res.Error = errs
+	ctx.JSON(http.StatusBadRequest, res)
+}
+
+// CheckPassword scores a password against the password policy without
+// storing it, so sign-up and change-password forms can show its strength.
+func (h *HandlerUser) CheckPassword(ctx *gin.Context) {
+	var req dto.PasswordCheck
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[UserHandler][CheckPassword]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result := h.Service.CheckPassword(req)
+
+	// Reasons sit in data, which the locale middleware leaves alone.
+	locale := ctx.GetString(utils.CtxKeyLocale)
+	for i, reason := range result.Reasons {
+		result.Reasons[i].Message = i18n.Message(locale, reason.Message)
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
 func (h *HandlerUser) Logout(ctx *gin.Context) {
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := "[UserController][Logout]"
@@ -232,7 +327,7 @@ func (h *HandlerUser) Logout(ctx *gin.Context) {
 	// Destroy session if Redis is available
 	if redisClient := database.GetRedisClient(); redisClient != nil {
 		sRepo := sessionRepo.NewSessionRepository(redisClient)
-		sSvc := sessionSvc.NewSessionService(sRepo)
+		sSvc := sessionSvc.NewSessionService(sRepo, nil, nil)
 
 		errSession := sSvc.DestroySessionByToken(context.Background(), token.(string))
 		if errSession != nil {
@@ -362,6 +457,12 @@ func (h *HandlerUser) Update(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, res)
 			return
 		}
+		if errors.Is(err, serviceuser.ErrUnsupportedLocale) {
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: serviceuser.ErrUnsupportedLocale.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
 
 		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
 		res.Error = err.Error()
@@ -405,6 +506,12 @@ func (h *HandlerUser) UpdateUserById(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, res)
 			return
 		}
+		if errors.Is(err, serviceuser.ErrUnsupportedLocale) {
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: serviceuser.ErrUnsupportedLocale.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
 
 		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
 		res.Error = err.Error()
@@ -450,6 +557,11 @@ func (h *HandlerUser) ChangePassword(ctx *gin.Context) {
 			return
 		}
 
+		if perr := new(passwordpolicy.Error); errors.As(err, &perr) {
+			h.respondWeakPassword(ctx, logId, perr)
+			return
+		}
+
 		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
 		res.Error = err.Error()
 		ctx.JSON(http.StatusBadRequest, res)
@@ -461,56 +573,51 @@ func (h *HandlerUser) ChangePassword(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
-func (h *HandlerUser) ForgotPassword(ctx *gin.Context) {
-	var req dto.ForgotPasswordRequest
+// ChangeExpiredPassword sets a new password with the ticket a login with an
+// expired password returned. The user then logs in with it.
+func (h *HandlerUser) ChangeExpiredPassword(ctx *gin.Context) {
+	var req dto.ChangeExpiredPassword
 	logId := utils.GenerateLogId(ctx)
-	logPrefix := "[UserHandler][ForgotPassword]"
+	logPrefix := "[UserHandler][ChangeExpiredPassword]"
 
 	if err := ctx.BindJSON(&req); err != nil {
 		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
 		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
 		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	token, err := h.Service.ForgotPassword(req)
+	data, err := h.Service.ChangeExpiredPassword(req)
 	if err != nil {
-		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.ForgotPassword; ERROR: %s;", logPrefix, err))
-		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
-		res.Error = err.Error()
-		ctx.JSON(http.StatusInternalServerError, res)
-		return
-	}
-
-	logger.WriteLogWithContext(ctx, logger.LogLevelInfo, fmt.Sprintf("MOCK EMAIL SENT: Reset Token for %s: %s", req.Email, token))
-
-	res := response.Response(http.StatusOK, "Password reset instructions sent to your email", logId, token)
-	ctx.JSON(http.StatusOK, res)
-}
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.ChangeExpiredPassword; ERROR: %s;", logPrefix, err))
+		if errors.Is(err, serviceuser.ErrChangeTicketInvalid) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "Invalid or expired ticket"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
 
-func (h *HandlerUser) ResetPassword(ctx *gin.Context) {
-	var req dto.ResetPasswordRequest
-	logId := utils.GenerateLogId(ctx)
-	logPrefix := "[UserHandler][ResetPassword]"
+		if perr := new(passwordpolicy.Error); errors.As(err, &perr) {
+			h.respondWeakPassword(ctx, logId, perr)
+			return
+		}
 
-	if err := ctx.BindJSON(&req); err != nil {
-		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
-		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
-		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
-		ctx.JSON(http.StatusBadRequest, res)
-		return
-	}
+		if errors.Is(err, servicepasswordhistory.ErrPasswordReused) {
+			res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
 
-	if err := h.Service.ResetPassword(req); err != nil {
-		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.ResetPassword; ERROR: %s;", logPrefix, err))
-		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
-		res.Error = err.Error()
-		ctx.JSON(http.StatusBadRequest, res)
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusInternalServerError, Message: "Unable to change password"}
+		ctx.JSON(http.StatusInternalServerError, res)
 		return
 	}
 
-	res := response.Response(http.StatusOK, "Password reset successfully", logId, nil)
+	res := response.Response(http.StatusOK, "User password changed successfully", logId, data)
 	ctx.JSON(http.StatusOK, res)
 }
 
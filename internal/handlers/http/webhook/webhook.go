@@ -0,0 +1,243 @@
+package handlerwebhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	domainwebhook "service-sender/internal/domain/webhook"
+	"service-sender/internal/dto"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	servicewebhook "service-sender/internal/services/webhook"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandlerWebhook struct {
+	Service interfacewebhook.ServiceWebhookInterface
+}
+
+func NewWebhookHandler(s interfacewebhook.ServiceWebhookInterface) *HandlerWebhook {
+	return &HandlerWebhook{Service: s}
+}
+
+// GetEvents lists the event types endpoints can subscribe to.
+func (h *HandlerWebhook) GetEvents(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	res := response.Response(http.StatusOK, "Get webhook events successfully", logId, domainwebhook.Events)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) CreateEndpoint(ctx *gin.Context) {
+	var req dto.WebhookEndpointCreate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][CreateEndpoint]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	authData := utils.GetAuthData(ctx)
+	data, err := h.Service.CreateEndpoint(req, utils.InterfaceString(authData["user_id"]))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.CreateEndpoint; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook endpoint not found")
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Webhook endpoint created successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data.Endpoint)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (h *HandlerWebhook) GetEndpoint(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][GetEndpoint]"
+
+	data, err := h.Service.GetEndpoint(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetEndpoint; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook endpoint not found")
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get webhook endpoint successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) GetEndpoints(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][GetEndpoints]"
+
+	params, err := filter.GetBaseParams(ctx, "created_at", "desc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"is_active", "event"})
+
+	data, total, err := h.Service.GetEndpoints(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetEndpoints; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) UpdateEndpoint(ctx *gin.Context) {
+	var req dto.WebhookEndpointUpdate
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][UpdateEndpoint]"
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.UpdateEndpoint(ctx.Param("id"), req)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.UpdateEndpoint; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook endpoint not found")
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Webhook endpoint updated successfully", logId, data)
+	logger.WriteLogWithContext(ctx, logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) RotateSecret(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][RotateSecret]"
+
+	data, err := h.Service.RotateSecret(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.RotateSecret; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook endpoint not found")
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Webhook secret rotated successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) DeleteEndpoint(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][DeleteEndpoint]"
+
+	if err := h.Service.DeleteEndpoint(ctx.Param("id")); err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.DeleteEndpoint; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook endpoint not found")
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Webhook endpoint deleted successfully", logId, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetDeliveries lists deliveries, optionally for one endpoint when routed
+// under /endpoints/:id.
+func (h *HandlerWebhook) GetDeliveries(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][GetDeliveries]"
+
+	params, err := filter.GetBaseParams(ctx, "created_at", "desc", 10)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; GetBaseParams; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	params.Filters = filter.WhitelistStringFilter(params.Filters, []string{"endpoint_id", "event_id", "event_type", "status"})
+	if id := ctx.Param("id"); id != "" {
+		if _, err := h.Service.GetEndpoint(id); err != nil {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetEndpoint; Error: %+v", logPrefix, err))
+			h.respondError(ctx, logId, err, "webhook endpoint not found")
+			return
+		}
+		params.Filters["endpoint_id"] = id
+	}
+
+	data, total, err := h.Service.GetDeliveries(params)
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetDeliveries; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(total), params.Page, params.Limit, logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) GetDelivery(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][GetDelivery]"
+
+	data, err := h.Service.GetDelivery(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.GetDelivery; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook delivery not found")
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Get webhook delivery successfully", logId, data)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWebhook) Redeliver(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := "[WebhookHandler][Redeliver]"
+
+	data, err := h.Service.Redeliver(ctx.Param("id"))
+	if err != nil {
+		logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Redeliver; Error: %+v", logPrefix, err))
+		h.respondError(ctx, logId, err, "webhook delivery not found")
+		return
+	}
+
+	res := response.Response(http.StatusAccepted, "Webhook delivery queued successfully", logId, data)
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+func (h *HandlerWebhook) respondError(ctx *gin.Context, logId uuid.UUID, err error, notFound string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: notFound}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, servicewebhook.ErrWebhookInvalidURL),
+		errors.Is(err, servicewebhook.ErrWebhookUnknownEvent):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
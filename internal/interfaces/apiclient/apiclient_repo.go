@@ -0,0 +1,36 @@
+package interfaceapiclient
+
+import (
+	"context"
+	"time"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	"service-sender/pkg/filter"
+)
+
+type RepoApiClientInterface interface {
+	Store(m domainapiclient.ApiClient) error
+	GetByID(id string) (domainapiclient.ApiClient, error)
+	GetByName(name string) (domainapiclient.ApiClient, error)
+	GetByKeyPrefix(prefix string) (domainapiclient.ApiClient, error)
+	GetAll(params filter.BaseParams) ([]domainapiclient.ApiClient, int64, error)
+	Update(m domainapiclient.ApiClient) error
+	TouchLastUsed(id string, at time.Time) error
+	Delete(id string) error
+}
+
+// RepoNonceInterface remembers request nonces so a signed request cannot be
+// replayed within the signature tolerance.
+type RepoNonceInterface interface {
+	Claim(ctx context.Context, clientId, nonce string, ttl time.Duration) (bool, error)
+}
+
+// RepoUsageInterface meters client sends per quota window.
+type RepoUsageInterface interface {
+	// Consume adds cost to every window unless that would push a limited
+	// window past its limit. It returns the usage per window after the call
+	// and the index of the window that refused it, or -1.
+	Consume(ctx context.Context, clientId string, cost int, windows []domainapiclient.QuotaWindow) ([]int, int, error)
+	Refund(ctx context.Context, clientId string, cost int, windows []domainapiclient.QuotaWindow) error
+	Get(ctx context.Context, clientId string, windows []domainapiclient.QuotaWindow) ([]int, error)
+}
@@ -0,0 +1,24 @@
+package interfaceapiclient
+
+import (
+	"context"
+	"time"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	"service-sender/internal/dto"
+	"service-sender/pkg/filter"
+)
+
+type ServiceApiClientInterface interface {
+	Create(req dto.ApiClientCreate, actorId string) (dto.ApiClientKey, error)
+	GetByID(id string) (domainapiclient.ApiClient, error)
+	GetAll(params filter.BaseParams) ([]domainapiclient.ApiClient, int64, error)
+	Update(id string, req dto.ApiClientUpdate) (domainapiclient.ApiClient, error)
+	Rotate(id string, req dto.ApiClientRotate) (dto.ApiClientKey, error)
+	Delete(id string) error
+
+	Authenticate(apiKey string) (domainapiclient.ApiClient, error)
+	ConsumeQuota(ctx context.Context, client domainapiclient.ApiClient, cost int) (dto.QuotaCheck, error)
+	RefundQuota(ctx context.Context, client domainapiclient.ApiClient, cost int, at time.Time) error
+	GetUsage(ctx context.Context, id string) (dto.ApiClientUsage, error)
+}
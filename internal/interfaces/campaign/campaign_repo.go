@@ -0,0 +1,38 @@
+package interfacecampaign
+
+import (
+	"time"
+
+	domaincampaign "service-sender/internal/domain/campaign"
+	domainuser "service-sender/internal/domain/user"
+	"service-sender/pkg/filter"
+)
+
+type RepoCampaignInterface interface {
+	Store(m domaincampaign.Campaign) error
+	GetByID(id string) (domaincampaign.Campaign, error)
+	GetAll(params filter.BaseParams) ([]domaincampaign.Campaign, int64, error)
+	Update(m domaincampaign.Campaign) error
+	Delete(id string) error
+
+	// SetStatus moves the campaign to status if it is currently in one of
+	// from, also writing fields. It reports false when the status did not
+	// match, so concurrent controls cannot overwrite each other.
+	SetStatus(id string, from []string, status string, fields map[string]interface{}) (bool, error)
+	// GetRunnable returns running campaigns whose lease has run out, that is,
+	// campaigns due for their next batch.
+	GetRunnable(now time.Time) ([]domaincampaign.Campaign, error)
+	// Lease claims a running campaign until the given time. It reports false
+	// when another worker holds it.
+	Lease(id string, now, until time.Time) (bool, error)
+	Release(id string, next time.Time) error
+	// Advance moves the cursor and adds to the progress counters.
+	Advance(id, cursorId string, sent, failed, skipped int, lastError string) error
+}
+
+// RepoSegmentInterface expands a segment over the users table.
+type RepoSegmentInterface interface {
+	Count(segment domaincampaign.Segment, createdBefore *time.Time) (int64, error)
+	// NextBatch returns up to limit users after afterId, ordered by id.
+	NextBatch(segment domaincampaign.Segment, createdBefore *time.Time, afterId string, limit int) ([]domainuser.Users, error)
+}
@@ -0,0 +1,26 @@
+package interfacecampaign
+
+import (
+	"context"
+
+	domaincampaign "service-sender/internal/domain/campaign"
+	"service-sender/internal/dto"
+	"service-sender/pkg/filter"
+)
+
+type ServiceCampaignInterface interface {
+	Create(req dto.CampaignCreate, actorId string) (domaincampaign.Campaign, error)
+	GetByID(id string) (domaincampaign.Campaign, error)
+	GetAll(params filter.BaseParams) ([]domaincampaign.Campaign, int64, error)
+	Update(id string, req dto.CampaignUpdate) (domaincampaign.Campaign, error)
+	Delete(id string) error
+	PreviewSegment(req dto.CampaignSegment) (dto.SegmentPreview, error)
+
+	Start(id string) (domaincampaign.Campaign, error)
+	Pause(id string) (domaincampaign.Campaign, error)
+	Resume(id string) (domaincampaign.Campaign, error)
+	Cancel(id string) (domaincampaign.Campaign, error)
+
+	// Run processes running campaigns until ctx is done.
+	Run(ctx context.Context)
+}
@@ -8,4 +8,6 @@ import (
 
 type ServiceEmailInterface interface {
 	Send(ctx context.Context, req dto.SendEmailRequest, appName string) (int, string, error)
+	Preview(ctx context.Context, key string, req dto.EmailTemplatePreviewRequest, appName string) (dto.EmailTemplatePreview, error)
+	SendTest(ctx context.Context, key string, req dto.EmailTemplatePreviewRequest, appName, userId string) (dto.EmailTemplatePreview, string, error)
 }
@@ -0,0 +1,23 @@
+package interfaceemaildelivery
+
+import (
+	"time"
+
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	"service-sender/pkg/filter"
+)
+
+type RepoEmailDeliveryInterface interface {
+	Store(m []domainemaildelivery.EmailDelivery) error
+	GetByMessageId(messageId string) ([]domainemaildelivery.EmailDelivery, error)
+	UpdateStatus(id, status, detail string, at time.Time) error
+	GetAll(params filter.BaseParams) ([]domainemaildelivery.EmailDelivery, int64, error)
+}
+
+type RepoSuppressionInterface interface {
+	Upsert(m domainemaildelivery.Suppression) error
+	Insert(m domainemaildelivery.Suppression) error
+	GetSuppressed(emails []string, includeUnsubscribes bool) ([]string, error)
+	GetAll(params filter.BaseParams) ([]domainemaildelivery.Suppression, int64, error)
+	Delete(email string) error
+}
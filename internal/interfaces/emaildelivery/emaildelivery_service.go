@@ -0,0 +1,19 @@
+package interfaceemaildelivery
+
+import (
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	"service-sender/internal/dto"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/mailer"
+)
+
+type ServiceEmailDeliveryInterface interface {
+	Record(emailType, appName, subject string, sent []mailer.SentMessage) error
+	FilterSuppressed(emails []string, emailType string) ([]string, error)
+	HandleEvents(events []mailer.DeliveryEvent) (dto.WebhookResult, error)
+
+	GetDeliveries(params filter.BaseParams) ([]domainemaildelivery.EmailDelivery, int64, error)
+	GetSuppressions(params filter.BaseParams) ([]domainemaildelivery.Suppression, int64, error)
+	CreateSuppression(req dto.SuppressionCreate, actorId string) (domainemaildelivery.Suppression, error)
+	DeleteSuppression(email string) error
+}
@@ -0,0 +1,18 @@
+package interfaceemailtemplate
+
+import (
+	domainemailtemplate "service-sender/internal/domain/emailtemplate"
+	"service-sender/pkg/filter"
+)
+
+type RepoEmailTemplateInterface interface {
+	GetActive(key, locale string) (domainemailtemplate.EmailTemplate, error)
+	GetVersion(key, locale string, version int) (domainemailtemplate.EmailTemplate, error)
+	GetVersions(key, locale string) ([]domainemailtemplate.EmailTemplate, error)
+	GetLatestVersion(key, locale string) (int, error)
+	GetAll(params filter.BaseParams) ([]domainemailtemplate.EmailTemplate, int64, error)
+
+	StoreVersion(m domainemailtemplate.EmailTemplate) error
+	ActivateVersion(key, locale string, version int) error
+	Delete(key, locale string) error
+}
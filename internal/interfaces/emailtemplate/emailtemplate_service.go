@@ -0,0 +1,19 @@
+package interfaceemailtemplate
+
+import (
+	domainemailtemplate "service-sender/internal/domain/emailtemplate"
+	"service-sender/internal/dto"
+	"service-sender/pkg/filter"
+)
+
+type ServiceEmailTemplateInterface interface {
+	Create(req dto.EmailTemplateCreate, actorId string) (domainemailtemplate.EmailTemplate, error)
+	GetActive(key, locale string) (domainemailtemplate.EmailTemplate, error)
+	GetAll(params filter.BaseParams) ([]domainemailtemplate.EmailTemplate, int64, error)
+	GetVersions(key, locale string) ([]domainemailtemplate.EmailTemplate, error)
+	Update(key string, req dto.EmailTemplateUpdate, actorId string) (domainemailtemplate.EmailTemplate, error)
+	Rollback(key string, req dto.EmailTemplateRollback) (domainemailtemplate.EmailTemplate, error)
+	Delete(key, locale string) error
+
+	Resolve(key, locale string) (domainemailtemplate.EmailTemplate, bool)
+}
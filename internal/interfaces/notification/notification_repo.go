@@ -0,0 +1,30 @@
+package interfacenotification
+
+import (
+	"context"
+	"time"
+
+	domainnotification "service-sender/internal/domain/notification"
+	"service-sender/pkg/filter"
+)
+
+type RepoNotificationInterface interface {
+	Store(m domainnotification.Notification) error
+	// StoreForRole copies m into the inbox of every active user holding role
+	// and returns the rows created.
+	StoreForRole(m domainnotification.Notification, role string) ([]domainnotification.Notification, error)
+	GetByUser(userId string, params filter.BaseParams) ([]domainnotification.Notification, int64, error)
+	// SetRead sets or, with a nil readAt, clears the read time of one of the
+	// user's notifications.
+	SetRead(userId, id string, readAt *time.Time) error
+	MarkAllRead(userId string, readAt time.Time) (int64, error)
+	CountUnread(userId string) (int64, error)
+}
+
+// RepoStreamInterface carries inbox events between instances, so a stream
+// opened on one instance sees notifications created on another.
+type RepoStreamInterface interface {
+	Publish(ctx context.Context, event domainnotification.Event) error
+	// Listen calls fn for every published event until ctx is done.
+	Listen(ctx context.Context, fn func(domainnotification.Event)) error
+}
@@ -0,0 +1,20 @@
+package interfacenotification
+
+import (
+	domainnotification "service-sender/internal/domain/notification"
+	"service-sender/internal/dto"
+	"service-sender/pkg/filter"
+)
+
+type ServiceNotificationInterface interface {
+	Create(req dto.NotificationCreate) (dto.NotificationCreated, error)
+	GetAll(userId string, params filter.BaseParams) ([]domainnotification.Notification, int64, error)
+	MarkRead(userId, id string) error
+	MarkUnread(userId, id string) error
+	MarkAllRead(userId string) error
+	UnreadCount(userId string) (dto.UnreadCount, error)
+
+	// Subscribe returns the live events of the user's inbox. The returned
+	// func must be called once the caller stops reading.
+	Subscribe(userId string) (<-chan domainnotification.Event, func())
+}
@@ -0,0 +1,14 @@
+package interfacenotify
+
+import (
+	"context"
+
+	"service-sender/internal/dto"
+)
+
+type ServiceNotifyInterface interface {
+	// Notify routes the notification to the channels of its rule. Channel
+	// problems are reported per channel; only request level problems, such
+	// as an unknown key or user, are returned as errors.
+	Notify(ctx context.Context, req dto.NotifyRequest, appName string) (dto.NotifyResult, error)
+}
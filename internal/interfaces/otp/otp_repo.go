@@ -5,18 +5,25 @@ import (
 	"time"
 )
 
+// RepoOTPInterface keeps codes, attempts, cooldowns and send counts apart
+// per purpose, so a registration code cannot be used to reset a password.
 type RepoOTPInterface interface {
-	SetOTP(ctx context.Context, email, hashed string, ttl time.Duration) error
-	GetOTP(ctx context.Context, email string) (string, error)
-	DeleteOTP(ctx context.Context, email string) error
+	SetOTP(ctx context.Context, purpose, email, hashed string, ttl time.Duration) error
+	GetOTP(ctx context.Context, purpose, email string) (string, error)
+	DeleteOTP(ctx context.Context, purpose, email string) error
 
-	IncrementAttempts(ctx context.Context, email string, ttl time.Duration) (int, error)
-	ResetAttempts(ctx context.Context, email string) error
+	IncrementAttempts(ctx context.Context, purpose, email string, ttl time.Duration) (int, error)
+	ResetAttempts(ctx context.Context, purpose, email string) error
 
-	SetCooldown(ctx context.Context, email string, ttl time.Duration) error
-	GetCooldownTTL(ctx context.Context, email string) (time.Duration, error)
-	ClearCooldown(ctx context.Context, email string) error
+	SetCooldown(ctx context.Context, purpose, email string, ttl time.Duration) error
+	GetCooldownTTL(ctx context.Context, purpose, email string) (time.Duration, error)
+	ClearCooldown(ctx context.Context, purpose, email string) error
 
-	IncrementSendCount(ctx context.Context, email string, ttl time.Duration) (int, time.Duration, error)
-	ClearSendCount(ctx context.Context, email string) error
+	IncrementSendCount(ctx context.Context, purpose, email string, ttl time.Duration) (int, time.Duration, error)
+	ClearSendCount(ctx context.Context, purpose, email string) error
+
+	// SetVerified remembers a verified address that has no account yet, so
+	// the account created for it starts out verified.
+	SetVerified(ctx context.Context, purpose, email string, ttl time.Duration) error
+	ConsumeVerified(ctx context.Context, purpose, email string) (bool, error)
 }
@@ -1,8 +1,22 @@
 package interfaceotp
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type ServiceOTPInterface interface {
-	SendRegisterOTP(ctx context.Context, email, appName string) error
+	SendRegisterOTP(ctx context.Context, email, appName, locale string) error
 	VerifyRegisterOTP(ctx context.Context, email, code string) error
+	// ConsumeRegisterVerification reports whether email passed registration
+	// OTP verification before it had an account, and forgets it.
+	ConsumeRegisterVerification(ctx context.Context, email string) bool
+
+	// Issue creates a code for purpose and hands it to deliver, applying the
+	// cooldown and rate limit of that purpose.
+	Issue(ctx context.Context, purpose, email string, deliver func(email, code string, ttl time.Duration) error) error
+	// Verify checks a code issued for purpose and uses it up.
+	Verify(ctx context.Context, purpose, email, code string) error
+	// ClearThrottle lifts the cooldown and rate limit of purpose for email.
+	ClearThrottle(ctx context.Context, purpose, email string) error
 }
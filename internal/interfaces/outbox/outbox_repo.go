@@ -0,0 +1,21 @@
+package interfaceoutbox
+
+import (
+	"time"
+
+	domainoutbox "service-sender/internal/domain/outbox"
+)
+
+type RepoOutboxInterface interface {
+	// RegisterConsumer records name as a relay target if it is new.
+	RegisterConsumer(name string) error
+	// Process hands up to limit events the consumer has not handled yet to
+	// handle, oldest first, and marks each one handled as it returns nil. It
+	// stops at the first error, which it returns along with the count of
+	// events handled before it. Only one relay processes a consumer at a
+	// time, holding it for at most lease; the others see a count of zero.
+	Process(consumer string, limit int, lease time.Duration, handle func(domainoutbox.Event) error) (int, error)
+	// Prune deletes events created before the given time that every
+	// consumer registered by then has handled.
+	Prune(before time.Time) (int64, error)
+}
@@ -0,0 +1,20 @@
+package interfaceoutbox
+
+import (
+	"context"
+
+	domainoutbox "service-sender/internal/domain/outbox"
+)
+
+// ConsumerInterface receives relayed outbox events. Consume may be called
+// again for an event it already handled if the relay dies before recording
+// it, so consumers should use the event id to ignore repeats.
+type ConsumerInterface interface {
+	Name() string
+	Consume(event domainoutbox.Event) error
+}
+
+type ServiceOutboxInterface interface {
+	// Run relays outbox events to the consumers until ctx is done.
+	Run(ctx context.Context)
+}
@@ -0,0 +1,12 @@
+package interfacepasswordhistory
+
+import (
+	domainpasswordhistory "service-sender/internal/domain/passwordhistory"
+)
+
+type RepoPasswordHistoryInterface interface {
+	// Store adds m and drops all but the keep most recent entries of its
+	// user.
+	Store(m domainpasswordhistory.Entry, keep int) error
+	GetRecent(userId string, limit int) ([]domainpasswordhistory.Entry, error)
+}
@@ -0,0 +1,16 @@
+package interfacepasswordhistory
+
+import (
+	domainuser "service-sender/internal/domain/user"
+)
+
+type ServicePasswordHistoryInterface interface {
+	// CheckReuse returns an error when password is user's current password
+	// or one of their recent ones.
+	CheckReuse(user domainuser.Users, password string) error
+	// Record remembers hash as the password user has just set.
+	Record(userId, hash string) error
+	// Expired reports whether user's password is older than the maximum
+	// age, if there is one.
+	Expired(user domainuser.Users) bool
+}
@@ -1,17 +1,20 @@
 package interfacepermission
 
 import (
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainpermission "service-sender/internal/domain/permission"
 	"service-sender/pkg/filter"
 )
 
+// RepoPermissionInterface writes events, when given, to the outbox in the
+// same transaction as the change.
 type RepoPermissionInterface interface {
-	Store(m domainpermission.Permission) error
+	Store(m domainpermission.Permission, events ...domainoutbox.Event) error
 	GetByID(id string) (domainpermission.Permission, error)
 	GetByName(name string) (domainpermission.Permission, error)
 	GetAll(params filter.BaseParams) ([]domainpermission.Permission, int64, error)
-	Update(m domainpermission.Permission) error
-	Delete(id string) error
+	Update(m domainpermission.Permission, events ...domainoutbox.Event) error
+	Delete(id string, events ...domainoutbox.Event) error
 
 	GetByResource(resource string) ([]domainpermission.Permission, error)
 	GetUserPermissions(userId string) ([]domainpermission.Permission, error)
@@ -0,0 +1,13 @@
+package interfacepreference
+
+import (
+	domainpreference "service-sender/internal/domain/preference"
+)
+
+type RepoPreferenceInterface interface {
+	GetByUser(userId string) ([]domainpreference.Preference, error)
+	Upsert(m []domainpreference.Preference) error
+	// GetOptedOutEmails returns the emails, out of emails, of users who turned
+	// off category on channel.
+	GetOptedOutEmails(emails []string, category, channel string) ([]string, error)
+}
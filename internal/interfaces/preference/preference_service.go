@@ -0,0 +1,14 @@
+package interfacepreference
+
+import (
+	"service-sender/internal/dto"
+)
+
+type ServicePreferenceInterface interface {
+	GetPreferences(userId string) ([]dto.PreferenceView, error)
+	UpdatePreferences(userId string, req dto.PreferenceUpdate) ([]dto.PreferenceView, error)
+
+	// FilterOptedOut returns the subset of emails belonging to users who do
+	// not want category on channel. Emails without a user are never returned.
+	FilterOptedOut(emails []string, category, channel string) ([]string, error)
+}
@@ -0,0 +1,17 @@
+package interfacepush
+
+import (
+	"time"
+
+	domainpush "service-sender/internal/domain/push"
+)
+
+type RepoPushInterface interface {
+	// Upsert stores the subscription, taking the endpoint over when another
+	// user or login registered it before.
+	Upsert(m domainpush.Subscription) (domainpush.Subscription, error)
+	GetByUser(userId string) ([]domainpush.Subscription, error)
+	DeleteByEndpoint(userId, endpoint string) error
+	Delete(id string) error
+	MarkSuccess(id string, at time.Time) error
+}
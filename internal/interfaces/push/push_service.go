@@ -0,0 +1,23 @@
+package interfacepush
+
+import (
+	"context"
+
+	domainpush "service-sender/internal/domain/push"
+	"service-sender/internal/dto"
+)
+
+type ServicePushInterface interface {
+	PublicKey() (dto.VapidPublicKey, error)
+	Subscribe(userId, tokenId, userAgent string, req dto.PushSubscriptionCreate) (domainpush.Subscription, error)
+	Unsubscribe(userId, endpoint string) error
+	GetSubscriptions(userId string) ([]domainpush.Subscription, error)
+
+	// SendToUser pushes msg to every subscription of the user and returns how
+	// many accepted it or were queued to be retried. Subscriptions the push
+	// service reports gone are deleted.
+	SendToUser(ctx context.Context, userId string, msg dto.PushMessage) (int, error)
+
+	// Run retries the sends SendToUser queued until ctx is done.
+	Run(ctx context.Context)
+}
@@ -3,6 +3,11 @@ package interfacereset
 import "context"
 
 type ServicePasswordResetInterface interface {
-	RequestReset(ctx context.Context, email, appName string) error
+	RequestReset(ctx context.Context, email, appName, locale, mode string) (string, error)
+	// RequestLockReset sends the reset that unlocks an account locked from a
+	// security alert. The cooldown and rate limit do not apply to it.
+	RequestLockReset(ctx context.Context, email, appName, locale string) error
 	VerifyReset(ctx context.Context, token string) (string, error)
+	VerifyResetCode(ctx context.Context, email, code, appName string) (string, error)
+	CompleteReset(ctx context.Context, token, newPassword string) error
 }
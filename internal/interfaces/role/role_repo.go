@@ -1,23 +1,26 @@
 package interfacerole
 
 import (
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainrole "service-sender/internal/domain/role"
 	"service-sender/pkg/filter"
 )
 
+// RepoRoleInterface writes events, when given, to the outbox in the same
+// transaction as the change.
 type RepoRoleInterface interface {
-	Store(m domainrole.Role) error
+	Store(m domainrole.Role, events ...domainoutbox.Event) error
 	GetByID(id string) (domainrole.Role, error)
 	GetByName(name string) (domainrole.Role, error)
 	GetAll(params filter.BaseParams) ([]domainrole.Role, int64, error)
-	Update(m domainrole.Role) error
-	Delete(id string) error
+	Update(m domainrole.Role, events ...domainoutbox.Event) error
+	Delete(id string, events ...domainoutbox.Event) error
 
-	AssignPermissions(roleId string, permissionIds []string) error
+	AssignPermissions(roleId string, permissionIds []string, events ...domainoutbox.Event) error
 	RemovePermissions(roleId string, permissionIds []string) error
 	GetRolePermissions(roleId string) ([]string, error)
 
-	AssignMenus(roleId string, menuIds []string) error
+	AssignMenus(roleId string, menuIds []string, events ...domainoutbox.Event) error
 	RemoveMenus(roleId string, menuIds []string) error
 	GetRoleMenus(roleId string) ([]string, error)
 }
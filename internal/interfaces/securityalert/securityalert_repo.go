@@ -0,0 +1,25 @@
+package interfacesecurityalert
+
+import (
+	"time"
+
+	domainsecurityalert "service-sender/internal/domain/securityalert"
+)
+
+type RepoAlertInterface interface {
+	Store(m domainsecurityalert.Alert) error
+	Update(m domainsecurityalert.Alert) error
+	GetByEventId(eventId string) (domainsecurityalert.Alert, error)
+	GetByTokenHash(hash string) (domainsecurityalert.Alert, error)
+	MarkSent(id string, at time.Time) error
+	// MarkUsed returns gorm.ErrRecordNotFound when the alert was used
+	// already, so a lock link works once.
+	MarkUsed(id string, at time.Time) error
+}
+
+type RepoDeviceInterface interface {
+	CountByUser(userId string) (int64, error)
+	// Touch records a login from the device, reporting whether it is the
+	// first from it.
+	Touch(m domainsecurityalert.Device) (bool, error)
+}
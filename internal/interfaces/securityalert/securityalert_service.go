@@ -0,0 +1,21 @@
+package interfacesecurityalert
+
+import (
+	"context"
+
+	domainuser "service-sender/internal/domain/user"
+	interfaceoutbox "service-sender/internal/interfaces/outbox"
+)
+
+type ServiceSecurityAlertInterface interface {
+	// ConsumerInterface alerts users to the account changes relayed from
+	// the outbox.
+	interfaceoutbox.ConsumerInterface
+
+	// CheckLogin records the device user logged in from and alerts them
+	// when they have not used it before.
+	CheckLogin(user domainuser.Users, ip, userAgent, appName string) error
+	// Lock follows an "it wasn't me" link: the account is locked, its
+	// sessions revoked and a password reset sent to the alerted address.
+	Lock(ctx context.Context, token string) error
+}
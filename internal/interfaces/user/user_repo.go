@@ -1,16 +1,24 @@
 package interfaceuser
 
 import (
+	"time"
+
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainuser "service-sender/internal/domain/user"
 	"service-sender/pkg/filter"
 )
 
+// RepoUserInterface writes events, when given, to the outbox in the same
+// transaction as the change.
 type RepoUserInterface interface {
-	Store(m domainuser.Users) error
+	Store(m domainuser.Users, events ...domainoutbox.Event) error
 	GetByEmail(email string) (domainuser.Users, error)
 	GetByPhone(phone string) (domainuser.Users, error)
 	GetByID(id string) (domainuser.Users, error)
 	GetAll(params filter.BaseParams) ([]domainuser.Users, int64, error)
-	Update(m domainuser.Users) error
-	Delete(id string) error
+	Update(m domainuser.Users, events ...domainoutbox.Event) error
+	Delete(id string, events ...domainoutbox.Event) error
+	// MarkEmailVerified stamps the account for email as verified at at,
+	// unless it already is. It reports whether an account was found.
+	MarkEmailVerified(email string, at time.Time) (bool, error)
 }
@@ -4,6 +4,7 @@ import (
 	domainuser "service-sender/internal/domain/user"
 	"service-sender/internal/dto"
 	"service-sender/pkg/filter"
+	"service-sender/pkg/passwordpolicy"
 )
 
 type ServiceUserInterface interface {
@@ -17,7 +18,7 @@ type ServiceUserInterface interface {
 	GetAllUsers(params filter.BaseParams, currentUserRole string) ([]domainuser.Users, int64, error)
 	Update(id, role string, req dto.UserUpdate) (domainuser.Users, error)
 	ChangePassword(id string, req dto.ChangePassword) (domainuser.Users, error)
-	ForgotPassword(req dto.ForgotPasswordRequest) (string, error)
-	ResetPassword(req dto.ResetPasswordRequest) error
+	ChangeExpiredPassword(req dto.ChangeExpiredPassword) (domainuser.Users, error)
+	CheckPassword(req dto.PasswordCheck) passwordpolicy.Result
 	Delete(id string) error
 }
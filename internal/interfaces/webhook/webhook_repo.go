@@ -0,0 +1,36 @@
+package interfacewebhook
+
+import (
+	"time"
+
+	domainwebhook "service-sender/internal/domain/webhook"
+	"service-sender/pkg/filter"
+)
+
+type RepoEndpointInterface interface {
+	Store(m domainwebhook.Endpoint) error
+	GetByID(id string) (domainwebhook.Endpoint, error)
+	GetAll(params filter.BaseParams) ([]domainwebhook.Endpoint, int64, error)
+	// GetActive returns every active endpoint; the caller matches events.
+	GetActive() ([]domainwebhook.Endpoint, error)
+	Update(m domainwebhook.Endpoint) error
+	Delete(id string) error
+}
+
+type RepoDeliveryInterface interface {
+	// StoreMany skips deliveries that already exist for the same endpoint
+	// and event.
+	StoreMany(m []domainwebhook.Delivery) error
+	GetByID(id string) (domainwebhook.Delivery, error)
+	GetAll(params filter.BaseParams) ([]domainwebhook.Delivery, int64, error)
+	// Claim leases up to limit due deliveries until the given time, skipping
+	// rows another worker is claiming at the same moment.
+	Claim(now, until time.Time, limit int) ([]domainwebhook.Delivery, error)
+	// Finish records the outcome of an attempt.
+	Finish(id string, fields map[string]interface{}) error
+	// Requeue makes a delivery due at now, whatever its status.
+	Requeue(id string, now time.Time) error
+
+	StoreAttempt(m domainwebhook.Attempt) error
+	GetAttempts(deliveryId string) ([]domainwebhook.Attempt, error)
+}
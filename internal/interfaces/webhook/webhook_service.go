@@ -0,0 +1,34 @@
+package interfacewebhook
+
+import (
+	"context"
+
+	domainwebhook "service-sender/internal/domain/webhook"
+	"service-sender/internal/dto"
+	"service-sender/pkg/filter"
+)
+
+// PublisherInterface is what emitting services depend on. Publish never
+// fails the caller: events are queued best effort and errors are logged.
+type PublisherInterface interface {
+	Publish(eventType string, data map[string]interface{})
+}
+
+type ServiceWebhookInterface interface {
+	PublisherInterface
+
+	CreateEndpoint(req dto.WebhookEndpointCreate, actorId string) (dto.WebhookEndpointSecret, error)
+	GetEndpoint(id string) (domainwebhook.Endpoint, error)
+	GetEndpoints(params filter.BaseParams) ([]domainwebhook.Endpoint, int64, error)
+	UpdateEndpoint(id string, req dto.WebhookEndpointUpdate) (domainwebhook.Endpoint, error)
+	DeleteEndpoint(id string) error
+	RotateSecret(id string) (dto.WebhookEndpointSecret, error)
+
+	GetDeliveries(params filter.BaseParams) ([]domainwebhook.Delivery, int64, error)
+	// GetDelivery returns the delivery with its attempt log.
+	GetDelivery(id string) (domainwebhook.Delivery, error)
+	Redeliver(id string) (domainwebhook.Delivery, error)
+
+	// Run delivers queued events until ctx is done.
+	Run(ctx context.Context)
+}
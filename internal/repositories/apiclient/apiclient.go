@@ -0,0 +1,116 @@
+package repositoryapiclient
+
+import (
+	"fmt"
+	domainapiclient "service-sender/internal/domain/apiclient"
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+	"service-sender/pkg/filter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewApiClientRepo(db *gorm.DB) interfaceapiclient.RepoApiClientInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) Store(m domainapiclient.ApiClient) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *repo) GetByID(id string) (ret domainapiclient.ApiClient, err error) {
+	if err = r.DB.Where("id = ?", id).First(&ret).Error; err != nil {
+		return domainapiclient.ApiClient{}, err
+	}
+	return ret, nil
+}
+
+func (r *repo) GetByName(name string) (ret domainapiclient.ApiClient, err error) {
+	if err = r.DB.Where("name = ?", name).First(&ret).Error; err != nil {
+		return domainapiclient.ApiClient{}, err
+	}
+	return ret, nil
+}
+
+// GetByKeyPrefix finds the client owning prefix as its current or, during a
+// rotation grace period, previous key.
+func (r *repo) GetByKeyPrefix(prefix string) (ret domainapiclient.ApiClient, err error) {
+	if err = r.DB.Where("key_prefix = ? OR previous_key_prefix = ?", prefix, prefix).First(&ret).Error; err != nil {
+		return domainapiclient.ApiClient{}, err
+	}
+	return ret, nil
+}
+
+func (r *repo) GetAll(params filter.BaseParams) (ret []domainapiclient.ApiClient, totalData int64, err error) {
+	query := r.DB.Model(&domainapiclient.ApiClient{})
+
+	if params.Search != "" {
+		searchPattern := "%" + params.Search + "%"
+		query = query.Where("LOWER(name) LIKE LOWER(?) OR LOWER(description) LIKE LOWER(?) OR key_prefix = ?", searchPattern, searchPattern, params.Search)
+	}
+
+	for key, value := range params.Filters {
+		if value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		default:
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"name":         true,
+			"is_active":    true,
+			"last_used_at": true,
+			"created_at":   true,
+			"updated_at":   true,
+		}
+
+		if _, ok := validColumns[params.OrderBy]; !ok {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *repo) Update(m domainapiclient.ApiClient) error {
+	return r.DB.Save(&m).Error
+}
+
+func (r *repo) TouchLastUsed(id string, at time.Time) error {
+	return r.DB.Model(&domainapiclient.ApiClient{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+func (r *repo) Delete(id string) error {
+	result := r.DB.Where("id = ?", id).Delete(&domainapiclient.ApiClient{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
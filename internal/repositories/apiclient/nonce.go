@@ -0,0 +1,28 @@
+package repositoryapiclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const apiClientNonceKeyPrefix = "api_client:nonce:"
+
+type redisNonceRepo struct {
+	Redis *redis.Client
+}
+
+// NewNonceRepo stores nonces in Redis so every instance sees them.
+func NewNonceRepo(redisClient *redis.Client) interfaceapiclient.RepoNonceInterface {
+	return &redisNonceRepo{Redis: redisClient}
+}
+
+// Claim records nonce for clientId and reports whether it was unused.
+func (r *redisNonceRepo) Claim(ctx context.Context, clientId, nonce string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%s:%s", apiClientNonceKeyPrefix, clientId, nonce)
+	return r.Redis.SetNX(ctx, key, "1", ttl).Result()
+}
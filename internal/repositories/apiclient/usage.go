@@ -0,0 +1,136 @@
+package repositoryapiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const apiClientUsageKeyPrefix = "api_client:usage:"
+
+// consumeScript checks every limited window before incrementing any of them,
+// so a refused request never counts against the windows it did fit in.
+// KEYS are the window counters; ARGV is the cost followed by each window's
+// limit and then each window's TTL in seconds.
+var consumeScript = redis.NewScript(`
+local cost = tonumber(ARGV[1])
+local n = #KEYS
+for i = 1, n do
+	local current = tonumber(redis.call('GET', KEYS[i]) or '0')
+	local limit = tonumber(ARGV[1 + i])
+	if limit > 0 and current + cost > limit then
+		return {0, i}
+	end
+end
+local out = {1}
+for i = 1, n do
+	local value = redis.call('INCRBY', KEYS[i], cost)
+	if value == cost then
+		redis.call('EXPIRE', KEYS[i], tonumber(ARGV[1 + n + i]))
+	end
+	table.insert(out, value)
+end
+return out
+`)
+
+// refundScript gives cost back to each window counter that still exists,
+// never below zero. A window that has already expired is left alone, since
+// decrementing it would start a negative counter with no TTL.
+var refundScript = redis.NewScript(`
+local cost = tonumber(ARGV[1])
+for i = 1, #KEYS do
+	local current = tonumber(redis.call('GET', KEYS[i]) or '0')
+	if current > 0 then
+		redis.call('DECRBY', KEYS[i], math.min(cost, current))
+	end
+end
+return 0
+`)
+
+type usageRepo struct {
+	Redis *redis.Client
+}
+
+func NewUsageRepo(redisClient *redis.Client) interfaceapiclient.RepoUsageInterface {
+	return &usageRepo{Redis: redisClient}
+}
+
+func (r *usageRepo) key(clientId string, w domainapiclient.QuotaWindow) string {
+	return fmt.Sprintf("%s%s:%s", apiClientUsageKeyPrefix, clientId, w.Bucket)
+}
+
+func (r *usageRepo) Consume(ctx context.Context, clientId string, cost int, windows []domainapiclient.QuotaWindow) ([]int, int, error) {
+	keys := make([]string, len(windows))
+	args := make([]interface{}, 0, 1+2*len(windows))
+	args = append(args, cost)
+	for i, w := range windows {
+		keys[i] = r.key(clientId, w)
+		args = append(args, w.Limit)
+	}
+	now := time.Now()
+	for _, w := range windows {
+		// Keep counters a minute past their window so late reads still see them.
+		args = append(args, int(w.ResetsAt.Sub(now).Seconds())+60)
+	}
+
+	raw, err := consumeScript.Run(ctx, r.Redis, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, -1, err
+	}
+	if len(raw) < 2 {
+		return nil, -1, errors.New("unexpected quota script result")
+	}
+
+	if raw[0] == 0 {
+		used, err := r.Get(ctx, clientId, windows)
+		if err != nil {
+			return nil, -1, err
+		}
+		return used, int(raw[1]) - 1, nil
+	}
+
+	used := make([]int, len(windows))
+	for i := range windows {
+		used[i] = int(raw[i+1])
+	}
+	return used, -1, nil
+}
+
+func (r *usageRepo) Refund(ctx context.Context, clientId string, cost int, windows []domainapiclient.QuotaWindow) error {
+	keys := make([]string, len(windows))
+	for i, w := range windows {
+		keys[i] = r.key(clientId, w)
+	}
+	return refundScript.Run(ctx, r.Redis, keys, cost).Err()
+}
+
+func (r *usageRepo) Get(ctx context.Context, clientId string, windows []domainapiclient.QuotaWindow) ([]int, error) {
+	keys := make([]string, len(windows))
+	for i, w := range windows {
+		keys[i] = r.key(clientId, w)
+	}
+
+	values, err := r.Redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	used := make([]int, len(windows))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(s); err == nil {
+			used[i] = n
+		}
+	}
+	return used, nil
+}
@@ -0,0 +1,135 @@
+package repositorycampaign
+
+import (
+	"fmt"
+	domaincampaign "service-sender/internal/domain/campaign"
+	interfacecampaign "service-sender/internal/interfaces/campaign"
+	"service-sender/pkg/filter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewCampaignRepo(db *gorm.DB) interfacecampaign.RepoCampaignInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) Store(m domaincampaign.Campaign) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *repo) GetByID(id string) (ret domaincampaign.Campaign, err error) {
+	if err = r.DB.Where("id = ?", id).First(&ret).Error; err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	return ret, nil
+}
+
+func (r *repo) GetAll(params filter.BaseParams) (ret []domaincampaign.Campaign, totalData int64, err error) {
+	query := r.DB.Model(&domaincampaign.Campaign{})
+
+	if params.Search != "" {
+		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+params.Search+"%")
+	}
+
+	for key, value := range params.Filters {
+		if v, ok := value.(string); ok && v != "" {
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"name":        true,
+			"status":      true,
+			"started_at":  true,
+			"finished_at": true,
+			"created_at":  true,
+			"updated_at":  true,
+		}
+
+		if !validColumns[params.OrderBy] {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *repo) Update(m domaincampaign.Campaign) error {
+	return r.DB.Save(&m).Error
+}
+
+func (r *repo) Delete(id string) error {
+	result := r.DB.Where("id = ?", id).Delete(&domaincampaign.Campaign{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repo) SetStatus(id string, from []string, status string, fields map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": status, "updated_at": time.Now()}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	result := r.DB.Model(&domaincampaign.Campaign{}).Where("id = ? AND status IN ?", id, from).UpdateColumns(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repo) GetRunnable(now time.Time) (ret []domaincampaign.Campaign, err error) {
+	err = r.DB.Where("status = ? AND (lease_until IS NULL OR lease_until < ?)", domaincampaign.StatusRunning, now).
+		Order("started_at ASC").
+		Find(&ret).Error
+	return ret, err
+}
+
+func (r *repo) Lease(id string, now, until time.Time) (bool, error) {
+	result := r.DB.Model(&domaincampaign.Campaign{}).
+		Where("id = ? AND status = ? AND (lease_until IS NULL OR lease_until < ?)", id, domaincampaign.StatusRunning, now).
+		UpdateColumn("lease_until", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release hands the campaign back to the workers once next has passed, which
+// is how the send rate is kept between batches.
+func (r *repo) Release(id string, next time.Time) error {
+	return r.DB.Model(&domaincampaign.Campaign{}).Where("id = ?", id).UpdateColumn("lease_until", next).Error
+}
+
+func (r *repo) Advance(id, cursorId string, sent, failed, skipped int, lastError string) error {
+	updates := map[string]interface{}{
+		"cursor_id":     cursorId,
+		"sent_count":    gorm.Expr("sent_count + ?", sent),
+		"failed_count":  gorm.Expr("failed_count + ?", failed),
+		"skipped_count": gorm.Expr("skipped_count + ?", skipped),
+		"updated_at":    time.Now(),
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	return r.DB.Model(&domaincampaign.Campaign{}).Where("id = ?", id).UpdateColumns(updates).Error
+}
@@ -0,0 +1,74 @@
+package repositorycampaign
+
+import (
+	"encoding/json"
+	domaincampaign "service-sender/internal/domain/campaign"
+	domainuser "service-sender/internal/domain/user"
+	interfacecampaign "service-sender/internal/interfaces/campaign"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type segmentRepo struct {
+	DB *gorm.DB
+}
+
+func NewSegmentRepo(db *gorm.DB) interfacecampaign.RepoSegmentInterface {
+	return &segmentRepo{DB: db}
+}
+
+func (r *segmentRepo) Count(segment domaincampaign.Segment, createdBefore *time.Time) (total int64, err error) {
+	query, err := r.segmentQuery(segment, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	err = query.Count(&total).Error
+	return total, err
+}
+
+func (r *segmentRepo) NextBatch(segment domaincampaign.Segment, createdBefore *time.Time, afterId string, limit int) (ret []domainuser.Users, err error) {
+	query, err := r.segmentQuery(segment, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	if afterId != "" {
+		query = query.Where("id > ?", afterId)
+	}
+	err = query.Order("id ASC").Limit(limit).Find(&ret).Error
+	return ret, err
+}
+
+// segmentQuery applies the segment to the users table. createdBefore freezes
+// the audience at campaign start so users signing up later are not mailed.
+func (r *segmentRepo) segmentQuery(segment domaincampaign.Segment, createdBefore *time.Time) (*gorm.DB, error) {
+	query := r.DB.Model(&domainuser.Users{}).Where("email <> ''")
+
+	if len(segment.Roles) > 0 {
+		query = query.Where("role IN ?", segment.Roles)
+	}
+	if segment.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *segment.CreatedFrom)
+	}
+	if segment.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *segment.CreatedTo)
+	}
+	if createdBefore != nil {
+		query = query.Where("created_at <= ?", *createdBefore)
+	}
+	if segment.Verified != nil {
+		if *segment.Verified {
+			query = query.Where("email_verified_at IS NOT NULL")
+		} else {
+			query = query.Where("email_verified_at IS NULL")
+		}
+	}
+	if len(segment.Attributes) > 0 {
+		attributes, err := json.Marshal(segment.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("attributes @> ?::jsonb", string(attributes))
+	}
+	return query, nil
+}
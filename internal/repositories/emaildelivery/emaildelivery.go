@@ -0,0 +1,102 @@
+package repositoryemaildelivery
+
+import (
+	"fmt"
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	interfaceemaildelivery "service-sender/internal/interfaces/emaildelivery"
+	"service-sender/pkg/filter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewEmailDeliveryRepo(db *gorm.DB) interfaceemaildelivery.RepoEmailDeliveryInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) Store(m []domainemaildelivery.EmailDelivery) error {
+	if len(m) == 0 {
+		return nil
+	}
+	return r.DB.Create(&m).Error
+}
+
+func (r *repo) GetByMessageId(messageId string) (ret []domainemaildelivery.EmailDelivery, err error) {
+	if err = r.DB.Where("message_id = ?", messageId).Find(&ret).Error; err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *repo) UpdateStatus(id, status, detail string, at time.Time) error {
+	updates := map[string]interface{}{
+		"status":        status,
+		"status_detail": detail,
+		"last_event_at": at,
+		"updated_at":    time.Now(),
+	}
+	switch status {
+	case domainemaildelivery.StatusDelivered:
+		updates["delivered_at"] = gorm.Expr("COALESCE(delivered_at, ?)", at)
+	case domainemaildelivery.StatusOpened, domainemaildelivery.StatusClicked:
+		updates["opened_at"] = gorm.Expr("COALESCE(opened_at, ?)", at)
+	}
+	return r.DB.Model(&domainemaildelivery.EmailDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *repo) GetAll(params filter.BaseParams) (ret []domainemaildelivery.EmailDelivery, totalData int64, err error) {
+	query := r.DB.Model(&domainemaildelivery.EmailDelivery{})
+
+	if params.Search != "" {
+		searchPattern := "%" + params.Search + "%"
+		query = query.Where("LOWER(recipient) LIKE LOWER(?) OR LOWER(subject) LIKE LOWER(?) OR message_id = ?", searchPattern, searchPattern, params.Search)
+	}
+
+	for key, value := range params.Filters {
+		if value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		case []string, []int:
+			query = query.Where(fmt.Sprintf("%s IN ?", key), v)
+		default:
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"recipient":     true,
+			"status":        true,
+			"email_type":    true,
+			"last_event_at": true,
+			"created_at":    true,
+		}
+
+		if !validColumns[params.OrderBy] {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
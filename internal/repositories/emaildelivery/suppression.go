@@ -0,0 +1,100 @@
+package repositoryemaildelivery
+
+import (
+	"fmt"
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	interfaceemaildelivery "service-sender/internal/interfaces/emaildelivery"
+	"service-sender/pkg/filter"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type suppressionRepo struct {
+	DB *gorm.DB
+}
+
+func NewSuppressionRepo(db *gorm.DB) interfaceemaildelivery.RepoSuppressionInterface {
+	return &suppressionRepo{DB: db}
+}
+
+// Upsert stores m, or refreshes the reason and source of an existing entry
+// for the same address.
+func (r *suppressionRepo) Upsert(m domainemaildelivery.Suppression) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "source", "detail", "message_id", "created_by", "updated_at"}),
+	}).Create(&m).Error
+}
+
+// Insert stores m unless the address is already suppressed, so a weaker
+// reason never replaces a stronger one.
+func (r *suppressionRepo) Insert(m domainemaildelivery.Suppression) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoNothing: true,
+	}).Create(&m).Error
+}
+
+func (r *suppressionRepo) GetSuppressed(emails []string, includeUnsubscribes bool) (ret []string, err error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	query := r.DB.Model(&domainemaildelivery.Suppression{}).Where("email IN ?", emails)
+	if !includeUnsubscribes {
+		query = query.Where("reason <> ?", domainemaildelivery.SuppressionUnsubscribe)
+	}
+	if err = query.Pluck("email", &ret).Error; err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *suppressionRepo) GetAll(params filter.BaseParams) (ret []domainemaildelivery.Suppression, totalData int64, err error) {
+	query := r.DB.Model(&domainemaildelivery.Suppression{})
+
+	if params.Search != "" {
+		query = query.Where("LOWER(email) LIKE LOWER(?)", "%"+params.Search+"%")
+	}
+
+	for key, value := range params.Filters {
+		if v, ok := value.(string); ok && v != "" {
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"email":      true,
+			"reason":     true,
+			"created_at": true,
+		}
+
+		if !validColumns[params.OrderBy] {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *suppressionRepo) Delete(email string) error {
+	result := r.DB.Where("email = ?", email).Delete(&domainemaildelivery.Suppression{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repositoryemailtemplate
+
+import (
+	"fmt"
+	domainemailtemplate "service-sender/internal/domain/emailtemplate"
+	interfaceemailtemplate "service-sender/internal/interfaces/emailtemplate"
+	"service-sender/pkg/filter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewEmailTemplateRepo(db *gorm.DB) interfaceemailtemplate.RepoEmailTemplateInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) GetActive(key, locale string) (ret domainemailtemplate.EmailTemplate, err error) {
+	if err = r.DB.Where("template_key = ? AND locale = ? AND is_active = ?", key, locale, true).First(&ret).Error; err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	return ret, nil
+}
+
+func (r *repo) GetVersion(key, locale string, version int) (ret domainemailtemplate.EmailTemplate, err error) {
+	if err = r.DB.Where("template_key = ? AND locale = ? AND version = ?", key, locale, version).First(&ret).Error; err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	return ret, nil
+}
+
+func (r *repo) GetVersions(key, locale string) (ret []domainemailtemplate.EmailTemplate, err error) {
+	if err = r.DB.Where("template_key = ? AND locale = ?", key, locale).Order("version desc").Find(&ret).Error; err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *repo) GetLatestVersion(key, locale string) (int, error) {
+	var latest *int
+	err := r.DB.Model(&domainemailtemplate.EmailTemplate{}).
+		Unscoped().
+		Where("template_key = ? AND locale = ?", key, locale).
+		Select("MAX(version)").
+		Scan(&latest).Error
+	if err != nil {
+		return 0, err
+	}
+	if latest == nil {
+		return 0, nil
+	}
+	return *latest, nil
+}
+
+func (r *repo) GetAll(params filter.BaseParams) (ret []domainemailtemplate.EmailTemplate, totalData int64, err error) {
+	query := r.DB.Model(&domainemailtemplate.EmailTemplate{}).Where("is_active = ?", true)
+
+	if params.Search != "" {
+		searchPattern := "%" + params.Search + "%"
+		query = query.Where("LOWER(template_key) LIKE LOWER(?) OR LOWER(subject) LIKE LOWER(?)", searchPattern, searchPattern)
+	}
+
+	for key, value := range params.Filters {
+		if value == nil {
+			continue
+		}
+
+		column := key
+		if key == "key" {
+			column = "template_key"
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("%s = ?", column), v)
+		case []string, []int:
+			query = query.Where(fmt.Sprintf("%s IN ?", column), v)
+		default:
+			query = query.Where(fmt.Sprintf("%s = ?", column), v)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]string{
+			"key":        "template_key",
+			"locale":     "locale",
+			"version":    "version",
+			"created_at": "created_at",
+			"updated_at": "updated_at",
+		}
+
+		column, ok := validColumns[params.OrderBy]
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", column, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+// StoreVersion inserts m as the active version, deactivating the previous one
+// in the same transaction.
+func (r *repo) StoreVersion(m domainemailtemplate.EmailTemplate) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deactivate(tx, m.Key, m.Locale); err != nil {
+			return err
+		}
+		m.IsActive = true
+		return tx.Create(&m).Error
+	})
+}
+
+func (r *repo) ActivateVersion(key, locale string, version int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deactivate(tx, key, locale); err != nil {
+			return err
+		}
+		result := tx.Model(&domainemailtemplate.EmailTemplate{}).
+			Where("template_key = ? AND locale = ? AND version = ?", key, locale, version).
+			Updates(map[string]interface{}{"is_active": true, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *repo) Delete(key, locale string) error {
+	result := r.DB.Where("template_key = ? AND locale = ?", key, locale).Delete(&domainemailtemplate.EmailTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func deactivate(tx *gorm.DB, key, locale string) error {
+	return tx.Model(&domainemailtemplate.EmailTemplate{}).
+		Where("template_key = ? AND locale = ? AND is_active = ?", key, locale, true).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()}).Error
+}
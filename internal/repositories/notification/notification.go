@@ -0,0 +1,110 @@
+package repositorynotification
+
+import (
+	"fmt"
+	"time"
+
+	domainnotification "service-sender/internal/domain/notification"
+	interfacenotification "service-sender/internal/interfaces/notification"
+	"service-sender/pkg/filter"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepo(db *gorm.DB) interfacenotification.RepoNotificationInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) Store(m domainnotification.Notification) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *repo) StoreForRole(m domainnotification.Notification, role string) (ret []domainnotification.Notification, err error) {
+	query := `
+		INSERT INTO notifications (user_id, title, body, link, category, created_at)
+		SELECT u.id, ?, ?, ?, ?, ?
+		FROM users u
+		WHERE u.role = ? AND u.deleted_at IS NULL
+		RETURNING *
+	`
+	err = r.DB.Raw(query, m.Title, m.Body, m.Link, m.Category, m.CreatedAt, role).Scan(&ret).Error
+	return ret, err
+}
+
+func (r *repo) GetByUser(userId string, params filter.BaseParams) (ret []domainnotification.Notification, totalData int64, err error) {
+	query := r.DB.Model(&domainnotification.Notification{}).Where("user_id = ?", userId)
+
+	if params.Search != "" {
+		query = query.Where("LOWER(title) LIKE LOWER(?)", "%"+params.Search+"%")
+	}
+
+	for key, value := range params.Filters {
+		switch key {
+		case "unread":
+			switch value {
+			case "true":
+				query = query.Where("read_at IS NULL")
+			case "false":
+				query = query.Where("read_at IS NOT NULL")
+			}
+		default:
+			if v, ok := value.(string); ok && v != "" {
+				query = query.Where(fmt.Sprintf("%s = ?", key), v)
+			}
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"created_at": true,
+			"read_at":    true,
+		}
+
+		if !validColumns[params.OrderBy] {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *repo) SetRead(userId, id string, readAt *time.Time) error {
+	result := r.DB.Model(&domainnotification.Notification{}).
+		Where("id = ? AND user_id = ?", id, userId).
+		UpdateColumn("read_at", readAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repo) MarkAllRead(userId string, readAt time.Time) (int64, error) {
+	result := r.DB.Model(&domainnotification.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		UpdateColumn("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
+
+func (r *repo) CountUnread(userId string) (count int64, err error) {
+	err = r.DB.Model(&domainnotification.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Count(&count).Error
+	return count, err
+}
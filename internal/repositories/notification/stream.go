@@ -0,0 +1,55 @@
+package repositorynotification
+
+import (
+	"context"
+	"encoding/json"
+
+	domainnotification "service-sender/internal/domain/notification"
+	interfacenotification "service-sender/internal/interfaces/notification"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const notificationEventsChannel = "notifications:events"
+
+type streamRepo struct {
+	Redis *redis.Client
+}
+
+func NewStreamRepo(redisClient *redis.Client) interfacenotification.RepoStreamInterface {
+	return &streamRepo{Redis: redisClient}
+}
+
+func (r *streamRepo) Publish(ctx context.Context, event domainnotification.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.Redis.Publish(ctx, notificationEventsChannel, payload).Err()
+}
+
+func (r *streamRepo) Listen(ctx context.Context, fn func(domainnotification.Event)) error {
+	sub := r.Redis.Subscribe(ctx, notificationEventsChannel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var event domainnotification.Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			fn(event)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package repositoryotp
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -17,29 +16,41 @@ func NewOTPRepository(redisClient *redis.Client) *OTPRepository {
 }
 
 const (
-	otpRegisterKeyPrefix = "otp:register:"
+	otpCodeKeyPrefix     = "otp:"
 	otpAttemptKeyPrefix  = "otp:attempt:"
 	otpCooldownKeyPrefix = "otp:cooldown:"
 	otpRateKeyPrefix     = "otp:rate:"
+	otpVerifiedKeyPrefix = "otp:verified:"
+
+	otpRegisterPurpose = "register"
 )
 
-func (r *OTPRepository) SetOTP(ctx context.Context, email, hashed string, ttl time.Duration) error {
-	key := fmt.Sprintf("%s%s", otpRegisterKeyPrefix, email)
+// otpKey scopes a key to purpose, so codes for different purposes never
+// collide. Registration keys predate purposes and keep their original form.
+func otpKey(prefix, purpose, email string) string {
+	if purpose == otpRegisterPurpose && prefix != otpCodeKeyPrefix {
+		return prefix + email
+	}
+	return prefix + purpose + ":" + email
+}
+
+func (r *OTPRepository) SetOTP(ctx context.Context, purpose, email, hashed string, ttl time.Duration) error {
+	key := otpKey(otpCodeKeyPrefix, purpose, email)
 	return r.Redis.Set(ctx, key, hashed, ttl).Err()
 }
 
-func (r *OTPRepository) GetOTP(ctx context.Context, email string) (string, error) {
-	key := fmt.Sprintf("%s%s", otpRegisterKeyPrefix, email)
+func (r *OTPRepository) GetOTP(ctx context.Context, purpose, email string) (string, error) {
+	key := otpKey(otpCodeKeyPrefix, purpose, email)
 	return r.Redis.Get(ctx, key).Result()
 }
 
-func (r *OTPRepository) DeleteOTP(ctx context.Context, email string) error {
-	key := fmt.Sprintf("%s%s", otpRegisterKeyPrefix, email)
+func (r *OTPRepository) DeleteOTP(ctx context.Context, purpose, email string) error {
+	key := otpKey(otpCodeKeyPrefix, purpose, email)
 	return r.Redis.Del(ctx, key).Err()
 }
 
-func (r *OTPRepository) IncrementAttempts(ctx context.Context, email string, ttl time.Duration) (int, error) {
-	key := fmt.Sprintf("%s%s", otpAttemptKeyPrefix, email)
+func (r *OTPRepository) IncrementAttempts(ctx context.Context, purpose, email string, ttl time.Duration) (int, error) {
+	key := otpKey(otpAttemptKeyPrefix, purpose, email)
 	count, err := r.Redis.Incr(ctx, key).Result()
 	if err != nil {
 		return 0, err
@@ -50,28 +61,28 @@ func (r *OTPRepository) IncrementAttempts(ctx context.Context, email string, ttl
 	return int(count), nil
 }
 
-func (r *OTPRepository) ResetAttempts(ctx context.Context, email string) error {
-	key := fmt.Sprintf("%s%s", otpAttemptKeyPrefix, email)
+func (r *OTPRepository) ResetAttempts(ctx context.Context, purpose, email string) error {
+	key := otpKey(otpAttemptKeyPrefix, purpose, email)
 	return r.Redis.Del(ctx, key).Err()
 }
 
-func (r *OTPRepository) SetCooldown(ctx context.Context, email string, ttl time.Duration) error {
-	key := fmt.Sprintf("%s%s", otpCooldownKeyPrefix, email)
+func (r *OTPRepository) SetCooldown(ctx context.Context, purpose, email string, ttl time.Duration) error {
+	key := otpKey(otpCooldownKeyPrefix, purpose, email)
 	return r.Redis.Set(ctx, key, "1", ttl).Err()
 }
 
-func (r *OTPRepository) GetCooldownTTL(ctx context.Context, email string) (time.Duration, error) {
-	key := fmt.Sprintf("%s%s", otpCooldownKeyPrefix, email)
+func (r *OTPRepository) GetCooldownTTL(ctx context.Context, purpose, email string) (time.Duration, error) {
+	key := otpKey(otpCooldownKeyPrefix, purpose, email)
 	return r.Redis.TTL(ctx, key).Result()
 }
 
-func (r *OTPRepository) ClearCooldown(ctx context.Context, email string) error {
-	key := fmt.Sprintf("%s%s", otpCooldownKeyPrefix, email)
+func (r *OTPRepository) ClearCooldown(ctx context.Context, purpose, email string) error {
+	key := otpKey(otpCooldownKeyPrefix, purpose, email)
 	return r.Redis.Del(ctx, key).Err()
 }
 
-func (r *OTPRepository) IncrementSendCount(ctx context.Context, email string, ttl time.Duration) (int, time.Duration, error) {
-	key := fmt.Sprintf("%s%s", otpRateKeyPrefix, email)
+func (r *OTPRepository) IncrementSendCount(ctx context.Context, purpose, email string, ttl time.Duration) (int, time.Duration, error) {
+	key := otpKey(otpRateKeyPrefix, purpose, email)
 	count, err := r.Redis.Incr(ctx, key).Result()
 	if err != nil {
 		return 0, 0, err
@@ -83,7 +94,21 @@ func (r *OTPRepository) IncrementSendCount(ctx context.Context, email string, tt
 	return int(count), retryAfter, nil
 }
 
-func (r *OTPRepository) ClearSendCount(ctx context.Context, email string) error {
-	key := fmt.Sprintf("%s%s", otpRateKeyPrefix, email)
+func (r *OTPRepository) ClearSendCount(ctx context.Context, purpose, email string) error {
+	key := otpKey(otpRateKeyPrefix, purpose, email)
 	return r.Redis.Del(ctx, key).Err()
 }
+
+func (r *OTPRepository) SetVerified(ctx context.Context, purpose, email string, ttl time.Duration) error {
+	key := otpKey(otpVerifiedKeyPrefix, purpose, email)
+	return r.Redis.Set(ctx, key, "1", ttl).Err()
+}
+
+func (r *OTPRepository) ConsumeVerified(ctx context.Context, purpose, email string) (bool, error) {
+	key := otpKey(otpVerifiedKeyPrefix, purpose, email)
+	deleted, err := r.Redis.Del(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
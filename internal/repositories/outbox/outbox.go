@@ -0,0 +1,150 @@
+package repositoryoutbox
+
+import (
+	"errors"
+	"fmt"
+	domainoutbox "service-sender/internal/domain/outbox"
+	interfaceoutbox "service-sender/internal/interfaces/outbox"
+	"service-sender/pkg/logger"
+	"service-sender/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrClaimLost is returned by Process when another relay took over the
+// consumer part way through a batch, which happens when handling one event
+// outlasts the lease.
+var ErrClaimLost = errors.New("outbox consumer claim lost")
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepo(db *gorm.DB) interfaceoutbox.RepoOutboxInterface {
+	return &repo{DB: db}
+}
+
+// Write runs fn and appends events to the outbox in one transaction. Without
+// events fn runs on db directly.
+func Write(db *gorm.DB, events []domainoutbox.Event, fn func(tx *gorm.DB) error) error {
+	if len(events) == 0 {
+		return fn(db)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return Append(tx, events...)
+	})
+}
+
+// Append writes events on tx, which should be the transaction of the change
+// they describe.
+func Append(tx *gorm.DB, events ...domainoutbox.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for i := range events {
+		if events[i].Id == "" {
+			events[i].Id = utils.CreateUUID()
+		}
+		if events[i].CreatedAt.IsZero() {
+			events[i].CreatedAt = time.Now()
+		}
+	}
+	return tx.Create(&events).Error
+}
+
+func (r *repo) RegisterConsumer(name string) error {
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domainoutbox.Consumer{Name: name, CreatedAt: time.Now()}).Error
+}
+
+// Process claims the consumer for lease and commits before handing events
+// to handle, so no transaction or lock is held while the consumer does its
+// I/O. The claim is renewed before each event; once another relay has
+// taken it over the batch stops. A relay that dies mid-batch leaves the
+// claim to expire.
+func (r *repo) Process(consumer string, limit int, lease time.Duration, handle func(domainoutbox.Event) error) (processed int, err error) {
+	token := utils.CreateUUID()
+	var events []domainoutbox.Event
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		// The database clock decides claims, so relays on hosts with skewed
+		// clocks agree on when one expires.
+		claim := tx.Exec(`
+			UPDATE outbox_consumers SET claimed_by = ?, claimed_until = NOW() + make_interval(secs => ?)
+			WHERE name = ? AND (claimed_until IS NULL OR claimed_until < NOW())
+		`, token, lease.Seconds(), consumer)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			return claim.Error
+		}
+
+		query := `
+			SELECT o.* FROM outbox o
+			JOIN outbox_consumers c ON c.name = ?
+			WHERE o.created_at >= c.created_at
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_processed p WHERE p.consumer = c.name AND p.event_id = o.id
+			)
+			ORDER BY o.created_at ASC, o.id ASC
+			LIMIT ?
+		`
+		return tx.Raw(query, consumer, limit).Scan(&events).Error
+	})
+	if err != nil || len(events) == 0 {
+		r.release(consumer, token)
+		return 0, err
+	}
+	defer r.release(consumer, token)
+
+	for _, event := range events {
+		renewed := r.DB.Exec(`
+			UPDATE outbox_consumers SET claimed_until = NOW() + make_interval(secs => ?)
+			WHERE name = ? AND claimed_by = ?
+		`, lease.Seconds(), consumer, token)
+		if renewed.Error != nil {
+			return processed, renewed.Error
+		}
+		if renewed.RowsAffected == 0 {
+			return processed, ErrClaimLost
+		}
+
+		if err := handle(event); err != nil {
+			return processed, err
+		}
+		mark := domainoutbox.Processed{Consumer: consumer, EventId: event.Id, ProcessedAt: time.Now()}
+		if err := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mark).Error; err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// release gives up a claim taken by Process, if it still holds it.
+func (r *repo) release(consumer, token string) {
+	err := r.DB.Exec("UPDATE outbox_consumers SET claimed_by = NULL, claimed_until = NULL WHERE name = ? AND claimed_by = ?", consumer, token).Error
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OutboxRepo][release]; %s; release claim error: %v", consumer, err))
+	}
+}
+
+// Prune deletes events created before the given time once every consumer
+// that receives them has handled them. Consumers registered after an event
+// never receive it and do not hold it back.
+func (r *repo) Prune(before time.Time) (int64, error) {
+	result := r.DB.Exec(`
+		DELETE FROM outbox o
+		WHERE o.created_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox_consumers c
+			WHERE c.created_at <= o.created_at
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_processed p WHERE p.consumer = c.name AND p.event_id = o.id
+			)
+		)
+	`, before)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,34 @@
+package repositorypasswordhistory
+
+import (
+	domainpasswordhistory "service-sender/internal/domain/passwordhistory"
+	interfacepasswordhistory "service-sender/internal/interfaces/passwordhistory"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewPasswordHistoryRepo(db *gorm.DB) interfacepasswordhistory.RepoPasswordHistoryInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) Store(m domainpasswordhistory.Entry, keep int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			DELETE FROM password_histories
+			WHERE user_id = ? AND id NOT IN (
+				SELECT id FROM password_histories WHERE user_id = ? ORDER BY created_at DESC LIMIT ?
+			)`, m.UserId, m.UserId, keep).Error
+	})
+}
+
+func (r *repo) GetRecent(userId string, limit int) (ret []domainpasswordhistory.Entry, err error) {
+	err = r.DB.Where("user_id = ?", userId).Order("created_at DESC").Limit(limit).Find(&ret).Error
+	return ret, err
+}
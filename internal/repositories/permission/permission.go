@@ -2,8 +2,10 @@ package repositorypermission
 
 import (
 	"fmt"
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainpermission "service-sender/internal/domain/permission"
 	interfacepermission "service-sender/internal/interfaces/permission"
+	repositoryoutbox "service-sender/internal/repositories/outbox"
 	"service-sender/pkg/filter"
 
 	"gorm.io/gorm"
@@ -17,8 +19,10 @@ func NewPermissionRepo(db *gorm.DB) interfacepermission.RepoPermissionInterface
 	return &repo{DB: db}
 }
 
-func (r *repo) Store(m domainpermission.Permission) error {
-	return r.DB.Create(&m).Error
+func (r *repo) Store(m domainpermission.Permission, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Create(&m).Error
+	})
 }
 
 func (r *repo) GetByID(id string) (ret domainpermission.Permission, err error) {
@@ -89,12 +93,16 @@ func (r *repo) GetAll(params filter.BaseParams) (ret []domainpermission.Permissi
 	return ret, totalData, nil
 }
 
-func (r *repo) Update(m domainpermission.Permission) error {
-	return r.DB.Save(&m).Error
+func (r *repo) Update(m domainpermission.Permission, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Save(&m).Error
+	})
 }
 
-func (r *repo) Delete(id string) error {
-	return r.DB.Where("id = ?", id).Delete(&domainpermission.Permission{}).Error
+func (r *repo) Delete(id string, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Where("id = ?", id).Delete(&domainpermission.Permission{}).Error
+	})
 }
 
 func (r *repo) GetByResource(resource string) (ret []domainpermission.Permission, err error) {
@@ -0,0 +1,44 @@
+package repositorypreference
+
+import (
+	domainpreference "service-sender/internal/domain/preference"
+	interfacepreference "service-sender/internal/interfaces/preference"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewPreferenceRepo(db *gorm.DB) interfacepreference.RepoPreferenceInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) GetByUser(userId string) (ret []domainpreference.Preference, err error) {
+	err = r.DB.Where("user_id = ?", userId).Find(&ret).Error
+	return ret, err
+}
+
+func (r *repo) Upsert(m []domainpreference.Preference) error {
+	if len(m) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&m).Error
+}
+
+func (r *repo) GetOptedOutEmails(emails []string, category, channel string) (ret []string, err error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	err = r.DB.Table("users").
+		Joins("JOIN notification_preferences p ON p.user_id = users.id").
+		Where("LOWER(users.email) IN ? AND users.deleted_at IS NULL", emails).
+		Where("p.category = ? AND p.channel = ? AND p.enabled = FALSE", category, channel).
+		Pluck("LOWER(users.email)", &ret).Error
+	return ret, err
+}
@@ -0,0 +1,54 @@
+package repositorypush
+
+import (
+	"time"
+
+	domainpush "service-sender/internal/domain/push"
+	interfacepush "service-sender/internal/interfaces/push"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewPushRepo(db *gorm.DB) interfacepush.RepoPushInterface {
+	return &repo{DB: db}
+}
+
+func (r *repo) Upsert(m domainpush.Subscription) (ret domainpush.Subscription, err error) {
+	err = r.DB.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "endpoint"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "token_id", "user_agent", "expires_at", "updated_at"}),
+		},
+		clause.Returning{},
+	).Create(&m).Error
+	return m, err
+}
+
+func (r *repo) GetByUser(userId string) (ret []domainpush.Subscription, err error) {
+	err = r.DB.Where("user_id = ?", userId).Order("created_at DESC").Find(&ret).Error
+	return ret, err
+}
+
+func (r *repo) DeleteByEndpoint(userId, endpoint string) error {
+	result := r.DB.Where("user_id = ? AND endpoint = ?", userId, endpoint).Delete(&domainpush.Subscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repo) Delete(id string) error {
+	return r.DB.Where("id = ?", id).Delete(&domainpush.Subscription{}).Error
+}
+
+func (r *repo) MarkSuccess(id string, at time.Time) error {
+	return r.DB.Model(&domainpush.Subscription{}).Where("id = ?", id).UpdateColumn("last_success_at", at).Error
+}
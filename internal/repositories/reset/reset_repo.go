@@ -32,6 +32,13 @@ func (r *PasswordResetRepository) GetEmailByToken(ctx context.Context, hash stri
 	return r.Redis.Get(ctx, key).Result()
 }
 
+// ConsumeToken returns the email for hash and deletes the token in the same
+// step, so a token can only be used once.
+func (r *PasswordResetRepository) ConsumeToken(ctx context.Context, hash string) (string, error) {
+	key := fmt.Sprintf("%s%s", resetTokenKeyPrefix, hash)
+	return r.Redis.GetDel(ctx, key).Result()
+}
+
 func (r *PasswordResetRepository) DeleteToken(ctx context.Context, hash string) error {
 	key := fmt.Sprintf("%s%s", resetTokenKeyPrefix, hash)
 	return r.Redis.Del(ctx, key).Err()
@@ -2,8 +2,10 @@ package repositoryrole
 
 import (
 	"fmt"
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainrole "service-sender/internal/domain/role"
 	interfacerole "service-sender/internal/interfaces/role"
+	repositoryoutbox "service-sender/internal/repositories/outbox"
 	"service-sender/pkg/filter"
 	"service-sender/utils"
 
@@ -18,8 +20,10 @@ func NewRoleRepo(db *gorm.DB) interfacerole.RepoRoleInterface {
 	return &repo{DB: db}
 }
 
-func (r *repo) Store(m domainrole.Role) error {
-	return r.DB.Create(&m).Error
+func (r *repo) Store(m domainrole.Role, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Create(&m).Error
+	})
 }
 
 func (r *repo) GetByID(id string) (ret domainrole.Role, err error) {
@@ -89,15 +93,19 @@ func (r *repo) GetAll(params filter.BaseParams) (ret []domainrole.Role, totalDat
 	return ret, totalData, nil
 }
 
-func (r *repo) Update(m domainrole.Role) error {
-	return r.DB.Save(&m).Error
+func (r *repo) Update(m domainrole.Role, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Save(&m).Error
+	})
 }
 
-func (r *repo) Delete(id string) error {
-	return r.DB.Where("id = ?", id).Delete(&domainrole.Role{}).Error
+func (r *repo) Delete(id string, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Where("id = ?", id).Delete(&domainrole.Role{}).Error
+	})
 }
 
-func (r *repo) AssignPermissions(roleId string, permissionIds []string) error {
+func (r *repo) AssignPermissions(roleId string, permissionIds []string, events ...domainoutbox.Event) error {
 	tx := r.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -122,6 +130,11 @@ func (r *repo) AssignPermissions(roleId string, permissionIds []string) error {
 		}
 	}
 
+	if err := repositoryoutbox.Append(tx, events...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
@@ -143,7 +156,7 @@ func (r *repo) GetRolePermissions(roleId string) ([]string, error) {
 	return permissionIds, nil
 }
 
-func (r *repo) AssignMenus(roleId string, menuIds []string) error {
+func (r *repo) AssignMenus(roleId string, menuIds []string, events ...domainoutbox.Event) error {
 	tx := r.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -168,6 +181,11 @@ func (r *repo) AssignMenus(roleId string, menuIds []string) error {
 		}
 	}
 
+	if err := repositoryoutbox.Append(tx, events...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
@@ -0,0 +1,55 @@
+package repositorysecurityalert
+
+import (
+	"time"
+
+	domainsecurityalert "service-sender/internal/domain/securityalert"
+	interfacesecurityalert "service-sender/internal/interfaces/securityalert"
+
+	"gorm.io/gorm"
+)
+
+type alertRepo struct {
+	DB *gorm.DB
+}
+
+func NewAlertRepo(db *gorm.DB) interfacesecurityalert.RepoAlertInterface {
+	return &alertRepo{DB: db}
+}
+
+func (r *alertRepo) Store(m domainsecurityalert.Alert) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *alertRepo) Update(m domainsecurityalert.Alert) error {
+	return r.DB.Save(&m).Error
+}
+
+func (r *alertRepo) GetByEventId(eventId string) (ret domainsecurityalert.Alert, err error) {
+	if err = r.DB.Where("event_id = ?", eventId).First(&ret).Error; err != nil {
+		return domainsecurityalert.Alert{}, err
+	}
+	return ret, nil
+}
+
+func (r *alertRepo) GetByTokenHash(hash string) (ret domainsecurityalert.Alert, err error) {
+	if err = r.DB.Where("lock_token_hash = ?", hash).First(&ret).Error; err != nil {
+		return domainsecurityalert.Alert{}, err
+	}
+	return ret, nil
+}
+
+func (r *alertRepo) MarkSent(id string, at time.Time) error {
+	return r.DB.Model(&domainsecurityalert.Alert{}).Where("id = ?", id).UpdateColumn("sent_at", at).Error
+}
+
+func (r *alertRepo) MarkUsed(id string, at time.Time) error {
+	result := r.DB.Model(&domainsecurityalert.Alert{}).Where("id = ? AND used_at IS NULL", id).UpdateColumn("used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package repositorysecurityalert
+
+import (
+	domainsecurityalert "service-sender/internal/domain/securityalert"
+	interfacesecurityalert "service-sender/internal/interfaces/securityalert"
+
+	"gorm.io/gorm"
+)
+
+type deviceRepo struct {
+	DB *gorm.DB
+}
+
+func NewDeviceRepo(db *gorm.DB) interfacesecurityalert.RepoDeviceInterface {
+	return &deviceRepo{DB: db}
+}
+
+func (r *deviceRepo) CountByUser(userId string) (total int64, err error) {
+	err = r.DB.Model(&domainsecurityalert.Device{}).Where("user_id = ?", userId).Count(&total).Error
+	return total, err
+}
+
+func (r *deviceRepo) Touch(m domainsecurityalert.Device) (bool, error) {
+	// xmax is zero only on a row the statement inserted.
+	var created bool
+	err := r.DB.Raw(`
+		INSERT INTO user_devices (id, user_id, fingerprint, user_agent, ip, first_seen_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE
+		SET user_agent = EXCLUDED.user_agent, ip = EXCLUDED.ip, last_seen_at = EXCLUDED.last_seen_at
+		RETURNING (xmax = 0)`,
+		m.Id, m.UserId, m.Fingerprint, m.UserAgent, m.IP, m.FirstSeenAt, m.LastSeenAt,
+	).Scan(&created).Error
+	return created, err
+}
@@ -2,9 +2,12 @@ package repositoryuser
 
 import (
 	"fmt"
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainuser "service-sender/internal/domain/user"
 	interfaceuser "service-sender/internal/interfaces/user"
+	repositoryoutbox "service-sender/internal/repositories/outbox"
 	"service-sender/pkg/filter"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,8 +20,10 @@ func NewUserRepo(db *gorm.DB) interfaceuser.RepoUserInterface {
 	return &repo{DB: db}
 }
 
-func (r *repo) Store(m domainuser.Users) error {
-	return r.DB.Create(&m).Error
+func (r *repo) Store(m domainuser.Users, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Create(&m).Error
+	})
 }
 
 func (r *repo) GetByEmail(email string) (ret domainuser.Users, err error) {
@@ -98,10 +103,29 @@ func (r *repo) GetAll(params filter.BaseParams) (ret []domainuser.Users, totalDa
 	return ret, totalData, nil
 }
 
-func (r *repo) Update(m domainuser.Users) error {
-	return r.DB.Save(&m).Error
+func (r *repo) Update(m domainuser.Users, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Save(&m).Error
+	})
 }
 
-func (r *repo) Delete(id string) error {
-	return r.DB.Where("id = ?", id).Delete(&domainuser.Users{}).Error
+func (r *repo) Delete(id string, events ...domainoutbox.Event) error {
+	return repositoryoutbox.Write(r.DB, events, func(tx *gorm.DB) error {
+		return tx.Where("id = ?", id).Delete(&domainuser.Users{}).Error
+	})
+}
+
+func (r *repo) MarkEmailVerified(email string, at time.Time) (bool, error) {
+	var count int64
+	if err := r.DB.Model(&domainuser.Users{}).Where("LOWER(email) = LOWER(?)", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	err := r.DB.Model(&domainuser.Users{}).
+		Where("LOWER(email) = LOWER(?) AND email_verified_at IS NULL", email).
+		Update("email_verified_at", at).Error
+	return true, err
 }
@@ -0,0 +1,126 @@
+package repositorywebhook
+
+import (
+	"fmt"
+	domainwebhook "service-sender/internal/domain/webhook"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	"service-sender/pkg/filter"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type deliveryRepo struct {
+	DB *gorm.DB
+}
+
+func NewDeliveryRepo(db *gorm.DB) interfacewebhook.RepoDeliveryInterface {
+	return &deliveryRepo{DB: db}
+}
+
+func (r *deliveryRepo) StoreMany(m []domainwebhook.Delivery) error {
+	if len(m) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint_id"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(&m).Error
+}
+
+func (r *deliveryRepo) GetByID(id string) (ret domainwebhook.Delivery, err error) {
+	if err = r.DB.Where("id = ?", id).First(&ret).Error; err != nil {
+		return domainwebhook.Delivery{}, err
+	}
+	return ret, nil
+}
+
+func (r *deliveryRepo) GetAll(params filter.BaseParams) (ret []domainwebhook.Delivery, totalData int64, err error) {
+	query := r.DB.Model(&domainwebhook.Delivery{})
+
+	if params.Search != "" {
+		query = query.Where("LOWER(event_type) LIKE LOWER(?)", "%"+params.Search+"%")
+	}
+
+	for key, value := range params.Filters {
+		if v, ok := value.(string); ok && v != "" {
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"event_type":      true,
+			"status":          true,
+			"attempts":        true,
+			"next_attempt_at": true,
+			"delivered_at":    true,
+			"created_at":      true,
+		}
+
+		if !validColumns[params.OrderBy] {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *deliveryRepo) Claim(now, until time.Time, limit int) (ret []domainwebhook.Delivery, err error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN (?, ?) AND next_attempt_at <= ?
+			ORDER BY next_attempt_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+	err = r.DB.Raw(query, until, now, domainwebhook.StatusPending, domainwebhook.StatusRetrying, now, limit).Scan(&ret).Error
+	return ret, err
+}
+
+func (r *deliveryRepo) Finish(id string, fields map[string]interface{}) error {
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	for k, v := range fields {
+		updates[k] = v
+	}
+	return r.DB.Model(&domainwebhook.Delivery{}).Where("id = ?", id).UpdateColumns(updates).Error
+}
+
+func (r *deliveryRepo) Requeue(id string, now time.Time) error {
+	result := r.DB.Model(&domainwebhook.Delivery{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"status":          domainwebhook.StatusPending,
+		"next_attempt_at": now,
+		"updated_at":      now,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *deliveryRepo) StoreAttempt(m domainwebhook.Attempt) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *deliveryRepo) GetAttempts(deliveryId string) (ret []domainwebhook.Attempt, err error) {
+	err = r.DB.Where("delivery_id = ?", deliveryId).Order("attempt ASC").Find(&ret).Error
+	return ret, err
+}
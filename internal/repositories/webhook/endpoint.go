@@ -0,0 +1,96 @@
+package repositorywebhook
+
+import (
+	"fmt"
+	domainwebhook "service-sender/internal/domain/webhook"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	"service-sender/pkg/filter"
+
+	"gorm.io/gorm"
+)
+
+type endpointRepo struct {
+	DB *gorm.DB
+}
+
+func NewEndpointRepo(db *gorm.DB) interfacewebhook.RepoEndpointInterface {
+	return &endpointRepo{DB: db}
+}
+
+func (r *endpointRepo) Store(m domainwebhook.Endpoint) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *endpointRepo) GetByID(id string) (ret domainwebhook.Endpoint, err error) {
+	if err = r.DB.Where("id = ?", id).First(&ret).Error; err != nil {
+		return domainwebhook.Endpoint{}, err
+	}
+	return ret, nil
+}
+
+func (r *endpointRepo) GetAll(params filter.BaseParams) (ret []domainwebhook.Endpoint, totalData int64, err error) {
+	query := r.DB.Model(&domainwebhook.Endpoint{})
+
+	if params.Search != "" {
+		query = query.Where("LOWER(name) LIKE LOWER(?) OR LOWER(url) LIKE LOWER(?)", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
+
+	for key, value := range params.Filters {
+		v, ok := value.(string)
+		if !ok || v == "" {
+			continue
+		}
+		switch key {
+		case "is_active":
+			query = query.Where("is_active = ?", v == "true")
+		case "event":
+			query = query.Where("events @> ?::jsonb OR events @> ?::jsonb", `["`+v+`"]`, `["`+domainwebhook.Wildcard+`"]`)
+		}
+	}
+
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"name":       true,
+			"url":        true,
+			"is_active":  true,
+			"created_at": true,
+			"updated_at": true,
+		}
+
+		if !validColumns[params.OrderBy] {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *endpointRepo) GetActive() (ret []domainwebhook.Endpoint, err error) {
+	err = r.DB.Where("is_active = ?", true).Find(&ret).Error
+	return ret, err
+}
+
+func (r *endpointRepo) Update(m domainwebhook.Endpoint) error {
+	return r.DB.Save(&m).Error
+}
+
+func (r *endpointRepo) Delete(id string) error {
+	result := r.DB.Where("id = ?", id).Delete(&domainwebhook.Endpoint{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -8,56 +9,576 @@ import (
 	"gorm.io/gorm"
 
 	"service-sender/infrastructure/database"
+	domainapiclient "service-sender/internal/domain/apiclient"
+	apiClientHandler "service-sender/internal/handlers/http/apiclient"
+	campaignHandler "service-sender/internal/handlers/http/campaign"
 	emailHandler "service-sender/internal/handlers/http/email"
+	emailDeliveryHandler "service-sender/internal/handlers/http/emaildelivery"
+	emailTemplateHandler "service-sender/internal/handlers/http/emailtemplate"
 	menuHandler "service-sender/internal/handlers/http/menu"
+	notificationHandler "service-sender/internal/handlers/http/notification"
+	notifyHandler "service-sender/internal/handlers/http/notify"
 	otpHandler "service-sender/internal/handlers/http/otp"
 	permissionHandler "service-sender/internal/handlers/http/permission"
+	preferenceHandler "service-sender/internal/handlers/http/preference"
+	pushHandler "service-sender/internal/handlers/http/push"
 	resetHandler "service-sender/internal/handlers/http/reset"
 	roleHandler "service-sender/internal/handlers/http/role"
+	securityAlertHandler "service-sender/internal/handlers/http/securityalert"
 	sessionHandler "service-sender/internal/handlers/http/session"
 	userHandler "service-sender/internal/handlers/http/user"
+	webhookHandler "service-sender/internal/handlers/http/webhook"
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+	interfaceemaildelivery "service-sender/internal/interfaces/emaildelivery"
+	interfaceemailtemplate "service-sender/internal/interfaces/emailtemplate"
+	interfacenotification "service-sender/internal/interfaces/notification"
+	interfaceoutbox "service-sender/internal/interfaces/outbox"
+	interfacepasswordhistory "service-sender/internal/interfaces/passwordhistory"
+	interfacepreference "service-sender/internal/interfaces/preference"
+	interfacepush "service-sender/internal/interfaces/push"
 	interfacereset "service-sender/internal/interfaces/reset"
+	interfacesession "service-sender/internal/interfaces/session"
+	interfaceuser "service-sender/internal/interfaces/user"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	apiClientRepo "service-sender/internal/repositories/apiclient"
 	authRepo "service-sender/internal/repositories/auth"
+	campaignRepo "service-sender/internal/repositories/campaign"
+	emailDeliveryRepo "service-sender/internal/repositories/emaildelivery"
+	emailTemplateRepo "service-sender/internal/repositories/emailtemplate"
 	menuRepo "service-sender/internal/repositories/menu"
+	notificationRepo "service-sender/internal/repositories/notification"
 	otpRepo "service-sender/internal/repositories/otp"
+	outboxRepo "service-sender/internal/repositories/outbox"
+	passwordHistoryRepo "service-sender/internal/repositories/passwordhistory"
 	permissionRepo "service-sender/internal/repositories/permission"
+	preferenceRepo "service-sender/internal/repositories/preference"
+	pushRepo "service-sender/internal/repositories/push"
 	resetRepo "service-sender/internal/repositories/reset"
 	roleRepo "service-sender/internal/repositories/role"
+	securityAlertRepo "service-sender/internal/repositories/securityalert"
 	sessionRepo "service-sender/internal/repositories/session"
 	userRepo "service-sender/internal/repositories/user"
+	webhookRepo "service-sender/internal/repositories/webhook"
+	apiClientSvc "service-sender/internal/services/apiclient"
+	campaignSvc "service-sender/internal/services/campaign"
 	emailSvc "service-sender/internal/services/email"
+	emailDeliverySvc "service-sender/internal/services/emaildelivery"
+	emailTemplateSvc "service-sender/internal/services/emailtemplate"
 	menuSvc "service-sender/internal/services/menu"
+	natsSvc "service-sender/internal/services/nats"
+	notificationSvc "service-sender/internal/services/notification"
+	notifySvc "service-sender/internal/services/notify"
 	otpSvc "service-sender/internal/services/otp"
+	outboxSvc "service-sender/internal/services/outbox"
+	passwordHistorySvc "service-sender/internal/services/passwordhistory"
 	permissionSvc "service-sender/internal/services/permission"
+	preferenceSvc "service-sender/internal/services/preference"
+	pushSvc "service-sender/internal/services/push"
 	resetSvc "service-sender/internal/services/reset"
 	roleSvc "service-sender/internal/services/role"
+	securityAlertSvc "service-sender/internal/services/securityalert"
 	sessionSvc "service-sender/internal/services/session"
 	userSvc "service-sender/internal/services/user"
+	webhookSvc "service-sender/internal/services/webhook"
 	"service-sender/middlewares"
 	"service-sender/pkg/config"
 	"service-sender/pkg/logger"
 	"service-sender/pkg/mailer"
+	"service-sender/pkg/natsbus"
+	"service-sender/pkg/passwordpolicy"
 	"service-sender/pkg/security"
+	"service-sender/pkg/sms"
+	"service-sender/pkg/webpush"
 	"service-sender/utils"
 )
 
 type Routes struct {
 	App *gin.Engine
 	DB  *gorm.DB
+
+	templateService *emailTemplateSvc.ServiceEmailTemplate
+	mailService     *emailSvc.ServiceEmail
+	deliveryService *emailDeliverySvc.ServiceEmailDelivery
+	clientService   *apiClientSvc.ServiceApiClient
+	clientAuth      *middlewares.ClientAuth
+	prefService     *preferenceSvc.ServicePreference
+	inboxService    *notificationSvc.ServiceNotification
+	pushSvc         *pushSvc.ServicePush
+	webhookSvc      *webhookSvc.ServiceWebhook
+	natsSvc         *natsSvc.ServiceNATS
+	natsLoaded      bool
+	resetSvc        interfacereset.ServicePasswordResetInterface
+	resetLoaded     bool
+	otpSvc          *otpSvc.ServiceOTP
+	otpLoaded       bool
+	alertSvc        *securityAlertSvc.ServiceSecurityAlert
+	policy          *passwordpolicy.Engine
+	historySvc      *passwordHistorySvc.ServicePasswordHistory
+
+	// workers are the background loops the routes need. main starts them
+	// with StartWorkers once every route is registered.
+	workers []worker
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+func (r *Routes) addWorker(name string, run func(ctx context.Context)) {
+	r.workers = append(r.workers, worker{name: name, run: run})
+}
+
+// StartWorkers runs the background workers the registered routes need until
+// ctx is done.
+func (r *Routes) StartWorkers(ctx context.Context) {
+	for _, w := range r.workers {
+		go w.run(ctx)
+		logger.WriteLog(logger.LogLevelInfo, w.name+" started")
+	}
 }
 
 func (r *Routes) EmailRoutes() {
+	h := emailHandler.NewEmailHandler(r.emailService())
+
+	email := r.App.Group("/api/email")
+	{
+		email.POST("/send", r.apiClientAuth().Require(domainapiclient.EndpointEmailSend), r.apiClientAuth().Quota(middlewares.RecipientCost), h.SendEmail)
+	}
+}
+
+// emailService returns the shared email service used by the send endpoint and
+// the template preview endpoints.
+func (r *Routes) emailService() *emailSvc.ServiceEmail {
+	if r.mailService != nil {
+		return r.mailService
+	}
+
 	sender, err := mailer.NewBrevoSenderFromEnv()
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, "Email sender not configured: "+err.Error())
 	}
 
-	svc := emailSvc.NewEmailService(sender)
-	h := emailHandler.NewEmailHandler(svc)
+	identities, err := mailer.LoadIdentityRegistryFromEnv()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelWarn, "Email sender identities ignored, sending from SMTP_FROM: "+err.Error())
+	}
 
-	email := r.App.Group("/api/email")
+	brands, err := mailer.LoadBrandRegistryFromEnv()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelWarn, "Email brand profiles ignored, using the default brand: "+err.Error())
+	}
+
+	var templates interfaceemailtemplate.ServiceEmailTemplateInterface
+	var users interfaceuser.RepoUserInterface
+	var deliveries interfaceemaildelivery.ServiceEmailDeliveryInterface
+	var preferences interfacepreference.ServicePreferenceInterface
+	if r.DB != nil {
+		templates = r.emailTemplateService()
+		users = userRepo.NewUserRepo(r.DB)
+		deliveries = r.emailDeliveryService()
+		preferences = r.preferenceService()
+	}
+
+	r.mailService = emailSvc.NewEmailService(sender, identities, brands, templates, users, deliveries, preferences)
+	return r.mailService
+}
+
+// emailTemplateService returns the shared template service so the management
+// API and the email sender see the same cache.
+func (r *Routes) emailTemplateService() *emailTemplateSvc.ServiceEmailTemplate {
+	if r.templateService == nil {
+		repo := emailTemplateRepo.NewEmailTemplateRepo(r.DB)
+		r.templateService = emailTemplateSvc.NewEmailTemplateService(repo, config.LoadEmailTemplateConfig())
+	}
+	return r.templateService
+}
+
+// apiClientService returns the shared API client service used by the admin
+// API and the client authentication middleware.
+func (r *Routes) apiClientService() *apiClientSvc.ServiceApiClient {
+	if r.clientService == nil {
+		var usage interfaceapiclient.RepoUsageInterface
+		if redisClient := database.GetRedisClient(); redisClient != nil {
+			usage = apiClientRepo.NewUsageRepo(redisClient)
+		} else {
+			logger.WriteLog(logger.LogLevelWarn, "Redis not available, API clients with quotas will be refused on metered routes")
+		}
+		r.clientService = apiClientSvc.NewApiClientService(apiClientRepo.NewApiClientRepo(r.DB), usage, config.LoadApiClientConfig())
+	}
+	return r.clientService
+}
+
+// apiClientAuth returns the middleware guarding the sender routes. Clients
+// live in the database, so with API_CLIENT_AUTH_ENABLED and no database every
+// guarded request is refused rather than let through.
+func (r *Routes) apiClientAuth() *middlewares.ClientAuth {
+	if r.clientAuth != nil {
+		return r.clientAuth
+	}
+
+	cfg := config.LoadApiClientConfig()
+	var svc interfaceapiclient.ServiceApiClientInterface
+	if r.DB != nil {
+		svc = r.apiClientService()
+	} else if cfg.Enabled {
+		logger.WriteLog(logger.LogLevelWarn, "API client auth is enabled but DB is disabled; sender routes will reject all requests")
+	}
+
+	// Nonces kept in one process would let a signed request be replayed
+	// against another instance, so without Redis signed requests are refused.
+	var nonces interfaceapiclient.RepoNonceInterface
+	if redisClient := database.GetRedisClient(); redisClient != nil {
+		nonces = apiClientRepo.NewNonceRepo(redisClient)
+	} else if cfg.Enabled {
+		logger.WriteLog(logger.LogLevelWarn, "Redis not available; signed API client requests will be rejected")
+	}
+
+	r.clientAuth = middlewares.NewClientAuth(svc, nonces, cfg)
+	return r.clientAuth
+}
+
+func (r *Routes) ApiClientRoutes() {
+	h := apiClientHandler.NewApiClientHandler(r.apiClientService())
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	clients := r.App.Group("/api/clients").Use(mdw.AuthMiddleware())
+	{
+		clients.GET("", mdw.PermissionMiddleware("api_clients", "list"), h.GetAll)
+		clients.POST("", mdw.PermissionMiddleware("api_clients", "create"), h.Create)
+		clients.GET("/:id", mdw.PermissionMiddleware("api_clients", "view"), h.GetByID)
+		clients.PUT("/:id", mdw.PermissionMiddleware("api_clients", "update"), h.Update)
+		clients.DELETE("/:id", mdw.PermissionMiddleware("api_clients", "delete"), h.Delete)
+		clients.POST("/:id/rotate", mdw.PermissionMiddleware("api_clients", "rotate"), h.Rotate)
+		clients.GET("/:id/usage", mdw.PermissionMiddleware("api_clients", "view"), h.GetUsage)
+	}
+}
+
+// preferenceService returns the shared notification preference service
+// consulted by the senders.
+func (r *Routes) preferenceService() *preferenceSvc.ServicePreference {
+	if r.prefService == nil {
+		r.prefService = preferenceSvc.NewPreferenceService(preferenceRepo.NewPreferenceRepo(r.DB))
+	}
+	return r.prefService
+}
+
+func (r *Routes) PreferenceRoutes() {
+	h := preferenceHandler.NewPreferenceHandler(r.preferenceService())
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	preferences := r.App.Group("/api/user/preferences").Use(mdw.AuthMiddleware())
 	{
-		email.POST("/send", h.SendEmail)
+		preferences.GET("", h.GetPreferences)
+		preferences.PUT("", h.UpdatePreferences)
+	}
+}
+
+// notificationService returns the shared inbox service, so notifications
+// created through /api/notify reach the same live streams.
+func (r *Routes) notificationService() *notificationSvc.ServiceNotification {
+	if r.inboxService == nil {
+		var stream interfacenotification.RepoStreamInterface
+		if redisClient := database.GetRedisClient(); redisClient != nil {
+			stream = notificationRepo.NewStreamRepo(redisClient)
+		} else {
+			logger.WriteLog(logger.LogLevelWarn, "Redis not available, notification streams only see events from this instance")
+		}
+		r.inboxService = notificationSvc.NewNotificationService(notificationRepo.NewNotificationRepo(r.DB), userRepo.NewUserRepo(r.DB), stream)
+	}
+	return r.inboxService
+}
+
+// NotificationRoutes registers the in-app inbox. Other services create
+// notifications with an API client key; users read their own inbox and follow
+// it live over Server-Sent Events.
+func (r *Routes) NotificationRoutes() {
+	svc := r.notificationService()
+	h := notificationHandler.NewNotificationHandler(svc, config.LoadNotificationConfig())
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	r.App.POST("/api/notifications", r.apiClientAuth().Require(domainapiclient.EndpointInboxCreate), h.Create)
+
+	inbox := r.App.Group("/api/user/notifications").Use(mdw.AuthMiddleware())
+	{
+		inbox.GET("", h.GetAll)
+		inbox.GET("/unread-count", h.UnreadCount)
+		inbox.POST("/stream-token", h.StreamToken)
+		inbox.POST("/read-all", h.MarkAllRead)
+		inbox.POST("/:id/read", h.MarkRead)
+		inbox.POST("/:id/unread", h.MarkUnread)
+	}
+	// Browsers open the stream with a token from /stream-token, as an
+	// EventSource cannot send the bearer header.
+	r.App.GET("/api/user/notifications/stream", mdw.ScopedTokenMiddleware(utils.ScopeNotificationStream), h.Stream)
+
+	r.addWorker("Notification stream relay", svc.Run)
+}
+
+// pushService returns the shared Web Push service used by the subscription
+// API and the notify push channel.
+func (r *Routes) pushService() *pushSvc.ServicePush {
+	if r.pushSvc == nil {
+		vapid, err := webpush.LoadVAPIDFromEnv()
+		if err != nil {
+			logger.WriteLog(logger.LogLevelWarn, "Web push disabled: "+err.Error())
+		}
+		r.pushSvc = pushSvc.NewPushService(pushRepo.NewPushRepo(r.DB), vapid, config.LoadWebPushConfig())
+		if r.pushSvc.Client != nil {
+			r.addWorker("Web push retry worker", r.pushSvc.Run)
+		}
+	}
+	return r.pushSvc
+}
+
+func (r *Routes) PushRoutes() {
+	h := pushHandler.NewPushHandler(r.pushService())
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	push := r.App.Group("/api/user/push").Use(mdw.AuthMiddleware())
+	{
+		push.GET("/vapid-public-key", h.PublicKey)
+		push.GET("/subscriptions", h.GetSubscriptions)
+		push.POST("/subscriptions", h.Subscribe)
+		push.DELETE("/subscriptions", h.Unsubscribe)
+	}
+}
+
+// NotifyRoutes registers the multi-channel notify endpoint. Without the
+// database only contact targets work and the inbox channel is skipped.
+func (r *Routes) NotifyRoutes() {
+	rules, err := notifySvc.LoadRuleRegistryFromEnv()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, "Notification rules not loaded: "+err.Error())
+	}
+
+	var smsSender sms.Sender
+	if s, err := sms.NewHTTPSenderFromEnv(); err == nil {
+		smsSender = s
+	} else {
+		logger.WriteLog(logger.LogLevelWarn, "SMS channel disabled: "+err.Error())
+	}
+
+	var users interfaceuser.RepoUserInterface
+	var preferences interfacepreference.ServicePreferenceInterface
+	var inbox interfacenotification.ServiceNotificationInterface
+	var push interfacepush.ServicePushInterface
+	if r.DB != nil {
+		users = userRepo.NewUserRepo(r.DB)
+		preferences = r.preferenceService()
+		inbox = r.notificationService()
+		push = r.pushService()
+	}
+
+	h := notifyHandler.NewNotifyHandler(notifySvc.NewNotifyService(rules, users, preferences, r.emailService(), inbox, push, smsSender, r.webhookPublisher()))
+	r.App.POST("/api/notify", r.apiClientAuth().Require(domainapiclient.EndpointNotifySend), r.apiClientAuth().Quota(nil), h.Notify)
+}
+
+// CampaignRoutes registers the campaign API and the campaign worker, which
+// main starts with StartWorkers.
+func (r *Routes) CampaignRoutes() {
+	cfg := config.LoadCampaignConfig()
+	svc := campaignSvc.NewCampaignService(campaignRepo.NewCampaignRepo(r.DB), campaignRepo.NewSegmentRepo(r.DB), r.emailService(), cfg)
+	h := campaignHandler.NewCampaignHandler(svc)
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	campaigns := r.App.Group("/api/campaigns").Use(mdw.AuthMiddleware())
+	{
+		campaigns.GET("", mdw.PermissionMiddleware("campaigns", "list"), h.GetAll)
+		campaigns.POST("", mdw.PermissionMiddleware("campaigns", "create"), h.Create)
+		campaigns.POST("/segment/preview", mdw.PermissionMiddleware("campaigns", "create"), h.PreviewSegment)
+		campaigns.GET("/:id", mdw.PermissionMiddleware("campaigns", "view"), h.GetByID)
+		campaigns.PUT("/:id", mdw.PermissionMiddleware("campaigns", "update"), h.Update)
+		campaigns.DELETE("/:id", mdw.PermissionMiddleware("campaigns", "delete"), h.Delete)
+		campaigns.POST("/:id/start", mdw.PermissionMiddleware("campaigns", "control"), h.Start)
+		campaigns.POST("/:id/pause", mdw.PermissionMiddleware("campaigns", "control"), h.Pause)
+		campaigns.POST("/:id/resume", mdw.PermissionMiddleware("campaigns", "control"), h.Resume)
+		campaigns.POST("/:id/cancel", mdw.PermissionMiddleware("campaigns", "control"), h.Cancel)
+	}
+
+	if cfg.WorkerEnabled {
+		r.addWorker("Campaign worker", svc.Run)
+	}
+}
+
+// webhookService returns the shared webhook service, which queues events for
+// subscriber endpoints and delivers them.
+func (r *Routes) webhookService() *webhookSvc.ServiceWebhook {
+	if r.webhookSvc == nil {
+		r.webhookSvc = webhookSvc.NewWebhookService(webhookRepo.NewEndpointRepo(r.DB), webhookRepo.NewDeliveryRepo(r.DB), config.LoadWebhookConfig())
+	}
+	return r.webhookSvc
+}
+
+// webhookPublisher is what emitting services are given. Without a database
+// there are no subscribers and events are dropped.
+func (r *Routes) webhookPublisher() interfacewebhook.PublisherInterface {
+	if r.DB == nil {
+		return nil
+	}
+	return r.webhookService()
+}
+
+// natsService returns the shared NATS publisher, or nil when NATS_URL is not
+// set or the stream cannot be set up.
+func (r *Routes) natsService() *natsSvc.ServiceNATS {
+	if r.natsLoaded {
+		return r.natsSvc
+	}
+	r.natsLoaded = true
+
+	cfg := config.LoadNATSConfig()
+	if cfg.URL == "" {
+		return nil
+	}
+	publisher, err := natsbus.NewJetStreamPublisher(cfg)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelWarn, "NATS publishing disabled: "+err.Error())
+		return nil
+	}
+	r.natsSvc = natsSvc.NewNATSService(publisher, cfg)
+	logger.WriteLog(logger.LogLevelInfo, "NATS publishing to stream "+cfg.Stream)
+	return r.natsSvc
+}
+
+// eventPublisher is what services emitting events outside the outbox are
+// given: webhook subscriptions when there is a database, and NATS when it is
+// configured.
+func (r *Routes) eventPublisher() interfacewebhook.PublisherInterface {
+	var all publishers
+	if r.DB != nil {
+		all = append(all, r.webhookService())
+	}
+	if svc := r.natsService(); svc != nil {
+		all = append(all, svc)
+	}
+
+	switch len(all) {
+	case 0:
+		return nil
+	case 1:
+		return all[0]
+	}
+	return all
+}
+
+// publishers hands each event to every publisher in turn.
+type publishers []interfacewebhook.PublisherInterface
+
+func (p publishers) Publish(eventType string, data map[string]interface{}) {
+	for _, publisher := range p {
+		publisher.Publish(eventType, data)
+	}
+}
+
+// WebhookRoutes registers the webhook subscription API and the delivery
+// worker.
+func (r *Routes) WebhookRoutes() {
+	svc := r.webhookService()
+	h := webhookHandler.NewWebhookHandler(svc)
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	webhooks := r.App.Group("/api/webhooks").Use(mdw.AuthMiddleware())
+	{
+		webhooks.GET("/events", mdw.PermissionMiddleware("webhooks", "list"), h.GetEvents)
+
+		webhooks.GET("/endpoints", mdw.PermissionMiddleware("webhooks", "list"), h.GetEndpoints)
+		webhooks.POST("/endpoints", mdw.PermissionMiddleware("webhooks", "create"), h.CreateEndpoint)
+		webhooks.GET("/endpoints/:id", mdw.PermissionMiddleware("webhooks", "view"), h.GetEndpoint)
+		webhooks.PUT("/endpoints/:id", mdw.PermissionMiddleware("webhooks", "update"), h.UpdateEndpoint)
+		webhooks.DELETE("/endpoints/:id", mdw.PermissionMiddleware("webhooks", "delete"), h.DeleteEndpoint)
+		webhooks.POST("/endpoints/:id/rotate-secret", mdw.PermissionMiddleware("webhooks", "update"), h.RotateSecret)
+		webhooks.GET("/endpoints/:id/deliveries", mdw.PermissionMiddleware("webhooks", "list"), h.GetDeliveries)
+
+		webhooks.GET("/deliveries", mdw.PermissionMiddleware("webhooks", "list"), h.GetDeliveries)
+		webhooks.GET("/deliveries/:id", mdw.PermissionMiddleware("webhooks", "view"), h.GetDelivery)
+		webhooks.POST("/deliveries/:id/redeliver", mdw.PermissionMiddleware("webhooks", "redeliver"), h.Redeliver)
+	}
+
+	if svc.Config.WorkerEnabled {
+		r.addWorker("Webhook delivery worker", svc.Run)
+	}
+}
+
+// OutboxRelay registers the relay that hands events written to the outbox
+// with user, role and permission changes to their consumers: the webhook
+// subscriptions, the security alerts and, when configured, NATS.
+func (r *Routes) OutboxRelay() {
+	consumers := []interfaceoutbox.ConsumerInterface{r.webhookService(), r.securityAlertService()}
+	if svc := r.natsService(); svc != nil {
+		consumers = append(consumers, svc)
+	}
+
+	svc := outboxSvc.NewOutboxService(outboxRepo.NewOutboxRepo(r.DB), config.LoadOutboxConfig(), consumers...)
+	if svc.Config.RelayEnabled {
+		r.addWorker("Outbox relay", svc.Run)
+	}
+}
+
+// emailDeliveryService returns the shared delivery log used by the email
+// service to record sends and skip suppressed recipients.
+func (r *Routes) emailDeliveryService() *emailDeliverySvc.ServiceEmailDelivery {
+	if r.deliveryService == nil {
+		r.deliveryService = emailDeliverySvc.NewEmailDeliveryService(
+			emailDeliveryRepo.NewEmailDeliveryRepo(r.DB),
+			emailDeliveryRepo.NewSuppressionRepo(r.DB),
+		)
+	}
+	return r.deliveryService
+}
+
+func (r *Routes) EmailDeliveryRoutes() {
+	h := emailDeliveryHandler.NewEmailDeliveryHandler(r.emailDeliveryService(), config.LoadEmailWebhookConfig())
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	r.App.POST("/api/webhooks/email/:provider", h.Webhook)
+
+	deliveries := r.App.Group("/api/email/deliveries").Use(mdw.AuthMiddleware())
+	{
+		deliveries.GET("", mdw.PermissionMiddleware("email_deliveries", "list"), h.GetDeliveries)
+	}
+
+	suppressions := r.App.Group("/api/email/suppressions").Use(mdw.AuthMiddleware())
+	{
+		suppressions.GET("", mdw.PermissionMiddleware("suppressions", "list"), h.GetSuppressions)
+		suppressions.POST("", mdw.PermissionMiddleware("suppressions", "create"), h.CreateSuppression)
+		suppressions.DELETE("/:email", mdw.PermissionMiddleware("suppressions", "delete"), h.DeleteSuppression)
+	}
+}
+
+func (r *Routes) EmailTemplateRoutes() {
+	svc := r.emailTemplateService()
+	h := emailTemplateHandler.NewEmailTemplateHandler(svc)
+	emailH := emailHandler.NewEmailHandler(r.emailService())
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	pRepo := permissionRepo.NewPermissionRepo(r.DB)
+	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
+
+	templates := r.App.Group("/api/email/templates").Use(mdw.AuthMiddleware())
+	{
+		templates.GET("", mdw.PermissionMiddleware("templates", "list"), h.GetAll)
+		templates.POST("", mdw.PermissionMiddleware("templates", "create"), h.Create)
+		templates.GET("/:key", mdw.PermissionMiddleware("templates", "view"), h.GetByKey)
+		templates.PUT("/:key", mdw.PermissionMiddleware("templates", "update"), h.Update)
+		templates.DELETE("/:key", mdw.PermissionMiddleware("templates", "delete"), h.Delete)
+		templates.GET("/:key/versions", mdw.PermissionMiddleware("templates", "view"), h.GetVersions)
+		templates.POST("/:key/rollback", mdw.PermissionMiddleware("templates", "rollback"), h.Rollback)
+		templates.POST("/:key/preview", mdw.PermissionMiddleware("templates", "preview"), emailH.PreviewTemplate)
+		templates.POST("/:key/test-send", mdw.PermissionMiddleware("templates", "test_send"), emailH.TestSendTemplate)
 	}
 }
 
@@ -67,6 +588,7 @@ func NewRoutes() *Routes {
 	app.Use(middlewares.CORS())
 	app.Use(gin.CustomRecovery(middlewares.ErrorHandler))
 	app.Use(middlewares.SetContextId())
+	app.Use(middlewares.Locale())
 	app.Use(middlewares.RequestLogger())
 
 	app.GET("/healthcheck", func(ctx *gin.Context) {
@@ -86,7 +608,10 @@ func (r *Routes) UserRoutes() {
 	repo := userRepo.NewUserRepo(r.DB)
 	rRepo := roleRepo.NewRoleRepo(r.DB)
 	pRepo := permissionRepo.NewPermissionRepo(r.DB)
-	uc := userSvc.NewUserService(repo, blacklistRepo, rRepo, pRepo)
+	uc := userSvc.NewUserService(repo, blacklistRepo, rRepo, pRepo, r.passwordPolicy(), r.passwordHistoryService(), config.LoadPasswordHistoryConfig().ChangeTicketTTL)
+	if otp := r.otpService(); otp != nil {
+		uc.Verifications = otp
+	}
 
 	// Setup login limiter if Redis is available
 	redisClient := database.GetRedisClient()
@@ -100,7 +625,7 @@ func (r *Routes) UserRoutes() {
 		)
 	}
 
-	h := userHandler.NewUserHandler(uc, loginLimiter)
+	h := userHandler.NewUserHandler(uc, loginLimiter, r.securityAlertService())
 	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
 
 	// Setup register rate limiter
@@ -116,12 +641,19 @@ func (r *Routes) UserRoutes() {
 		time.Duration(registerWindowSeconds)*time.Second,
 	)
 
+	checkLimiter := middlewares.IPRateLimitMiddleware(
+		redisClient,
+		"password_check",
+		utils.GetEnv("PASSWORD_CHECK_RATE_LIMIT", 30).(int),
+		time.Minute,
+	)
+	r.App.POST("/api/auth/password/check", checkLimiter, h.CheckPassword)
+
 	user := r.App.Group("/api/user")
 	{
 		user.POST("/register", registerLimiter, h.Register)
 		user.POST("/login", h.Login)
-		user.POST("/forgot-password", h.ForgotPassword)
-		user.POST("/reset-password", h.ResetPassword)
+		user.PUT("/change/expired-password", h.ChangeExpiredPassword)
 
 		userPriv := user.Group("").Use(mdw.AuthMiddleware())
 		{
@@ -223,50 +755,164 @@ func (r *Routes) OTPRoutes() {
 		return
 	}
 
+	svc := r.otpService()
+	h := otpHandler.NewOTPHandler(svc)
+
+	otp := r.App.Group("/api/auth/otp")
+	{
+		otp.POST("/send", r.apiClientAuth().Require(domainapiclient.EndpointOTPSend), r.apiClientAuth().Quota(nil), h.SendRegisterOTP)
+		otp.POST("/verify", r.apiClientAuth().Require(domainapiclient.EndpointOTPVerify), h.VerifyRegisterOTP)
+	}
+}
+
+// otpService returns the shared OTP service, which also marks accounts
+// verified when their registration code checks out. It is nil without Redis.
+func (r *Routes) otpService() *otpSvc.ServiceOTP {
+	if r.otpLoaded {
+		return r.otpSvc
+	}
+	r.otpLoaded = true
+
+	redisClient := database.GetRedisClient()
+	if redisClient == nil {
+		return nil
+	}
+
 	sender, err := mailer.NewBrevoSenderFromEnv()
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, "OTP sender not configured: "+err.Error())
 	}
 
-	repo := otpRepo.NewOTPRepository(redisClient)
-	svc := otpSvc.NewOTPService(repo, sender, config.LoadOTPConfig())
-	h := otpHandler.NewOTPHandler(svc)
+	var users interfaceuser.RepoUserInterface
+	if r.DB != nil {
+		users = userRepo.NewUserRepo(r.DB)
+	}
+	r.otpSvc = otpSvc.NewOTPService(otpRepo.NewOTPRepository(redisClient), sender, config.LoadOTPConfig(), r.eventPublisher(), users)
+	return r.otpSvc
+}
 
-	otp := r.App.Group("/api/auth/otp")
-	{
-		otp.POST("/send", h.SendRegisterOTP)
-		otp.POST("/verify", h.VerifyRegisterOTP)
+// passwordPolicy returns the shared password policy engine that registration,
+// password changes and resets check new passwords with.
+func (r *Routes) passwordPolicy() *passwordpolicy.Engine {
+	if r.policy != nil {
+		return r.policy
+	}
+
+	cfg, err := config.LoadPasswordPolicyConfig()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, "Password role policies ignored: "+err.Error())
+	}
+	r.policy, err = passwordpolicy.NewEngine(cfg)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, "Password policy not fully loaded: "+err.Error())
 	}
+	return r.policy
 }
 
-func (r *Routes) PasswordResetRoutes() {
+// passwordHistoryService returns the shared password history service, which
+// rejects reused passwords on change and reset and expires old ones.
+func (r *Routes) passwordHistoryService() *passwordHistorySvc.ServicePasswordHistory {
+	if r.historySvc == nil {
+		r.historySvc = passwordHistorySvc.NewPasswordHistoryService(passwordHistoryRepo.NewPasswordHistoryRepo(r.DB), config.LoadPasswordHistoryConfig())
+	}
+	return r.historySvc
+}
+
+// passwordResetService returns the shared password reset service, also used
+// to start a reset when an account is locked from a security alert. It is
+// nil without Redis.
+func (r *Routes) passwordResetService() interfacereset.ServicePasswordResetInterface {
+	if r.resetLoaded {
+		return r.resetSvc
+	}
+	r.resetLoaded = true
+
 	redisClient := database.GetRedisClient()
+	if redisClient == nil {
+		logger.WriteLog(logger.LogLevelWarn, "Redis not available, password reset routes other than /email will not be registered")
+		return nil
+	}
 
 	sender, err := mailer.NewBrevoSenderFromEnv()
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, "Password reset sender not configured: "+err.Error())
 	}
 
-	cfg := config.LoadPasswordResetConfig()
+	var users interfaceuser.RepoUserInterface
+	var sessions interfacesession.ServiceSessionInterface
+	var history interfacepasswordhistory.ServicePasswordHistoryInterface
+	if r.DB != nil {
+		users = userRepo.NewUserRepo(r.DB)
+		sessions = sessionSvc.NewSessionService(sessionRepo.NewSessionRepository(redisClient), authRepo.NewBlacklistRepo(r.DB), r.eventPublisher())
+		history = r.passwordHistoryService()
+	}
+	otp := r.otpService()
+	repo := resetRepo.NewPasswordResetRepository(redisClient)
+	r.resetSvc = resetSvc.NewPasswordResetService(repo, sender, users, sessions, otp, config.LoadPasswordResetConfig(), r.eventPublisher(), r.passwordPolicy(), history)
+	return r.resetSvc
+}
 
-	var svc interfacereset.ServicePasswordResetInterface
-	if redisClient == nil {
-		logger.WriteLog(logger.LogLevelWarn, "Redis not available, password reset verify routes will not be registered")
-	} else {
-		repo := resetRepo.NewPasswordResetRepository(redisClient)
-		svc = resetSvc.NewPasswordResetService(repo, sender, cfg)
+func (r *Routes) PasswordResetRoutes() {
+	sender, err := mailer.NewBrevoSenderFromEnv()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, "Password reset sender not configured: "+err.Error())
 	}
 
-	h := resetHandler.NewResetHandler(svc, sender, cfg)
+	h := resetHandler.NewResetHandler(r.passwordResetService(), sender, config.LoadPasswordResetConfig())
 
 	reset := r.App.Group("/api/auth/reset-password")
 	{
-		reset.POST("/email", h.SendResetEmail)
+		reset.POST("/email", r.apiClientAuth().Require(domainapiclient.EndpointResetMail), r.apiClientAuth().Quota(nil), h.SendResetEmail)
+		// The token flow needs the Redis-backed service; without it only
+		// /email, which mails a caller-supplied link, is served.
+		if h.Service == nil {
+			return
+		}
 		reset.POST("/request", h.RequestReset)
 		reset.POST("/verify", h.VerifyReset)
+		reset.POST("/verify-code", h.VerifyResetCode)
+		reset.POST("/complete", h.CompleteReset)
 	}
 }
 
+// securityAlertService returns the shared security alert service, which
+// consumes relayed account changes and checks logins for new devices.
+func (r *Routes) securityAlertService() *securityAlertSvc.ServiceSecurityAlert {
+	if r.alertSvc != nil {
+		return r.alertSvc
+	}
+
+	var sender mailer.SecurityAlertSender
+	if s, err := mailer.NewBrevoSenderFromEnv(); err == nil {
+		sender = s
+	} else {
+		logger.WriteLog(logger.LogLevelWarn, "Security alert emails disabled: "+err.Error())
+	}
+
+	var sessions interfacesession.ServiceSessionInterface
+	if redisClient := database.GetRedisClient(); redisClient != nil {
+		sessions = sessionSvc.NewSessionService(sessionRepo.NewSessionRepository(redisClient), authRepo.NewBlacklistRepo(r.DB), r.eventPublisher())
+	}
+
+	r.alertSvc = securityAlertSvc.NewSecurityAlertService(
+		securityAlertRepo.NewAlertRepo(r.DB),
+		securityAlertRepo.NewDeviceRepo(r.DB),
+		userRepo.NewUserRepo(r.DB),
+		sender,
+		sessions,
+		r.passwordResetService(),
+		config.LoadSecurityAlertConfig(),
+	)
+	return r.alertSvc
+}
+
+// SecurityAlertRoutes registers the endpoint behind the "it wasn't me" link
+// of security alert emails.
+func (r *Routes) SecurityAlertRoutes() {
+	h := securityAlertHandler.NewSecurityAlertHandler(r.securityAlertService())
+	r.App.POST("/api/auth/security/lock", h.Lock)
+}
+
 func (r *Routes) SessionRoutes() {
 	redisClient := database.GetRedisClient()
 	if redisClient == nil {
@@ -274,10 +920,10 @@ func (r *Routes) SessionRoutes() {
 		return
 	}
 
+	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
 	repo := sessionRepo.NewSessionRepository(redisClient)
-	svc := sessionSvc.NewSessionService(repo)
+	svc := sessionSvc.NewSessionService(repo, blacklistRepo, r.eventPublisher())
 	h := sessionHandler.NewSessionHandler(svc)
-	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
 	pRepo := permissionRepo.NewPermissionRepo(r.DB)
 	mdw := middlewares.NewMiddleware(blacklistRepo, pRepo)
 
@@ -0,0 +1,331 @@
+package serviceapiclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	"service-sender/internal/dto"
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+	"service-sender/pkg/config"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/security"
+	"service-sender/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrApiClientExists   = errors.New("api client with this name already exists")
+	ErrApiKeyInvalid     = errors.New("api key is invalid")
+	ErrApiClientInactive = errors.New("api client is inactive")
+	ErrUsageUnavailable  = errors.New("usage metering is not available")
+)
+
+// lastUsedResolution limits how often a busy client's last_used_at is
+// written.
+const lastUsedResolution = time.Minute
+
+type ServiceApiClient struct {
+	Repo   interfaceapiclient.RepoApiClientInterface
+	Usage  interfaceapiclient.RepoUsageInterface
+	Config config.ApiClientConfig
+}
+
+func NewApiClientService(repo interfaceapiclient.RepoApiClientInterface, usage interfaceapiclient.RepoUsageInterface, cfg config.ApiClientConfig) *ServiceApiClient {
+	return &ServiceApiClient{Repo: repo, Usage: usage, Config: cfg}
+}
+
+func (s *ServiceApiClient) Create(req dto.ApiClientCreate, actorId string) (dto.ApiClientKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if existing, err := s.Repo.GetByName(name); err == nil && existing.Id != "" {
+		return dto.ApiClientKey{}, ErrApiClientExists
+	}
+
+	prefix, key, err := security.GenerateApiKey()
+	if err != nil {
+		return dto.ApiClientKey{}, err
+	}
+	secret, err := security.GenerateSigningSecret()
+	if err != nil {
+		return dto.ApiClientKey{}, err
+	}
+
+	data := domainapiclient.ApiClient{
+		Id:               utils.CreateUUID(),
+		Name:             name,
+		Description:      strings.TrimSpace(req.Description),
+		KeyPrefix:        prefix,
+		KeyHash:          security.HashApiKey(key),
+		SigningSecret:    &secret,
+		AllowedEndpoints: normalizeList(req.AllowedEndpoints),
+		AllowedApps:      normalizeList(req.AllowedApps),
+		RequireSignature: req.RequireSignature,
+		BurstPerMinute:   req.BurstPerMinute,
+		DailyQuota:       req.DailyQuota,
+		MonthlyQuota:     req.MonthlyQuota,
+		IsActive:         true,
+		CreatedAt:        time.Now(),
+	}
+	if actorId != "" {
+		data.CreatedBy = &actorId
+	}
+
+	if err := s.Repo.Store(data); err != nil {
+		return dto.ApiClientKey{}, err
+	}
+	return dto.ApiClientKey{Client: data, ApiKey: key, SigningSecret: secret}, nil
+}
+
+func (s *ServiceApiClient) GetByID(id string) (domainapiclient.ApiClient, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *ServiceApiClient) GetAll(params filter.BaseParams) ([]domainapiclient.ApiClient, int64, error) {
+	return s.Repo.GetAll(params)
+}
+
+func (s *ServiceApiClient) Update(id string, req dto.ApiClientUpdate) (domainapiclient.ApiClient, error) {
+	client, err := s.Repo.GetByID(id)
+	if err != nil {
+		return domainapiclient.ApiClient{}, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" && name != client.Name {
+		if existing, err := s.Repo.GetByName(name); err == nil && existing.Id != client.Id {
+			return domainapiclient.ApiClient{}, ErrApiClientExists
+		}
+		client.Name = name
+	}
+	if req.Description != nil {
+		client.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.AllowedEndpoints != nil {
+		client.AllowedEndpoints = normalizeList(req.AllowedEndpoints)
+	}
+	if req.AllowedApps != nil {
+		client.AllowedApps = normalizeList(req.AllowedApps)
+	}
+	if req.RequireSignature != nil {
+		client.RequireSignature = *req.RequireSignature
+	}
+	if req.IsActive != nil {
+		client.IsActive = *req.IsActive
+	}
+	if req.BurstPerMinute != nil {
+		client.BurstPerMinute = *req.BurstPerMinute
+	}
+	if req.DailyQuota != nil {
+		client.DailyQuota = *req.DailyQuota
+	}
+	if req.MonthlyQuota != nil {
+		client.MonthlyQuota = *req.MonthlyQuota
+	}
+
+	now := time.Now()
+	client.UpdatedAt = &now
+	if err := s.Repo.Update(client); err != nil {
+		return domainapiclient.ApiClient{}, err
+	}
+	return client, nil
+}
+
+// Rotate issues a new key and signing secret. The current ones stay valid for
+// the grace period so callers can roll the new ones out without downtime;
+// those still in an earlier grace period are dropped.
+func (s *ServiceApiClient) Rotate(id string, req dto.ApiClientRotate) (dto.ApiClientKey, error) {
+	client, err := s.Repo.GetByID(id)
+	if err != nil {
+		return dto.ApiClientKey{}, err
+	}
+
+	prefix, key, err := security.GenerateApiKey()
+	if err != nil {
+		return dto.ApiClientKey{}, err
+	}
+	secret, err := security.GenerateSigningSecret()
+	if err != nil {
+		return dto.ApiClientKey{}, err
+	}
+
+	grace := s.Config.RotationGrace
+	if req.GraceSeconds != nil {
+		grace = time.Duration(*req.GraceSeconds) * time.Second
+	}
+
+	now := time.Now()
+	client.PreviousKeyPrefix, client.PreviousKeyHash, client.PreviousKeyExpiresAt = nil, nil, nil
+	client.PreviousSigningSecret = nil
+	if grace > 0 {
+		previousPrefix, previousHash, expiresAt := client.KeyPrefix, client.KeyHash, now.Add(grace)
+		client.PreviousKeyPrefix = &previousPrefix
+		client.PreviousKeyHash = &previousHash
+		client.PreviousKeyExpiresAt = &expiresAt
+		client.PreviousSigningSecret = client.SigningSecret
+	}
+	client.KeyPrefix = prefix
+	client.KeyHash = security.HashApiKey(key)
+	client.SigningSecret = &secret
+	client.UpdatedAt = &now
+
+	if err := s.Repo.Update(client); err != nil {
+		return dto.ApiClientKey{}, err
+	}
+	return dto.ApiClientKey{Client: client, ApiKey: key, SigningSecret: secret}, nil
+}
+
+func (s *ServiceApiClient) Delete(id string) error {
+	return s.Repo.Delete(id)
+}
+
+// Authenticate resolves the client owning apiKey, accepting a rotated-out key
+// until its grace period ends.
+func (s *ServiceApiClient) Authenticate(apiKey string) (domainapiclient.ApiClient, error) {
+	prefix, ok := security.ParseApiKey(apiKey)
+	if !ok {
+		return domainapiclient.ApiClient{}, ErrApiKeyInvalid
+	}
+
+	client, err := s.Repo.GetByKeyPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domainapiclient.ApiClient{}, ErrApiKeyInvalid
+		}
+		return domainapiclient.ApiClient{}, err
+	}
+
+	now := time.Now()
+	switch {
+	case client.KeyPrefix == prefix && security.ApiKeyMatches(apiKey, client.KeyHash):
+	case client.PreviousKeyPrefix != nil && *client.PreviousKeyPrefix == prefix &&
+		client.PreviousKeyHash != nil && security.ApiKeyMatches(apiKey, *client.PreviousKeyHash) &&
+		client.PreviousKeyExpiresAt != nil && now.Before(*client.PreviousKeyExpiresAt):
+	default:
+		return domainapiclient.ApiClient{}, ErrApiKeyInvalid
+	}
+
+	if !client.IsActive {
+		return domainapiclient.ApiClient{}, ErrApiClientInactive
+	}
+
+	if client.LastUsedAt == nil || now.Sub(*client.LastUsedAt) >= lastUsedResolution {
+		if err := s.Repo.TouchLastUsed(client.Id, now); err != nil {
+			logger.WriteLog(logger.LogLevelError, "[ApiClientService][Authenticate]; TouchLastUsed error: "+err.Error())
+		}
+		client.LastUsedAt = &now
+	}
+	return client, nil
+}
+
+// ConsumeQuota reserves cost sends for client in every quota window. A
+// client with no limits is always allowed; one with limits gets
+// ErrUsageUnavailable without Redis, so its quota is never skipped.
+func (s *ServiceApiClient) ConsumeQuota(ctx context.Context, client domainapiclient.ApiClient, cost int) (dto.QuotaCheck, error) {
+	now := time.Now()
+	check := dto.QuotaCheck{Allowed: true, CheckedAt: now}
+
+	windows := client.QuotaWindows(now)
+	if !hasLimit(windows) {
+		return check, nil
+	}
+	if s.Usage == nil {
+		return check, ErrUsageUnavailable
+	}
+
+	used, refused, err := s.Usage.Consume(ctx, client.Id, cost, windows)
+	if err != nil {
+		return check, err
+	}
+
+	if refused >= 0 {
+		usage := quotaUsage(windows[refused], used[refused])
+		check.Allowed = false
+		check.Tightest = &usage
+		return check, nil
+	}
+
+	for i, w := range windows {
+		if w.Limit <= 0 {
+			continue
+		}
+		usage := quotaUsage(w, used[i])
+		if check.Tightest == nil || *usage.Remaining < *check.Tightest.Remaining {
+			check.Tightest = &usage
+		}
+	}
+	return check, nil
+}
+
+func hasLimit(windows []domainapiclient.QuotaWindow) bool {
+	for _, w := range windows {
+		if w.Limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RefundQuota gives back sends reserved at at, for requests that ended up
+// not sending anything.
+func (s *ServiceApiClient) RefundQuota(ctx context.Context, client domainapiclient.ApiClient, cost int, at time.Time) error {
+	if s.Usage == nil {
+		return nil
+	}
+	return s.Usage.Refund(ctx, client.Id, cost, client.QuotaWindows(at))
+}
+
+func (s *ServiceApiClient) GetUsage(ctx context.Context, id string) (dto.ApiClientUsage, error) {
+	client, err := s.Repo.GetByID(id)
+	if err != nil {
+		return dto.ApiClientUsage{}, err
+	}
+	if s.Usage == nil {
+		return dto.ApiClientUsage{}, ErrUsageUnavailable
+	}
+
+	windows := client.QuotaWindows(time.Now())
+	used, err := s.Usage.Get(ctx, client.Id, windows)
+	if err != nil {
+		return dto.ApiClientUsage{}, err
+	}
+
+	data := dto.ApiClientUsage{ClientId: client.Id, Windows: make([]dto.QuotaUsage, len(windows))}
+	for i, w := range windows {
+		data.Windows[i] = quotaUsage(w, used[i])
+	}
+	return data, nil
+}
+
+func quotaUsage(w domainapiclient.QuotaWindow, used int) dto.QuotaUsage {
+	usage := dto.QuotaUsage{Window: w.Name, Used: used, Limit: w.Limit, ResetsAt: w.ResetsAt}
+	if w.Limit > 0 {
+		remaining := w.Limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		usage.Remaining = &remaining
+	}
+	return usage
+}
+
+func normalizeList(values []string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[strings.ToLower(v)]; ok {
+			continue
+		}
+		seen[strings.ToLower(v)] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
+
+var _ interfaceapiclient.ServiceApiClientInterface = (*ServiceApiClient)(nil)
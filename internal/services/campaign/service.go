@@ -0,0 +1,261 @@
+package servicecampaign
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	domaincampaign "service-sender/internal/domain/campaign"
+	"service-sender/internal/dto"
+	interfacecampaign "service-sender/internal/interfaces/campaign"
+	interfaceemail "service-sender/internal/interfaces/email"
+	"service-sender/pkg/config"
+	"service-sender/pkg/filter"
+	"service-sender/utils"
+)
+
+var (
+	ErrCampaignContentRequired = errors.New("one of template_key, text_body, html_body or markdown_body must be provided")
+	ErrCampaignSubjectRequired = errors.New("subject is required when template_key is empty")
+	ErrCampaignRateTooHigh     = errors.New("rate_per_minute exceeds the configured maximum")
+	ErrCampaignNotEditable     = errors.New("only draft campaigns can be edited")
+	ErrCampaignActive          = errors.New("running or paused campaigns must be cancelled before they are deleted")
+	ErrCampaignTransition      = errors.New("campaign status does not allow this action")
+)
+
+type ServiceCampaign struct {
+	Repo     interfacecampaign.RepoCampaignInterface
+	Segments interfacecampaign.RepoSegmentInterface
+	Email    interfaceemail.ServiceEmailInterface
+	Config   config.CampaignConfig
+}
+
+func NewCampaignService(repo interfacecampaign.RepoCampaignInterface, segments interfacecampaign.RepoSegmentInterface, email interfaceemail.ServiceEmailInterface, cfg config.CampaignConfig) *ServiceCampaign {
+	return &ServiceCampaign{Repo: repo, Segments: segments, Email: email, Config: cfg}
+}
+
+func (s *ServiceCampaign) Create(req dto.CampaignCreate, actorId string) (domaincampaign.Campaign, error) {
+	rate := req.RatePerMinute
+	if rate == 0 {
+		rate = s.Config.DefaultRate
+	}
+	if rate > s.Config.MaxRate {
+		return domaincampaign.Campaign{}, ErrCampaignRateTooHigh
+	}
+
+	data := domaincampaign.Campaign{
+		Id:           utils.CreateUUID(),
+		Name:         strings.TrimSpace(req.Name),
+		AppName:      strings.TrimSpace(req.AppName),
+		Sender:       strings.TrimSpace(req.Sender),
+		Subject:      strings.TrimSpace(req.Subject),
+		TemplateKey:  strings.TrimSpace(req.TemplateKey),
+		TemplateData: req.TemplateData,
+		TextBody:     req.TextBody,
+		HTMLBody:     req.HTMLBody,
+		MarkdownBody: req.MarkdownBody,
+		Segment:      toSegment(req.Segment),
+		Status:       domaincampaign.StatusDraft,
+		RatePerMin:   rate,
+		CreatedAt:    time.Now(),
+	}
+	if err := validateContent(data); err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	if actorId != "" {
+		data.CreatedBy = &actorId
+	}
+
+	if err := s.Repo.Store(data); err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	return data.WithProgress(), nil
+}
+
+func (s *ServiceCampaign) GetByID(id string) (domaincampaign.Campaign, error) {
+	data, err := s.Repo.GetByID(id)
+	if err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	return data.WithProgress(), nil
+}
+
+func (s *ServiceCampaign) GetAll(params filter.BaseParams) ([]domaincampaign.Campaign, int64, error) {
+	data, total, err := s.Repo.GetAll(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range data {
+		data[i] = data[i].WithProgress()
+	}
+	return data, total, nil
+}
+
+func (s *ServiceCampaign) Update(id string, req dto.CampaignUpdate) (domaincampaign.Campaign, error) {
+	data, err := s.Repo.GetByID(id)
+	if err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	if data.Status != domaincampaign.StatusDraft {
+		return domaincampaign.Campaign{}, ErrCampaignNotEditable
+	}
+
+	if req.Name != "" {
+		data.Name = strings.TrimSpace(req.Name)
+	}
+	if req.AppName != nil {
+		data.AppName = strings.TrimSpace(*req.AppName)
+	}
+	if req.Sender != nil {
+		data.Sender = strings.TrimSpace(*req.Sender)
+	}
+	if req.Subject != nil {
+		data.Subject = strings.TrimSpace(*req.Subject)
+	}
+	if req.TemplateKey != nil {
+		data.TemplateKey = strings.TrimSpace(*req.TemplateKey)
+	}
+	if req.TemplateData != nil {
+		data.TemplateData = req.TemplateData
+	}
+	if req.TextBody != nil {
+		data.TextBody = *req.TextBody
+	}
+	if req.HTMLBody != nil {
+		data.HTMLBody = *req.HTMLBody
+	}
+	if req.MarkdownBody != nil {
+		data.MarkdownBody = *req.MarkdownBody
+	}
+	if req.Segment != nil {
+		data.Segment = toSegment(*req.Segment)
+	}
+	if req.RatePerMinute != nil {
+		if *req.RatePerMinute > s.Config.MaxRate {
+			return domaincampaign.Campaign{}, ErrCampaignRateTooHigh
+		}
+		data.RatePerMin = *req.RatePerMinute
+	}
+	if err := validateContent(data); err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+
+	now := time.Now()
+	data.UpdatedAt = &now
+	if err := s.Repo.Update(data); err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	return data.WithProgress(), nil
+}
+
+func (s *ServiceCampaign) Delete(id string) error {
+	data, err := s.Repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if data.Status == domaincampaign.StatusRunning || data.Status == domaincampaign.StatusPaused {
+		return ErrCampaignActive
+	}
+	return s.Repo.Delete(id)
+}
+
+// PreviewSegment counts the users a segment currently matches.
+func (s *ServiceCampaign) PreviewSegment(req dto.CampaignSegment) (dto.SegmentPreview, error) {
+	count, err := s.Segments.Count(toSegment(req), nil)
+	if err != nil {
+		return dto.SegmentPreview{}, err
+	}
+	return dto.SegmentPreview{Count: count}, nil
+}
+
+// Start freezes the audience at the current time, records its size and hands
+// the campaign to the worker.
+func (s *ServiceCampaign) Start(id string) (domaincampaign.Campaign, error) {
+	data, err := s.Repo.GetByID(id)
+	if err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	if data.Status != domaincampaign.StatusDraft {
+		return domaincampaign.Campaign{}, ErrCampaignTransition
+	}
+
+	now := time.Now()
+	total, err := s.Segments.Count(data.Segment, &now)
+	if err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+
+	return s.transition(id, []string{domaincampaign.StatusDraft}, domaincampaign.StatusRunning, map[string]interface{}{
+		"total_count": int(total),
+		"started_at":  now,
+		"lease_until": nil,
+	})
+}
+
+// Pause stops the campaign after the send in progress, if any.
+func (s *ServiceCampaign) Pause(id string) (domaincampaign.Campaign, error) {
+	return s.transition(id, []string{domaincampaign.StatusRunning}, domaincampaign.StatusPaused, nil)
+}
+
+func (s *ServiceCampaign) Resume(id string) (domaincampaign.Campaign, error) {
+	return s.transition(id, []string{domaincampaign.StatusPaused}, domaincampaign.StatusRunning, map[string]interface{}{
+		"lease_until": nil,
+	})
+}
+
+// Cancel ends the campaign for good. Recipients not reached yet are never
+// mailed.
+func (s *ServiceCampaign) Cancel(id string) (domaincampaign.Campaign, error) {
+	return s.transition(id, domaincampaign.SourcesOf(domaincampaign.StatusCancelled), domaincampaign.StatusCancelled, map[string]interface{}{
+		"finished_at": time.Now(),
+	})
+}
+
+// transition applies a status change guarded by the current status, so a
+// control racing the worker either wins or reports a conflict.
+func (s *ServiceCampaign) transition(id string, from []string, status string, fields map[string]interface{}) (domaincampaign.Campaign, error) {
+	if _, err := s.Repo.GetByID(id); err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+
+	ok, err := s.Repo.SetStatus(id, from, status, fields)
+	if err != nil {
+		return domaincampaign.Campaign{}, err
+	}
+	if !ok {
+		return domaincampaign.Campaign{}, ErrCampaignTransition
+	}
+	return s.GetByID(id)
+}
+
+func toSegment(req dto.CampaignSegment) domaincampaign.Segment {
+	segment := domaincampaign.Segment{
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Verified:    req.Verified,
+		Attributes:  req.Attributes,
+	}
+	for _, role := range req.Roles {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			segment.Roles = append(segment.Roles, role)
+		}
+	}
+	return segment
+}
+
+// validateContent rejects campaigns the email service would refuse for every
+// recipient.
+func validateContent(c domaincampaign.Campaign) error {
+	if c.TemplateKey != "" {
+		return nil
+	}
+	if strings.TrimSpace(c.TextBody) == "" && strings.TrimSpace(c.HTMLBody) == "" && strings.TrimSpace(c.MarkdownBody) == "" {
+		return ErrCampaignContentRequired
+	}
+	if c.Subject == "" {
+		return ErrCampaignSubjectRequired
+	}
+	return nil
+}
+
+var _ interfacecampaign.ServiceCampaignInterface = (*ServiceCampaign)(nil)
@@ -0,0 +1,209 @@
+package servicecampaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domaincampaign "service-sender/internal/domain/campaign"
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	domainpreference "service-sender/internal/domain/preference"
+	domainuser "service-sender/internal/domain/user"
+	"service-sender/internal/dto"
+	serviceemail "service-sender/internal/services/email"
+	"service-sender/pkg/logger"
+)
+
+// leaseMargin is added to the expected batch duration so a campaign is only
+// picked up by another worker when its holder has clearly died.
+const leaseMargin = time.Minute
+
+// Run processes running campaigns every worker interval until ctx is done.
+// Each due campaign is leased, sent one batch and released until its next
+// batch is due, so the send rate holds across batches and instances.
+func (s *ServiceCampaign) Run(ctx context.Context) {
+	if !s.Config.WorkerEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.WorkerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *ServiceCampaign) tick(ctx context.Context) {
+	now := time.Now()
+	campaigns, err := s.Repo.GetRunnable(now)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[CampaignWorker][tick]; GetRunnable error: %v", err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range campaigns {
+		size, spacing := s.batchPlan(c)
+		leased, err := s.Repo.Lease(c.Id, now, now.Add(time.Duration(size)*spacing+leaseMargin))
+		if err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[CampaignWorker][tick]; Lease %s error: %v", c.Id, err))
+			continue
+		}
+		if !leased {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c domaincampaign.Campaign) {
+			defer wg.Done()
+			s.runBatch(ctx, c, size, spacing)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// batchPlan spreads the campaign rate over the worker interval: sends are
+// spacing apart and a batch covers about one interval.
+func (s *ServiceCampaign) batchPlan(c domaincampaign.Campaign) (int, time.Duration) {
+	rate := c.RatePerMin
+	if rate <= 0 {
+		rate = s.Config.DefaultRate
+	}
+	spacing := time.Minute / time.Duration(rate)
+	size := int(s.Config.WorkerInterval / spacing)
+	if size < 1 {
+		size = 1
+	}
+	return size, spacing
+}
+
+// runBatch sends to the next users of the segment. Progress is written after
+// every recipient, so an interrupted batch resends to at most one user, and
+// the status is checked between sends so a pause or cancel stops the batch.
+func (s *ServiceCampaign) runBatch(ctx context.Context, c domaincampaign.Campaign, size int, spacing time.Duration) {
+	logPrefix := fmt.Sprintf("[CampaignWorker][runBatch][%s]", c.Id)
+	started := time.Now()
+
+	cursor := ""
+	if c.CursorId != nil {
+		cursor = *c.CursorId
+	}
+	users, err := s.Segments.NextBatch(c.Segment, c.StartedAt, cursor, size)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; NextBatch error: %v", logPrefix, err))
+		s.release(c.Id, started.Add(s.Config.WorkerInterval))
+		return
+	}
+	if len(users) == 0 {
+		if _, err := s.Repo.SetStatus(c.Id, []string{domaincampaign.StatusRunning}, domaincampaign.StatusCompleted, map[string]interface{}{
+			"finished_at": time.Now(),
+			"lease_until": nil,
+		}); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; complete error: %v", logPrefix, err))
+		}
+		return
+	}
+
+	for i, user := range users {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				s.release(c.Id, time.Now())
+				return
+			case <-time.After(spacing):
+			}
+			if current, err := s.Repo.GetByID(c.Id); err == nil && current.Status != domaincampaign.StatusRunning {
+				return
+			}
+		}
+
+		var sent, failed, skipped int
+		var lastError string
+		err := s.sendTo(ctx, c, user)
+		switch {
+		case err == nil:
+			sent = 1
+		case errors.Is(err, serviceemail.ErrAllRecipientsSuppressed), errors.Is(err, serviceemail.ErrAllRecipientsOptedOut):
+			skipped = 1
+		case haltsCampaign(err):
+			// The same error would hit every recipient; stop and let an
+			// operator fix the campaign or configuration and resume.
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; pausing campaign: %v", logPrefix, err))
+			if _, pauseErr := s.Repo.SetStatus(c.Id, []string{domaincampaign.StatusRunning}, domaincampaign.StatusPaused, map[string]interface{}{
+				"last_error":  err.Error(),
+				"lease_until": nil,
+			}); pauseErr != nil {
+				logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; pause error: %v", logPrefix, pauseErr))
+			}
+			return
+		default:
+			failed = 1
+			lastError = err.Error()
+			logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("%s; send to user %s failed: %v", logPrefix, user.Id, err))
+		}
+
+		if err := s.Repo.Advance(c.Id, user.Id, sent, failed, skipped, lastError); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Advance error: %v", logPrefix, err))
+			s.release(c.Id, time.Now().Add(s.Config.WorkerInterval))
+			return
+		}
+	}
+
+	s.release(c.Id, started.Add(time.Duration(len(users))*spacing))
+}
+
+// sendTo mails one user. Suppressed and unsubscribed addresses and users who
+// turned off marketing email are filtered by the email service.
+func (s *ServiceCampaign) sendTo(ctx context.Context, c domaincampaign.Campaign, user domainuser.Users) error {
+	data := make(map[string]interface{}, len(c.TemplateData)+1)
+	for k, v := range c.TemplateData {
+		data[k] = v
+	}
+	data["Recipient"] = map[string]interface{}{
+		"Name":       user.Name,
+		"Email":      user.Email,
+		"Attributes": user.Attributes,
+	}
+
+	_, _, err := s.Email.Send(ctx, dto.SendEmailRequest{
+		Type:           domainemaildelivery.EmailTypeCampaign,
+		Category:       domainpreference.CategoryMarketing,
+		To:             []string{user.Email},
+		Sender:         c.Sender,
+		Subject:        c.Subject,
+		TextBody:       c.TextBody,
+		HTMLBody:       c.HTMLBody,
+		MarkdownBody:   c.MarkdownBody,
+		TemplateKey:    c.TemplateKey,
+		TemplateData:   data,
+		Locale:         user.Locale,
+		IdempotencyKey: c.Id + ":" + user.Id,
+	}, c.AppName)
+	return err
+}
+
+func (s *ServiceCampaign) release(id string, next time.Time) {
+	if err := s.Repo.Release(id, next); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[CampaignWorker][release]; Release %s error: %v", id, err))
+	}
+}
+
+// haltsCampaign reports errors caused by the campaign or the service setup
+// rather than by the recipient.
+func haltsCampaign(err error) bool {
+	var dataErr *serviceemail.TemplateDataError
+	return errors.Is(err, serviceemail.ErrEmailNotConfigured) ||
+		errors.Is(err, serviceemail.ErrSubjectRequired) ||
+		errors.Is(err, serviceemail.ErrEmailBodyRequired) ||
+		errors.Is(err, serviceemail.ErrTemplateNotFound) ||
+		errors.Is(err, serviceemail.ErrSenderIdentityNotFound) ||
+		errors.Is(err, serviceemail.ErrSenderIdentityNotAllowed) ||
+		errors.As(err, &dataErr)
+}
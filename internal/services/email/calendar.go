@@ -0,0 +1,112 @@
+package serviceemail
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"service-sender/internal/dto"
+	"service-sender/pkg/mailer"
+)
+
+const (
+	emailTypeCalendar       = "calendar"
+	calendarDefaultTemplate = "calendar_default"
+	calendarTimeLayout      = "Mon, 02 Jan 2006 15:04 MST"
+)
+
+var ErrCalendarEventRequired = errors.New("event is required for calendar emails")
+var ErrCalendarTimezoneInvalid = errors.New("invalid calendar timezone")
+
+// calendarView is the event as templates see it under .Event, with times
+// already formatted in the event timezone.
+type calendarView struct {
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       string
+	End         string
+	Timezone    string
+	Organizer   string
+	Cancelled   bool
+}
+
+// toCalendarEvent maps the request onto the invite. Without an explicit
+// attendee list every To recipient is a required participant and every Cc
+// recipient an optional one; Bcc recipients are never listed.
+func toCalendarEvent(req *dto.CalendarEvent, to, cc []string) (*mailer.CalendarEvent, error) {
+	if req == nil {
+		return nil, ErrCalendarEventRequired
+	}
+
+	loc := time.UTC
+	if tz := strings.TrimSpace(req.Timezone); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, ErrCalendarTimezoneInvalid
+		}
+	}
+
+	event := &mailer.CalendarEvent{
+		UID:         strings.TrimSpace(req.UID),
+		Method:      strings.ToUpper(strings.TrimSpace(req.Method)),
+		Sequence:    req.Sequence,
+		Summary:     strings.TrimSpace(req.Summary),
+		Description: strings.TrimSpace(req.Description),
+		Location:    strings.TrimSpace(req.Location),
+		URL:         strings.TrimSpace(req.URL),
+		Start:       req.Start,
+		End:         req.End,
+		Timezone:    loc,
+	}
+	if event.Method == "" {
+		event.Method = mailer.CalendarMethodRequest
+	}
+	if req.Organizer != nil {
+		event.OrganizerName = strings.TrimSpace(req.Organizer.Name)
+		event.OrganizerEmail = strings.ToLower(strings.TrimSpace(req.Organizer.Email))
+	}
+
+	if len(req.Attendees) > 0 {
+		for _, a := range req.Attendees {
+			rsvp := true
+			if a.RSVP != nil {
+				rsvp = *a.RSVP
+			}
+			event.Attendees = append(event.Attendees, mailer.CalendarAttendee{
+				Name:  strings.TrimSpace(a.Name),
+				Email: strings.ToLower(strings.TrimSpace(a.Email)),
+				Role:  a.Role,
+				RSVP:  rsvp,
+			})
+		}
+		return event, nil
+	}
+
+	for _, email := range to {
+		event.Attendees = append(event.Attendees, mailer.CalendarAttendee{Email: email, Role: "REQ-PARTICIPANT", RSVP: true})
+	}
+	for _, email := range cc {
+		event.Attendees = append(event.Attendees, mailer.CalendarAttendee{Email: email, Role: "OPT-PARTICIPANT", RSVP: true})
+	}
+	return event, nil
+}
+
+func newCalendarView(event *mailer.CalendarEvent) calendarView {
+	organizer := event.OrganizerName
+	if organizer == "" {
+		organizer = event.OrganizerEmail
+	}
+	return calendarView{
+		Summary:     event.Summary,
+		Description: event.Description,
+		Location:    event.Location,
+		URL:         event.URL,
+		Start:       event.Start.In(event.Timezone).Format(calendarTimeLayout),
+		End:         event.End.In(event.Timezone).Format(calendarTimeLayout),
+		Timezone:    event.Timezone.String(),
+		Organizer:   organizer,
+		Cancelled:   event.Cancelled(),
+	}
+}
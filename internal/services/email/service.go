@@ -6,20 +6,58 @@ import (
 	"fmt"
 	"strings"
 
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	domainpreference "service-sender/internal/domain/preference"
 	"service-sender/internal/dto"
 	interfaceemail "service-sender/internal/interfaces/email"
+	interfaceemaildelivery "service-sender/internal/interfaces/emaildelivery"
+	interfaceemailtemplate "service-sender/internal/interfaces/emailtemplate"
+	interfacepreference "service-sender/internal/interfaces/preference"
+	interfaceuser "service-sender/internal/interfaces/user"
+	"service-sender/pkg/i18n"
+	"service-sender/pkg/logger"
 	"service-sender/pkg/mailer"
 )
 
 var ErrEmailNotConfigured = errors.New("email sender not configured")
-var ErrEmailBodyRequired = errors.New("either text_body or html_body must be provided")
+var ErrEmailBodyRequired = errors.New("one of text_body, html_body or markdown_body must be provided")
+var ErrSenderIdentityNotFound = errors.New("sender identity not found")
+var ErrSenderIdentityNotAllowed = errors.New("sender identity not allowed for this type or app")
+var ErrTestRecipientNotFound = errors.New("test recipient has no email address")
+var ErrAllRecipientsSuppressed = errors.New("all recipients are suppressed")
+var ErrAllRecipientsOptedOut = errors.New("all recipients opted out of this category")
+var ErrCategoryNotAllowed = errors.New("campaigns cannot be sent in the security category")
+var ErrPrivateToWithCopies = errors.New("private_to cannot be combined with cc or bcc")
+
+// PartialSendError is returned when a private_to send failed after some
+// recipients already got their copy. Sent counts them; Failed lists the
+// recipients that got nothing.
+type PartialSendError struct {
+	Sent   int
+	Failed []string
+	Err    error
+}
+
+func (e *PartialSendError) Error() string {
+	return fmt.Sprintf("sent to %d recipients, %d failed: %v", e.Sent, len(e.Failed), e.Err)
+}
+
+func (e *PartialSendError) Unwrap() error {
+	return e.Err
+}
 
 type ServiceEmail struct {
-	Sender mailer.EmailSender
+	Sender      mailer.EmailSender
+	Identities  *mailer.IdentityRegistry
+	Brands      *mailer.BrandRegistry
+	Templates   interfaceemailtemplate.ServiceEmailTemplateInterface
+	Users       interfaceuser.RepoUserInterface
+	Deliveries  interfaceemaildelivery.ServiceEmailDeliveryInterface
+	Preferences interfacepreference.ServicePreferenceInterface
 }
 
-func NewEmailService(sender mailer.EmailSender) *ServiceEmail {
-	return &ServiceEmail{Sender: sender}
+func NewEmailService(sender mailer.EmailSender, identities *mailer.IdentityRegistry, brands *mailer.BrandRegistry, templates interfaceemailtemplate.ServiceEmailTemplateInterface, users interfaceuser.RepoUserInterface, deliveries interfaceemaildelivery.ServiceEmailDeliveryInterface, preferences interfacepreference.ServicePreferenceInterface) *ServiceEmail {
+	return &ServiceEmail{Sender: sender, Identities: identities, Brands: brands, Templates: templates, Users: users, Deliveries: deliveries, Preferences: preferences}
 }
 
 func (s *ServiceEmail) Send(_ context.Context, req dto.SendEmailRequest, appName string) (int, string, error) {
@@ -27,42 +65,327 @@ func (s *ServiceEmail) Send(_ context.Context, req dto.SendEmailRequest, appName
 		return 0, "", ErrEmailNotConfigured
 	}
 
-	subject, textBody, htmlBody, err := renderEmailContent(
+	// Nobody can opt out of security email, so marketing must never use it.
+	if req.Type == domainemaildelivery.EmailTypeCampaign && req.Category == domainpreference.CategorySecurity {
+		return 0, "", ErrCategoryNotAllowed
+	}
+
+	to, cc, bcc := dedupeRecipients(req.To, req.Cc, req.Bcc)
+	if len(to) == 0 {
+		return 0, "", fmt.Errorf("recipient list is empty")
+	}
+	if req.PrivateTo && len(cc)+len(bcc) > 0 {
+		return 0, "", ErrPrivateToWithCopies
+	}
+	to, cc, bcc, err := s.dropSuppressed(req.Type, to, cc, bcc)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(to) == 0 {
+		return 0, "", ErrAllRecipientsSuppressed
+	}
+	if to, cc, bcc, err = s.dropOptedOut(emailCategory(req), to, cc, bcc); err != nil {
+		return 0, "", err
+	}
+	if len(to) == 0 {
+		return 0, "", ErrAllRecipientsOptedOut
+	}
+
+	if req.Locale == "" {
+		req.Locale = i18n.First(s.profileLocale(to[0]), req.AcceptLocale)
+	}
+
+	var event *mailer.CalendarEvent
+	var vars map[string]interface{}
+	if req.Type == emailTypeCalendar {
+		if event, err = toCalendarEvent(req.Event, to, cc); err != nil {
+			return 0, "", err
+		}
+		vars = map[string]interface{}{"Event": newCalendarView(event)}
+		hasBody := strings.TrimSpace(req.TextBody) != "" || strings.TrimSpace(req.HTMLBody) != "" || strings.TrimSpace(req.MarkdownBody) != ""
+		if strings.TrimSpace(req.TemplateKey) == "" && !hasBody {
+			req.TemplateKey = calendarDefaultTemplate
+		}
+		if strings.TrimSpace(req.Subject) == "" && strings.TrimSpace(req.TemplateKey) == "" {
+			req.Subject = event.Summary
+		}
+	}
+
+	subject, textBody, htmlBody, err := s.renderEmailContent(
 		req.Subject,
 		req.TextBody,
 		req.HTMLBody,
+		req.MarkdownBody,
 		req.TemplateKey,
+		req.Locale,
 		req.TemplateData,
+		vars,
 		strings.TrimSpace(appName),
 	)
 	if err != nil {
 		return 0, "", err
 	}
 
-	to := dedupeEmails(req.To)
-	if len(to) == 0 {
-		return 0, "", fmt.Errorf("recipient list is empty")
+	from, replyTo, err := s.resolveSender(req.Sender, req.Type, strings.TrimSpace(appName))
+	if err != nil {
+		return 0, "", err
+	}
+	if v := strings.TrimSpace(req.ReplyTo); v != "" {
+		replyTo = v
 	}
 
 	payload := mailer.EmailPayload{
 		Type:           req.Type,
+		From:           from,
 		To:             to,
+		Cc:             cc,
+		Bcc:            bcc,
 		Subject:        subject,
 		TextBody:       textBody,
 		HTMLBody:       htmlBody,
-		ReplyTo:        strings.TrimSpace(req.ReplyTo),
+		ReplyTo:        replyTo,
 		AppName:        strings.TrimSpace(appName),
 		IdempotencyKey: strings.TrimSpace(req.IdempotencyKey),
+		PrivateTo:      req.PrivateTo,
+		Calendar:       event,
 	}
 
-	if err := s.Sender.SendEmail(payload); err != nil {
+	sent, err := s.Sender.SendEmail(payload)
+	s.recordDeliveries(req.Type, payload.AppName, subject, sent)
+	if err != nil {
+		if len(sent) > 0 {
+			return 0, subject, partialSendError(to, sent, err)
+		}
 		return 0, "", err
 	}
-	return len(to), subject, nil
+	return len(to) + len(cc) + len(bcc), subject, nil
+}
+
+// partialSendError tells which of to were not reached when only some of the
+// private copies went out.
+func partialSendError(to []string, sent []mailer.SentMessage, err error) *PartialSendError {
+	reached := make(map[string]struct{}, len(to))
+	for _, msg := range sent {
+		for _, recipient := range msg.Recipients {
+			reached[recipient] = struct{}{}
+		}
+	}
+	failed := make([]string, 0, len(to)-len(reached))
+	for _, recipient := range to {
+		if _, ok := reached[recipient]; !ok {
+			failed = append(failed, recipient)
+		}
+	}
+	return &PartialSendError{Sent: len(reached), Failed: failed, Err: err}
+}
+
+// profileLocale is the locale on the account of email, or "" when there is
+// none. It is the step of the locale chain after the request's own locale.
+func (s *ServiceEmail) profileLocale(email string) string {
+	if s.Users == nil {
+		return ""
+	}
+	user, err := s.Users.GetByEmail(email)
+	if err != nil {
+		return ""
+	}
+	return user.Locale
+}
+
+// Preview renders a template with the given data without sending anything.
+// Variables referenced by the template but absent from template_data are
+// reported as warnings rather than errors. Without a locale in the request
+// the Accept-Language locale is used, as Send does when the recipient has no
+// profile.
+func (s *ServiceEmail) Preview(_ context.Context, key string, req dto.EmailTemplatePreviewRequest, appName string) (dto.EmailTemplatePreview, error) {
+	if strings.TrimSpace(key) == "" {
+		return dto.EmailTemplatePreview{}, ErrTemplateNotFound
+	}
+	if req.Locale == "" {
+		req.Locale = i18n.First(req.AcceptLocale)
+	}
+
+	subject, textBody, htmlBody, err := s.renderEmailContent(
+		req.Subject,
+		"",
+		"",
+		"",
+		key,
+		req.Locale,
+		req.TemplateData,
+		nil,
+		strings.TrimSpace(appName),
+	)
+	if err != nil {
+		return dto.EmailTemplatePreview{}, err
+	}
+
+	return dto.EmailTemplatePreview{
+		Subject:  subject,
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+		Warnings: s.missingVariables(key, req.Locale, req.TemplateData),
+	}, nil
+}
+
+// SendTest renders the template like Preview and delivers it only to the
+// caller's own email address. The recipient is looked up from userId and is
+// never taken from the request.
+func (s *ServiceEmail) SendTest(ctx context.Context, key string, req dto.EmailTemplatePreviewRequest, appName, userId string) (dto.EmailTemplatePreview, string, error) {
+	if s == nil || s.Sender == nil {
+		return dto.EmailTemplatePreview{}, "", ErrEmailNotConfigured
+	}
+	if s.Users == nil {
+		return dto.EmailTemplatePreview{}, "", ErrTestRecipientNotFound
+	}
+
+	user, err := s.Users.GetByID(userId)
+	if err != nil {
+		return dto.EmailTemplatePreview{}, "", err
+	}
+	recipient := strings.ToLower(strings.TrimSpace(user.Email))
+	if recipient == "" {
+		return dto.EmailTemplatePreview{}, "", ErrTestRecipientNotFound
+	}
+	if req.Locale == "" {
+		req.Locale = i18n.First(user.Locale, req.AcceptLocale)
+	}
+
+	preview, err := s.Preview(ctx, key, req, appName)
+	if err != nil {
+		return dto.EmailTemplatePreview{}, "", err
+	}
+
+	from, replyTo, err := s.resolveSender("", "test", strings.TrimSpace(appName))
+	if err != nil {
+		return dto.EmailTemplatePreview{}, "", err
+	}
+
+	payload := mailer.EmailPayload{
+		Type:     "test",
+		From:     from,
+		To:       []string{recipient},
+		Subject:  preview.Subject,
+		TextBody: preview.TextBody,
+		HTMLBody: preview.HTMLBody,
+		ReplyTo:  replyTo,
+		AppName:  strings.TrimSpace(appName),
+	}
+	sent, err := s.Sender.SendEmail(payload)
+	s.recordDeliveries(payload.Type, payload.AppName, payload.Subject, sent)
+	if err != nil {
+		return dto.EmailTemplatePreview{}, "", err
+	}
+	return preview, recipient, nil
+}
+
+// resolveSender returns the From and default Reply-To for a request. An
+// explicit identity key must be allowed for the type and app; otherwise the
+// configured default for the pair is used, or empty values so the sender
+// falls back to SMTP_FROM.
+func (s *ServiceEmail) resolveSender(key, emailType, appName string) (string, string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		if identity, ok := s.Identities.Resolve(emailType, appName); ok {
+			return identity.From(), identity.ReplyTo, nil
+		}
+		return "", "", nil
+	}
+
+	identity, ok := s.Identities.Get(key)
+	if !ok {
+		return "", "", ErrSenderIdentityNotFound
+	}
+	if !s.Identities.Allowed(identity, emailType, appName) {
+		return "", "", ErrSenderIdentityNotAllowed
+	}
+	return identity.From(), identity.ReplyTo, nil
+}
+
+// dropSuppressed removes addresses suppressed for emailType from every
+// recipient list. Without a delivery log there is no suppression list and
+// nothing is removed.
+func (s *ServiceEmail) dropSuppressed(emailType string, to, cc, bcc []string) ([]string, []string, []string, error) {
+	if s.Deliveries == nil {
+		return to, cc, bcc, nil
+	}
+
+	all := make([]string, 0, len(to)+len(cc)+len(bcc))
+	all = append(append(append(all, to...), cc...), bcc...)
+	suppressed, err := s.Deliveries.FilterSuppressed(all, emailType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(suppressed) == 0 {
+		return to, cc, bcc, nil
+	}
+
+	skip := make(map[string]struct{}, len(suppressed))
+	for _, email := range suppressed {
+		skip[email] = struct{}{}
+	}
+	return dedupeEmails(to, skip), dedupeEmails(cc, skip), dedupeEmails(bcc, skip), nil
+}
+
+// dropOptedOut removes recipients whose user account turned off category
+// for email. Addresses that do not belong to a user are kept, and security
+// mail is never filtered.
+func (s *ServiceEmail) dropOptedOut(category string, to, cc, bcc []string) ([]string, []string, []string, error) {
+	if s.Preferences == nil {
+		return to, cc, bcc, nil
+	}
+
+	all := make([]string, 0, len(to)+len(cc)+len(bcc))
+	all = append(append(append(all, to...), cc...), bcc...)
+	optedOut, err := s.Preferences.FilterOptedOut(all, category, domainpreference.ChannelEmail)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(optedOut) == 0 {
+		return to, cc, bcc, nil
+	}
+
+	skip := make(map[string]struct{}, len(optedOut))
+	for _, email := range optedOut {
+		skip[email] = struct{}{}
+	}
+	return dedupeEmails(to, skip), dedupeEmails(cc, skip), dedupeEmails(bcc, skip), nil
+}
+
+// emailCategory is the preference category of a send: the explicit category,
+// or marketing for campaigns and product for everything else. The send
+// endpoint does not accept security, which users cannot opt out of; only
+// system senders such as notification rules set it.
+func emailCategory(req dto.SendEmailRequest) string {
+	if req.Category != "" {
+		return req.Category
+	}
+	if req.Type == domainemaildelivery.EmailTypeCampaign {
+		return domainpreference.CategoryMarketing
+	}
+	return domainpreference.CategoryProduct
+}
+
+// recordDeliveries logs accepted messages for webhook tracking. A failure
+// here must not fail a send the provider already accepted, so it is only
+// logged.
+func (s *ServiceEmail) recordDeliveries(emailType, appName, subject string, sent []mailer.SentMessage) {
+	if s.Deliveries == nil || len(sent) == 0 {
+		return
+	}
+	if err := s.Deliveries.Record(emailType, appName, subject, sent); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[EmailService][recordDeliveries]; Record error: %v", err))
+	}
+}
+
+// dedupeRecipients normalizes every list and removes duplicates across them.
+// An address keeps its most visible slot: to wins over cc, cc wins over bcc.
+func dedupeRecipients(to, cc, bcc []string) ([]string, []string, []string) {
+	seen := make(map[string]struct{}, len(to)+len(cc)+len(bcc))
+	return dedupeEmails(to, seen), dedupeEmails(cc, seen), dedupeEmails(bcc, seen)
 }
 
-func dedupeEmails(input []string) []string {
-	seen := make(map[string]struct{}, len(input))
+func dedupeEmails(input []string, seen map[string]struct{}) []string {
 	out := make([]string, 0, len(input))
 	for _, raw := range input {
 		email := strings.ToLower(strings.TrimSpace(raw))
@@ -0,0 +1,69 @@
+package serviceemail
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"service-sender/internal/dto"
+	"service-sender/pkg/mailer"
+)
+
+// flakySender accepts the first ok private copies and fails the rest.
+type flakySender struct {
+	ok       int
+	payloads []mailer.EmailPayload
+}
+
+func (f *flakySender) SendEmail(payload mailer.EmailPayload) ([]mailer.SentMessage, error) {
+	f.payloads = append(f.payloads, payload)
+	var sent []mailer.SentMessage
+	for i, to := range payload.To {
+		if i >= f.ok {
+			return sent, errors.New("relay down")
+		}
+		sent = append(sent, mailer.SentMessage{MessageID: to, Recipients: []string{to}})
+	}
+	return sent, nil
+}
+
+func TestSendReportsPartialPrivateSend(t *testing.T) {
+	sender := &flakySender{ok: 1}
+	svc := NewEmailService(sender, nil, nil, nil, nil, nil, nil)
+
+	_, _, err := svc.Send(context.Background(), dto.SendEmailRequest{
+		Type:      "info",
+		To:        []string{"a@example.test", "b@example.test", "c@example.test"},
+		Subject:   "Hi",
+		TextBody:  "hello",
+		PrivateTo: true,
+	}, "App")
+
+	var partial *PartialSendError
+	if !errors.As(err, &partial) {
+		t.Fatalf("error = %v, want *PartialSendError", err)
+	}
+	if partial.Sent != 1 || !reflect.DeepEqual(partial.Failed, []string{"b@example.test", "c@example.test"}) {
+		t.Errorf("partial = %+v", partial)
+	}
+}
+
+func TestSendRejectsPrivateToWithCopies(t *testing.T) {
+	sender := &flakySender{ok: 10}
+	svc := NewEmailService(sender, nil, nil, nil, nil, nil, nil)
+
+	_, _, err := svc.Send(context.Background(), dto.SendEmailRequest{
+		Type:      "info",
+		To:        []string{"a@example.test"},
+		Cc:        []string{"c@example.test"},
+		TextBody:  "hello",
+		PrivateTo: true,
+	}, "App")
+	if !errors.Is(err, ErrPrivateToWithCopies) {
+		t.Fatalf("error = %v, want ErrPrivateToWithCopies", err)
+	}
+	if len(sender.payloads) != 0 {
+		t.Errorf("sent %d payloads, want none", len(sender.payloads))
+	}
+}
@@ -4,41 +4,108 @@ import (
 	"bytes"
 	"fmt"
 	htmltemplate "html/template"
+	"sort"
 	"strings"
 	texttemplate "text/template"
+	"text/template/parse"
+
+	"service-sender/pkg/i18n"
+	"service-sender/pkg/mailer"
 )
 
 var ErrTemplateNotFound = fmt.Errorf("email template not found")
 var ErrSubjectRequired = fmt.Errorf("subject is required")
 
-func renderEmailContent(reqSubject, reqText, reqHTML, templateKey string, templateData map[string]interface{}, appName string) (string, string, string, error) {
+// rendererVariables are injected by renderEmailContent and always available
+// to templates regardless of template_data. Recipient is set per user by
+// campaign sends.
+var rendererVariables = map[string]struct{}{
+	"AppName":   {},
+	"Subject":   {},
+	"Brand":     {},
+	"Locale":    {},
+	"Event":     {},
+	"Recipient": {},
+}
+
+// markdownContent wraps a rendered markdown_body in the base layout.
+const markdownContent = `{{define "title"}}{{or .Subject .Brand.AppName}}{{end}}{{define "content"}}<div style="font-size: 15px; line-height: 1.6;">{{.Body}}</div>{{end}}`
+
+// renderEmailContent resolves the final subject and bodies. vars are
+// renderer-supplied variables such as the calendar Event. Explicit
+// text_body and html_body win over markdown_body, which wins over the
+// template. The HTML part has its <style> rules inlined and a missing text
+// part is derived from the HTML.
+func (s *ServiceEmail) renderEmailContent(reqSubject, reqText, reqHTML, reqMarkdown, templateKey, locale string, templateData, vars map[string]interface{}, appName string) (string, string, string, error) {
+	subject, textBody, htmlBody, err := s.renderBodies(reqSubject, reqText, reqHTML, reqMarkdown, templateKey, locale, templateData, vars, appName)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if htmlBody != "" {
+		inlined, err := mailer.InlineCSS(htmlBody)
+		if err != nil {
+			return "", "", "", fmt.Errorf("inline css: %w", err)
+		}
+		htmlBody = strings.TrimSpace(inlined)
+	}
+	if textBody == "" {
+		textBody = mailer.HTMLToText(htmlBody)
+	}
+	return subject, textBody, htmlBody, nil
+}
+
+func (s *ServiceEmail) renderBodies(reqSubject, reqText, reqHTML, reqMarkdown, templateKey, locale string, templateData, vars map[string]interface{}, appName string) (string, string, string, error) {
 	subject := strings.TrimSpace(reqSubject)
 	textBody := strings.TrimSpace(reqText)
 	htmlBody := strings.TrimSpace(reqHTML)
+	markdown := strings.TrimSpace(reqMarkdown)
 
+	locale = i18n.Normalize(locale)
 	key := strings.TrimSpace(templateKey)
 	if key == "" {
 		if subject == "" {
 			return "", "", "", ErrSubjectRequired
 		}
-		if textBody == "" && htmlBody == "" {
+		if textBody == "" && htmlBody == "" && markdown == "" {
 			return "", "", "", ErrEmailBodyRequired
 		}
+		if htmlBody == "" && markdown != "" {
+			var err error
+			htmlBody, err = s.renderMarkdown(markdown, subject, locale, appName)
+			if err != nil {
+				return "", "", "", err
+			}
+		}
 		return subject, textBody, htmlBody, nil
 	}
 
-	tpl, ok := defaultTemplates[key]
+	tpl, ok := s.findTemplate(key, locale)
 	if !ok {
 		return "", "", "", ErrTemplateNotFound
 	}
+	if locale == "" {
+		locale = i18n.EmailDefaultLocale()
+	}
+
+	if err := validateTemplateData(tpl.Schema, templateData); err != nil {
+		return "", "", "", err
+	}
 
 	data := map[string]interface{}{
 		"AppName": appName,
 		"Subject": subject,
+		"Brand":   s.Brands.Resolve(appName),
+		"Locale":  locale,
 	}
 	for k, v := range templateData {
 		data[k] = v
 	}
+	// Renderer variables such as the calendar Event describe what is actually
+	// sent, so template_data cannot replace them.
+	for k, v := range vars {
+		data[k] = v
+	}
 
 	renderedSubject, err := renderTextTemplate("email_subject", tpl.Subject, data)
 	if err != nil {
@@ -48,36 +115,152 @@ func renderEmailContent(reqSubject, reqText, reqHTML, templateKey string, templa
 	if err != nil {
 		return "", "", "", fmt.Errorf("render text: %w", err)
 	}
-	renderedHTML, err := renderHTMLTemplate("email_html", tpl.HTML, data)
+	renderedHTML, err := mailer.RenderHTML("email_html", tpl.HTML, data)
 	if err != nil {
 		return "", "", "", fmt.Errorf("render html: %w", err)
 	}
 
-	if strings.TrimSpace(textBody) != "" {
+	if markdown != "" && htmlBody == "" {
+		htmlBody, err = s.renderMarkdown(markdown, strings.TrimSpace(renderedSubject), locale, appName)
+		if err != nil {
+			return "", "", "", err
+		}
+		if textBody == "" {
+			renderedText = ""
+		}
+	}
+	if textBody != "" {
 		renderedText = textBody
 	}
-	if strings.TrimSpace(htmlBody) != "" {
+	if htmlBody != "" {
 		renderedHTML = htmlBody
 	}
 
 	return strings.TrimSpace(renderedSubject), strings.TrimSpace(renderedText), strings.TrimSpace(renderedHTML), nil
 }
 
-func renderTextTemplate(name, content string, data map[string]interface{}) (string, error) {
-	tpl, err := texttemplate.New(name).Parse(content)
+// renderMarkdown renders markdown_body inside the shared layout so it gets
+// the same brand header and footer as template emails.
+func (s *ServiceEmail) renderMarkdown(markdown, subject, locale, appName string) (string, error) {
+	if locale == "" {
+		locale = i18n.EmailDefaultLocale()
+	}
+	rendered, err := mailer.RenderHTML("email_markdown", markdownContent, map[string]interface{}{
+		"AppName": appName,
+		"Subject": subject,
+		"Brand":   s.Brands.Resolve(appName),
+		"Locale":  locale,
+		"Body":    htmltemplate.HTML(mailer.MarkdownToHTML(markdown)),
+	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("render markdown: %w", err)
 	}
+	return rendered, nil
+}
 
-	var buf bytes.Buffer
-	if err := tpl.Execute(&buf, data); err != nil {
-		return "", err
+// findTemplate looks the key up in the template store for locale, then for
+// the default locale, and falls back to the built-in defaults when the store
+// is unavailable or has no active version. Built-ins localize through the
+// catalog.
+func (s *ServiceEmail) findTemplate(key, locale string) (emailTemplate, bool) {
+	if s.Templates != nil {
+		candidates := []string{locale}
+		if locale != "" {
+			candidates = append(candidates, "")
+		}
+		for _, l := range candidates {
+			if stored, ok := s.Templates.Resolve(key, l); ok {
+				return emailTemplate{Subject: stored.Subject, Text: stored.TextBody, HTML: stored.HTMLBody, Schema: stored.Schema}, true
+			}
+		}
 	}
-	return buf.String(), nil
+
+	tpl, ok := defaultTemplates[key]
+	return tpl, ok
 }
 
-func renderHTMLTemplate(name, content string, data map[string]interface{}) (string, error) {
-	tpl, err := htmltemplate.New(name).Parse(content)
+// missingVariables lists the top-level fields referenced by the template that
+// are not present in templateData. Renderer-supplied variables are never
+// reported.
+func (s *ServiceEmail) missingVariables(key, locale string, templateData map[string]interface{}) []string {
+	tpl, ok := s.findTemplate(strings.TrimSpace(key), locale)
+	if !ok {
+		return []string{}
+	}
+
+	referenced := make(map[string]struct{})
+	for _, content := range []string{tpl.Subject, tpl.Text, tpl.HTML} {
+		parsed, err := texttemplate.New("vars").Funcs(mailer.TemplateFuncs).Parse(content)
+		if err != nil {
+			continue
+		}
+		for _, t := range parsed.Templates() {
+			if t.Tree != nil {
+				collectFields(t.Tree.Root, referenced)
+			}
+		}
+	}
+
+	warnings := make([]string, 0)
+	for name := range referenced {
+		if _, ok := rendererVariables[name]; ok {
+			continue
+		}
+		if _, ok := templateData[name]; ok {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("missing template variable: %s", name))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// collectFields walks a parse tree and records fields read from the root
+// data. Bodies of range and with blocks are skipped because dot is rebound
+// there.
+func collectFields(node parse.Node, out map[string]struct{}) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, out)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, out)
+	case *parse.IfNode:
+		collectFields(n.Pipe, out)
+		collectFields(n.List, out)
+		collectFields(n.ElseList, out)
+	case *parse.RangeNode:
+		collectFields(n.Pipe, out)
+		collectFields(n.ElseList, out)
+	case *parse.WithNode:
+		collectFields(n.Pipe, out)
+		collectFields(n.ElseList, out)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, out)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, out)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(arg, out)
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) > 0 {
+			out[n.Ident[0]] = struct{}{}
+		}
+	}
+}
+
+func renderTextTemplate(name, content string, data map[string]interface{}) (string, error) {
+	tpl, err := texttemplate.New(name).Funcs(mailer.TemplateFuncs).Parse(content)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,145 @@
+package serviceemail
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	domainemailtemplate "service-sender/internal/domain/emailtemplate"
+	"service-sender/utils"
+)
+
+var ErrTemplateDataInvalid = errors.New("template_data is invalid")
+
+// TemplateDataError carries field-level validation failures for template_data
+// in the same shape as request binding errors.
+type TemplateDataError struct {
+	Fields []utils.ValidateMessage
+}
+
+func (e *TemplateDataError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrTemplateDataInvalid.Error(), strings.Join(parts, "; "))
+}
+
+func (e *TemplateDataError) Unwrap() error {
+	return ErrTemplateDataInvalid
+}
+
+// validateTemplateData checks data against schema. A nil schema accepts
+// anything. Renderer-supplied variables are never treated as unknown in
+// strict mode.
+func validateTemplateData(schema *domainemailtemplate.VariableSchema, data map[string]interface{}) error {
+	if schema == nil {
+		return nil
+	}
+
+	var fields []utils.ValidateMessage
+	declared := make(map[string]struct{}, len(schema.Variables))
+	for _, rule := range schema.Variables {
+		declared[rule.Name] = struct{}{}
+		if msg := validateVariable(rule, data[rule.Name]); msg != "" {
+			fields = append(fields, utils.ValidateMessage{Field: "template_data." + rule.Name, Message: msg})
+		}
+	}
+
+	if schema.Strict {
+		unknown := make([]string, 0)
+		for name := range data {
+			if _, ok := declared[name]; ok {
+				continue
+			}
+			if _, ok := rendererVariables[name]; ok {
+				continue
+			}
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			fields = append(fields, utils.ValidateMessage{Field: "template_data." + name, Message: "Unknown variable"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &TemplateDataError{Fields: fields}
+	}
+	return nil
+}
+
+func validateVariable(rule domainemailtemplate.VariableRule, value interface{}) string {
+	if isEmptyValue(value) {
+		if rule.Required {
+			return "This field is required"
+		}
+		return ""
+	}
+
+	switch rule.Type {
+	case domainemailtemplate.VariableTypeNumber:
+		var digits string
+		switch n := value.(type) {
+		case float64:
+			digits = strconv.FormatFloat(n, 'f', -1, 64)
+		case float32:
+			digits = strconv.FormatFloat(float64(n), 'f', -1, 32)
+		case int:
+			digits = strconv.Itoa(n)
+		case int32:
+			digits = strconv.FormatInt(int64(n), 10)
+		case int64:
+			digits = strconv.FormatInt(n, 10)
+		default:
+			return "Should be a number"
+		}
+		// A number's length is that of the text the template prints.
+		if rule.MaxLength > 0 && len(digits) > rule.MaxLength {
+			return fmt.Sprintf("Maximum %d", rule.MaxLength)
+		}
+		return ""
+	case domainemailtemplate.VariableTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "Should be a boolean"
+		}
+		return ""
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "Should be a string"
+	}
+	if rule.MaxLength > 0 && utf8.RuneCountInString(str) > rule.MaxLength {
+		return fmt.Sprintf("Maximum %d", rule.MaxLength)
+	}
+
+	switch rule.Type {
+	case domainemailtemplate.VariableTypeURL:
+		u, err := url.ParseRequestURI(str)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "Invalid URL"
+		}
+	case domainemailtemplate.VariableTypeEmail:
+		addr, err := mail.ParseAddress(str)
+		if err != nil || addr.Address != str {
+			return "Invalid email"
+		}
+	}
+	return ""
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if str, ok := value.(string); ok {
+		return strings.TrimSpace(str) == ""
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package serviceemail
+
+import (
+	"errors"
+	"testing"
+
+	domainemailtemplate "service-sender/internal/domain/emailtemplate"
+)
+
+func TestValidateTemplateDataCampaignDefault(t *testing.T) {
+	schema := defaultTemplates["campaign_default"].Schema
+
+	err := validateTemplateData(schema, map[string]interface{}{"Message": "Hello"})
+	var dataErr *TemplateDataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("error = %v, want *TemplateDataError", err)
+	}
+	got := map[string]string{}
+	for _, f := range dataErr.Fields {
+		got[f.Field] = f.Message
+	}
+	for _, field := range []string{"template_data.Headline", "template_data.CTAURL"} {
+		if got[field] != "This field is required" {
+			t.Errorf("%s: %q", field, got[field])
+		}
+	}
+
+	err = validateTemplateData(schema, map[string]interface{}{"Headline": "Spring sale", "CTAURL": "https://shop.example.test/sale"})
+	if err != nil {
+		t.Errorf("complete data rejected: %v", err)
+	}
+}
+
+func TestValidateVariableNumberMaxLength(t *testing.T) {
+	rule := domainemailtemplate.VariableRule{Name: "Amount", Type: domainemailtemplate.VariableTypeNumber, MaxLength: 4}
+
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{float64(1234), ""},
+		{float64(12.5), ""},
+		{float64(12345), "Maximum 4"},
+		{float64(-1.25), "Maximum 4"},
+		{int64(99999), "Maximum 4"},
+		{"12", "Should be a number"},
+	}
+	for _, tt := range tests {
+		if got := validateVariable(rule, tt.value); got != tt.want {
+			t.Errorf("validateVariable(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
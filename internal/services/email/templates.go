@@ -1,127 +1,125 @@
 package serviceemail
 
+import domainemailtemplate "service-sender/internal/domain/emailtemplate"
+
 type emailTemplate struct {
 	Subject string
 	Text    string
 	HTML    string
+	Schema  *domainemailtemplate.VariableSchema
 }
 
 var defaultTemplates = map[string]emailTemplate{
 	"campaign_default": {
-		Subject: "{{if .Subject}}{{.Subject}}{{else}}Campaign Update{{end}}",
-		Text: `Halo,
+		Subject: `{{if .Subject}}{{.Subject}}{{else}}{{t .Locale "campaign.fallback_subject"}}{{end}}`,
+		Text: `{{t .Locale "common.greeting"}}
 
-{{if .Headline}}{{.Headline}}{{else}}Campaign Update{{end}}
+{{.Headline}}
 
-{{if .Message}}{{.Message}}{{else}}Kami punya update terbaru untuk Anda.{{end}}
-{{if .CTAURL}}
-Lihat selengkapnya: {{.CTAURL}}
-{{end}}
-Terima kasih,
-{{.AppName}} Team`,
-		HTML: `<!DOCTYPE html>
-<html lang="id">
-<body style="margin:0;padding:24px;background:#f3f6fb;font-family:Segoe UI,Tahoma,Arial,sans-serif;color:#1f2937;">
-  <table role="presentation" width="100%" cellspacing="0" cellpadding="0" style="max-width:640px;margin:0 auto;background:#ffffff;border-radius:14px;overflow:hidden;border:1px solid #e5e7eb;">
-    <tr>
-      <td style="background:linear-gradient(120deg,#0f172a,#1d4ed8);padding:28px 24px;">
-        <p style="margin:0;color:#bfdbfe;font-size:12px;letter-spacing:1px;text-transform:uppercase;">Campaign</p>
-        <h1 style="margin:8px 0 0 0;color:#ffffff;font-size:24px;line-height:1.3;">{{if .Headline}}{{.Headline}}{{else}}Campaign Update{{end}}</h1>
-      </td>
-    </tr>
-    <tr>
-      <td style="padding:24px;">
-        <p style="margin:0 0 16px 0;color:#475569;font-size:15px;line-height:1.7;">{{if .Message}}{{.Message}}{{else}}Kami punya update terbaru untuk Anda.{{end}}</p>
-        {{if .CTAURL}}
-        <p style="margin:0 0 20px 0;">
-          <a href="{{.CTAURL}}" style="display:inline-block;padding:12px 18px;background:#2563eb;color:#ffffff;text-decoration:none;border-radius:10px;font-weight:600;">{{if .CTALabel}}{{.CTALabel}}{{else}}Lihat Detail{{end}}</a>
-        </p>
-        <p style="margin:0;color:#64748b;font-size:12px;line-height:1.6;">Jika tombol tidak berfungsi, buka link ini: <a href="{{.CTAURL}}" style="color:#2563eb;word-break:break-all;">{{.CTAURL}}</a></p>
-        {{end}}
-      </td>
-    </tr>
-    <tr>
-      <td style="padding:16px 24px;background:#f8fafc;border-top:1px solid #e5e7eb;">
-        <p style="margin:0;color:#94a3b8;font-size:12px;">Dikirim oleh {{.AppName}}</p>
-      </td>
-    </tr>
-  </table>
-</body>
-</html>`,
+{{if .Message}}{{.Message}}{{else}}{{t .Locale "campaign.fallback_message"}}{{end}}
+
+{{t .Locale "campaign.read_more"}} {{.CTAURL}}
+
+{{t .Locale "common.thanks"}}
+{{t .Locale "common.team" .AppName}}`,
+		HTML: `{{define "content"}}<p style="margin:0;color:#64748b;font-size:12px;letter-spacing:1px;text-transform:uppercase;">{{t .Locale "campaign.label"}}</p>
+<h1 style="margin:8px 0 16px 0;color:#0f172a;font-size:24px;line-height:1.3;">{{.Headline}}</h1>
+<p style="margin:0 0 16px 0;color:#475569;font-size:15px;line-height:1.7;">{{if .Message}}{{.Message}}{{else}}{{t .Locale "campaign.fallback_message"}}{{end}}</p>
+{{template "button" (dict "URL" .CTAURL "Label" (or .CTALabel (t .Locale "common.view_detail")) "Color" .Brand.PrimaryColor)}}
+<p style="margin:0;color:#64748b;font-size:12px;line-height:1.6;">{{t .Locale "campaign.link_hint"}} <a href="{{.CTAURL}}" style="color:{{.Brand.PrimaryColor}};word-break:break-all;">{{.CTAURL}}</a></p>
+{{end}}`,
+		Schema: &domainemailtemplate.VariableSchema{
+			Variables: []domainemailtemplate.VariableRule{
+				{Name: "Headline", Type: domainemailtemplate.VariableTypeString, Required: true, MaxLength: 200},
+				{Name: "Message", Type: domainemailtemplate.VariableTypeString, MaxLength: 5000},
+				{Name: "CTAURL", Type: domainemailtemplate.VariableTypeURL, Required: true, MaxLength: 2048},
+				{Name: "CTALabel", Type: domainemailtemplate.VariableTypeString, MaxLength: 50},
+			},
+		},
 	},
 	"info_default": {
-		Subject: "{{if .Subject}}{{.Subject}}{{else}}Informasi Penting{{end}}",
-		Text: `Halo,
+		Subject: `{{if .Subject}}{{.Subject}}{{else}}{{t .Locale "info.fallback_subject"}}{{end}}`,
+		Text: `{{t .Locale "common.greeting"}}
 
-{{if .Title}}{{.Title}}{{else}}Informasi Penting{{end}}
+{{if .Title}}{{.Title}}{{else}}{{t .Locale "info.fallback_title"}}{{end}}
 
-{{if .Message}}{{.Message}}{{else}}Ada informasi penting untuk Anda.{{end}}
-{{if .Reference}}Referensi: {{.Reference}}{{end}}
+{{if .Message}}{{.Message}}{{else}}{{t .Locale "info.fallback_message"}}{{end}}
+{{if .Reference}}{{t .Locale "info.reference"}}: {{.Reference}}{{end}}
 
-Salam,
-{{.AppName}} Team`,
-		HTML: `<!DOCTYPE html>
-<html lang="id">
-<body style="margin:0;padding:24px;background:#f8fafc;font-family:Segoe UI,Tahoma,Arial,sans-serif;color:#1e293b;">
-  <table role="presentation" width="100%" cellspacing="0" cellpadding="0" style="max-width:640px;margin:0 auto;background:#ffffff;border-radius:14px;overflow:hidden;border:1px solid #e2e8f0;">
-    <tr>
-      <td style="padding:22px 24px;background:#0f766e;">
-        <p style="margin:0;color:#ccfbf1;font-size:12px;letter-spacing:1px;text-transform:uppercase;">Informasi</p>
-        <h2 style="margin:8px 0 0 0;color:#ffffff;font-size:22px;">{{if .Title}}{{.Title}}{{else}}Informasi Penting{{end}}</h2>
-      </td>
-    </tr>
-    <tr>
-      <td style="padding:24px;">
-        <div style="background:#f8fafc;border-left:4px solid #14b8a6;border-radius:8px;padding:14px 16px;margin-bottom:16px;">
-          <p style="margin:0;color:#334155;font-size:15px;line-height:1.7;">{{if .Message}}{{.Message}}{{else}}Ada informasi penting untuk Anda.{{end}}</p>
-        </div>
-        {{if .Reference}}
-        <p style="margin:0;color:#64748b;font-size:13px;">Referensi: <strong style="color:#334155;">{{.Reference}}</strong></p>
-        {{end}}
-      </td>
-    </tr>
-    <tr>
-      <td style="padding:16px 24px;background:#f8fafc;border-top:1px solid #e2e8f0;">
-        <p style="margin:0;color:#94a3b8;font-size:12px;">{{.AppName}}</p>
-      </td>
-    </tr>
-  </table>
-</body>
-</html>`,
+{{t .Locale "common.regards"}}
+{{t .Locale "common.team" .AppName}}`,
+		HTML: `{{define "content"}}<p style="margin:0;color:#64748b;font-size:12px;letter-spacing:1px;text-transform:uppercase;">{{t .Locale "info.label"}}</p>
+<h2 style="margin:8px 0 16px 0;color:#0f172a;font-size:22px;">{{if .Title}}{{.Title}}{{else}}{{t .Locale "info.fallback_title"}}{{end}}</h2>
+{{template "notice" (dict "Tone" "info" "Text" (or .Message (t .Locale "info.fallback_message")))}}
+{{if .Reference}}
+<p style="margin:0;color:#64748b;font-size:13px;">{{t .Locale "info.reference"}}: <strong style="color:#334155;">{{.Reference}}</strong></p>
+{{end}}{{end}}`,
+		Schema: &domainemailtemplate.VariableSchema{
+			Variables: []domainemailtemplate.VariableRule{
+				{Name: "Title", Type: domainemailtemplate.VariableTypeString, MaxLength: 200},
+				{Name: "Message", Type: domainemailtemplate.VariableTypeString, MaxLength: 5000},
+				{Name: "Reference", Type: domainemailtemplate.VariableTypeString, MaxLength: 100},
+			},
+		},
 	},
 	"notification_default": {
-		Subject: "{{if .Subject}}{{.Subject}}{{else}}Notifikasi{{end}}",
-		Text: `Halo,
+		Subject: `{{if .Subject}}{{.Subject}}{{else}}{{t .Locale "notification.fallback_subject"}}{{end}}`,
+		Text: `{{t .Locale "common.greeting"}}
 
-{{if .Message}}{{.Message}}{{else}}Anda menerima notifikasi baru.{{end}}
+{{if .Message}}{{.Message}}{{else}}{{t .Locale "notification.fallback_message"}}{{end}}
 {{if .ActionURL}}
-Tindak lanjuti di: {{.ActionURL}}
+{{t .Locale "notification.follow_up"}} {{.ActionURL}}
 {{end}}
-Waktu: {{if .Timestamp}}{{.Timestamp}}{{else}}Sekarang{{end}}
+{{t .Locale "notification.time"}}: {{if .Timestamp}}{{.Timestamp}}{{else}}{{t .Locale "notification.now"}}{{end}}
 
-Salam,
+{{t .Locale "common.regards"}}
 {{.AppName}}`,
-		HTML: `<!DOCTYPE html>
-<html lang="id">
-<body style="margin:0;padding:24px;background:#f1f5f9;font-family:Segoe UI,Tahoma,Arial,sans-serif;color:#0f172a;">
-  <table role="presentation" width="100%" cellspacing="0" cellpadding="0" style="max-width:620px;margin:0 auto;background:#ffffff;border-radius:12px;overflow:hidden;border:1px solid #e2e8f0;">
-    <tr>
-      <td style="padding:20px 24px;background:#1e293b;">
-        <p style="margin:0;color:#cbd5e1;font-size:12px;letter-spacing:1px;text-transform:uppercase;">Notification</p>
-        <h3 style="margin:8px 0 0 0;color:#ffffff;font-size:20px;">{{if .Title}}{{.Title}}{{else}}Notifikasi Baru{{end}}</h3>
-      </td>
-    </tr>
-    <tr>
-      <td style="padding:24px;">
-        <p style="margin:0 0 14px 0;color:#334155;font-size:15px;line-height:1.7;">{{if .Message}}{{.Message}}{{else}}Anda menerima notifikasi baru.{{end}}</p>
-        <p style="margin:0 0 18px 0;color:#64748b;font-size:12px;">Waktu: <strong style="color:#334155;">{{if .Timestamp}}{{.Timestamp}}{{else}}Sekarang{{end}}</strong></p>
-        {{if .ActionURL}}
-        <a href="{{.ActionURL}}" style="display:inline-block;padding:10px 16px;background:#0ea5e9;color:#ffffff;text-decoration:none;border-radius:8px;font-weight:600;">{{if .ActionLabel}}{{.ActionLabel}}{{else}}Lihat Detail{{end}}</a>
-        {{end}}
-      </td>
-    </tr>
-  </table>
-</body>
-</html>`,
+		HTML: `{{define "content"}}<p style="margin:0;color:#64748b;font-size:12px;letter-spacing:1px;text-transform:uppercase;">{{t .Locale "notification.label"}}</p>
+<h3 style="margin:8px 0 16px 0;color:#0f172a;font-size:20px;">{{if .Title}}{{.Title}}{{else}}{{t .Locale "notification.fallback_title"}}{{end}}</h3>
+<p style="margin:0 0 14px 0;color:#334155;font-size:15px;line-height:1.7;">{{if .Message}}{{.Message}}{{else}}{{t .Locale "notification.fallback_message"}}{{end}}</p>
+<p style="margin:0 0 18px 0;color:#64748b;font-size:12px;">{{t .Locale "notification.time"}}: <strong style="color:#334155;">{{if .Timestamp}}{{.Timestamp}}{{else}}{{t .Locale "notification.now"}}{{end}}</strong></p>
+{{if .ActionURL}}
+{{template "button" (dict "URL" .ActionURL "Label" (or .ActionLabel (t .Locale "common.view_detail")) "Color" .Brand.PrimaryColor)}}
+{{end}}{{end}}`,
+		Schema: &domainemailtemplate.VariableSchema{
+			Variables: []domainemailtemplate.VariableRule{
+				{Name: "Title", Type: domainemailtemplate.VariableTypeString, MaxLength: 200},
+				{Name: "Message", Type: domainemailtemplate.VariableTypeString, MaxLength: 5000},
+				{Name: "ActionURL", Type: domainemailtemplate.VariableTypeURL, MaxLength: 2048},
+				{Name: "ActionLabel", Type: domainemailtemplate.VariableTypeString, MaxLength: 50},
+				{Name: "Timestamp", Type: domainemailtemplate.VariableTypeString, MaxLength: 100},
+			},
+		},
+	},
+	"calendar_default": {
+		Subject: `{{if .Subject}}{{.Subject}}{{else}}{{with .Event}}{{if .Cancelled}}{{t $.Locale "calendar.cancel_subject" .Summary}}{{else}}{{t $.Locale "calendar.invite_subject" .Summary}}{{end}}{{end}}{{end}}`,
+		Text: `{{t .Locale "common.greeting"}}
+
+{{with .Event}}{{if .Cancelled}}{{t $.Locale "calendar.cancel_intro"}}{{else}}{{t $.Locale "calendar.invite_intro"}}{{end}}
+
+{{.Summary}}
+{{t $.Locale "calendar.when"}}: {{.Start}} - {{.End}} ({{.Timezone}})
+{{if .Location}}{{t $.Locale "calendar.where"}}: {{.Location}}
+{{end}}{{if .Organizer}}{{t $.Locale "calendar.organizer"}}: {{.Organizer}}
+{{end}}{{if .URL}}{{t $.Locale "calendar.details"}}: {{.URL}}
+{{end}}{{if .Description}}
+{{.Description}}
+{{end}}
+{{if not .Cancelled}}{{t $.Locale "calendar.attachment_hint"}}
+{{end}}{{end}}
+{{t .Locale "common.regards"}}
+{{t .Locale "common.team" .AppName}}`,
+		HTML: `{{define "content"}}{{with .Event}}<p style="margin:0;color:#64748b;font-size:12px;letter-spacing:1px;text-transform:uppercase;">{{t $.Locale "calendar.label"}}</p>
+<h2 style="margin:8px 0 12px 0;color:#0f172a;font-size:22px;{{if .Cancelled}}text-decoration:line-through;{{end}}">{{.Summary}}</h2>
+{{if .Cancelled}}{{template "notice" (dict "Tone" "warning" "Text" (t $.Locale "calendar.cancel_intro"))}}{{else}}<p style="margin:0 0 16px 0;color:#475569;font-size:15px;line-height:1.7;">{{t $.Locale "calendar.invite_intro"}}</p>{{end}}
+<table style="width:100%;border-collapse:collapse;font-size:14px;color:#334155;margin-bottom:16px;">
+<tr><td style="padding:6px 12px 6px 0;color:#64748b;white-space:nowrap;vertical-align:top;">{{t $.Locale "calendar.when"}}</td><td style="padding:6px 0;">{{.Start}} - {{.End}}<br><span style="color:#64748b;font-size:12px;">{{.Timezone}}</span></td></tr>
+{{if .Location}}<tr><td style="padding:6px 12px 6px 0;color:#64748b;white-space:nowrap;vertical-align:top;">{{t $.Locale "calendar.where"}}</td><td style="padding:6px 0;">{{.Location}}</td></tr>{{end}}
+{{if .Organizer}}<tr><td style="padding:6px 12px 6px 0;color:#64748b;white-space:nowrap;vertical-align:top;">{{t $.Locale "calendar.organizer"}}</td><td style="padding:6px 0;">{{.Organizer}}</td></tr>{{end}}
+</table>
+{{if .Description}}<p style="margin:0 0 16px 0;color:#475569;font-size:14px;line-height:1.7;white-space:pre-line;">{{.Description}}</p>{{end}}
+{{if .URL}}{{template "button" (dict "URL" .URL "Label" (t $.Locale "calendar.details") "Color" $.Brand.PrimaryColor)}}{{end}}
+{{if not .Cancelled}}<p style="margin:0;color:#64748b;font-size:12px;line-height:1.6;">{{t $.Locale "calendar.attachment_hint"}}</p>{{end}}
+{{end}}{{end}}`,
 	},
 }
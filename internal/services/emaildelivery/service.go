@@ -0,0 +1,210 @@
+package serviceemaildelivery
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domainemaildelivery "service-sender/internal/domain/emaildelivery"
+	"service-sender/internal/dto"
+	interfaceemaildelivery "service-sender/internal/interfaces/emaildelivery"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/mailer"
+	"service-sender/utils"
+)
+
+// eventStatus maps normalized provider events onto delivery statuses.
+var eventStatus = map[string]string{
+	mailer.EventSent:         domainemaildelivery.StatusSent,
+	mailer.EventDeferred:     domainemaildelivery.StatusDeferred,
+	mailer.EventSoftBounce:   domainemaildelivery.StatusSoftBounced,
+	mailer.EventDelivered:    domainemaildelivery.StatusDelivered,
+	mailer.EventOpened:       domainemaildelivery.StatusOpened,
+	mailer.EventClicked:      domainemaildelivery.StatusClicked,
+	mailer.EventUnsubscribed: domainemaildelivery.StatusUnsubscribed,
+	mailer.EventHardBounce:   domainemaildelivery.StatusBounced,
+	mailer.EventBlocked:      domainemaildelivery.StatusBlocked,
+	mailer.EventFailed:       domainemaildelivery.StatusFailed,
+	mailer.EventComplaint:    domainemaildelivery.StatusComplained,
+}
+
+type ServiceEmailDelivery struct {
+	Deliveries   interfaceemaildelivery.RepoEmailDeliveryInterface
+	Suppressions interfaceemaildelivery.RepoSuppressionInterface
+}
+
+func NewEmailDeliveryService(deliveries interfaceemaildelivery.RepoEmailDeliveryInterface, suppressions interfaceemaildelivery.RepoSuppressionInterface) *ServiceEmailDelivery {
+	return &ServiceEmailDelivery{
+		Deliveries:   deliveries,
+		Suppressions: suppressions,
+	}
+}
+
+// Record logs one "sent" delivery per recipient of every accepted message.
+func (s *ServiceEmailDelivery) Record(emailType, appName, subject string, sent []mailer.SentMessage) error {
+	now := time.Now()
+	var rows []domainemaildelivery.EmailDelivery
+	for _, msg := range sent {
+		for _, recipient := range msg.Recipients {
+			rows = append(rows, domainemaildelivery.EmailDelivery{
+				Id:          utils.CreateUUID(),
+				MessageId:   mailer.NormalizeMessageID(msg.MessageID),
+				EmailType:   emailType,
+				AppName:     appName,
+				Recipient:   strings.ToLower(strings.TrimSpace(recipient)),
+				Subject:     truncate(subject, 255),
+				Status:      domainemaildelivery.StatusSent,
+				LastEventAt: &now,
+				CreatedAt:   now,
+			})
+		}
+	}
+	return s.Deliveries.Store(rows)
+}
+
+// FilterSuppressed returns the subset of emails that must not receive an
+// email of emailType. Unsubscribed addresses are only filtered for campaigns.
+func (s *ServiceEmailDelivery) FilterSuppressed(emails []string, emailType string) ([]string, error) {
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(email)))
+	}
+	return s.Suppressions.GetSuppressed(normalized, emailType == domainemaildelivery.EmailTypeCampaign)
+}
+
+// HandleEvents applies provider events to the delivery log and suppresses
+// hard-bounced and complaining addresses. Events for unknown messages still
+// suppress, since the address is bad regardless of who sent to it.
+// Unsubscribes are recorded too, but never replace a stronger suppression.
+func (s *ServiceEmailDelivery) HandleEvents(events []mailer.DeliveryEvent) (dto.WebhookResult, error) {
+	result := dto.WebhookResult{Received: len(events)}
+	for _, event := range events {
+		updated, err := s.applyEvent(event)
+		if err != nil {
+			return result, err
+		}
+		if updated {
+			result.Updated++
+		}
+
+		if event.Suppresses() && event.Email != "" {
+			reason := domainemaildelivery.SuppressionHardBounce
+			if event.Kind == mailer.EventComplaint {
+				reason = domainemaildelivery.SuppressionComplaint
+			}
+			now := time.Now()
+			if err := s.Suppressions.Upsert(domainemaildelivery.Suppression{
+				Id:        utils.CreateUUID(),
+				Email:     event.Email,
+				Reason:    reason,
+				Source:    "webhook:" + event.Provider,
+				Detail:    event.Reason,
+				MessageId: event.MessageID,
+				CreatedAt: now,
+				UpdatedAt: &now,
+			}); err != nil {
+				return result, err
+			}
+			result.Suppressed++
+		} else if event.Kind == mailer.EventUnsubscribed && event.Email != "" {
+			now := time.Now()
+			if err := s.Suppressions.Insert(domainemaildelivery.Suppression{
+				Id:        utils.CreateUUID(),
+				Email:     event.Email,
+				Reason:    domainemaildelivery.SuppressionUnsubscribe,
+				Source:    "webhook:" + event.Provider,
+				MessageId: event.MessageID,
+				CreatedAt: now,
+				UpdatedAt: &now,
+			}); err != nil {
+				return result, err
+			}
+			result.Suppressed++
+		}
+	}
+	return result, nil
+}
+
+func (s *ServiceEmailDelivery) applyEvent(event mailer.DeliveryEvent) (bool, error) {
+	status, ok := eventStatus[event.Kind]
+	if !ok || event.MessageID == "" {
+		return false, nil
+	}
+
+	deliveries, err := s.Deliveries.GetByMessageId(event.MessageID)
+	if err != nil {
+		return false, err
+	}
+
+	detail := event.RawEvent
+	if event.Reason != "" {
+		detail += ": " + event.Reason
+	}
+
+	updated := false
+	for _, d := range deliveries {
+		if event.Email != "" && d.Recipient != event.Email {
+			continue
+		}
+		if !domainemaildelivery.CanTransition(d.Status, status) {
+			continue
+		}
+		if err := s.Deliveries.UpdateStatus(d.Id, status, truncate(detail, 1000), event.OccurredAt); err != nil {
+			return updated, err
+		}
+		updated = true
+	}
+	return updated, nil
+}
+
+func (s *ServiceEmailDelivery) GetDeliveries(params filter.BaseParams) ([]domainemaildelivery.EmailDelivery, int64, error) {
+	return s.Deliveries.GetAll(params)
+}
+
+func (s *ServiceEmailDelivery) GetSuppressions(params filter.BaseParams) ([]domainemaildelivery.Suppression, int64, error) {
+	return s.Suppressions.GetAll(params)
+}
+
+func (s *ServiceEmailDelivery) CreateSuppression(req dto.SuppressionCreate, actorId string) (domainemaildelivery.Suppression, error) {
+	now := time.Now()
+	reason := domainemaildelivery.SuppressionManual
+	if req.Reason == domainemaildelivery.SuppressionUnsubscribe {
+		reason = domainemaildelivery.SuppressionUnsubscribe
+	}
+	m := domainemaildelivery.Suppression{
+		Id:        utils.CreateUUID(),
+		Email:     strings.ToLower(strings.TrimSpace(req.Email)),
+		Reason:    reason,
+		Source:    "api",
+		Detail:    strings.TrimSpace(req.Detail),
+		CreatedAt: now,
+		UpdatedAt: &now,
+	}
+	if actorId != "" {
+		m.CreatedBy = &actorId
+	}
+	store := s.Suppressions.Upsert
+	if reason == domainemaildelivery.SuppressionUnsubscribe {
+		store = s.Suppressions.Insert
+	}
+	if err := store(m); err != nil {
+		return domainemaildelivery.Suppression{}, err
+	}
+	return m, nil
+}
+
+func (s *ServiceEmailDelivery) DeleteSuppression(email string) error {
+	return s.Suppressions.Delete(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+var _ interfaceemaildelivery.ServiceEmailDeliveryInterface = (*ServiceEmailDelivery)(nil)
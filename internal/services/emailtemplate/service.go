@@ -0,0 +1,308 @@
+package serviceemailtemplate
+
+import (
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	domainemailtemplate "service-sender/internal/domain/emailtemplate"
+	"service-sender/internal/dto"
+	interfaceemailtemplate "service-sender/internal/interfaces/emailtemplate"
+	"service-sender/pkg/config"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/mailer"
+	"service-sender/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTemplateExists        = errors.New("email template already exists")
+	ErrTemplateInvalid       = errors.New("email template is invalid")
+	ErrTemplateBodyMissing   = errors.New("either text_body or html_body must be provided")
+	ErrTemplateSchemaInvalid = errors.New("variable_schema is invalid")
+)
+
+// variableNamePattern matches identifiers usable as {{.Name}} in templates.
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type cacheEntry struct {
+	template  domainemailtemplate.EmailTemplate
+	found     bool
+	expiresAt time.Time
+}
+
+type ServiceEmailTemplate struct {
+	Repo   interfaceemailtemplate.RepoEmailTemplateInterface
+	Config config.EmailTemplateConfig
+
+	mu    sync.RWMutex
+	cache map[string]cacheEntry
+}
+
+func NewEmailTemplateService(repo interfaceemailtemplate.RepoEmailTemplateInterface, cfg config.EmailTemplateConfig) *ServiceEmailTemplate {
+	return &ServiceEmailTemplate{
+		Repo:   repo,
+		Config: cfg,
+		cache:  make(map[string]cacheEntry),
+	}
+}
+
+func (s *ServiceEmailTemplate) Create(req dto.EmailTemplateCreate, actorId string) (domainemailtemplate.EmailTemplate, error) {
+	key := normalizeKey(req.Key)
+	locale := s.normalizeLocale(req.Locale)
+
+	latest, err := s.Repo.GetLatestVersion(key, locale)
+	if err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	if _, err := s.Repo.GetActive(key, locale); err == nil {
+		return domainemailtemplate.EmailTemplate{}, ErrTemplateExists
+	}
+
+	data := domainemailtemplate.EmailTemplate{
+		Id:        utils.CreateUUID(),
+		Key:       key,
+		Locale:    locale,
+		Subject:   strings.TrimSpace(req.Subject),
+		TextBody:  req.TextBody,
+		HTMLBody:  req.HTMLBody,
+		Version:   latest + 1,
+		Schema:    toVariableSchema(req.VariableSchema),
+		CreatedBy: optionalString(actorId),
+		CreatedAt: time.Now(),
+	}
+	if err := validateTemplate(data); err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+
+	if err := s.Repo.StoreVersion(data); err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	s.invalidate(key, locale)
+
+	data.IsActive = true
+	return data, nil
+}
+
+func (s *ServiceEmailTemplate) GetActive(key, locale string) (domainemailtemplate.EmailTemplate, error) {
+	return s.Repo.GetActive(normalizeKey(key), s.normalizeLocale(locale))
+}
+
+func (s *ServiceEmailTemplate) GetAll(params filter.BaseParams) ([]domainemailtemplate.EmailTemplate, int64, error) {
+	return s.Repo.GetAll(params)
+}
+
+func (s *ServiceEmailTemplate) GetVersions(key, locale string) ([]domainemailtemplate.EmailTemplate, error) {
+	versions, err := s.Repo.GetVersions(normalizeKey(key), s.normalizeLocale(locale))
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return versions, nil
+}
+
+// Update stores the merged content as a new active version; earlier versions
+// are kept for rollback.
+func (s *ServiceEmailTemplate) Update(key string, req dto.EmailTemplateUpdate, actorId string) (domainemailtemplate.EmailTemplate, error) {
+	key = normalizeKey(key)
+	locale := s.normalizeLocale(req.Locale)
+
+	current, err := s.Repo.GetActive(key, locale)
+	if err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+
+	latest, err := s.Repo.GetLatestVersion(key, locale)
+	if err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+
+	data := domainemailtemplate.EmailTemplate{
+		Id:        utils.CreateUUID(),
+		Key:       key,
+		Locale:    locale,
+		Subject:   current.Subject,
+		TextBody:  current.TextBody,
+		HTMLBody:  current.HTMLBody,
+		Version:   latest + 1,
+		Schema:    current.Schema,
+		CreatedBy: optionalString(actorId),
+		CreatedAt: time.Now(),
+	}
+	if v := strings.TrimSpace(req.Subject); v != "" {
+		data.Subject = v
+	}
+	if req.TextBody != "" {
+		data.TextBody = req.TextBody
+	}
+	if req.HTMLBody != "" {
+		data.HTMLBody = req.HTMLBody
+	}
+	if req.VariableSchema != nil {
+		data.Schema = toVariableSchema(req.VariableSchema)
+	}
+	if err := validateTemplate(data); err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+
+	if err := s.Repo.StoreVersion(data); err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	s.invalidate(key, locale)
+
+	data.IsActive = true
+	return data, nil
+}
+
+func (s *ServiceEmailTemplate) Rollback(key string, req dto.EmailTemplateRollback) (domainemailtemplate.EmailTemplate, error) {
+	key = normalizeKey(key)
+	locale := s.normalizeLocale(req.Locale)
+
+	if _, err := s.Repo.GetVersion(key, locale, req.Version); err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	if err := s.Repo.ActivateVersion(key, locale, req.Version); err != nil {
+		return domainemailtemplate.EmailTemplate{}, err
+	}
+	s.invalidate(key, locale)
+
+	return s.Repo.GetActive(key, locale)
+}
+
+func (s *ServiceEmailTemplate) Delete(key, locale string) error {
+	key = normalizeKey(key)
+	locale = s.normalizeLocale(locale)
+
+	if err := s.Repo.Delete(key, locale); err != nil {
+		return err
+	}
+	s.invalidate(key, locale)
+	return nil
+}
+
+// Resolve returns the active template for rendering. Lookups, including
+// misses, are cached for Config.CacheTTL so sends do not hit the database on
+// every request; errors are not cached.
+func (s *ServiceEmailTemplate) Resolve(key, locale string) (domainemailtemplate.EmailTemplate, bool) {
+	if s == nil || s.Repo == nil {
+		return domainemailtemplate.EmailTemplate{}, false
+	}
+
+	key = normalizeKey(key)
+	locale = s.normalizeLocale(locale)
+	cacheKey := key + ":" + locale
+
+	s.mu.RLock()
+	entry, ok := s.cache[cacheKey]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.template, entry.found
+	}
+
+	tpl, err := s.Repo.GetActive(key, locale)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[EmailTemplateService][Resolve]; Repo.GetActive %s error: %v", cacheKey, err))
+		return domainemailtemplate.EmailTemplate{}, false
+	}
+
+	entry = cacheEntry{template: tpl, found: err == nil, expiresAt: time.Now().Add(s.Config.CacheTTL)}
+	if s.Config.CacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[cacheKey] = entry
+		s.mu.Unlock()
+	}
+	return entry.template, entry.found
+}
+
+func (s *ServiceEmailTemplate) invalidate(key, locale string) {
+	s.mu.Lock()
+	delete(s.cache, key+":"+locale)
+	s.mu.Unlock()
+}
+
+func (s *ServiceEmailTemplate) normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" {
+		return s.Config.DefaultLocale
+	}
+	return locale
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func optionalString(v string) *string {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	return &v
+}
+
+func validateTemplate(tpl domainemailtemplate.EmailTemplate) error {
+	if strings.TrimSpace(tpl.TextBody) == "" && strings.TrimSpace(tpl.HTMLBody) == "" {
+		return ErrTemplateBodyMissing
+	}
+	if _, err := texttemplate.New("subject").Funcs(mailer.TemplateFuncs).Parse(tpl.Subject); err != nil {
+		return fmt.Errorf("%w: subject: %v", ErrTemplateInvalid, err)
+	}
+	if _, err := texttemplate.New("text").Funcs(mailer.TemplateFuncs).Parse(tpl.TextBody); err != nil {
+		return fmt.Errorf("%w: text_body: %v", ErrTemplateInvalid, err)
+	}
+	if _, err := htmltemplate.New("html").Funcs(mailer.TemplateFuncs).Parse(tpl.HTMLBody); err != nil {
+		return fmt.Errorf("%w: html_body: %v", ErrTemplateInvalid, err)
+	}
+	return validateSchema(tpl.Schema)
+}
+
+func validateSchema(schema *domainemailtemplate.VariableSchema) error {
+	if schema == nil {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(schema.Variables))
+	for _, rule := range schema.Variables {
+		if !variableNamePattern.MatchString(rule.Name) {
+			return fmt.Errorf("%w: invalid variable name %q", ErrTemplateSchemaInvalid, rule.Name)
+		}
+		if _, ok := seen[rule.Name]; ok {
+			return fmt.Errorf("%w: duplicate variable %q", ErrTemplateSchemaInvalid, rule.Name)
+		}
+		if !domainemailtemplate.IsValidVariableType(rule.Type) {
+			return fmt.Errorf("%w: unknown type %q for %s", ErrTemplateSchemaInvalid, rule.Type, rule.Name)
+		}
+		seen[rule.Name] = struct{}{}
+	}
+	return nil
+}
+
+func toVariableSchema(req *dto.EmailTemplateVariableSchema) *domainemailtemplate.VariableSchema {
+	if req == nil {
+		return nil
+	}
+
+	schema := &domainemailtemplate.VariableSchema{
+		Strict:    req.Strict,
+		Variables: make([]domainemailtemplate.VariableRule, 0, len(req.Variables)),
+	}
+	for _, v := range req.Variables {
+		schema.Variables = append(schema.Variables, domainemailtemplate.VariableRule{
+			Name:      strings.TrimSpace(v.Name),
+			Type:      strings.ToLower(strings.TrimSpace(v.Type)),
+			Required:  v.Required,
+			MaxLength: v.MaxLength,
+		})
+	}
+	return schema
+}
+
+var _ interfaceemailtemplate.ServiceEmailTemplateInterface = (*ServiceEmailTemplate)(nil)
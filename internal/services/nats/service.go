@@ -0,0 +1,78 @@
+package servicenats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainoutbox "service-sender/internal/domain/outbox"
+	interfaceoutbox "service-sender/internal/interfaces/outbox"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	"service-sender/pkg/config"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/natsbus"
+	"service-sender/utils"
+)
+
+// outboxConsumer is the name the relay tracks this service's progress under.
+const outboxConsumer = "nats"
+
+// ServiceNATS publishes domain events to NATS JetStream. Events written to
+// the outbox reach it through the relay, which retries until JetStream
+// acknowledges them; the rest are published as they happen, best effort.
+// Only the types in natsbus.Versions are published.
+type ServiceNATS struct {
+	Publisher natsbus.Publisher
+	Config    config.NATSConfig
+}
+
+func NewNATSService(publisher natsbus.Publisher, cfg config.NATSConfig) *ServiceNATS {
+	return &ServiceNATS{
+		Publisher: publisher,
+		Config:    cfg,
+	}
+}
+
+// Name identifies the service as an outbox consumer.
+func (s *ServiceNATS) Name() string {
+	return outboxConsumer
+}
+
+// Consume publishes an outbox event under its own id, so a repeat after a
+// relay restart is dropped by JetStream's duplicate window.
+func (s *ServiceNATS) Consume(event domainoutbox.Event) error {
+	if !natsbus.Publishes(event.Type) {
+		return nil
+	}
+	return s.publish(event.Id, event.Type, event.CreatedAt, event.Payload)
+}
+
+// Publish sends an event that has no outbox row. It returns at once; a
+// failure is logged and the event is lost.
+func (s *ServiceNATS) Publish(eventType string, data map[string]interface{}) {
+	if !natsbus.Publishes(eventType) {
+		return
+	}
+
+	id := utils.CreateUUID()
+	occurredAt := time.Now()
+	go func() {
+		if err := s.publish(id, eventType, occurredAt, data); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceNATS][Publish]; %s; publish error: %v", eventType, err))
+		}
+	}()
+}
+
+func (s *ServiceNATS) publish(id, eventType string, occurredAt time.Time, data map[string]interface{}) error {
+	env, err := natsbus.NewEnvelope(id, eventType, s.Config.Source, occurredAt, data)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Config.Timeout)
+	defer cancel()
+	return s.Publisher.Publish(ctx, s.Config.Subject(eventType), env)
+}
+
+var _ interfaceoutbox.ConsumerInterface = (*ServiceNATS)(nil)
+var _ interfacewebhook.PublisherInterface = (*ServiceNATS)(nil)
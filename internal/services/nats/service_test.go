@@ -0,0 +1,104 @@
+package servicenats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	domainoutbox "service-sender/internal/domain/outbox"
+	"service-sender/pkg/config"
+	"service-sender/pkg/natsbus"
+
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func newTestService(t *testing.T) (*ServiceNATS, *natsbus.JetStreamPublisher) {
+	t.Helper()
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natsserver.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+
+	cfg := config.NATSConfig{
+		URL:           srv.ClientURL(),
+		Stream:        "TEST_EVENTS",
+		CreateStream:  true,
+		SubjectPrefix: "test.events",
+		Subjects:      map[string]string{"user.deleted": "audit.user.deleted"},
+		Source:        "service-test",
+		Timeout:       2 * time.Second,
+	}
+	publisher, err := natsbus.NewJetStreamPublisher(cfg)
+	if err != nil {
+		t.Fatalf("NewJetStreamPublisher: %v", err)
+	}
+	t.Cleanup(publisher.Close)
+	return NewNATSService(publisher, cfg), publisher
+}
+
+func TestConsumePublishesOutboxEvents(t *testing.T) {
+	svc, publisher := newTestService(t)
+
+	events := []domainoutbox.Event{
+		domainoutbox.NewEvent("user.registered", domainoutbox.AggregateUser, "u-1", map[string]interface{}{"id": "u-1"}),
+		domainoutbox.NewEvent("user.updated", domainoutbox.AggregateUser, "u-1", map[string]interface{}{"id": "u-1"}),
+		domainoutbox.NewEvent("user.deleted", domainoutbox.AggregateUser, "u-1", map[string]interface{}{"id": "u-1"}),
+	}
+	for i := range events {
+		// The outbox assigns ids when it stores events.
+		events[i].Id = fmt.Sprintf("evt-%d", i)
+	}
+	for _, event := range events {
+		if err := svc.Consume(event); err != nil {
+			t.Fatalf("Consume %s: %v", event.Type, err)
+		}
+	}
+	// A relay retry hands the same event over again.
+	if err := svc.Consume(events[0]); err != nil {
+		t.Fatalf("Consume retry: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	consumer, err := publisher.JS.CreateOrUpdateConsumer(ctx, "TEST_EVENTS", jetstream.ConsumerConfig{
+		Durable:   "service-test",
+		AckPolicy: jetstream.AckExplicitPolicy,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateConsumer: %v", err)
+	}
+
+	batch, err := consumer.Fetch(10, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	var got []string
+	for msg := range batch.Messages() {
+		var env natsbus.Envelope
+		if err := json.Unmarshal(msg.Data(), &env); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		got = append(got, msg.Subject()+" "+env.Id)
+		if err := msg.Ack(); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+	}
+
+	want := []string{
+		"test.events.user.registered " + events[0].Id,
+		"audit.user.deleted " + events[2].Id,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("delivered %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("message %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package servicenotification
+
+import (
+	"sync"
+
+	domainnotification "service-sender/internal/domain/notification"
+)
+
+// streamBuffer is how many events an open stream may fall behind before
+// further events are dropped for it.
+const streamBuffer = 32
+
+// hub fans inbox events out to the streams open on this instance.
+type hub struct {
+	mu      sync.RWMutex
+	streams map[string]map[chan domainnotification.Event]struct{}
+}
+
+func newHub() *hub {
+	return &hub{streams: make(map[string]map[chan domainnotification.Event]struct{})}
+}
+
+func (h *hub) subscribe(userId string) (<-chan domainnotification.Event, func()) {
+	ch := make(chan domainnotification.Event, streamBuffer)
+
+	h.mu.Lock()
+	if h.streams[userId] == nil {
+		h.streams[userId] = make(map[chan domainnotification.Event]struct{})
+	}
+	h.streams[userId][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.streams[userId], ch)
+			if len(h.streams[userId]) == 0 {
+				delete(h.streams, userId)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// deliver never blocks: a stream that stopped reading loses the event rather
+// than holding up everyone else. Read and unread events carry the unread
+// count, so the client catches up on the next one.
+func (h *hub) deliver(event domainnotification.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.streams[event.UserId] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package servicenotification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domainnotification "service-sender/internal/domain/notification"
+	domainpreference "service-sender/internal/domain/preference"
+	"service-sender/internal/dto"
+	interfacenotification "service-sender/internal/interfaces/notification"
+	interfaceuser "service-sender/internal/interfaces/user"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/utils"
+)
+
+// listenRetry is how long Run waits before resubscribing after the event
+// stream failed.
+const listenRetry = 5 * time.Second
+
+type ServiceNotification struct {
+	Repo  interfacenotification.RepoNotificationInterface
+	Users interfaceuser.RepoUserInterface
+	// Stream shares events between instances. Without it events only reach
+	// streams open on the instance that produced them.
+	Stream interfacenotification.RepoStreamInterface
+
+	hub *hub
+}
+
+func NewNotificationService(repo interfacenotification.RepoNotificationInterface, users interfaceuser.RepoUserInterface, stream interfacenotification.RepoStreamInterface) *ServiceNotification {
+	return &ServiceNotification{Repo: repo, Users: users, Stream: stream, hub: newHub()}
+}
+
+// Create adds a notification to one user's inbox, or to the inbox of every
+// user holding the role.
+func (s *ServiceNotification) Create(req dto.NotificationCreate) (dto.NotificationCreated, error) {
+	category := req.Category
+	if category == "" {
+		category = domainpreference.CategoryProduct
+	}
+	data := domainnotification.Notification{
+		Title:     strings.TrimSpace(req.Title),
+		Body:      req.Body,
+		Link:      strings.TrimSpace(req.Link),
+		Category:  category,
+		CreatedAt: time.Now(),
+	}
+
+	if req.UserId != "" {
+		if _, err := s.Users.GetByID(req.UserId); err != nil {
+			return dto.NotificationCreated{}, err
+		}
+		data.Id = utils.CreateUUID()
+		data.UserId = req.UserId
+		if err := s.Repo.Store(data); err != nil {
+			return dto.NotificationCreated{}, err
+		}
+		s.publish(domainnotification.Event{Type: domainnotification.EventCreated, UserId: data.UserId, Notification: &data})
+		return dto.NotificationCreated{Created: 1}, nil
+	}
+
+	created, err := s.Repo.StoreForRole(data, strings.ToLower(strings.TrimSpace(req.Role)))
+	if err != nil {
+		return dto.NotificationCreated{}, err
+	}
+	for i := range created {
+		s.publish(domainnotification.Event{Type: domainnotification.EventCreated, UserId: created[i].UserId, Notification: &created[i]})
+	}
+	return dto.NotificationCreated{Created: int64(len(created))}, nil
+}
+
+func (s *ServiceNotification) GetAll(userId string, params filter.BaseParams) ([]domainnotification.Notification, int64, error) {
+	return s.Repo.GetByUser(userId, params)
+}
+
+func (s *ServiceNotification) MarkRead(userId, id string) error {
+	now := time.Now()
+	if err := s.Repo.SetRead(userId, id, &now); err != nil {
+		return err
+	}
+	s.publishCount(domainnotification.EventRead, userId, id)
+	return nil
+}
+
+func (s *ServiceNotification) MarkUnread(userId, id string) error {
+	if err := s.Repo.SetRead(userId, id, nil); err != nil {
+		return err
+	}
+	s.publishCount(domainnotification.EventUnread, userId, id)
+	return nil
+}
+
+func (s *ServiceNotification) MarkAllRead(userId string) error {
+	updated, err := s.Repo.MarkAllRead(userId, time.Now())
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		s.publishCount(domainnotification.EventReadAll, userId, "")
+	}
+	return nil
+}
+
+func (s *ServiceNotification) UnreadCount(userId string) (dto.UnreadCount, error) {
+	count, err := s.Repo.CountUnread(userId)
+	if err != nil {
+		return dto.UnreadCount{}, err
+	}
+	return dto.UnreadCount{Unread: count}, nil
+}
+
+func (s *ServiceNotification) Subscribe(userId string) (<-chan domainnotification.Event, func()) {
+	return s.hub.subscribe(userId)
+}
+
+// Run relays events published by any instance to the streams open on this
+// one until ctx is done. It returns at once when there is no shared stream.
+func (s *ServiceNotification) Run(ctx context.Context) {
+	if s.Stream == nil {
+		return
+	}
+
+	for {
+		err := s.Stream.Listen(ctx, s.hub.deliver)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[NotificationService][Run]; Listen stopped: %v", err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
+		}
+	}
+}
+
+// publishCount sends a read state change along with the new unread count.
+func (s *ServiceNotification) publishCount(eventType, userId, id string) {
+	count, err := s.Repo.CountUnread(userId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[NotificationService][publishCount]; CountUnread error: %v", err))
+		return
+	}
+	s.publish(domainnotification.Event{Type: eventType, UserId: userId, Id: id, Unread: &count})
+}
+
+// publish hands the event to every instance, falling back to the local
+// streams when the shared stream is missing or failing.
+func (s *ServiceNotification) publish(event domainnotification.Event) {
+	if s.Stream != nil {
+		err := s.Stream.Publish(context.Background(), event)
+		if err == nil {
+			return
+		}
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[NotificationService][publish]; Publish error: %v", err))
+	}
+	s.hub.deliver(event)
+}
+
+var _ interfacenotification.ServiceNotificationInterface = (*ServiceNotification)(nil)
@@ -0,0 +1,171 @@
+package servicenotify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	domainnotify "service-sender/internal/domain/notify"
+	domainpreference "service-sender/internal/domain/preference"
+	domainwebhook "service-sender/internal/domain/webhook"
+)
+
+// RuleRegistry holds the notification rules with their channel templates
+// parsed once at startup.
+type RuleRegistry struct {
+	rules map[string]*compiledRule
+}
+
+type compiledRule struct {
+	domainnotify.Rule
+	smsText    *template.Template
+	inAppTitle *template.Template
+	inAppBody  *template.Template
+	inAppLink  *template.Template
+	pushTitle  *template.Template
+	pushBody   *template.Template
+	pushLink   *template.Template
+}
+
+func NewRuleRegistry(rules []domainnotify.Rule) (*RuleRegistry, error) {
+	registry := &RuleRegistry{rules: make(map[string]*compiledRule, len(rules))}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := registry.rules[compiled.Key]; ok {
+			return nil, fmt.Errorf("duplicate notification rule: %s", compiled.Key)
+		}
+		registry.rules[compiled.Key] = compiled
+	}
+	return registry, nil
+}
+
+// LoadRuleRegistryFromEnv reads NOTIFY_RULES as a JSON array of rules, or the
+// file named by NOTIFY_RULES_FILE when set. No rules yields an empty registry.
+func LoadRuleRegistryFromEnv() (*RuleRegistry, error) {
+	raw := strings.TrimSpace(os.Getenv("NOTIFY_RULES"))
+	if path := strings.TrimSpace(os.Getenv("NOTIFY_RULES_FILE")); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read NOTIFY_RULES_FILE: %w", err)
+		}
+		raw = strings.TrimSpace(string(content))
+	}
+	if raw == "" {
+		return &RuleRegistry{rules: map[string]*compiledRule{}}, nil
+	}
+
+	var rules []domainnotify.Rule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("parse notification rules: %w", err)
+	}
+	return NewRuleRegistry(rules)
+}
+
+func (r *RuleRegistry) get(key string) (*compiledRule, bool) {
+	if r == nil {
+		return nil, false
+	}
+	rule, ok := r.rules[strings.TrimSpace(key)]
+	return rule, ok
+}
+
+func compileRule(rule domainnotify.Rule) (*compiledRule, error) {
+	rule.Key = strings.TrimSpace(rule.Key)
+	if rule.Key == "" {
+		return nil, fmt.Errorf("notification rule key is required")
+	}
+	if rule.Category == "" {
+		rule.Category = domainpreference.CategoryProduct
+	}
+	if !slices.Contains(domainpreference.Categories, rule.Category) {
+		return nil, fmt.Errorf("notification rule %s: unknown category %q", rule.Key, rule.Category)
+	}
+	if rule.Mode == "" {
+		rule.Mode = domainnotify.ModeAll
+	}
+	if rule.Mode != domainnotify.ModeAll && rule.Mode != domainnotify.ModeFirst {
+		return nil, fmt.Errorf("notification rule %s: unknown mode %q", rule.Key, rule.Mode)
+	}
+	if len(rule.Channels) == 0 {
+		return nil, fmt.Errorf("notification rule %s: at least one channel is required", rule.Key)
+	}
+
+	compiled := &compiledRule{Rule: rule}
+	seen := make(map[string]struct{}, len(rule.Channels))
+	for _, channel := range rule.Channels {
+		if _, ok := seen[channel]; ok {
+			return nil, fmt.Errorf("notification rule %s: duplicate channel %s", rule.Key, channel)
+		}
+		seen[channel] = struct{}{}
+
+		var err error
+		switch channel {
+		case domainnotify.ChannelEmail:
+			if rule.Email == nil || strings.TrimSpace(rule.Email.TemplateKey) == "" {
+				return nil, fmt.Errorf("notification rule %s: email.template_key is required", rule.Key)
+			}
+		case domainnotify.ChannelSMS:
+			if rule.SMS == nil || strings.TrimSpace(rule.SMS.Text) == "" {
+				return nil, fmt.Errorf("notification rule %s: sms.text is required", rule.Key)
+			}
+			compiled.smsText, err = parseTemplate(rule.Key, "sms.text", rule.SMS.Text)
+		case domainnotify.ChannelInApp:
+			if rule.InApp == nil || strings.TrimSpace(rule.InApp.Title) == "" {
+				return nil, fmt.Errorf("notification rule %s: in_app.title is required", rule.Key)
+			}
+			if compiled.inAppTitle, err = parseTemplate(rule.Key, "in_app.title", rule.InApp.Title); err != nil {
+				break
+			}
+			if compiled.inAppBody, err = parseTemplate(rule.Key, "in_app.body", rule.InApp.Body); err != nil {
+				break
+			}
+			compiled.inAppLink, err = parseTemplate(rule.Key, "in_app.link", rule.InApp.Link)
+		case domainnotify.ChannelPush:
+			if rule.Push == nil || strings.TrimSpace(rule.Push.Title) == "" {
+				return nil, fmt.Errorf("notification rule %s: push.title is required", rule.Key)
+			}
+			if compiled.pushTitle, err = parseTemplate(rule.Key, "push.title", rule.Push.Title); err != nil {
+				break
+			}
+			if compiled.pushBody, err = parseTemplate(rule.Key, "push.body", rule.Push.Body); err != nil {
+				break
+			}
+			compiled.pushLink, err = parseTemplate(rule.Key, "push.link", rule.Push.Link)
+		case domainnotify.ChannelWebhook:
+			if rule.Webhook == nil || strings.TrimSpace(rule.Webhook.Event) == "" {
+				return nil, fmt.Errorf("notification rule %s: webhook.event is required", rule.Key)
+			}
+			if !strings.HasPrefix(rule.Webhook.Event, domainwebhook.NotifyEventPrefix) {
+				return nil, fmt.Errorf("notification rule %s: webhook.event must start with %q", rule.Key, domainwebhook.NotifyEventPrefix)
+			}
+		default:
+			return nil, fmt.Errorf("notification rule %s: unknown channel %q", rule.Key, channel)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+func parseTemplate(key, field, text string) (*template.Template, error) {
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("notification rule %s: %s: %w", key, field, err)
+	}
+	return tmpl, nil
+}
+
+func render(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
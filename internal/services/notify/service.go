@@ -0,0 +1,304 @@
+package servicenotify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	domainnotify "service-sender/internal/domain/notify"
+	domainpreference "service-sender/internal/domain/preference"
+	"service-sender/internal/dto"
+	interfaceemail "service-sender/internal/interfaces/email"
+	interfacenotification "service-sender/internal/interfaces/notification"
+	interfacenotify "service-sender/internal/interfaces/notify"
+	interfacepreference "service-sender/internal/interfaces/preference"
+	interfacepush "service-sender/internal/interfaces/push"
+	interfaceuser "service-sender/internal/interfaces/user"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	serviceemail "service-sender/internal/services/email"
+	servicepush "service-sender/internal/services/push"
+	"service-sender/pkg/sms"
+)
+
+var (
+	ErrNotifyRuleNotFound = errors.New("notification rule not found")
+	ErrNotifyNoDatabase   = errors.New("user_id targets require the database")
+)
+
+const emailTypeNotification = "notification"
+
+// Reasons reported for channels that were skipped.
+const (
+	reasonNotConfigured = "channel is not configured"
+	reasonOptedOut      = "recipient opted out of this category"
+	reasonNoEmail       = "recipient has no email address"
+	reasonNoPhone       = "recipient has no phone number"
+	reasonNoUser        = "channel needs a registered user"
+	reasonNoDevices     = "recipient has no push subscriptions"
+	reasonNotRequested  = "channel not requested"
+)
+
+// ServiceNotify routes one notification to email, SMS, the in-app inbox, Web
+// Push or webhook subscribers. Channels without a backing service are
+// reported as skipped.
+type ServiceNotify struct {
+	Rules       *RuleRegistry
+	Users       interfaceuser.RepoUserInterface
+	Preferences interfacepreference.ServicePreferenceInterface
+	Email       interfaceemail.ServiceEmailInterface
+	Inbox       interfacenotification.ServiceNotificationInterface
+	Push        interfacepush.ServicePushInterface
+	SMS         sms.Sender
+	Webhooks    interfacewebhook.PublisherInterface
+}
+
+func NewNotifyService(rules *RuleRegistry, users interfaceuser.RepoUserInterface, preferences interfacepreference.ServicePreferenceInterface, email interfaceemail.ServiceEmailInterface, inbox interfacenotification.ServiceNotificationInterface, push interfacepush.ServicePushInterface, smsSender sms.Sender, webhooks interfacewebhook.PublisherInterface) *ServiceNotify {
+	return &ServiceNotify{Rules: rules, Users: users, Preferences: preferences, Email: email, Inbox: inbox, Push: push, SMS: smsSender, Webhooks: webhooks}
+}
+
+type recipient struct {
+	UserId string
+	Name   string
+	Email  string
+	Phone  string
+	Locale string
+}
+
+func (s *ServiceNotify) Notify(ctx context.Context, req dto.NotifyRequest, appName string) (dto.NotifyResult, error) {
+	rule, ok := s.Rules.get(req.Key)
+	if !ok {
+		return dto.NotifyResult{}, ErrNotifyRuleNotFound
+	}
+
+	to, err := s.recipient(req)
+	if err != nil {
+		return dto.NotifyResult{}, err
+	}
+
+	optedOut, err := s.optedOutChannels(to.UserId, rule.Category)
+	if err != nil {
+		return dto.NotifyResult{}, err
+	}
+
+	data := make(map[string]interface{}, len(req.Data)+1)
+	for k, v := range req.Data {
+		data[k] = v
+	}
+	data["Recipient"] = map[string]interface{}{
+		"Name":  to.Name,
+		"Email": to.Email,
+		"Phone": to.Phone,
+	}
+
+	result := dto.NotifyResult{Key: rule.Key, Channels: make([]dto.NotifyChannelResult, 0, len(rule.Channels))}
+	for _, channel := range rule.Channels {
+		item := dto.NotifyChannelResult{Channel: channel}
+		switch {
+		case len(req.Channels) > 0 && !slices.Contains(req.Channels, channel):
+			item.Status, item.Reason = domainnotify.StatusSkipped, reasonNotRequested
+		case rule.Mode == domainnotify.ModeFirst && result.Delivered:
+			item.Status = domainnotify.StatusNotAttempted
+		case optedOut[channel]:
+			item.Status, item.Reason = domainnotify.StatusSkipped, reasonOptedOut
+		default:
+			item.Status, item.Reason = s.deliver(ctx, channel, rule, to, data, appName)
+		}
+		if item.Status == domainnotify.StatusSent {
+			result.Delivered = true
+		}
+		result.Channels = append(result.Channels, item)
+	}
+	return result, nil
+}
+
+func (s *ServiceNotify) recipient(req dto.NotifyRequest) (recipient, error) {
+	if req.UserId == "" {
+		to := recipient{Locale: req.Locale}
+		if req.Contact != nil {
+			to.Name = strings.TrimSpace(req.Contact.Name)
+			to.Email = strings.ToLower(strings.TrimSpace(req.Contact.Email))
+			to.Phone = strings.TrimSpace(req.Contact.Phone)
+		}
+		return to, nil
+	}
+
+	if s.Users == nil {
+		return recipient{}, ErrNotifyNoDatabase
+	}
+	user, err := s.Users.GetByID(req.UserId)
+	if err != nil {
+		return recipient{}, err
+	}
+	to := recipient{UserId: user.Id, Name: user.Name, Email: user.Email, Phone: user.Phone, Locale: user.Locale}
+	if req.Locale != "" {
+		to.Locale = req.Locale
+	}
+	return to, nil
+}
+
+// optedOutChannels returns the channels a registered user turned off for the
+// category. The inbox and webhooks are not covered by preferences.
+func (s *ServiceNotify) optedOutChannels(userId, category string) (map[string]bool, error) {
+	if userId == "" || s.Preferences == nil || domainpreference.Locked(category) {
+		return nil, nil
+	}
+	views, err := s.Preferences.GetPreferences(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]bool)
+	for _, view := range views {
+		if view.Category != category || view.Enabled {
+			continue
+		}
+		switch view.Channel {
+		case domainpreference.ChannelEmail:
+			out[domainnotify.ChannelEmail] = true
+		case domainpreference.ChannelSMS:
+			out[domainnotify.ChannelSMS] = true
+		case domainpreference.ChannelPush:
+			out[domainnotify.ChannelPush] = true
+		}
+	}
+	return out, nil
+}
+
+func (s *ServiceNotify) deliver(ctx context.Context, channel string, rule *compiledRule, to recipient, data map[string]interface{}, appName string) (string, string) {
+	switch channel {
+	case domainnotify.ChannelEmail:
+		return s.sendEmail(ctx, rule, to, data, appName)
+	case domainnotify.ChannelSMS:
+		return s.sendSMS(ctx, rule, to, data, appName)
+	case domainnotify.ChannelInApp:
+		return s.sendInApp(rule, to, data)
+	case domainnotify.ChannelPush:
+		return s.sendPush(ctx, rule, to, data)
+	case domainnotify.ChannelWebhook:
+		return s.sendWebhook(rule, to, data, appName)
+	}
+	return domainnotify.StatusSkipped, reasonNotConfigured
+}
+
+func (s *ServiceNotify) sendEmail(ctx context.Context, rule *compiledRule, to recipient, data map[string]interface{}, appName string) (string, string) {
+	if s.Email == nil {
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	}
+	if to.Email == "" {
+		return domainnotify.StatusSkipped, reasonNoEmail
+	}
+
+	_, _, err := s.Email.Send(ctx, dto.SendEmailRequest{
+		Type:         emailTypeNotification,
+		Category:     rule.Category,
+		To:           []string{to.Email},
+		Sender:       rule.Email.Sender,
+		TemplateKey:  rule.Email.TemplateKey,
+		TemplateData: data,
+		Locale:       to.Locale,
+	}, appName)
+	switch {
+	case err == nil:
+		return domainnotify.StatusSent, ""
+	case errors.Is(err, serviceemail.ErrEmailNotConfigured):
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	case errors.Is(err, serviceemail.ErrAllRecipientsSuppressed), errors.Is(err, serviceemail.ErrAllRecipientsOptedOut):
+		return domainnotify.StatusSkipped, err.Error()
+	}
+	return domainnotify.StatusFailed, err.Error()
+}
+
+func (s *ServiceNotify) sendSMS(ctx context.Context, rule *compiledRule, to recipient, data map[string]interface{}, appName string) (string, string) {
+	if s.SMS == nil {
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	}
+	if to.Phone == "" {
+		return domainnotify.StatusSkipped, reasonNoPhone
+	}
+
+	text, err := render(rule.smsText, data)
+	if err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	if err := s.SMS.Send(ctx, to.Phone, text, appName); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	return domainnotify.StatusSent, ""
+}
+
+func (s *ServiceNotify) sendInApp(rule *compiledRule, to recipient, data map[string]interface{}) (string, string) {
+	if s.Inbox == nil {
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	}
+	if to.UserId == "" {
+		return domainnotify.StatusSkipped, reasonNoUser
+	}
+
+	req := dto.NotificationCreate{UserId: to.UserId, Category: rule.Category}
+	var err error
+	if req.Title, err = render(rule.inAppTitle, data); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	if req.Body, err = render(rule.inAppBody, data); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	if req.Link, err = render(rule.inAppLink, data); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	if _, err := s.Inbox.Create(req); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	return domainnotify.StatusSent, ""
+}
+
+func (s *ServiceNotify) sendPush(ctx context.Context, rule *compiledRule, to recipient, data map[string]interface{}) (string, string) {
+	if s.Push == nil {
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	}
+	if to.UserId == "" {
+		return domainnotify.StatusSkipped, reasonNoUser
+	}
+
+	msg := dto.PushMessage{Tag: rule.Key}
+	var err error
+	if msg.Title, err = render(rule.pushTitle, data); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	if msg.Body, err = render(rule.pushBody, data); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+	if msg.URL, err = render(rule.pushLink, data); err != nil {
+		return domainnotify.StatusFailed, err.Error()
+	}
+
+	sent, err := s.Push.SendToUser(ctx, to.UserId, msg)
+	switch {
+	case errors.Is(err, servicepush.ErrPushNotConfigured):
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	case err != nil:
+		return domainnotify.StatusFailed, err.Error()
+	case sent == 0:
+		return domainnotify.StatusSkipped, reasonNoDevices
+	}
+	return domainnotify.StatusSent, ""
+}
+
+// sendWebhook queues the rule's event for webhook subscribers. Sent means
+// queued; delivery is tracked in the webhook delivery log.
+func (s *ServiceNotify) sendWebhook(rule *compiledRule, to recipient, data map[string]interface{}, appName string) (string, string) {
+	if s.Webhooks == nil {
+		return domainnotify.StatusSkipped, reasonNotConfigured
+	}
+
+	s.Webhooks.Publish(rule.Webhook.Event, map[string]interface{}{
+		"key":      rule.Key,
+		"category": rule.Category,
+		"app_name": appName,
+		"user_id":  to.UserId,
+		"data":     data,
+	})
+	return domainnotify.StatusSent, ""
+}
+
+var _ interfacenotify.ServiceNotifyInterface = (*ServiceNotify)(nil)
@@ -8,7 +8,10 @@ import (
 	"strings"
 	"time"
 
+	domainwebhook "service-sender/internal/domain/webhook"
 	interfaceotp "service-sender/internal/interfaces/otp"
+	interfaceuser "service-sender/internal/interfaces/user"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
 	"service-sender/pkg/config"
 	"service-sender/pkg/mailer"
 
@@ -22,6 +25,13 @@ var (
 	ErrOTPDeliveryFailed = errors.New("otp delivery failed")
 )
 
+// Purposes keep codes apart: a code is only accepted for the purpose it was
+// issued for.
+const (
+	PurposeRegister = "register"
+	PurposeReset    = "reset"
+)
+
 type ThrottleError struct {
 	Reason     string
 	RetryAfter time.Duration
@@ -38,18 +48,77 @@ type ServiceOTP struct {
 	Repo   interfaceotp.RepoOTPInterface
 	Sender mailer.Sender
 	Config config.OTPConfig
+	// Events receives otp.sent and otp.verified for webhook and NATS
+	// subscribers. It may be nil.
+	Events interfacewebhook.PublisherInterface
+	// Users records verified addresses on their accounts. It may be nil,
+	// leaving registration OTPs without effect on accounts.
+	Users interfaceuser.RepoUserInterface
 }
 
-func NewOTPService(repo interfaceotp.RepoOTPInterface, sender mailer.Sender, cfg config.OTPConfig) *ServiceOTP {
+// registerVerifiedTTL is how long a verified address without an account is
+// remembered for the registration that follows.
+const registerVerifiedTTL = 24 * time.Hour
+
+func NewOTPService(repo interfaceotp.RepoOTPInterface, sender mailer.Sender, cfg config.OTPConfig, events interfacewebhook.PublisherInterface, users interfaceuser.RepoUserInterface) *ServiceOTP {
 	return &ServiceOTP{
 		Repo:   repo,
 		Sender: sender,
 		Config: cfg,
+		Events: events,
+		Users:  users,
 	}
 }
 
-func (s *ServiceOTP) SendRegisterOTP(ctx context.Context, email, appName string) error {
-	if s == nil || s.Repo == nil || s.Sender == nil {
+func (s *ServiceOTP) SendRegisterOTP(ctx context.Context, email, appName, locale string) error {
+	if s == nil || s.Sender == nil {
+		return ErrOTPNotConfigured
+	}
+	return s.Issue(ctx, PurposeRegister, email, func(to, code string, _ time.Duration) error {
+		return s.Sender.SendOTP(to, code, appName, locale)
+	})
+}
+
+// VerifyRegisterOTP marks the account for email as verified. An address
+// without an account yet is remembered until it registers.
+func (s *ServiceOTP) VerifyRegisterOTP(ctx context.Context, email, code string) error {
+	if err := s.Verify(ctx, PurposeRegister, email, code); err != nil {
+		return err
+	}
+	if s.Users == nil {
+		return nil
+	}
+
+	normalizedEmail := normalizeEmail(email)
+	found, err := s.Users.MarkEmailVerified(normalizedEmail, time.Now())
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OTP][VerifyRegisterOTP]; mark email verified: %v", err))
+		return nil
+	}
+	if !found {
+		if err := s.Repo.SetVerified(ctx, PurposeRegister, normalizedEmail, registerVerifiedTTL); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OTP][VerifyRegisterOTP]; remember verified email: %v", err))
+		}
+	}
+	return nil
+}
+
+func (s *ServiceOTP) ConsumeRegisterVerification(ctx context.Context, email string) bool {
+	if s == nil || s.Repo == nil {
+		return false
+	}
+	verified, err := s.Repo.ConsumeVerified(ctx, PurposeRegister, normalizeEmail(email))
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OTP][ConsumeRegisterVerification]; %v", err))
+		return false
+	}
+	return verified
+}
+
+// Issue creates a code for purpose and hands it to deliver. Each purpose has
+// its own code, attempts, cooldown and rate limit.
+func (s *ServiceOTP) Issue(ctx context.Context, purpose, email string, deliver func(email, code string, ttl time.Duration) error) error {
+	if s == nil || s.Repo == nil {
 		return ErrOTPNotConfigured
 	}
 
@@ -58,7 +127,7 @@ func (s *ServiceOTP) SendRegisterOTP(ctx context.Context, email, appName string)
 		return ErrOTPInvalid
 	}
 
-	cooldownTTL, err := s.Repo.GetCooldownTTL(ctx, normalizedEmail)
+	cooldownTTL, err := s.Repo.GetCooldownTTL(ctx, purpose, normalizedEmail)
 	if err != nil {
 		return fmt.Errorf("check cooldown: %w", err)
 	}
@@ -67,7 +136,7 @@ func (s *ServiceOTP) SendRegisterOTP(ctx context.Context, email, appName string)
 	}
 
 	if s.Config.RateLimit > 0 && s.Config.RateWindow > 0 {
-		count, retryAfter, err := s.Repo.IncrementSendCount(ctx, normalizedEmail, s.Config.RateWindow)
+		count, retryAfter, err := s.Repo.IncrementSendCount(ctx, purpose, normalizedEmail, s.Config.RateWindow)
 		if err != nil {
 			return fmt.Errorf("rate limit: %w", err)
 		}
@@ -82,31 +151,34 @@ func (s *ServiceOTP) SendRegisterOTP(ctx context.Context, email, appName string)
 	}
 
 	hashed := hashOTP(code, s.Config.Secret)
-	if err := s.Repo.SetOTP(ctx, normalizedEmail, hashed, s.Config.TTL); err != nil {
-		_ = s.Repo.ClearSendCount(ctx, normalizedEmail)
+	if err := s.Repo.SetOTP(ctx, purpose, normalizedEmail, hashed, s.Config.TTL); err != nil {
+		_ = s.Repo.ClearSendCount(ctx, purpose, normalizedEmail)
 		return fmt.Errorf("store otp: %w", err)
 	}
-	_ = s.Repo.ResetAttempts(ctx, normalizedEmail)
-	if err := s.Repo.SetCooldown(ctx, normalizedEmail, s.Config.Cooldown); err != nil {
-		_ = s.Repo.DeleteOTP(ctx, normalizedEmail)
-		_ = s.Repo.ResetAttempts(ctx, normalizedEmail)
-		_ = s.Repo.ClearSendCount(ctx, normalizedEmail)
+	_ = s.Repo.ResetAttempts(ctx, purpose, normalizedEmail)
+	if err := s.Repo.SetCooldown(ctx, purpose, normalizedEmail, s.Config.Cooldown); err != nil {
+		_ = s.Repo.DeleteOTP(ctx, purpose, normalizedEmail)
+		_ = s.Repo.ResetAttempts(ctx, purpose, normalizedEmail)
+		_ = s.Repo.ClearSendCount(ctx, purpose, normalizedEmail)
 		return fmt.Errorf("set cooldown: %w", err)
 	}
 
-	if err := s.Sender.SendOTP(normalizedEmail, code, appName); err != nil {
-		_ = s.Repo.DeleteOTP(ctx, normalizedEmail)
-		_ = s.Repo.ResetAttempts(ctx, normalizedEmail)
-		_ = s.Repo.ClearCooldown(ctx, normalizedEmail)
-		_ = s.Repo.ClearSendCount(ctx, normalizedEmail)
+	if err := deliver(normalizedEmail, code, s.Config.TTL); err != nil {
+		_ = s.Repo.DeleteOTP(ctx, purpose, normalizedEmail)
+		_ = s.Repo.ResetAttempts(ctx, purpose, normalizedEmail)
+		_ = s.Repo.ClearCooldown(ctx, purpose, normalizedEmail)
+		_ = s.Repo.ClearSendCount(ctx, purpose, normalizedEmail)
 		logger.WriteLog(logger.LogLevelError, "OTP delivery error: ", err)
 		return ErrOTPDeliveryFailed
 	}
 
+	s.publish(domainwebhook.EventOTPSent, purpose, normalizedEmail)
 	return nil
 }
 
-func (s *ServiceOTP) VerifyRegisterOTP(ctx context.Context, email, code string) error {
+// Verify checks code against the one issued for purpose. A match uses the
+// code up and lifts the cooldown.
+func (s *ServiceOTP) Verify(ctx context.Context, purpose, email, code string) error {
 	if s == nil || s.Repo == nil {
 		return ErrOTPNotConfigured
 	}
@@ -117,7 +189,7 @@ func (s *ServiceOTP) VerifyRegisterOTP(ctx context.Context, email, code string)
 		return ErrOTPInvalid
 	}
 
-	hashed, err := s.Repo.GetOTP(ctx, normalizedEmail)
+	hashed, err := s.Repo.GetOTP(ctx, purpose, normalizedEmail)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return ErrOTPInvalid
@@ -125,32 +197,51 @@ func (s *ServiceOTP) VerifyRegisterOTP(ctx context.Context, email, code string)
 		return fmt.Errorf("get otp: %w", err)
 	}
 
-	attempts, err := s.Repo.IncrementAttempts(ctx, normalizedEmail, s.Config.TTL)
+	attempts, err := s.Repo.IncrementAttempts(ctx, purpose, normalizedEmail, s.Config.TTL)
 	if err != nil {
 		return fmt.Errorf("increment attempts: %w", err)
 	}
 
 	if s.Config.MaxAttempts > 0 && attempts > s.Config.MaxAttempts {
-		_ = s.Repo.DeleteOTP(ctx, normalizedEmail)
-		_ = s.Repo.ResetAttempts(ctx, normalizedEmail)
+		_ = s.Repo.DeleteOTP(ctx, purpose, normalizedEmail)
+		_ = s.Repo.ResetAttempts(ctx, purpose, normalizedEmail)
 		return ErrOTPTooManyAttempt
 	}
 
 	if !verifyOTP(cleanCode, hashed, s.Config.Secret) {
 		if s.Config.MaxAttempts > 0 && attempts >= s.Config.MaxAttempts {
-			_ = s.Repo.DeleteOTP(ctx, normalizedEmail)
-			_ = s.Repo.ResetAttempts(ctx, normalizedEmail)
+			_ = s.Repo.DeleteOTP(ctx, purpose, normalizedEmail)
+			_ = s.Repo.ResetAttempts(ctx, purpose, normalizedEmail)
 			return ErrOTPTooManyAttempt
 		}
 		return ErrOTPInvalid
 	}
 
-	_ = s.Repo.DeleteOTP(ctx, normalizedEmail)
-	_ = s.Repo.ResetAttempts(ctx, normalizedEmail)
-	_ = s.Repo.ClearCooldown(ctx, normalizedEmail)
-	_ = s.Repo.ClearSendCount(ctx, normalizedEmail)
+	_ = s.Repo.DeleteOTP(ctx, purpose, normalizedEmail)
+	_ = s.Repo.ResetAttempts(ctx, purpose, normalizedEmail)
+	_ = s.Repo.ClearCooldown(ctx, purpose, normalizedEmail)
+	_ = s.Repo.ClearSendCount(ctx, purpose, normalizedEmail)
 
+	s.publish(domainwebhook.EventOTPVerified, purpose, normalizedEmail)
 	return nil
 }
 
+func (s *ServiceOTP) ClearThrottle(ctx context.Context, purpose, email string) error {
+	if s == nil || s.Repo == nil {
+		return ErrOTPNotConfigured
+	}
+	normalizedEmail := normalizeEmail(email)
+	if err := s.Repo.ClearCooldown(ctx, purpose, normalizedEmail); err != nil {
+		return err
+	}
+	return s.Repo.ClearSendCount(ctx, purpose, normalizedEmail)
+}
+
+// publish never carries the code; subscribers only learn whose OTP it was.
+func (s *ServiceOTP) publish(eventType, purpose, email string) {
+	if s.Events != nil {
+		s.Events.Publish(eventType, map[string]interface{}{"email": email, "purpose": purpose})
+	}
+}
+
 var _ interfaceotp.ServiceOTPInterface = (*ServiceOTP)(nil)
@@ -0,0 +1,89 @@
+package serviceoutbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	interfaceoutbox "service-sender/internal/interfaces/outbox"
+	"service-sender/pkg/config"
+	"service-sender/pkg/logger"
+)
+
+// pruneInterval is how often the relay deletes events past retention.
+const pruneInterval = time.Hour
+
+// ServiceOutbox relays outbox events, handing each one to every consumer
+// once. A consumer that fails keeps its place: the event is retried on the
+// next tick and later events wait behind it, so each consumer sees events in
+// order.
+type ServiceOutbox struct {
+	Repo      interfaceoutbox.RepoOutboxInterface
+	Consumers []interfaceoutbox.ConsumerInterface
+	Config    config.OutboxConfig
+
+	registered map[string]bool
+	lastPrune  time.Time
+}
+
+func NewOutboxService(repo interfaceoutbox.RepoOutboxInterface, cfg config.OutboxConfig, consumers ...interfaceoutbox.ConsumerInterface) *ServiceOutbox {
+	return &ServiceOutbox{
+		Repo:       repo,
+		Consumers:  consumers,
+		Config:     cfg,
+		registered: make(map[string]bool, len(consumers)),
+	}
+}
+
+func (s *ServiceOutbox) Run(ctx context.Context) {
+	if !s.Config.RelayEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.RelayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *ServiceOutbox) tick(ctx context.Context) {
+	for _, consumer := range s.Consumers {
+		name := consumer.Name()
+		if !s.registered[name] {
+			if err := s.Repo.RegisterConsumer(name); err != nil {
+				logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OutboxRelay][tick]; RegisterConsumer %s error: %v", name, err))
+				continue
+			}
+			s.registered[name] = true
+		}
+
+		// Drain the backlog in batches before waiting for the next tick.
+		for ctx.Err() == nil {
+			n, err := s.Repo.Process(name, s.Config.BatchSize, s.Config.ClaimTTL, consumer.Consume)
+			if err != nil {
+				logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OutboxRelay][tick]; %s; Process error after %d events: %v", name, n, err))
+				break
+			}
+			if n < s.Config.BatchSize {
+				break
+			}
+		}
+	}
+
+	if time.Since(s.lastPrune) >= pruneInterval {
+		s.lastPrune = time.Now()
+		if n, err := s.Repo.Prune(time.Now().Add(-s.Config.Retention)); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[OutboxRelay][tick]; Prune error: %v", err))
+		} else if n > 0 {
+			logger.WriteLog(logger.LogLevelInfo, fmt.Sprintf("[OutboxRelay][tick]; pruned %d events", n))
+		}
+	}
+}
+
+var _ interfaceoutbox.ServiceOutboxInterface = (*ServiceOutbox)(nil)
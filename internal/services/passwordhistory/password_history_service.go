@@ -0,0 +1,88 @@
+package servicepasswordhistory
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	domainpasswordhistory "service-sender/internal/domain/passwordhistory"
+	domainuser "service-sender/internal/domain/user"
+	interfacepasswordhistory "service-sender/internal/interfaces/passwordhistory"
+	"service-sender/pkg/config"
+	"service-sender/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrPasswordReused = errors.New("password was used recently, choose a different one")
+
+// ServicePasswordHistory keeps users from going back to their recent
+// passwords and tells when a password has expired.
+type ServicePasswordHistory struct {
+	Repo   interfacepasswordhistory.RepoPasswordHistoryInterface
+	Config config.PasswordHistoryConfig
+}
+
+func NewPasswordHistoryService(repo interfacepasswordhistory.RepoPasswordHistoryInterface, cfg config.PasswordHistoryConfig) *ServicePasswordHistory {
+	return &ServicePasswordHistory{
+		Repo:   repo,
+		Config: cfg,
+	}
+}
+
+// CheckReuse compares password with the current hash of user and their
+// recorded ones. Users from before the history was kept only have the
+// current one.
+func (s *ServicePasswordHistory) CheckReuse(user domainuser.Users, password string) error {
+	if s.Config.Size == 0 {
+		return nil
+	}
+
+	if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil {
+		return ErrPasswordReused
+	}
+
+	entries, err := s.Repo.GetRecent(user.Id, s.Config.Size)
+	if err != nil {
+		return fmt.Errorf("get password history: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.PasswordHash == user.Password {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(entry.PasswordHash), []byte(password)) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// Record keeps hash, dropping entries beyond the history size.
+func (s *ServicePasswordHistory) Record(userId, hash string) error {
+	if s.Config.Size == 0 {
+		return nil
+	}
+
+	return s.Repo.Store(domainpasswordhistory.Entry{
+		Id:           utils.CreateUUID(),
+		UserId:       userId,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}, s.Config.Size)
+}
+
+// Expired counts the password's age from its last change, or from when the
+// account was created if it has never changed.
+func (s *ServicePasswordHistory) Expired(user domainuser.Users) bool {
+	if s.Config.MaxAge <= 0 {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > s.Config.MaxAge
+}
+
+var _ interfacepasswordhistory.ServicePasswordHistoryInterface = (*ServicePasswordHistory)(nil)
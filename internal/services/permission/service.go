@@ -2,7 +2,9 @@ package servicepermission
 
 import (
 	"errors"
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainpermission "service-sender/internal/domain/permission"
+	domainwebhook "service-sender/internal/domain/webhook"
 	"service-sender/internal/dto"
 	interfacepermission "service-sender/internal/interfaces/permission"
 	"service-sender/pkg/filter"
@@ -36,7 +38,7 @@ func (s *PermissionService) Create(req dto.PermissionCreate) (domainpermission.P
 		CreatedAt:   time.Now(),
 	}
 
-	if err := s.PermissionRepo.Store(data); err != nil {
+	if err := s.PermissionRepo.Store(data, permissionEvent(domainwebhook.EventPermissionCreated, data)); err != nil {
 		return domainpermission.Permission{}, err
 	}
 
@@ -78,7 +80,7 @@ func (s *PermissionService) Update(id string, req dto.PermissionUpdate) (domainp
 	now := time.Now()
 	permission.UpdatedAt = &now
 
-	if err := s.PermissionRepo.Update(permission); err != nil {
+	if err := s.PermissionRepo.Update(permission, permissionEvent(domainwebhook.EventPermissionUpdated, permission)); err != nil {
 		return domainpermission.Permission{}, err
 	}
 
@@ -86,7 +88,18 @@ func (s *PermissionService) Update(id string, req dto.PermissionUpdate) (domainp
 }
 
 func (s *PermissionService) Delete(id string) error {
-	return s.PermissionRepo.Delete(id)
+	event := domainoutbox.NewEvent(domainwebhook.EventPermissionDeleted, domainoutbox.AggregatePermission, id, map[string]interface{}{"id": id})
+	return s.PermissionRepo.Delete(id, event)
+}
+
+// permissionEvent describes a change to p for event consumers.
+func permissionEvent(eventType string, p domainpermission.Permission) domainoutbox.Event {
+	return domainoutbox.NewEvent(eventType, domainoutbox.AggregatePermission, p.Id, map[string]interface{}{
+		"id":       p.Id,
+		"name":     p.Name,
+		"resource": p.Resource,
+		"action":   p.Action,
+	})
 }
 
 var _ interfacepermission.ServicePermissionInterface = (*PermissionService)(nil)
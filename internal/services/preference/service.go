@@ -0,0 +1,94 @@
+package servicepreference
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	domainpreference "service-sender/internal/domain/preference"
+	"service-sender/internal/dto"
+	interfacepreference "service-sender/internal/interfaces/preference"
+)
+
+var ErrPreferenceLocked = errors.New("security notifications cannot be turned off")
+
+type ServicePreference struct {
+	Repo interfacepreference.RepoPreferenceInterface
+}
+
+func NewPreferenceService(repo interfacepreference.RepoPreferenceInterface) *ServicePreference {
+	return &ServicePreference{Repo: repo}
+}
+
+// GetPreferences returns the full category by channel matrix for the user,
+// with defaults filled in for cells the user never changed.
+func (s *ServicePreference) GetPreferences(userId string) ([]dto.PreferenceView, error) {
+	stored, err := s.Repo.GetByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[string]bool, len(stored))
+	for _, p := range stored {
+		enabled[p.Category+":"+p.Channel] = p.Enabled
+	}
+
+	views := make([]dto.PreferenceView, 0, len(domainpreference.Categories)*len(domainpreference.Channels))
+	for _, category := range domainpreference.Categories {
+		for _, channel := range domainpreference.Channels {
+			view := dto.PreferenceView{Category: category, Channel: channel, Enabled: true, Locked: domainpreference.Locked(category)}
+			if v, ok := enabled[category+":"+channel]; ok && !view.Locked {
+				view.Enabled = v
+			}
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+func (s *ServicePreference) UpdatePreferences(userId string, req dto.PreferenceUpdate) ([]dto.PreferenceView, error) {
+	now := time.Now()
+	rows := make([]domainpreference.Preference, 0, len(req.Preferences))
+	index := make(map[string]int, len(req.Preferences))
+	for _, item := range req.Preferences {
+		if domainpreference.Locked(item.Category) {
+			if !*item.Enabled {
+				return nil, ErrPreferenceLocked
+			}
+			continue
+		}
+		// A repeated cell keeps the last value sent.
+		key := item.Category + ":" + item.Channel
+		if i, ok := index[key]; ok {
+			rows[i].Enabled = *item.Enabled
+			continue
+		}
+		index[key] = len(rows)
+		rows = append(rows, domainpreference.Preference{
+			UserId:    userId,
+			Category:  item.Category,
+			Channel:   item.Channel,
+			Enabled:   *item.Enabled,
+			CreatedAt: now,
+			UpdatedAt: &now,
+		})
+	}
+
+	if err := s.Repo.Upsert(rows); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userId)
+}
+
+func (s *ServicePreference) FilterOptedOut(emails []string, category, channel string) ([]string, error) {
+	if domainpreference.Locked(category) {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(email)))
+	}
+	return s.Repo.GetOptedOutEmails(normalized, category, channel)
+}
+
+var _ interfacepreference.ServicePreferenceInterface = (*ServicePreference)(nil)
@@ -0,0 +1,255 @@
+package servicepush
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domainpush "service-sender/internal/domain/push"
+	"service-sender/internal/dto"
+	interfacepush "service-sender/internal/interfaces/push"
+	"service-sender/pkg/config"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/webpush"
+	"service-sender/utils"
+)
+
+var (
+	ErrPushNotConfigured      = errors.New("web push is not configured")
+	ErrPushEndpointNotAllowed = errors.New("push endpoint is not an allowed push service")
+	ErrPushInvalidKeys        = errors.New("push subscription keys are invalid")
+)
+
+// retryQueueSize bounds the sends waiting to be retried. Beyond it, failed
+// sends are dropped.
+const retryQueueSize = 1000
+
+type ServicePush struct {
+	Repo   interfacepush.RepoPushInterface
+	Client *webpush.Client
+	Config config.WebPushConfig
+
+	retries chan retry
+}
+
+// retry is a send to one subscription that failed in a way that may pass.
+type retry struct {
+	sub     domainpush.Subscription
+	payload []byte
+	// attempt is the number of sends made so far.
+	attempt int
+}
+
+// NewPushService returns a service that stores subscriptions either way but
+// only sends when vapid is set.
+func NewPushService(repo interfacepush.RepoPushInterface, vapid *webpush.VAPID, cfg config.WebPushConfig) *ServicePush {
+	s := &ServicePush{Repo: repo, Config: cfg, retries: make(chan retry, retryQueueSize)}
+	if vapid != nil {
+		s.Client = webpush.NewClient(vapid, cfg.TTL)
+	}
+	return s
+}
+
+func (s *ServicePush) PublicKey() (dto.VapidPublicKey, error) {
+	if s.Client == nil {
+		return dto.VapidPublicKey{}, ErrPushNotConfigured
+	}
+	return dto.VapidPublicKey{PublicKey: s.Client.VAPID.PublicKey}, nil
+}
+
+func (s *ServicePush) Subscribe(userId, tokenId, userAgent string, req dto.PushSubscriptionCreate) (domainpush.Subscription, error) {
+	if s.Client == nil {
+		return domainpush.Subscription{}, ErrPushNotConfigured
+	}
+	endpoint := strings.TrimSpace(req.Endpoint)
+	if !s.allowedEndpoint(endpoint) {
+		return domainpush.Subscription{}, ErrPushEndpointNotAllowed
+	}
+	// Encrypting an empty payload checks the keys the way every send will.
+	if _, err := webpush.Encrypt(req.Keys.P256dh, req.Keys.Auth, nil); err != nil {
+		return domainpush.Subscription{}, ErrPushInvalidKeys
+	}
+
+	now := time.Now()
+	data := domainpush.Subscription{
+		Id:        utils.CreateUUID(),
+		UserId:    userId,
+		Endpoint:  endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		TokenId:   tokenId,
+		UserAgent: truncate(userAgent, 500),
+		CreatedAt: now,
+		UpdatedAt: &now,
+	}
+	if req.ExpirationTime != nil && *req.ExpirationTime > 0 {
+		expiresAt := time.UnixMilli(*req.ExpirationTime)
+		data.ExpiresAt = &expiresAt
+	}
+	return s.Repo.Upsert(data)
+}
+
+func (s *ServicePush) Unsubscribe(userId, endpoint string) error {
+	return s.Repo.DeleteByEndpoint(userId, strings.TrimSpace(endpoint))
+}
+
+func (s *ServicePush) GetSubscriptions(userId string) ([]domainpush.Subscription, error) {
+	return s.Repo.GetByUser(userId)
+}
+
+func (s *ServicePush) SendToUser(ctx context.Context, userId string, msg dto.PushMessage) (int, error) {
+	if s.Client == nil {
+		return 0, ErrPushNotConfigured
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	subs, err := s.Repo.GetByUser(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	logPrefix := fmt.Sprintf("[PushService][SendToUser][%s]", userId)
+	now := time.Now()
+	sent := 0
+	var lastErr error
+	for _, sub := range subs {
+		if sub.ExpiresAt != nil && sub.ExpiresAt.Before(now) {
+			s.prune(sub.Id)
+			continue
+		}
+
+		err := s.send(ctx, sub, payload)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, webpush.ErrSubscriptionGone):
+			s.prune(sub.Id)
+		case errors.Is(err, webpush.ErrPayloadTooLarge):
+			return 0, err
+		case webpush.Retryable(err) && s.Config.MaxRetries > 0:
+			// Retries wait on the push service, so they happen in Run
+			// rather than while the caller waits.
+			if s.schedule(retry{sub: sub, payload: payload, attempt: 1}, err) {
+				sent++
+				continue
+			}
+			lastErr = err
+		default:
+			lastErr = err
+			logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("%s; send to subscription %s failed: %v", logPrefix, sub.Id, err))
+		}
+	}
+
+	if sent == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return sent, nil
+}
+
+// Run retries the sends SendToUser queued until ctx is done. Sends still
+// waiting then are dropped.
+func (s *ServicePush) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r := <-s.retries:
+			s.retry(ctx, r)
+		}
+	}
+}
+
+func (s *ServicePush) retry(ctx context.Context, r retry) {
+	logPrefix := fmt.Sprintf("[PushService][retry][%s]", r.sub.Id)
+	err := s.send(ctx, r.sub, r.payload)
+	r.attempt++
+	switch {
+	case err == nil:
+	case errors.Is(err, webpush.ErrSubscriptionGone):
+		s.prune(r.sub.Id)
+	case webpush.Retryable(err) && r.attempt <= s.Config.MaxRetries:
+		s.schedule(r, err)
+	default:
+		logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("%s; giving up after %d attempts: %v", logPrefix, r.attempt, err))
+	}
+}
+
+func (s *ServicePush) send(ctx context.Context, sub domainpush.Subscription, payload []byte) error {
+	err := s.Client.Send(ctx, webpush.Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, payload, webpush.UrgencyNormal)
+	if err == nil {
+		if err := s.Repo.MarkSuccess(sub.Id, time.Now()); err != nil {
+			logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("[PushService][send]; MarkSuccess %s error: %v", sub.Id, err))
+		}
+	}
+	return err
+}
+
+// schedule queues r for Run once the delay the push service asked for, or
+// else the backoff for its attempt, has passed. It reports false when the
+// queue is full.
+func (s *ServicePush) schedule(r retry, err error) bool {
+	if len(s.retries) == cap(s.retries) {
+		logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("[PushService][schedule]; retry queue full, dropping send to %s: %v", r.sub.Id, err))
+		return false
+	}
+
+	wait := s.Config.RetryBackoff << (r.attempt - 1)
+	var statusErr *webpush.StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		wait = statusErr.RetryAfter
+	}
+	time.AfterFunc(wait, func() {
+		select {
+		case s.retries <- r:
+		default:
+			logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("[PushService][schedule]; retry queue full, dropping send to %s", r.sub.Id))
+		}
+	})
+	return true
+}
+
+func (s *ServicePush) prune(id string) {
+	if err := s.Repo.Delete(id); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[PushService][prune]; Delete %s error: %v", id, err))
+	}
+}
+
+// allowedEndpoint admits https endpoints on a configured push service host,
+// since the service makes requests to whatever URL users register.
+func (s *ServicePush) allowedEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && s.Config.AllowHTTP) {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range s.Config.AllowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+var _ interfacepush.ServicePushInterface = (*ServicePush)(nil)
@@ -0,0 +1,198 @@
+package servicepush
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domainpush "service-sender/internal/domain/push"
+	"service-sender/internal/dto"
+	"service-sender/pkg/config"
+	"service-sender/pkg/webpush"
+)
+
+type fakeRepo struct {
+	mu        sync.Mutex
+	subs      []domainpush.Subscription
+	deleted   []string
+	succeeded []string
+}
+
+func (r *fakeRepo) Upsert(m domainpush.Subscription) (domainpush.Subscription, error) {
+	return m, nil
+}
+
+func (r *fakeRepo) GetByUser(userId string) ([]domainpush.Subscription, error) {
+	return r.subs, nil
+}
+
+func (r *fakeRepo) DeleteByEndpoint(userId, endpoint string) error {
+	return nil
+}
+
+func (r *fakeRepo) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *fakeRepo) MarkSuccess(id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.succeeded = append(r.succeeded, id)
+	return nil
+}
+
+func (r *fakeRepo) snapshot() (deleted, succeeded []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.deleted...), append([]string(nil), r.succeeded...)
+}
+
+func newTestService(t *testing.T, repo *fakeRepo) *ServicePush {
+	t.Helper()
+	public, private, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys: %v", err)
+	}
+	vapid, err := webpush.NewVAPID(public, private, "mailto:ops@example.test")
+	if err != nil {
+		t.Fatalf("NewVAPID: %v", err)
+	}
+	return NewPushService(repo, vapid, config.WebPushConfig{
+		TTL:          time.Hour,
+		MaxRetries:   2,
+		RetryBackoff: 10 * time.Millisecond,
+	})
+}
+
+func subscription(id, endpoint string) domainpush.Subscription {
+	return domainpush.Subscription{
+		Id:       id,
+		UserId:   "u-1",
+		Endpoint: endpoint,
+		P256dh:   "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:     "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+}
+
+func TestSendToUserPrunesGoneSubscriptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusCreated)
+		case "/expired":
+			w.WriteHeader(http.StatusGone)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	past := time.Now().Add(-time.Hour)
+	lapsed := subscription("lapsed", srv.URL+"/ok")
+	lapsed.ExpiresAt = &past
+	repo := &fakeRepo{subs: []domainpush.Subscription{
+		subscription("ok", srv.URL+"/ok"),
+		subscription("expired", srv.URL+"/expired"),
+		subscription("unknown", srv.URL+"/unknown"),
+		lapsed,
+	}}
+	svc := newTestService(t, repo)
+
+	sent, err := svc.SendToUser(context.Background(), "u-1", dto.PushMessage{Title: "hi"})
+	if err != nil {
+		t.Fatalf("SendToUser: %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+	deleted, succeeded := repo.snapshot()
+	if len(deleted) != 3 || deleted[0] != "expired" || deleted[1] != "unknown" || deleted[2] != "lapsed" {
+		t.Errorf("deleted = %v", deleted)
+	}
+	if len(succeeded) != 1 || succeeded[0] != "ok" {
+		t.Errorf("succeeded = %v", succeeded)
+	}
+}
+
+func TestSendToUserRetriesInBackground(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{subs: []domainpush.Subscription{subscription("busy", srv.URL)}}
+	svc := newTestService(t, repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx)
+
+	start := time.Now()
+	sent, err := svc.SendToUser(ctx, "u-1", dto.PushMessage{Title: "hi"})
+	if err != nil {
+		t.Fatalf("SendToUser: %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("sent = %d, want the queued retry counted", sent)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("SendToUser waited %s for the retry", elapsed)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, succeeded := repo.snapshot(); len(succeeded) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("retry not delivered; push service saw %d requests", calls.Load())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	// The retry honoured Retry-After.
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, before Retry-After", elapsed)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("push service saw %d requests, want 2", n)
+	}
+}
+
+func TestSendToUserGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{subs: []domainpush.Subscription{subscription("down", srv.URL)}}
+	svc := newTestService(t, repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx)
+
+	if _, err := svc.SendToUser(ctx, "u-1", dto.PushMessage{Title: "hi"}); err != nil {
+		t.Fatalf("SendToUser: %v", err)
+	}
+	// One send and MaxRetries retries, 10ms and 20ms apart.
+	time.Sleep(300 * time.Millisecond)
+	if n := calls.Load(); n != 3 {
+		t.Errorf("push service saw %d requests, want 3", n)
+	}
+	if deleted, succeeded := repo.snapshot(); len(deleted) != 0 || len(succeeded) != 0 {
+		t.Errorf("deleted = %v, succeeded = %v", deleted, succeeded)
+	}
+}
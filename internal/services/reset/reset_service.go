@@ -11,19 +11,50 @@ import (
 	"strings"
 	"time"
 
+	domainoutbox "service-sender/internal/domain/outbox"
+	domainwebhook "service-sender/internal/domain/webhook"
+	interfaceotp "service-sender/internal/interfaces/otp"
+	interfacepasswordhistory "service-sender/internal/interfaces/passwordhistory"
 	interfacereset "service-sender/internal/interfaces/reset"
+	interfacesession "service-sender/internal/interfaces/session"
+	interfaceuser "service-sender/internal/interfaces/user"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	serviceotp "service-sender/internal/services/otp"
+	servicepasswordhistory "service-sender/internal/services/passwordhistory"
 	"service-sender/pkg/config"
+	"service-sender/pkg/logger"
 	"service-sender/pkg/mailer"
+	"service-sender/pkg/passwordpolicy"
 
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrResetInvalid        = errors.New("reset token invalid or expired")
 	ErrResetNotConfigured  = errors.New("password reset service not configured")
 	ErrResetDeliveryFailed = errors.New("password reset delivery failed")
+	ErrResetModeNotAllowed = errors.New("password reset mode not allowed for this app")
+	ErrResetTooManyAttempt = errors.New("password reset code too many attempts")
+	// ErrSessionsNotRevoked means the password was changed but the user's
+	// existing sessions could not be signed out.
+	ErrSessionsNotRevoked = errors.New("password reset sessions not revoked")
 )
 
+// WeakPasswordError is returned when the new password breaks the password
+// policy or was used recently. The token is not used up.
+type WeakPasswordError struct {
+	Err error
+}
+
+func (e *WeakPasswordError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *WeakPasswordError) Unwrap() error {
+	return e.Err
+}
+
 type ThrottleError struct {
 	Reason     string
 	RetryAfter time.Duration
@@ -39,27 +70,104 @@ func (e *ThrottleError) Error() string {
 type ServiceReset struct {
 	Repo   interfacereset.RepoPasswordResetInterface
 	Sender mailer.PasswordResetSender
+	Users  interfaceuser.RepoUserInterface
+	// Sessions revokes every session of a user whose password was reset,
+	// blacklisting their tokens. It may be nil when sessions are not kept.
+	Sessions interfacesession.ServiceSessionInterface
+	// OTP issues and checks the codes of the code mode. It may be nil, in
+	// which case only links work.
+	OTP    interfaceotp.ServiceOTPInterface
 	Config config.PasswordResetConfig
+	// Events receives reset requests and verifications for webhook and NATS
+	// subscribers. It may be nil.
+	Events interfacewebhook.PublisherInterface
+	// Policy checks the new password against the policy of the user's role.
+	// A nil Policy applies the default rules.
+	Policy *passwordpolicy.Engine
+	// History rejects the user's recent passwords. It may be nil.
+	History interfacepasswordhistory.ServicePasswordHistoryInterface
 }
 
-func NewPasswordResetService(repo interfacereset.RepoPasswordResetInterface, sender mailer.PasswordResetSender, cfg config.PasswordResetConfig) *ServiceReset {
+func NewPasswordResetService(repo interfacereset.RepoPasswordResetInterface, sender mailer.PasswordResetSender, users interfaceuser.RepoUserInterface, sessions interfacesession.ServiceSessionInterface, otp interfaceotp.ServiceOTPInterface, cfg config.PasswordResetConfig, events interfacewebhook.PublisherInterface, policy *passwordpolicy.Engine, history interfacepasswordhistory.ServicePasswordHistoryInterface) *ServiceReset {
 	return &ServiceReset{
-		Repo:   repo,
-		Sender: sender,
-		Config: cfg,
+		Repo:     repo,
+		Sender:   sender,
+		Users:    users,
+		Sessions: sessions,
+		OTP:      otp,
+		Config:   cfg,
+		Events:   events,
+		Policy:   policy,
+		History:  history,
 	}
 }
 
-func (s *ServiceReset) RequestReset(ctx context.Context, email, appName string) error {
+// RequestReset sends a reset link or code to email and returns the mode used.
+// mode may be empty for the app's configured mode; apps allowing both get a
+// link unless a code is asked for. locale comes from the request; when empty
+// the user's profile locale is used, and the sender applies the default after
+// that.
+func (s *ServiceReset) RequestReset(ctx context.Context, email, appName, locale, mode string) (string, error) {
 	if s == nil || s.Repo == nil || s.Sender == nil {
-		return ErrResetNotConfigured
+		return "", ErrResetNotConfigured
+	}
+
+	mode, err := s.resolveMode(appName, mode)
+	if err != nil {
+		return "", err
 	}
 
 	normalizedEmail := normalizeEmail(email)
 	if normalizedEmail == "" {
-		return ErrResetInvalid
+		return "", ErrResetInvalid
+	}
+
+	if locale == "" && s.Users != nil {
+		if user, err := s.Users.GetByEmail(normalizedEmail); err == nil {
+			locale = user.Locale
+		}
+	}
+
+	if mode == config.ResetModeCode {
+		err = s.requestCode(ctx, normalizedEmail, appName, locale)
+	} else {
+		err = s.requestLink(ctx, normalizedEmail, appName, locale)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.publish(domainwebhook.EventResetRequested, normalizedEmail)
+	return mode, nil
+}
+
+func (s *ServiceReset) RequestLockReset(ctx context.Context, email, appName, locale string) error {
+	if s == nil || s.Repo == nil || s.Sender == nil {
+		return ErrResetNotConfigured
 	}
 
+	// The lock is only reachable through a single-use token, so lifting the
+	// throttle cannot be used to flood the address.
+	normalizedEmail := normalizeEmail(email)
+	if err := s.Repo.ClearCooldown(ctx, normalizedEmail); err != nil {
+		return fmt.Errorf("clear cooldown: %w", err)
+	}
+	if err := s.Repo.ClearSendCount(ctx, normalizedEmail); err != nil {
+		return fmt.Errorf("clear rate limit: %w", err)
+	}
+	if s.OTP != nil {
+		if err := s.OTP.ClearThrottle(ctx, serviceotp.PurposeReset, normalizedEmail); err != nil {
+			return fmt.Errorf("clear code throttle: %w", err)
+		}
+	}
+
+	_, err := s.RequestReset(ctx, normalizedEmail, appName, locale, "")
+	return err
+}
+
+// requestLink emails a one-time token, inside a link when a URL template is
+// configured.
+func (s *ServiceReset) requestLink(ctx context.Context, normalizedEmail, appName, locale string) error {
 	cooldownTTL, err := s.Repo.GetCooldownTTL(ctx, normalizedEmail)
 	if err != nil {
 		return fmt.Errorf("check cooldown: %w", err)
@@ -96,16 +204,93 @@ func (s *ServiceReset) RequestReset(ctx context.Context, email, appName string)
 	}
 
 	resetURL := buildResetURL(s.Config.URLTemplate, token)
-	if err := s.Sender.SendPasswordReset(normalizedEmail, token, appName, resetURL, s.Config.TTL); err != nil {
+	if err := s.Sender.SendPasswordReset(normalizedEmail, token, appName, locale, resetURL, s.Config.TTL); err != nil {
 		_ = s.Repo.DeleteToken(ctx, hash)
 		_ = s.Repo.ClearCooldown(ctx, normalizedEmail)
 		_ = s.Repo.ClearSendCount(ctx, normalizedEmail)
 		return ErrResetDeliveryFailed
 	}
-
 	return nil
 }
 
+// requestCode emails a 6-digit code through the OTP service, which applies
+// its own attempts, cooldown and rate limit to reset codes.
+func (s *ServiceReset) requestCode(ctx context.Context, normalizedEmail, appName, locale string) error {
+	if s.OTP == nil {
+		return ErrResetNotConfigured
+	}
+
+	err := s.OTP.Issue(ctx, serviceotp.PurposeReset, normalizedEmail, func(to, code string, ttl time.Duration) error {
+		return s.Sender.SendPasswordReset(to, code, appName, locale, "", ttl)
+	})
+	if throttle := new(serviceotp.ThrottleError); errors.As(err, &throttle) {
+		return &ThrottleError{Reason: throttle.Reason, RetryAfter: throttle.RetryAfter}
+	}
+	switch {
+	case errors.Is(err, serviceotp.ErrOTPInvalid):
+		return ErrResetInvalid
+	case errors.Is(err, serviceotp.ErrOTPNotConfigured):
+		return ErrResetNotConfigured
+	case errors.Is(err, serviceotp.ErrOTPDeliveryFailed):
+		return ErrResetDeliveryFailed
+	}
+	return err
+}
+
+// VerifyResetCode checks a code sent by RequestReset and exchanges it for a
+// ticket. The ticket is used like a link token with CompleteReset, but
+// expires after Config.TicketTTL.
+func (s *ServiceReset) VerifyResetCode(ctx context.Context, email, code, appName string) (string, error) {
+	if s == nil || s.Repo == nil || s.OTP == nil {
+		return "", ErrResetNotConfigured
+	}
+	if !s.Config.Allows(appName, config.ResetModeCode) {
+		return "", ErrResetModeNotAllowed
+	}
+
+	normalizedEmail := normalizeEmail(email)
+	if err := s.OTP.Verify(ctx, serviceotp.PurposeReset, normalizedEmail, code); err != nil {
+		switch {
+		case errors.Is(err, serviceotp.ErrOTPInvalid):
+			return "", ErrResetInvalid
+		case errors.Is(err, serviceotp.ErrOTPTooManyAttempt):
+			return "", ErrResetTooManyAttempt
+		case errors.Is(err, serviceotp.ErrOTPNotConfigured):
+			return "", ErrResetNotConfigured
+		}
+		return "", err
+	}
+
+	ticket, err := generateResetToken()
+	if err != nil {
+		return "", fmt.Errorf("generate ticket: %w", err)
+	}
+	if err := s.Repo.SetToken(ctx, hashToken(ticket, s.Config.Secret), normalizedEmail, s.Config.TicketTTL); err != nil {
+		return "", fmt.Errorf("store ticket: %w", err)
+	}
+
+	s.publish(domainwebhook.EventResetVerified, normalizedEmail)
+	return ticket, nil
+}
+
+// resolveMode checks mode against what appName allows, filling in the
+// app's mode when none is asked for.
+func (s *ServiceReset) resolveMode(appName, mode string) (string, error) {
+	configured := s.Config.ModeFor(appName)
+	if mode == "" {
+		if configured == config.ResetModeBoth {
+			return config.ResetModeLink, nil
+		}
+		return configured, nil
+	}
+	if !s.Config.Allows(appName, mode) {
+		return "", ErrResetModeNotAllowed
+	}
+	return mode, nil
+}
+
+// VerifyReset returns the email a token was issued for, so a reset form can
+// be shown. The token stays valid until CompleteReset uses it.
 func (s *ServiceReset) VerifyReset(ctx context.Context, token string) (string, error) {
 	if s == nil || s.Repo == nil {
 		return "", ErrResetNotConfigured
@@ -116,8 +301,7 @@ func (s *ServiceReset) VerifyReset(ctx context.Context, token string) (string, e
 		return "", ErrResetInvalid
 	}
 
-	hash := hashToken(cleanToken, s.Config.Secret)
-	email, err := s.Repo.GetEmailByToken(ctx, hash)
+	email, err := s.Repo.GetEmailByToken(ctx, hashToken(cleanToken, s.Config.Secret))
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return "", ErrResetInvalid
@@ -125,11 +309,104 @@ func (s *ServiceReset) VerifyReset(ctx context.Context, token string) (string, e
 		return "", fmt.Errorf("get token: %w", err)
 	}
 
-	_ = s.Repo.DeleteToken(ctx, hash)
+	s.publish(domainwebhook.EventResetVerified, email)
+	return email, nil
+}
+
+// CompleteReset uses up token, from a link or VerifyResetCode, and sets the user's password to newPassword.
+// Every session the user had is then revoked, so a stolen login does not
+// outlive the reset, and an account locked from a security alert is
+// unlocked.
+func (s *ServiceReset) CompleteReset(ctx context.Context, token, newPassword string) error {
+	if s == nil || s.Repo == nil || s.Users == nil {
+		return ErrResetNotConfigured
+	}
+
+	cleanToken := strings.TrimSpace(token)
+	if cleanToken == "" {
+		return ErrResetInvalid
+	}
+
+	// The policy depends on whose password it is, so the token is looked at
+	// first and only used up once the password is accepted.
+	tokenHash := hashToken(cleanToken, s.Config.Secret)
+	email, err := s.Repo.GetEmailByToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrResetInvalid
+		}
+		return fmt.Errorf("get token: %w", err)
+	}
+
+	user, err := s.Users.GetByEmail(email)
+	if err != nil {
+		return ErrResetInvalid
+	}
+
+	if err := s.Policy.Validate(newPassword, user.Role, passwordpolicy.Subject{Email: user.Email, Name: user.Name}); err != nil {
+		return &WeakPasswordError{Err: err}
+	}
+	if s.History != nil {
+		if err := s.History.CheckReuse(user, newPassword); errors.Is(err, servicepasswordhistory.ErrPasswordReused) {
+			return &WeakPasswordError{Err: err}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.Repo.ConsumeToken(ctx, tokenHash); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrResetInvalid
+		}
+		return fmt.Errorf("consume token: %w", err)
+	}
 	_ = s.Repo.ClearCooldown(ctx, email)
 	_ = s.Repo.ClearSendCount(ctx, email)
 
-	return email, nil
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	now := time.Now()
+	user.Password = string(hashed)
+	user.PasswordChangedAt = &now
+	user.LockedAt = nil
+
+	event := domainoutbox.NewEvent(domainwebhook.EventResetCompleted, domainoutbox.AggregateUser, user.Id, map[string]interface{}{
+		"id":    user.Id,
+		"name":  user.Name,
+		"email": user.Email,
+		"role":  user.Role,
+	})
+	if err := s.Users.Update(user, event); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	if s.History != nil {
+		if err := s.History.Record(user.Id, user.Password); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceReset][CompleteReset]; Record %s error: %v", user.Id, err))
+		}
+	}
+
+	if s.Sessions != nil {
+		// A reset is often how a hijacked account is recovered, so sessions
+		// left signed in must not be reported as a clean success.
+		err := s.Sessions.DestroyAllUserSessions(ctx, user.Id)
+		if err != nil {
+			logger.WriteLog(logger.LogLevelWarn, fmt.Sprintf("[ServiceReset][CompleteReset]; DestroyAllUserSessions %s error, retrying: %v", user.Id, err))
+			err = s.Sessions.DestroyAllUserSessions(ctx, user.Id)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrSessionsNotRevoked, err)
+		}
+	}
+	return nil
+}
+
+// publish never carries the token; subscribers only learn whose reset it was.
+func (s *ServiceReset) publish(eventType, email string) {
+	if s.Events != nil {
+		s.Events.Publish(eventType, map[string]interface{}{"email": email})
+	}
 }
 
 func normalizeEmail(email string) string {
@@ -2,7 +2,9 @@ package servicerole
 
 import (
 	"errors"
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainrole "service-sender/internal/domain/role"
+	domainwebhook "service-sender/internal/domain/webhook"
 	"service-sender/internal/dto"
 	interfacemenu "service-sender/internal/interfaces/menu"
 	interfacepermission "service-sender/internal/interfaces/permission"
@@ -45,7 +47,7 @@ func (s *RoleService) Create(req dto.RoleCreate) (domainrole.Role, error) {
 		CreatedAt:   time.Now(),
 	}
 
-	if err := s.RoleRepo.Store(data); err != nil {
+	if err := s.RoleRepo.Store(data, roleEvent(domainwebhook.EventRoleCreated, data)); err != nil {
 		return domainrole.Role{}, err
 	}
 
@@ -127,7 +129,7 @@ func (s *RoleService) Update(id string, req dto.RoleUpdate) (domainrole.Role, er
 	now := time.Now()
 	role.UpdatedAt = &now
 
-	if err := s.RoleRepo.Update(role); err != nil {
+	if err := s.RoleRepo.Update(role, roleEvent(domainwebhook.EventRoleUpdated, role)); err != nil {
 		return domainrole.Role{}, err
 	}
 
@@ -144,7 +146,7 @@ func (s *RoleService) Delete(id string) error {
 		return errors.New("cannot delete system roles")
 	}
 
-	return s.RoleRepo.Delete(id)
+	return s.RoleRepo.Delete(id, roleEvent(domainwebhook.EventRoleDeleted, role))
 }
 
 func (s *RoleService) AssignPermissions(roleId string, req dto.AssignPermissions, currentUserRole string) error {
@@ -169,7 +171,9 @@ func (s *RoleService) AssignPermissions(roleId string, req dto.AssignPermissions
 		}
 	}
 
-	return s.RoleRepo.AssignPermissions(roleId, req.PermissionIds)
+	event := roleEvent(domainwebhook.EventRolePermissionsChanged, role)
+	event.Payload["permission_ids"] = req.PermissionIds
+	return s.RoleRepo.AssignPermissions(roleId, req.PermissionIds, event)
 }
 
 func (s *RoleService) AssignMenus(roleId string, req dto.AssignMenus, currentUserRole string) error {
@@ -194,7 +198,9 @@ func (s *RoleService) AssignMenus(roleId string, req dto.AssignMenus, currentUse
 		}
 	}
 
-	return s.RoleRepo.AssignMenus(roleId, req.MenuIds)
+	event := roleEvent(domainwebhook.EventRoleMenusChanged, role)
+	event.Payload["menu_ids"] = req.MenuIds
+	return s.RoleRepo.AssignMenus(roleId, req.MenuIds, event)
 }
 
 func (s *RoleService) GetRolePermissions(roleId string) ([]string, error) {
@@ -205,4 +211,14 @@ func (s *RoleService) GetRoleMenus(roleId string) ([]string, error) {
 	return s.RoleRepo.GetRoleMenus(roleId)
 }
 
+// roleEvent describes a change to r for event consumers.
+func roleEvent(eventType string, r domainrole.Role) domainoutbox.Event {
+	return domainoutbox.NewEvent(eventType, domainoutbox.AggregateRole, r.Id, map[string]interface{}{
+		"id":           r.Id,
+		"name":         r.Name,
+		"display_name": r.DisplayName,
+		"is_system":    r.IsSystem,
+	})
+}
+
 var _ interfacerole.ServiceRoleInterface = (*RoleService)(nil)
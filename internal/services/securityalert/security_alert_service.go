@@ -0,0 +1,351 @@
+package servicesecurityalert
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domainoutbox "service-sender/internal/domain/outbox"
+	domainsecurityalert "service-sender/internal/domain/securityalert"
+	domainuser "service-sender/internal/domain/user"
+	domainwebhook "service-sender/internal/domain/webhook"
+	interfacereset "service-sender/internal/interfaces/reset"
+	interfacesecurityalert "service-sender/internal/interfaces/securityalert"
+	interfacesession "service-sender/internal/interfaces/session"
+	interfaceuser "service-sender/internal/interfaces/user"
+	"service-sender/pkg/config"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/mailer"
+	"service-sender/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLockInvalid        = errors.New("lock link invalid or expired")
+	ErrAlertNotConfigured = errors.New("security alert service not configured")
+	// ErrLockEmailTaken is returned, leaving the link unused, when the
+	// address an email change is being undone to now belongs to another
+	// account.
+	ErrLockEmailTaken = errors.New("previous email address belongs to another account")
+	// ErrLockResetNotSent is returned when the account was locked but the
+	// password reset that unlocks it could not be sent.
+	ErrLockResetNotSent = errors.New("account locked but password reset not sent")
+)
+
+// outboxConsumer is the name the relay tracks this service's progress under.
+const outboxConsumer = "security_alert"
+
+// alertTypes maps the relayed events users are alerted to onto their alert
+// type.
+var alertTypes = map[string]string{
+	domainwebhook.EventUserPasswordChanged: domainsecurityalert.TypePasswordChanged,
+	domainwebhook.EventResetCompleted:      domainsecurityalert.TypePasswordReset,
+	domainwebhook.EventUserEmailChanged:    domainsecurityalert.TypeEmailChanged,
+	domainwebhook.EventUserRoleChanged:     domainsecurityalert.TypeRoleChanged,
+	domainwebhook.EventUserDeleted:         domainsecurityalert.TypeAccountDeleted,
+}
+
+// ServiceSecurityAlert emails users when their password, email or role
+// changes, their account is deleted or they log in from a new device. Each
+// alert but the deletion notice carries an "it wasn't me" link that locks
+// the account until its password is reset.
+type ServiceSecurityAlert struct {
+	Repo    interfacesecurityalert.RepoAlertInterface
+	Devices interfacesecurityalert.RepoDeviceInterface
+	Users   interfaceuser.RepoUserInterface
+	Sender  mailer.SecurityAlertSender
+	// Sessions revokes the sessions of a locked account and Resets sends
+	// it a password reset. Either may be nil, leaving that step out.
+	Sessions interfacesession.ServiceSessionInterface
+	Resets   interfacereset.ServicePasswordResetInterface
+	Config   config.SecurityAlertConfig
+}
+
+func NewSecurityAlertService(repo interfacesecurityalert.RepoAlertInterface, devices interfacesecurityalert.RepoDeviceInterface, users interfaceuser.RepoUserInterface, sender mailer.SecurityAlertSender, sessions interfacesession.ServiceSessionInterface, resets interfacereset.ServicePasswordResetInterface, cfg config.SecurityAlertConfig) *ServiceSecurityAlert {
+	return &ServiceSecurityAlert{
+		Repo:     repo,
+		Devices:  devices,
+		Users:    users,
+		Sender:   sender,
+		Sessions: sessions,
+		Resets:   resets,
+		Config:   cfg,
+	}
+}
+
+// Name identifies the service as an outbox consumer.
+func (s *ServiceSecurityAlert) Name() string {
+	return outboxConsumer
+}
+
+// Consume alerts the user an outbox event is about. An alert already sent
+// for the event is not sent again; one whose sending failed is retried with
+// a fresh lock token.
+func (s *ServiceSecurityAlert) Consume(event domainoutbox.Event) error {
+	alertType, ok := alertTypes[event.Type]
+	if !ok || !s.Config.Enabled || s.Sender == nil {
+		return nil
+	}
+
+	alert, err := s.Repo.GetByEventId(event.Id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if alert.SentAt != nil {
+		return nil
+	}
+
+	eventId := event.Id
+	alert.EventId = &eventId
+	alert.UserId = event.AggregateId
+	alert.Type = alertType
+	alert.AppName = s.Config.AppName
+
+	notice := mailer.SecurityAlert{Type: alertType, OccurredAt: event.CreatedAt}
+	var locale string
+	if alertType == domainsecurityalert.TypeAccountDeleted {
+		// The user is gone; the event carries what is left of them.
+		alert.Email = payloadString(event.Payload, "email")
+		notice.Name = payloadString(event.Payload, "name")
+	} else {
+		user, err := s.Users.GetByID(event.AggregateId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		alert.Email = user.Email
+		notice.Name = user.Name
+		locale = user.Locale
+	}
+
+	switch alertType {
+	case domainsecurityalert.TypeEmailChanged:
+		// The old address is the one an intruder cannot read.
+		alert.Email = payloadString(event.Payload, "previous_email")
+		notice.Previous = alert.Email
+		notice.Current = payloadString(event.Payload, "email")
+	case domainsecurityalert.TypeRoleChanged:
+		notice.Previous = payloadString(event.Payload, "previous_role")
+		notice.Current = payloadString(event.Payload, "role")
+	}
+	if alert.Email == "" {
+		return nil
+	}
+
+	alert.Metadata = map[string]interface{}{"event_type": event.Type}
+	if notice.Previous != "" {
+		alert.Metadata["previous"] = notice.Previous
+		alert.Metadata["current"] = notice.Current
+	}
+
+	return s.send(alert, notice, locale, alertType != domainsecurityalert.TypeAccountDeleted)
+}
+
+// CheckLogin alerts user to a login from a User-Agent they have not logged
+// in with before. The first device a user logs in from is only recorded.
+func (s *ServiceSecurityAlert) CheckLogin(user domainuser.Users, ip, userAgent, appName string) error {
+	if !s.Config.Enabled || !s.Config.NewDevice || s.Devices == nil {
+		return nil
+	}
+
+	known, err := s.Devices.CountByUser(user.Id)
+	if err != nil {
+		return fmt.Errorf("count devices: %w", err)
+	}
+
+	userAgent = strings.TrimSpace(userAgent)
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+	now := time.Now()
+	created, err := s.Devices.Touch(domainsecurityalert.Device{
+		Id:          utils.CreateUUID(),
+		UserId:      user.Id,
+		Fingerprint: hashValue(userAgent, ""),
+		UserAgent:   userAgent,
+		IP:          ip,
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	})
+	if err != nil {
+		return fmt.Errorf("record device: %w", err)
+	}
+	if !created || known == 0 || s.Sender == nil {
+		return nil
+	}
+
+	if appName == "" {
+		appName = s.Config.AppName
+	}
+	alert := domainsecurityalert.Alert{
+		UserId:   user.Id,
+		Type:     domainsecurityalert.TypeNewDevice,
+		Email:    user.Email,
+		AppName:  appName,
+		Metadata: map[string]interface{}{"ip": ip, "user_agent": userAgent},
+	}
+	notice := mailer.SecurityAlert{
+		Type:       domainsecurityalert.TypeNewDevice,
+		Name:       user.Name,
+		OccurredAt: now,
+		IP:         ip,
+		Device:     userAgent,
+	}
+	return s.send(alert, notice, user.Locale, true)
+}
+
+// send stores alert, with a new lock token when lockable, and emails it.
+func (s *ServiceSecurityAlert) send(alert domainsecurityalert.Alert, notice mailer.SecurityAlert, locale string, lockable bool) error {
+	alert.LockTokenHash = nil
+	alert.LockExpiresAt = nil
+	if lockable && s.Config.LockURLTemplate != "" {
+		token, err := generateLockToken()
+		if err != nil {
+			return fmt.Errorf("generate lock token: %w", err)
+		}
+		hash := hashValue(token, s.Config.Secret)
+		expiresAt := time.Now().Add(s.Config.LockTTL)
+		alert.LockTokenHash = &hash
+		alert.LockExpiresAt = &expiresAt
+		notice.LockURL = buildLockURL(s.Config.LockURLTemplate, token)
+		notice.LockTTL = s.Config.LockTTL
+	}
+
+	if alert.Id == "" {
+		alert.Id = utils.CreateUUID()
+		alert.CreatedAt = time.Now()
+		if err := s.Repo.Store(alert); err != nil {
+			return fmt.Errorf("store alert: %w", err)
+		}
+	} else if err := s.Repo.Update(alert); err != nil {
+		return fmt.Errorf("update alert: %w", err)
+	}
+
+	if err := s.Sender.SendSecurityAlert(alert.Email, alert.AppName, locale, notice); err != nil {
+		return fmt.Errorf("send %s alert: %w", alert.Type, err)
+	}
+	if err := s.Repo.MarkSent(alert.Id, time.Now()); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceSecurityAlert][send]; MarkSent %s error: %v", alert.Id, err))
+	}
+	return nil
+}
+
+// Lock uses up the lock token of an alert. The account is locked until its
+// password is reset; an email change being reported is undone first, so
+// the reset reaches the address that was alerted.
+func (s *ServiceSecurityAlert) Lock(ctx context.Context, token string) error {
+	if s == nil || s.Repo == nil || s.Users == nil {
+		return ErrAlertNotConfigured
+	}
+
+	cleanToken := strings.TrimSpace(token)
+	if cleanToken == "" {
+		return ErrLockInvalid
+	}
+
+	alert, err := s.Repo.GetByTokenHash(hashValue(cleanToken, s.Config.Secret))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLockInvalid
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if alert.UsedAt != nil || alert.LockExpiresAt == nil || now.After(*alert.LockExpiresAt) {
+		return ErrLockInvalid
+	}
+
+	user, err := s.Users.GetByID(alert.UserId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLockInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	revert := alert.Type == domainsecurityalert.TypeEmailChanged && !strings.EqualFold(user.Email, alert.Email)
+	if revert {
+		other, err := s.Users.GetByEmail(alert.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && other.Id != user.Id {
+			return ErrLockEmailTaken
+		}
+	}
+
+	if err := s.Repo.MarkUsed(alert.Id, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLockInvalid
+		}
+		return err
+	}
+
+	if revert {
+		user.Email = alert.Email
+	}
+	user.LockedAt = &now
+
+	event := domainoutbox.NewEvent(domainwebhook.EventUserLocked, domainoutbox.AggregateUser, user.Id, map[string]interface{}{
+		"id":         user.Id,
+		"name":       user.Name,
+		"email":      user.Email,
+		"role":       user.Role,
+		"alert_type": alert.Type,
+	})
+	if err := s.Users.Update(user, event); err != nil {
+		return fmt.Errorf("lock account: %w", err)
+	}
+
+	if s.Sessions != nil {
+		if err := s.Sessions.DestroyAllUserSessions(ctx, user.Id); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceSecurityAlert][Lock]; DestroyAllUserSessions %s error: %v", user.Id, err))
+		}
+	}
+	if s.Resets != nil {
+		if err := s.Resets.RequestLockReset(ctx, alert.Email, alert.AppName, user.Locale); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceSecurityAlert][Lock]; RequestLockReset %s error: %v", user.Id, err))
+			return fmt.Errorf("%w: %v", ErrLockResetNotSent, err)
+		}
+	}
+	return nil
+}
+
+func payloadString(payload map[string]interface{}, key string) string {
+	v, _ := payload[key].(string)
+	return v
+}
+
+func generateLockToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashValue(value, secret string) string {
+	h := sha256.Sum256([]byte(value + secret))
+	return hex.EncodeToString(h[:])
+}
+
+func buildLockURL(template, token string) string {
+	if strings.Contains(template, "{token}") {
+		return strings.ReplaceAll(template, "{token}", token)
+	}
+	if strings.Contains(template, "?") {
+		return template + "&token=" + token
+	}
+	return template + "?token=" + token
+}
+
+var _ interfacesecurityalert.ServiceSecurityAlertInterface = (*ServiceSecurityAlert)(nil)
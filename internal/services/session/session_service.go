@@ -3,9 +3,13 @@ package servicesession
 import (
 	"context"
 	"fmt"
+	domainauth "service-sender/internal/domain/auth"
 	domainsession "service-sender/internal/domain/session"
 	domainuser "service-sender/internal/domain/user"
+	domainwebhook "service-sender/internal/domain/webhook"
+	interfaceauth "service-sender/internal/interfaces/auth"
 	interfacesession "service-sender/internal/interfaces/session"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
 	"service-sender/utils"
 	"time"
 
@@ -15,11 +19,19 @@ import (
 
 type ServiceSession struct {
 	SessionRepo interfacesession.RepoSessionInterface
+	// BlacklistRepo receives the token of every revoked session so it stops
+	// authenticating before it expires. It may be nil.
+	BlacklistRepo interfaceauth.RepoAuthInterface
+	// Events receives session.revoked when sessions are revoked, not when
+	// they expire or the user logs out. It may be nil.
+	Events interfacewebhook.PublisherInterface
 }
 
-func NewSessionService(sessionRepo interfacesession.RepoSessionInterface) *ServiceSession {
+func NewSessionService(sessionRepo interfacesession.RepoSessionInterface, blacklistRepo interfaceauth.RepoAuthInterface, events interfacewebhook.PublisherInterface) *ServiceSession {
 	return &ServiceSession{
-		SessionRepo: sessionRepo,
+		SessionRepo:   sessionRepo,
+		BlacklistRepo: blacklistRepo,
+		Events:        events,
 	}
 }
 
@@ -95,7 +107,11 @@ func (s *ServiceSession) GetUserSessions(ctx context.Context, userID string, cur
 }
 
 func (s *ServiceSession) DestroySession(ctx context.Context, sessionID string) error {
-	return s.SessionRepo.Delete(ctx, sessionID)
+	session, err := s.SessionRepo.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		return s.SessionRepo.Delete(ctx, sessionID)
+	}
+	return s.revoke(ctx, session, "revoked")
 }
 
 func (s *ServiceSession) DestroySessionByToken(ctx context.Context, token string) error {
@@ -115,6 +131,15 @@ func (s *ServiceSession) GetSessionBySessionID(ctx context.Context, sessionID st
 }
 
 func (s *ServiceSession) DestroyAllUserSessions(ctx context.Context, userID string) error {
+	sessions, err := s.SessionRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.revoke(ctx, session, "revoked_all"); err != nil {
+			return err
+		}
+	}
 	return s.SessionRepo.DeleteByUserID(ctx, userID)
 }
 
@@ -126,7 +151,7 @@ func (s *ServiceSession) DestroyOtherSessions(ctx context.Context, userID string
 
 	for _, session := range sessions {
 		if session.SessionID != currentSessionID {
-			if err := s.SessionRepo.Delete(ctx, session.SessionID); err != nil {
+			if err := s.revoke(ctx, session, "revoked_others"); err != nil {
 				return err
 			}
 		}
@@ -135,6 +160,37 @@ func (s *ServiceSession) DestroyOtherSessions(ctx context.Context, userID string
 	return nil
 }
 
+// revoke blacklists the session's token, deletes the session and publishes
+// session.revoked. reason says which revocation ended the session; the token
+// is never published.
+func (s *ServiceSession) revoke(ctx context.Context, session *domainsession.Session, reason string) error {
+	if s.BlacklistRepo != nil && session.Token != "" {
+		blacklist := domainauth.Blacklist{
+			ID:        uuid.New().String(),
+			Token:     session.Token,
+			CreatedAt: time.Now(),
+		}
+		if err := s.BlacklistRepo.Store(blacklist); err != nil {
+			return fmt.Errorf("blacklist session token: %w", err)
+		}
+	}
+
+	if err := s.SessionRepo.Delete(ctx, session.SessionID); err != nil {
+		return err
+	}
+
+	if s.Events == nil {
+		return nil
+	}
+	s.Events.Publish(domainwebhook.EventSessionRevoked, map[string]interface{}{
+		"session_id": session.SessionID,
+		"user_id":    session.UserID,
+		"email":      session.Email,
+		"reason":     reason,
+	})
+	return nil
+}
+
 func extractDeviceInfo(ctx *gin.Context) string {
 	userAgent := ctx.GetHeader("User-Agent")
 
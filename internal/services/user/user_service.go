@@ -1,67 +1,77 @@
 package serviceuser
 
 import (
+	"context"
 	"errors"
-	"regexp"
+	"fmt"
 	domainauth "service-sender/internal/domain/auth"
+	domainoutbox "service-sender/internal/domain/outbox"
 	domainuser "service-sender/internal/domain/user"
+	domainwebhook "service-sender/internal/domain/webhook"
 	"service-sender/internal/dto"
 	interfaceauth "service-sender/internal/interfaces/auth"
+	interfaceotp "service-sender/internal/interfaces/otp"
+	interfacepasswordhistory "service-sender/internal/interfaces/passwordhistory"
 	interfacepermission "service-sender/internal/interfaces/permission"
 	interfacerole "service-sender/internal/interfaces/role"
 	interfaceuser "service-sender/internal/interfaces/user"
 	"service-sender/pkg/filter"
+	"service-sender/pkg/i18n"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/passwordpolicy"
 	"service-sender/utils"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// ErrAccountLocked is returned on login to an account locked from a security
+// alert, until its password is reset.
+var ErrAccountLocked = errors.New("account locked")
+
+// ErrPasswordExpired is returned on login with a password past its maximum
+// age, along with a ticket to change it instead of a token.
+var ErrPasswordExpired = errors.New("password expired")
+
+var ErrChangeTicketInvalid = errors.New("password change ticket invalid or expired")
+
+// ErrUnsupportedLocale is returned when a profile update asks for a locale
+// without a catalog.
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
 type ServiceUser struct {
 	UserRepo       interfaceuser.RepoUserInterface
 	BlacklistRepo  interfaceauth.RepoAuthInterface
 	RoleRepo       interfacerole.RepoRoleInterface
 	PermissionRepo interfacepermission.RepoPermissionInterface
+	// Policy decides which new passwords are accepted. A nil Policy applies
+	// the default rules.
+	Policy *passwordpolicy.Engine
+	// History rejects recently used passwords and expires old ones. It may
+	// be nil, leaving both out.
+	History interfacepasswordhistory.ServicePasswordHistoryInterface
+	// TicketTTL is how long the ticket from a login with an expired
+	// password lasts.
+	TicketTTL time.Duration
+	// Verifications tells whether a new account's address already passed
+	// registration OTP verification. It may be nil.
+	Verifications interfaceotp.ServiceOTPInterface
 }
 
-func NewUserService(userRepo interfaceuser.RepoUserInterface, blacklistRepo interfaceauth.RepoAuthInterface, roleRepo interfacerole.RepoRoleInterface, permissionRepo interfacepermission.RepoPermissionInterface) *ServiceUser {
+func NewUserService(userRepo interfaceuser.RepoUserInterface, blacklistRepo interfaceauth.RepoAuthInterface, roleRepo interfacerole.RepoRoleInterface, permissionRepo interfacepermission.RepoPermissionInterface, policy *passwordpolicy.Engine, history interfacepasswordhistory.ServicePasswordHistoryInterface, ticketTTL time.Duration) *ServiceUser {
 	return &ServiceUser{
 		UserRepo:       userRepo,
 		BlacklistRepo:  blacklistRepo,
 		RoleRepo:       roleRepo,
 		PermissionRepo: permissionRepo,
+		Policy:         policy,
+		History:        history,
+		TicketTTL:      ticketTTL,
 	}
 }
 
-func ValidatePasswordStrength(password string) error {
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters long")
-	}
-
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	if !hasLower {
-		return errors.New("password must contain at least 1 lowercase letter (a-z)")
-	}
-
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	if !hasUpper {
-		return errors.New("password must contain at least 1 uppercase letter (A-Z)")
-	}
-
-	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
-	if !hasNumber {
-		return errors.New("password must contain at least 1 number (0-9)")
-	}
-
-	hasSymbol := regexp.MustCompile(`[^a-zA-Z0-9]`).MatchString(password)
-	if !hasSymbol {
-		return errors.New("password must contain at least 1 symbol (!@#$%^&*...)")
-	}
-
-	return nil
-}
-
 func (s *ServiceUser) RegisterUser(req dto.UserRegister) (domainuser.Users, error) {
 	phone := utils.NormalizePhoneTo62(req.Phone)
 
@@ -75,7 +85,11 @@ func (s *ServiceUser) RegisterUser(req dto.UserRegister) (domainuser.Users, erro
 		return domainuser.Users{}, errors.New("phone number already exists")
 	}
 
-	if err := ValidatePasswordStrength(req.Password); err != nil {
+	// SECURITY: Public registration always uses vendor role
+	// This prevents privilege escalation through request manipulation
+	roleName := utils.RoleViewer
+
+	if err := s.Policy.Validate(req.Password, roleName, passwordpolicy.Subject{Email: req.Email, Name: req.Name}); err != nil {
 		return domainuser.Users{}, err
 	}
 
@@ -84,30 +98,33 @@ func (s *ServiceUser) RegisterUser(req dto.UserRegister) (domainuser.Users, erro
 		return domainuser.Users{}, err
 	}
 
-	// SECURITY: Public registration always uses vendor role
-	// This prevents privilege escalation through request manipulation
-	roleName := utils.RoleViewer
-
 	var roleId *string
 	roleEntity, err := s.RoleRepo.GetByName(roleName)
 	if err == nil && roleEntity.Id != "" {
 		roleId = &roleEntity.Id
 	}
 
+	now := time.Now()
 	data = domainuser.Users{
-		Id:        utils.CreateUUID(),
-		Name:      req.Name,
-		Phone:     phone,
-		Email:     req.Email,
-		Password:  string(hashedPwd),
-		Role:      roleName,
-		RoleId:    roleId,
-		CreatedAt: time.Now(),
-	}
-
-	if err = s.UserRepo.Store(data); err != nil {
+		Id:                utils.CreateUUID(),
+		Name:              req.Name,
+		Phone:             phone,
+		Email:             req.Email,
+		Password:          string(hashedPwd),
+		Role:              roleName,
+		RoleId:            roleId,
+		Locale:            i18n.Normalize(req.Locale),
+		PasswordChangedAt: &now,
+		CreatedAt:         now,
+	}
+	if s.Verifications != nil && s.Verifications.ConsumeRegisterVerification(context.Background(), req.Email) {
+		data.EmailVerifiedAt = &now
+	}
+
+	if err = s.UserRepo.Store(data, userEvent(domainwebhook.EventUserRegistered, data)); err != nil {
 		return domainuser.Users{}, err
 	}
+	s.recordPassword(data)
 
 	return data, nil
 }
@@ -127,7 +144,9 @@ func (s *ServiceUser) AdminCreateUser(req dto.AdminCreateUser, creatorRole strin
 		}
 	}
 
-	if err := ValidatePasswordStrength(req.Password); err != nil {
+	roleName := strings.ToLower(strings.TrimSpace(req.Role))
+
+	if err := s.Policy.Validate(req.Password, roleName, passwordpolicy.Subject{Email: req.Email, Name: req.Name}); err != nil {
 		return domainuser.Users{}, err
 	}
 
@@ -136,8 +155,6 @@ func (s *ServiceUser) AdminCreateUser(req dto.AdminCreateUser, creatorRole strin
 		return domainuser.Users{}, err
 	}
 
-	roleName := strings.ToLower(strings.TrimSpace(req.Role))
-
 	// SECURITY: Validate role assignment based on creator's role
 	// Only superadmin can create superadmin users
 	if roleName == utils.RoleSuperAdmin && creatorRole != utils.RoleSuperAdmin {
@@ -152,20 +169,23 @@ func (s *ServiceUser) AdminCreateUser(req dto.AdminCreateUser, creatorRole strin
 		return domainuser.Users{}, errors.New("invalid role: " + roleName)
 	}
 
+	now := time.Now()
 	data = domainuser.Users{
-		Id:        utils.CreateUUID(),
-		Name:      req.Name,
-		Phone:     phone,
-		Email:     req.Email,
-		Password:  string(hashedPwd),
-		Role:      roleName,
-		RoleId:    roleId,
-		CreatedAt: time.Now(),
-	}
-
-	if err = s.UserRepo.Store(data); err != nil {
+		Id:                utils.CreateUUID(),
+		Name:              req.Name,
+		Phone:             phone,
+		Email:             req.Email,
+		Password:          string(hashedPwd),
+		Role:              roleName,
+		RoleId:            roleId,
+		PasswordChangedAt: &now,
+		CreatedAt:         now,
+	}
+
+	if err = s.UserRepo.Store(data, userEvent(domainwebhook.EventUserCreated, data)); err != nil {
 		return domainuser.Users{}, err
 	}
+	s.recordPassword(data)
 
 	return data, nil
 }
@@ -180,6 +200,19 @@ func (s *ServiceUser) LoginUser(req dto.Login, logId string) (string, error) {
 		return "", err
 	}
 
+	if data.LockedAt != nil {
+		return "", ErrAccountLocked
+	}
+
+	// The password is right but too old; the ticket only lets it be changed.
+	if s.History != nil && s.History.Expired(data) {
+		ticket, err := utils.GenerateScopedJwt(&data, logId, utils.ScopePasswordChange, s.TicketTTL)
+		if err != nil {
+			return "", err
+		}
+		return ticket, ErrPasswordExpired
+	}
+
 	token, err := utils.GenerateJwt(&data, logId)
 	if err != nil {
 		return "", err
@@ -294,6 +327,8 @@ func (s *ServiceUser) Update(id, role string, req dto.UserUpdate) (domainuser.Us
 	if data.Role == utils.RoleSuperAdmin && role != utils.RoleSuperAdmin {
 		return domainuser.Users{}, errors.New("cannot modify superadmin users")
 	}
+	previousRole := data.Role
+	previousEmail := data.Email
 
 	if req.Name != "" {
 		data.Name = req.Name
@@ -308,6 +343,29 @@ func (s *ServiceUser) Update(id, role string, req dto.UserUpdate) (domainuser.Us
 		data.Email = req.Email
 	}
 
+	if req.Locale != "" {
+		locale := i18n.Normalize(req.Locale)
+		if locale == "" {
+			return domainuser.Users{}, ErrUnsupportedLocale
+		}
+		data.Locale = locale
+	}
+
+	// Verification and segment attributes are managed by admins only.
+	if role == utils.RoleAdmin || role == utils.RoleSuperAdmin {
+		if req.EmailVerified != nil {
+			if !*req.EmailVerified {
+				data.EmailVerifiedAt = nil
+			} else if data.EmailVerifiedAt == nil {
+				now := time.Now()
+				data.EmailVerifiedAt = &now
+			}
+		}
+		if req.Attributes != nil {
+			data.Attributes = req.Attributes
+		}
+	}
+
 	if role == utils.RoleAdmin && strings.TrimSpace(req.Role) != "" {
 		newRoleName := strings.ToLower(req.Role)
 
@@ -337,7 +395,19 @@ func (s *ServiceUser) Update(id, role string, req dto.UserUpdate) (domainuser.Us
 		}
 	}
 
-	if err = s.UserRepo.Update(data); err != nil {
+	var events []domainoutbox.Event
+	if data.Role != previousRole {
+		event := userEvent(domainwebhook.EventUserRoleChanged, data)
+		event.Payload["previous_role"] = previousRole
+		events = append(events, event)
+	}
+	if !strings.EqualFold(data.Email, previousEmail) {
+		event := userEvent(domainwebhook.EventUserEmailChanged, data)
+		event.Payload["previous_email"] = previousEmail
+		events = append(events, event)
+	}
+
+	if err = s.UserRepo.Update(data, events...); err != nil {
 		return domainuser.Users{}, err
 	}
 
@@ -349,10 +419,6 @@ func (s *ServiceUser) ChangePassword(id string, req dto.ChangePassword) (domainu
 		return domainuser.Users{}, errors.New("new password must be different from current password")
 	}
 
-	if err := ValidatePasswordStrength(req.NewPassword); err != nil {
-		return domainuser.Users{}, err
-	}
-
 	data, err := s.UserRepo.GetByID(id)
 	if err != nil {
 		return domainuser.Users{}, err
@@ -362,69 +428,100 @@ func (s *ServiceUser) ChangePassword(id string, req dto.ChangePassword) (domainu
 		return domainuser.Users{}, err
 	}
 
-	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	return s.setPassword(data, req.NewPassword)
+}
+
+// ChangeExpiredPassword sets a new password with the ticket a login with an
+// expired password returned. The ticket stops working once the password is
+// no longer expired.
+func (s *ServiceUser) ChangeExpiredPassword(req dto.ChangeExpiredPassword) (domainuser.Users, error) {
+	claims, err := utils.JwtClaim(req.Ticket)
+	if err != nil || utils.InterfaceString(claims["scope"]) != utils.ScopePasswordChange {
+		return domainuser.Users{}, ErrChangeTicketInvalid
+	}
+
+	data, err := s.UserRepo.GetByID(utils.InterfaceString(claims["user_id"]))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainuser.Users{}, ErrChangeTicketInvalid
+	}
 	if err != nil {
 		return domainuser.Users{}, err
 	}
+	if s.History == nil || !s.History.Expired(data) || data.LockedAt != nil {
+		return domainuser.Users{}, ErrChangeTicketInvalid
+	}
 
-	data.Password = string(hashedPwd)
+	return s.setPassword(data, req.NewPassword)
+}
 
-	if err = s.UserRepo.Update(data); err != nil {
+// setPassword checks password against the policy and the user's history,
+// then stores it.
+func (s *ServiceUser) setPassword(data domainuser.Users, password string) (domainuser.Users, error) {
+	if err := s.Policy.Validate(password, data.Role, passwordpolicy.Subject{Email: data.Email, Name: data.Name}); err != nil {
 		return domainuser.Users{}, err
 	}
 
-	return data, nil
-}
+	if s.History != nil {
+		if err := s.History.CheckReuse(data, password); err != nil {
+			return domainuser.Users{}, err
+		}
+	}
 
-func (s *ServiceUser) ForgotPassword(req dto.ForgotPasswordRequest) (string, error) {
-	data, err := s.UserRepo.GetByEmail(req.Email)
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", nil
+		return domainuser.Users{}, err
 	}
 
-	token, err := utils.GenerateJwt(&data, "reset_password")
-	if err != nil {
-		return "", err
+	now := time.Now()
+	data.Password = string(hashedPwd)
+	data.PasswordChangedAt = &now
+
+	if err = s.UserRepo.Update(data, userEvent(domainwebhook.EventUserPasswordChanged, data)); err != nil {
+		return domainuser.Users{}, err
 	}
+	s.recordPassword(data)
 
-	return token, nil
+	return data, nil
 }
 
-func (s *ServiceUser) ResetPassword(req dto.ResetPasswordRequest) error {
-	if err := ValidatePasswordStrength(req.NewPassword); err != nil {
-		return err
+// recordPassword adds the password data was just given to its history. A
+// failure only weakens the reuse check, so it is logged.
+func (s *ServiceUser) recordPassword(data domainuser.Users) {
+	if s.History == nil {
+		return
 	}
-
-	claims, err := utils.JwtClaim(req.Token)
-	if err != nil {
-		return errors.New("invalid or expired token")
+	if err := s.History.Record(data.Id, data.Password); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceUser][recordPassword]; Record %s error: %v", data.Id, err))
 	}
+}
 
-	userId := claims["user_id"].(string)
-
-	data, err := s.UserRepo.GetByID(userId)
-	if err != nil {
-		return errors.New("user not found")
+// CheckPassword scores a password against the policy of req.Role, the
+// default when empty, without storing anything.
+func (s *ServiceUser) CheckPassword(req dto.PasswordCheck) passwordpolicy.Result {
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if role == "" {
+		role = utils.RoleViewer
 	}
+	return s.Policy.Check(req.Password, role, passwordpolicy.Subject{Email: req.Email, Name: req.Name})
+}
 
-	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+func (s *ServiceUser) Delete(id string) error {
+	data, err := s.UserRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
-
-	data.Password = string(hashedPwd)
-
-	if err = s.UserRepo.Update(data); err != nil {
-		return err
-	}
-
-	_ = s.LogoutUser(req.Token)
-
-	return nil
+	return s.UserRepo.Delete(id, userEvent(domainwebhook.EventUserDeleted, data))
 }
 
-func (s *ServiceUser) Delete(id string) error {
-	return s.UserRepo.Delete(id)
+// userEvent describes a change to u. The payload is what event consumers,
+// such as webhook subscribers, get to see of the user.
+func userEvent(eventType string, u domainuser.Users) domainoutbox.Event {
+	return domainoutbox.NewEvent(eventType, domainoutbox.AggregateUser, u.Id, map[string]interface{}{
+		"id":    u.Id,
+		"name":  u.Name,
+		"email": u.Email,
+		"role":  u.Role,
+	})
 }
 
 var _ interfaceuser.ServiceUserInterface = (*ServiceUser)(nil)
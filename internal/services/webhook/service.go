@@ -0,0 +1,287 @@
+package servicewebhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	domainoutbox "service-sender/internal/domain/outbox"
+	domainwebhook "service-sender/internal/domain/webhook"
+	"service-sender/internal/dto"
+	interfaceoutbox "service-sender/internal/interfaces/outbox"
+	interfacewebhook "service-sender/internal/interfaces/webhook"
+	"service-sender/pkg/config"
+	"service-sender/pkg/filter"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/security"
+	"service-sender/utils"
+)
+
+// outboxConsumer is the name the service is registered under with the outbox
+// relay.
+const outboxConsumer = "webhooks"
+
+var (
+	ErrWebhookInvalidURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookUnknownEvent = errors.New("unknown webhook event")
+)
+
+type ServiceWebhook struct {
+	Endpoints  interfacewebhook.RepoEndpointInterface
+	Deliveries interfacewebhook.RepoDeliveryInterface
+	Config     config.WebhookConfig
+	Client     *http.Client
+	wake       chan struct{}
+}
+
+func NewWebhookService(endpoints interfacewebhook.RepoEndpointInterface, deliveries interfacewebhook.RepoDeliveryInterface, cfg config.WebhookConfig) *ServiceWebhook {
+	return &ServiceWebhook{
+		Endpoints:  endpoints,
+		Deliveries: deliveries,
+		Config:     cfg,
+		Client: &http.Client{
+			Timeout: cfg.Timeout,
+			// A redirect is reported as a failed attempt rather than
+			// followed, so the signed body only ever reaches the
+			// configured URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Publish queues eventType for every active endpoint subscribed to it. It is
+// used for events that are not written to the outbox.
+func (s *ServiceWebhook) Publish(eventType string, data map[string]interface{}) {
+	if err := s.enqueue(utils.CreateUUID(), eventType, time.Now(), data); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceWebhook][Publish]; %s; enqueue error: %v", eventType, err))
+	}
+}
+
+// Name identifies the service as an outbox consumer.
+func (s *ServiceWebhook) Name() string {
+	return outboxConsumer
+}
+
+// Consume queues a relayed outbox event. The event id is kept, so a repeated
+// event does not add deliveries.
+func (s *ServiceWebhook) Consume(event domainoutbox.Event) error {
+	return s.enqueue(event.Id, event.Type, event.CreatedAt, event.Payload)
+}
+
+// enqueue adds a delivery per subscribed endpoint and wakes the worker so
+// they go out without waiting for its next tick.
+func (s *ServiceWebhook) enqueue(eventId, eventType string, occurredAt time.Time, data map[string]interface{}) error {
+	endpoints, err := s.Endpoints.GetActive()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []domainwebhook.Delivery
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(eventType) {
+			continue
+		}
+		deliveries = append(deliveries, domainwebhook.Delivery{
+			Id:            utils.CreateUUID(),
+			EndpointId:    endpoint.Id,
+			EventId:       eventId,
+			EventType:     eventType,
+			OccurredAt:    occurredAt,
+			Data:          data,
+			Status:        domainwebhook.StatusPending,
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.Deliveries.StoreMany(deliveries); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func (s *ServiceWebhook) CreateEndpoint(req dto.WebhookEndpointCreate, actorId string) (dto.WebhookEndpointSecret, error) {
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+	endpointURL, err := normalizeURL(req.URL)
+	if err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+	secret, err := security.GenerateWebhookSecret()
+	if err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+
+	data := domainwebhook.Endpoint{
+		Id:          utils.CreateUUID(),
+		Name:        strings.TrimSpace(req.Name),
+		URL:         endpointURL,
+		Secret:      secret,
+		Events:      events,
+		Description: strings.TrimSpace(req.Description),
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+	}
+	if actorId != "" {
+		data.CreatedBy = &actorId
+	}
+
+	if err := s.Endpoints.Store(data); err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+	return dto.WebhookEndpointSecret{Endpoint: data, Secret: secret}, nil
+}
+
+func (s *ServiceWebhook) GetEndpoint(id string) (domainwebhook.Endpoint, error) {
+	return s.Endpoints.GetByID(id)
+}
+
+func (s *ServiceWebhook) GetEndpoints(params filter.BaseParams) ([]domainwebhook.Endpoint, int64, error) {
+	return s.Endpoints.GetAll(params)
+}
+
+func (s *ServiceWebhook) UpdateEndpoint(id string, req dto.WebhookEndpointUpdate) (domainwebhook.Endpoint, error) {
+	data, err := s.Endpoints.GetByID(id)
+	if err != nil {
+		return domainwebhook.Endpoint{}, err
+	}
+
+	if req.Name != "" {
+		data.Name = strings.TrimSpace(req.Name)
+	}
+	if req.URL != "" {
+		if data.URL, err = normalizeURL(req.URL); err != nil {
+			return domainwebhook.Endpoint{}, err
+		}
+	}
+	if req.Events != nil {
+		if data.Events, err = normalizeEvents(req.Events); err != nil {
+			return domainwebhook.Endpoint{}, err
+		}
+	}
+	if req.Description != nil {
+		data.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.IsActive != nil {
+		data.IsActive = *req.IsActive
+	}
+	now := time.Now()
+	data.UpdatedAt = &now
+
+	if err := s.Endpoints.Update(data); err != nil {
+		return domainwebhook.Endpoint{}, err
+	}
+	return data, nil
+}
+
+func (s *ServiceWebhook) DeleteEndpoint(id string) error {
+	return s.Endpoints.Delete(id)
+}
+
+// RotateSecret replaces the signing secret. Deliveries already queued are
+// signed with the new secret when they are sent.
+func (s *ServiceWebhook) RotateSecret(id string) (dto.WebhookEndpointSecret, error) {
+	data, err := s.Endpoints.GetByID(id)
+	if err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+	if data.Secret, err = security.GenerateWebhookSecret(); err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+	now := time.Now()
+	data.UpdatedAt = &now
+
+	if err := s.Endpoints.Update(data); err != nil {
+		return dto.WebhookEndpointSecret{}, err
+	}
+	return dto.WebhookEndpointSecret{Endpoint: data, Secret: data.Secret}, nil
+}
+
+func (s *ServiceWebhook) GetDeliveries(params filter.BaseParams) ([]domainwebhook.Delivery, int64, error) {
+	return s.Deliveries.GetAll(params)
+}
+
+func (s *ServiceWebhook) GetDelivery(id string) (domainwebhook.Delivery, error) {
+	data, err := s.Deliveries.GetByID(id)
+	if err != nil {
+		return domainwebhook.Delivery{}, err
+	}
+	if data.AttemptLog, err = s.Deliveries.GetAttempts(id); err != nil {
+		return domainwebhook.Delivery{}, err
+	}
+	return data, nil
+}
+
+// Redeliver queues the delivery for an immediate attempt, whatever its status.
+// A delivery that has used up its attempts gets exactly one more.
+func (s *ServiceWebhook) Redeliver(id string) (domainwebhook.Delivery, error) {
+	if err := s.Deliveries.Requeue(id, time.Now()); err != nil {
+		return domainwebhook.Delivery{}, err
+	}
+	s.notify()
+	return s.Deliveries.GetByID(id)
+}
+
+// notify wakes the worker without blocking when it is already awake.
+func (s *ServiceWebhook) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func normalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrWebhookInvalidURL
+	}
+	return raw, nil
+}
+
+// normalizeEvents checks events against the known event types, renames legacy
+// ones and removes duplicates. Notification rule events are accepted by prefix. A wildcard makes every other entry redundant.
+func normalizeEvents(events []string) ([]string, error) {
+	known := make(map[string]bool, len(domainwebhook.Events))
+	for _, ev := range domainwebhook.Events {
+		known[ev] = true
+	}
+
+	seen := make(map[string]bool, len(events))
+	out := make([]string, 0, len(events))
+	for _, ev := range events {
+		ev = strings.TrimSpace(ev)
+		if ev == domainwebhook.Wildcard {
+			return []string{domainwebhook.Wildcard}, nil
+		}
+		if current, ok := domainwebhook.LegacyEvents[ev]; ok {
+			ev = current
+		}
+		if !known[ev] && !strings.HasPrefix(ev, domainwebhook.NotifyEventPrefix) {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookUnknownEvent, ev)
+		}
+		if !seen[ev] {
+			seen[ev] = true
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+var (
+	_ interfacewebhook.ServiceWebhookInterface = (*ServiceWebhook)(nil)
+	_ interfaceoutbox.ConsumerInterface        = (*ServiceWebhook)(nil)
+)
@@ -0,0 +1,187 @@
+package servicewebhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domainwebhook "service-sender/internal/domain/webhook"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/security"
+	"service-sender/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// leaseMargin is added to the request timeout so a delivery is only
+	// picked up by another worker when its holder has clearly died.
+	leaseMargin = time.Minute
+	// maxResponseBody is how much of the subscriber's response is kept in
+	// the attempt log.
+	maxResponseBody = 2048
+	userAgent       = "service-sender-webhooks/1.0"
+)
+
+// Run delivers due events every worker interval, or sooner when Publish or
+// Redeliver queue something, until ctx is done.
+func (s *ServiceWebhook) Run(ctx context.Context) {
+	if !s.Config.WorkerEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.WorkerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.tick(ctx)
+	}
+}
+
+func (s *ServiceWebhook) tick(ctx context.Context) {
+	now := time.Now()
+	deliveries, err := s.Deliveries.Claim(now, now.Add(s.Config.Timeout+leaseMargin), s.Config.BatchSize)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[WebhookWorker][tick]; Claim error: %v", err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d domainwebhook.Delivery) {
+			defer wg.Done()
+			s.deliver(ctx, d)
+		}(d)
+	}
+	wg.Wait()
+
+	// A full batch means more may be due; go again rather than wait a tick.
+	if len(deliveries) == s.Config.BatchSize {
+		s.notify()
+	}
+}
+
+func (s *ServiceWebhook) deliver(ctx context.Context, d domainwebhook.Delivery) {
+	endpoint, err := s.Endpoints.GetByID(d.EndpointId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.finish(d, domainwebhook.StatusFailed, nil, "endpoint deleted", nil)
+			return
+		}
+		// The lease runs out and another tick retries it.
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[WebhookWorker][deliver]; %s; GetByID error: %v", d.Id, err))
+		return
+	}
+	if !endpoint.IsActive {
+		s.finish(d, domainwebhook.StatusFailed, nil, "endpoint disabled", nil)
+		return
+	}
+
+	attempt := domainwebhook.Attempt{
+		Id:         utils.CreateUUID(),
+		DeliveryId: d.Id,
+		Attempt:    d.Attempts + 1,
+		CreatedAt:  time.Now(),
+	}
+	statusCode, body, err := s.post(ctx, endpoint, d)
+	attempt.DurationMs = time.Since(attempt.CreatedAt).Milliseconds()
+	attempt.ResponseBody = body
+	if statusCode > 0 {
+		attempt.StatusCode = &statusCode
+	}
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("endpoint responded with status %d", statusCode)
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	if storeErr := s.Deliveries.StoreAttempt(attempt); storeErr != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[WebhookWorker][deliver]; %s; StoreAttempt error: %v", d.Id, storeErr))
+	}
+
+	d.Attempts = attempt.Attempt
+	if err == nil {
+		s.finish(d, domainwebhook.StatusSucceeded, attempt.StatusCode, "", nil)
+		return
+	}
+	if d.Attempts >= s.Config.MaxAttempts {
+		s.finish(d, domainwebhook.StatusFailed, attempt.StatusCode, attempt.Error, nil)
+		return
+	}
+	next := time.Now().Add(s.backoff(d.Attempts))
+	s.finish(d, domainwebhook.StatusRetrying, attempt.StatusCode, attempt.Error, &next)
+}
+
+// post sends the signed envelope. The signature covers "<timestamp>.<body>",
+// which security.VerifyWebhook checks on the receiving side.
+func (s *ServiceWebhook) post(ctx context.Context, endpoint domainwebhook.Endpoint, d domainwebhook.Delivery) (int, string, error) {
+	payload, err := json.Marshal(d.Envelope())
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Webhook-Id", d.EventId)
+	req.Header.Set("X-Webhook-Delivery", d.Id)
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set(security.WebhookTimestampHeader, timestamp)
+	req.Header.Set(security.WebhookSignatureHeader, security.SignWebhook(endpoint.Secret, timestamp, payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, strings.ToValidUTF8(string(body), ""), nil
+}
+
+func (s *ServiceWebhook) finish(d domainwebhook.Delivery, status string, statusCode *int, lastError string, next *time.Time) {
+	fields := map[string]interface{}{
+		"status":           status,
+		"attempts":         d.Attempts,
+		"last_status_code": statusCode,
+		"last_error":       lastError,
+		"next_attempt_at":  next,
+	}
+	if status == domainwebhook.StatusSucceeded {
+		fields["delivered_at"] = time.Now()
+	}
+	if err := s.Deliveries.Finish(d.Id, fields); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[WebhookWorker][finish]; %s; Finish error: %v", d.Id, err))
+	}
+}
+
+// backoff doubles RetryBackoff for every attempt made, capped at MaxBackoff.
+func (s *ServiceWebhook) backoff(attempts int) time.Duration {
+	wait := s.Config.RetryBackoff
+	for i := 1; i < attempts && wait < s.Config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > s.Config.MaxBackoff {
+		wait = s.Config.MaxBackoff
+	}
+	return wait
+}
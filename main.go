@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"service-sender/infrastructure/database"
 	"service-sender/internal/router"
+	"service-sender/pkg/breached"
 	"service-sender/pkg/config"
 	"service-sender/pkg/logger"
+	"service-sender/pkg/webpush"
 	"service-sender/utils"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -58,9 +65,43 @@ func main() {
 	logger.WriteLog(logger.LogLevelInfo, "Server IP: "+myAddr)
 
 	var port, appName string
+	var genVAPIDKeys bool
+	var breachedSource, breachedOut string
+	var breachedFPRate float64
 	flag.StringVar(&port, "port", os.Getenv("PORT"), "port of the service")
 	flag.StringVar(&appName, "appname", os.Getenv("APP_NAME"), "service name")
+	flag.BoolVar(&genVAPIDKeys, "gen-vapid-keys", false, "print a new Web Push VAPID key pair and exit")
+	flag.StringVar(&breachedSource, "build-breached-filter", "", "build the breached-password filter from a SHA-1 hash list (HIBP format) and exit")
+	flag.StringVar(&breachedOut, "breached-filter-out", "", "where -build-breached-filter writes the filter (default PASSWORD_BREACHED_FILE)")
+	flag.Float64Var(&breachedFPRate, "breached-fp-rate", 0, "false-positive rate of the filter -build-breached-filter writes (default PASSWORD_BREACHED_FP_RATE)")
 	flag.Parse()
+
+	if genVAPIDKeys {
+		publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+		FailOnError(err, "Failed to generate VAPID keys")
+		fmt.Printf("WEBPUSH_VAPID_PUBLIC_KEY=%s\nWEBPUSH_VAPID_PRIVATE_KEY=%s\n", publicKey, privateKey)
+		return
+	}
+
+	if breachedSource != "" {
+		if breachedOut == "" || breachedFPRate <= 0 {
+			policyConf, err := config.LoadPasswordPolicyConfig()
+			FailOnError(err, "Failed to load password policy config")
+			if breachedOut == "" {
+				breachedOut = policyConf.BreachedFile
+			}
+			if breachedFPRate <= 0 {
+				breachedFPRate = policyConf.BreachedFPRate
+			}
+		}
+		if breachedOut == "" {
+			breachedOut = "breached-passwords.bf"
+		}
+		added, skipped, err := breached.BuildFile(breachedSource, breachedOut, breachedFPRate)
+		FailOnError(err, "Failed to build breached password filter")
+		fmt.Printf("Wrote %s: %d hashes, %d lines skipped, false-positive rate %v\nPASSWORD_BREACHED_FILE=%s\n", breachedOut, added, skipped, breachedFPRate, breachedOut)
+		return
+	}
 	logger.WriteLog(logger.LogLevelInfo, "APP: "+appName+"; PORT: "+port)
 
 	confID := config.GetAppConf("CONFIG_ID", "", nil)
@@ -90,9 +131,19 @@ func main() {
 		defer sqlDb.Close()
 
 		routes.UserRoutes()
+		routes.PreferenceRoutes()
+		routes.NotificationRoutes()
+		routes.PushRoutes()
 		routes.RoleRoutes()
 		routes.PermissionRoutes()
 		routes.MenuRoutes()
+		routes.EmailTemplateRoutes()
+		routes.EmailDeliveryRoutes()
+		routes.ApiClientRoutes()
+		routes.CampaignRoutes()
+		routes.WebhookRoutes()
+		routes.OutboxRelay()
+		routes.SecurityAlertRoutes()
 
 		// Register session routes if Redis is available
 		if redisClient != nil {
@@ -103,11 +154,29 @@ func main() {
 	routes.OTPRoutes()
 	routes.PasswordResetRoutes()
 	routes.EmailRoutes()
+	routes.NotifyRoutes()
 
 	logger.WriteLog(logger.LogLevelInfo, "All routes registered successfully")
 
-	err = routes.App.Run(fmt.Sprintf(":%s", port))
-	FailOnError(err, "Failed run service")
+	// Workers and the server stop on SIGINT or SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	routes.StartWorkers(ctx)
+
+	srv := &http.Server{Addr: fmt.Sprintf(":%s", port), Handler: routes.App}
+	go func() {
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			FailOnError(err, "Failed run service")
+		}
+	}()
+
+	<-ctx.Done()
+	logger.WriteLog(logger.LogLevelInfo, "Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.WriteLog(logger.LogLevelError, "Server shutdown - Error: "+err.Error())
+	}
 }
 
 func runMigration() {
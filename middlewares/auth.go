@@ -50,6 +50,14 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 		}
 		logPrefix += fmt.Sprintf("[%s][%s]", utils.InterfaceString(dataJWT["jti"]), utils.InterfaceString(dataJWT["user_id"]))
 
+		if scope := utils.InterfaceString(dataJWT["scope"]); scope != "" {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Invalid Token: scoped to %s;", logPrefix, scope))
+			res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusUnauthorized, Message: "Password change required"}
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
 		_, err = m.BlacklistRepo.GetByToken(tokenString)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; blacklistRepo.GetByToken; Error: %+v", logPrefix, err))
@@ -75,6 +83,61 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// ScopedTokenMiddleware accepts a token issued for scope from the token query
+// parameter or the cookie named after scope, for clients such as a browser
+// EventSource that cannot send an Authorization header. Requests without
+// either go through AuthMiddleware.
+func (m *Middleware) ScopedTokenMiddleware(scope string) gin.HandlerFunc {
+	auth := m.AuthMiddleware()
+	return func(ctx *gin.Context) {
+		tokenString := ctx.Query("token")
+		if tokenString == "" {
+			tokenString, _ = ctx.Cookie(scope)
+		}
+		if tokenString == "" {
+			auth(ctx)
+			return
+		}
+
+		logId := utils.GenerateLogId(ctx)
+		logPrefix := "[ScopedTokenMiddleware]"
+
+		dataJWT, err := utils.JwtClaim(tokenString)
+		if err == nil && utils.InterfaceString(dataJWT["scope"]) != scope {
+			err = errors.New("token is not valid for this endpoint")
+		}
+		if err != nil {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Invalid Token; Error: %s;", logPrefix, err.Error()))
+			res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+		logPrefix += fmt.Sprintf("[%s][%s]", utils.InterfaceString(dataJWT["jti"]), utils.InterfaceString(dataJWT["user_id"]))
+
+		_, err = m.BlacklistRepo.GetByToken(tokenString)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; blacklistRepo.GetByToken; Error: %+v", logPrefix, err))
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Invalid Token; Error: token is blacklisted;", logPrefix))
+			res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+			res.Error = "Please login and try again"
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
+		ctx.Set(utils.CtxKeyAuthData, dataJWT)
+		ctx.Set("userId", utils.InterfaceString(dataJWT["user_id"]))
+
+		ctx.Next()
+	}
+}
+
 func (m *Middleware) RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var (
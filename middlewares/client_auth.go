@@ -0,0 +1,171 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	interfaceapiclient "service-sender/internal/interfaces/apiclient"
+	serviceapiclient "service-sender/internal/services/apiclient"
+	"service-sender/pkg/config"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/pkg/security"
+	"service-sender/utils"
+)
+
+// ClientAuth authenticates service-to-service calls to the sender APIs by
+// API key, optionally with an HMAC request signature. Without Nonces signed
+// requests cannot be protected from replay and are refused.
+type ClientAuth struct {
+	Service interfaceapiclient.ServiceApiClientInterface
+	Nonces  interfaceapiclient.RepoNonceInterface
+	Config  config.ApiClientConfig
+}
+
+func NewClientAuth(service interfaceapiclient.ServiceApiClientInterface, nonces interfaceapiclient.RepoNonceInterface, cfg config.ApiClientConfig) *ClientAuth {
+	return &ClientAuth{Service: service, Nonces: nonces, Config: cfg}
+}
+
+// Require only lets through clients granted endpoint and the X-App-Name they
+// send. Requests are signed when the client requires it or when an
+// X-Signature header is present. The authenticated client is stored under
+// utils.CtxKeyApiClient.
+func (m *ClientAuth) Require(endpoint string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !m.Config.Enabled {
+			ctx.Next()
+			return
+		}
+
+		logId := utils.GenerateLogId(ctx)
+		logPrefix := fmt.Sprintf("[ClientAuth][%s]", endpoint)
+
+		abort := func(status int, message string) {
+			res := response.Response(status, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: status, Message: message}
+			ctx.AbortWithStatusJSON(status, res)
+		}
+
+		if m.Service == nil {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; API client store is not available", logPrefix))
+			abort(http.StatusServiceUnavailable, "API client authentication is not available")
+			return
+		}
+
+		apiKey := strings.TrimSpace(ctx.GetHeader(security.ApiKeyHeader))
+		if apiKey == "" {
+			abort(http.StatusUnauthorized, "X-API-Key header is required")
+			return
+		}
+
+		client, err := m.Service.Authenticate(apiKey)
+		if err != nil {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.Authenticate; Error: %+v", logPrefix, err))
+			// Inactive clients get the same answer as unknown keys so the
+			// response does not reveal which keys exist.
+			if errors.Is(err, serviceapiclient.ErrApiKeyInvalid) || errors.Is(err, serviceapiclient.ErrApiClientInactive) {
+				abort(http.StatusUnauthorized, serviceapiclient.ErrApiKeyInvalid.Error())
+				return
+			}
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusInternalServerError, Message: messages.MsgFail}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+			return
+		}
+		logPrefix += fmt.Sprintf("[%s]", client.Name)
+
+		if !client.AllowsEndpoint(endpoint) {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; endpoint not allowed", logPrefix))
+			abort(http.StatusForbidden, "api client is not allowed to call this endpoint")
+			return
+		}
+		if appName := ctx.GetHeader("X-App-Name"); !client.AllowsApp(appName) {
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; app %q not allowed", logPrefix, appName))
+			abort(http.StatusForbidden, "api client is not allowed to send for this app")
+			return
+		}
+
+		if client.RequireSignature || ctx.GetHeader(security.RequestSignatureHeader) != "" {
+			if m.Nonces == nil {
+				logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; nonce store is not available", logPrefix))
+				abort(http.StatusServiceUnavailable, "request signing is not available")
+				return
+			}
+			if err := m.verifySignature(ctx, client); err != nil {
+				logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; verifySignature; Error: %+v", logPrefix, err))
+				var tooLarge *http.MaxBytesError
+				switch {
+				case errors.As(err, &tooLarge):
+					abort(http.StatusRequestEntityTooLarge, "request body is too large")
+				case errors.Is(err, errNonceStore):
+					abort(http.StatusServiceUnavailable, "request signing is not available")
+				default:
+					abort(http.StatusUnauthorized, "request signature is invalid")
+				}
+				return
+			}
+		}
+
+		ctx.Set(utils.CtxKeyApiClient, client)
+		ctx.Next()
+	}
+}
+
+var (
+	errNonceReused     = errors.New("request nonce has already been used")
+	errNoSigningSecret = errors.New("api client has no signing secret; rotate its key to get one")
+	errNonceStore      = errors.New("request nonce store failed")
+)
+
+// verifySignature checks the request signature against the client's signing
+// secrets and claims its nonce. At most Config.MaxBodyBytes of the body are
+// read; it is restored so handlers can still bind it.
+func (m *ClientAuth) verifySignature(ctx *gin.Context, client domainapiclient.ApiClient) error {
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, m.Config.MaxBodyBytes))
+	if err != nil {
+		return err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	now := time.Now()
+	secrets := client.SigningSecrets(now)
+	if len(secrets) == 0 {
+		return errNoSigningSecret
+	}
+	for _, secret := range secrets {
+		err = security.VerifyRequestSignature(ctx.Request.Header, secret, ctx.Request.Method, ctx.Request.URL.RequestURI(), body, m.Config.SignatureTolerance, now)
+		if !errors.Is(err, security.ErrSignatureInvalid) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// A nonce only needs to be remembered while its timestamp is accepted.
+	ttl := 2 * m.Config.SignatureTolerance
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	fresh, err := m.Nonces.Claim(reqCtx, client.Id, strings.TrimSpace(ctx.GetHeader(security.RequestNonceHeader)), ttl)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errNonceStore, err)
+	}
+	if !fresh {
+		return errNonceReused
+	}
+	return nil
+}
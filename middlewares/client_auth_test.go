@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	"service-sender/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerifySignatureCapsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/email/send", strings.NewReader(`{"to":["a@example.test"]}`))
+
+	m := &ClientAuth{Config: config.ApiClientConfig{MaxBodyBytes: 8}}
+	err := m.verifySignature(ctx, domainapiclient.ApiClient{})
+
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("verifySignature() error = %v, want *http.MaxBytesError", err)
+	}
+}
@@ -0,0 +1,147 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	domainapiclient "service-sender/internal/domain/apiclient"
+	"service-sender/internal/dto"
+	"service-sender/pkg/logger"
+	"service-sender/pkg/messages"
+	"service-sender/pkg/response"
+	"service-sender/utils"
+)
+
+// QuotaCost returns how many sends a request counts for. A nil QuotaCost
+// counts every request as one send.
+type QuotaCost func(ctx *gin.Context) int
+
+// RecipientCost counts the distinct to, cc and bcc addresses of a send
+// request, which is what the email provider bills for: an address listed
+// twice is only sent once. The body is restored for the handler.
+func RecipientCost(ctx *gin.Context) int {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return 1
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		To  []string `json:"to"`
+		Cc  []string `json:"cc"`
+		Bcc []string `json:"bcc"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return 1
+	}
+	seen := make(map[string]struct{}, len(req.To)+len(req.Cc)+len(req.Bcc))
+	for _, list := range [][]string{req.To, req.Cc, req.Bcc} {
+		for _, raw := range list {
+			if email := strings.ToLower(strings.TrimSpace(raw)); email != "" {
+				seen[email] = struct{}{}
+			}
+		}
+	}
+	if len(seen) > 0 {
+		return len(seen)
+	}
+	return 1
+}
+
+// Quota meters sends of the client authenticated by Require against its
+// burst, daily and monthly limits. Reserved sends the handler did not make
+// are given back, so rejected requests and unreached recipients are not
+// billed.
+func (m *ClientAuth) Quota(cost QuotaCost) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, ok := ctx.Get(utils.CtxKeyApiClient)
+		if !ok || m.Service == nil {
+			ctx.Next()
+			return
+		}
+		client := value.(domainapiclient.ApiClient)
+
+		logId := utils.GenerateLogId(ctx)
+		logPrefix := fmt.Sprintf("[ClientQuota][%s]", client.Name)
+
+		units := 1
+		if cost != nil {
+			units = cost(ctx)
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 2*time.Second)
+		check, err := m.Service.ConsumeQuota(reqCtx, client, units)
+		cancel()
+		if err != nil {
+			// A quota that cannot be checked is not enforced, so refuse
+			// rather than send unmetered.
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.ConsumeQuota; Error: %+v", logPrefix, err))
+			res := response.Response(http.StatusServiceUnavailable, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusServiceUnavailable, Message: "quota metering is not available"}
+			ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, res)
+			return
+		}
+
+		setQuotaHeaders(ctx, check.Tightest)
+		if !check.Allowed {
+			retryAfter := int(math.Ceil(time.Until(check.Tightest.ResetsAt).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+
+			logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; %s quota exceeded: used %d of %d", logPrefix, check.Tightest.Window, check.Tightest.Used, check.Tightest.Limit))
+			res := response.Response(http.StatusTooManyRequests, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusTooManyRequests, Message: "Quota exceeded for this API client"}
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, res)
+			return
+		}
+
+		ctx.Next()
+
+		if unused := unusedUnits(ctx, units); unused > 0 {
+			refundCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := m.Service.RefundQuota(refundCtx, client, unused, check.CheckedAt); err != nil {
+				logger.WriteLogWithContext(ctx, logger.LogLevelError, fmt.Sprintf("%s; Service.RefundQuota; Error: %+v", logPrefix, err))
+			}
+		}
+	}
+}
+
+// unusedUnits is how many reserved sends the handler did not make. Handlers
+// that can send to some recipients and not others report their count under
+// utils.CtxKeyQuotaUsed; for the rest an error response means nothing was
+// sent.
+func unusedUnits(ctx *gin.Context, units int) int {
+	if value, ok := ctx.Get(utils.CtxKeyQuotaUsed); ok {
+		used, _ := value.(int)
+		return max(units-used, 0)
+	}
+	if ctx.Writer.Status() >= http.StatusBadRequest {
+		return units
+	}
+	return 0
+}
+
+// setQuotaHeaders reports the tightest limited window. Headers are written
+// before the handler runs, so they describe the state after this request.
+func setQuotaHeaders(ctx *gin.Context, usage *dto.QuotaUsage) {
+	if usage == nil || usage.Remaining == nil {
+		return
+	}
+	ctx.Header("X-Quota-Window", usage.Window)
+	ctx.Header("X-Quota-Limit", strconv.Itoa(usage.Limit))
+	ctx.Header("X-Quota-Remaining", strconv.Itoa(*usage.Remaining))
+	ctx.Header("X-Quota-Reset", strconv.FormatInt(usage.ResetsAt.Unix(), 10))
+}
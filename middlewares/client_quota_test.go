@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecipientCostCountsDistinctAddresses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	body := `{"to":["a@example.test","A@example.test "],"cc":["b@example.test"],"bcc":["a@example.test","c@example.test"]}`
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/email/send", strings.NewReader(body))
+
+	if got := RecipientCost(ctx); got != 3 {
+		t.Errorf("RecipientCost() = %d, want 3", got)
+	}
+}
+
+func TestUnusedUnits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		status int
+		used   interface{}
+		want   int
+	}{
+		{"success", http.StatusOK, nil, 0},
+		{"error without report", http.StatusBadRequest, nil, 5},
+		{"partial send", http.StatusMultiStatus, 2, 3},
+		{"reported failure", http.StatusBadGateway, 0, 5},
+		{"over-reported", http.StatusOK, 7, 0},
+	}
+	for _, tt := range tests {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Status(tt.status)
+		if tt.used != nil {
+			ctx.Set(utils.CtxKeyQuotaUsed, tt.used)
+		}
+		if got := unusedUnits(ctx, 5); got != tt.want {
+			t.Errorf("%s: unusedUnits() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"service-sender/pkg/i18n"
+	"service-sender/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale resolves the API response language from the locale query parameter,
+// then Accept-Language, then APP_DEFAULT_LOCALE, and translates the message
+// fields of JSON responses into it. Other content types are streamed as-is.
+func Locale() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locale := utils.RequestLocale(ctx, ctx.Query("locale"))
+		if locale == "" {
+			locale = i18n.DefaultLocale()
+		}
+		ctx.Set(utils.CtxKeyLocale, locale)
+		ctx.Header("Content-Language", locale)
+
+		if locale == i18n.SourceLocale {
+			ctx.Next()
+			return
+		}
+
+		original := ctx.Writer
+		writer := &localizedWriter{ResponseWriter: original, locale: locale}
+		ctx.Writer = writer
+		defer func() {
+			// Restore first so a recovering middleware writes straight through.
+			ctx.Writer = original
+			writer.flush()
+		}()
+		ctx.Next()
+	}
+}
+
+type localizedWriter struct {
+	gin.ResponseWriter
+	locale    string
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (w *localizedWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.buffering = strings.Contains(w.Header().Get("Content-Type"), "application/json")
+	}
+	if w.buffering {
+		return w.buf.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *localizedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *localizedWriter) flush() {
+	if !w.buffering {
+		return
+	}
+	body := translateBody(w.buf.Bytes(), w.locale)
+	w.Header().Del("Content-Length")
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// translateBody rewrites "message", "error.message" and "error[].message" of
+// a JSON object. Anything it cannot parse is returned unchanged.
+func translateBody(body []byte, locale string) []byte {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+
+	changed := translateField(doc, "message", locale)
+	if raw, ok := doc["error"]; ok {
+		var obj map[string]json.RawMessage
+		var list []map[string]json.RawMessage
+		switch {
+		case json.Unmarshal(raw, &obj) == nil:
+			if translateField(obj, "message", locale) {
+				doc["error"], _ = json.Marshal(obj)
+				changed = true
+			}
+		case json.Unmarshal(raw, &list) == nil:
+			listChanged := false
+			for _, item := range list {
+				listChanged = translateField(item, "message", locale) || listChanged
+			}
+			if listChanged {
+				doc["error"], _ = json.Marshal(list)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return body
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func translateField(obj map[string]json.RawMessage, key, locale string) bool {
+	raw, ok := obj[key]
+	if !ok {
+		return false
+	}
+	var msg string
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return false
+	}
+	translated := i18n.Message(locale, msg)
+	if translated == msg {
+		return false
+	}
+	obj[key], _ = json.Marshal(translated)
+	return true
+}
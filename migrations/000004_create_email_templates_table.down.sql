@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE resource = 'templates';
+DROP TABLE IF EXISTS email_templates;
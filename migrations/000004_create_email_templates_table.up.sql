@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS email_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_key VARCHAR(100) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    subject VARCHAR(200) NOT NULL,
+    text_body TEXT,
+    html_body TEXT,
+    version INT NOT NULL DEFAULT 1,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE(template_key, locale, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_templates_key_locale ON email_templates(template_key, locale);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_active ON email_templates(template_key, locale) WHERE is_active AND deleted_at IS NULL;
+
+INSERT INTO permissions (id, name, display_name, resource, action) VALUES
+    (gen_random_uuid(), 'list_templates', 'List Email Templates', 'templates', 'list'),
+    (gen_random_uuid(), 'view_templates', 'View Email Template Detail', 'templates', 'view'),
+    (gen_random_uuid(), 'create_templates', 'Create Email Templates', 'templates', 'create'),
+    (gen_random_uuid(), 'update_templates', 'Update Email Templates', 'templates', 'update'),
+    (gen_random_uuid(), 'delete_templates', 'Delete Email Templates', 'templates', 'delete'),
+    (gen_random_uuid(), 'rollback_templates', 'Rollback Email Templates', 'templates', 'rollback')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name IN ('superadmin', 'admin')
+AND p.resource = 'templates'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE name IN ('preview_templates', 'test_send_templates');
@@ -0,0 +1,12 @@
+INSERT INTO permissions (id, name, display_name, resource, action) VALUES
+    (gen_random_uuid(), 'preview_templates', 'Preview Email Templates', 'templates', 'preview'),
+    (gen_random_uuid(), 'test_send_templates', 'Test Send Email Templates', 'templates', 'test_send')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name IN ('superadmin', 'admin')
+AND p.name IN ('preview_templates', 'test_send_templates')
+ON CONFLICT DO NOTHING;
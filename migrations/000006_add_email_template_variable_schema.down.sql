@@ -0,0 +1 @@
+ALTER TABLE email_templates DROP COLUMN IF EXISTS variable_schema;
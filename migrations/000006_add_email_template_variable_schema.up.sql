@@ -0,0 +1 @@
+ALTER TABLE email_templates ADD COLUMN IF NOT EXISTS variable_schema JSONB;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE resource IN ('email_deliveries', 'suppressions');
+DROP TABLE IF EXISTS email_suppressions;
+DROP TABLE IF EXISTS email_deliveries;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS email_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id VARCHAR(255) NOT NULL,
+    email_type VARCHAR(50) NOT NULL,
+    app_name VARCHAR(100),
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255),
+    status VARCHAR(30) NOT NULL,
+    status_detail TEXT,
+    last_event_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    opened_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_deliveries_message_id ON email_deliveries(message_id);
+CREATE INDEX IF NOT EXISTS idx_email_deliveries_recipient ON email_deliveries(recipient);
+CREATE INDEX IF NOT EXISTS idx_email_deliveries_created_at ON email_deliveries(created_at);
+
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL UNIQUE,
+    reason VARCHAR(30) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    detail TEXT,
+    message_id VARCHAR(255),
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+INSERT INTO permissions (id, name, display_name, resource, action) VALUES
+    (gen_random_uuid(), 'list_email_deliveries', 'List Email Deliveries', 'email_deliveries', 'list'),
+    (gen_random_uuid(), 'list_suppressions', 'List Email Suppressions', 'suppressions', 'list'),
+    (gen_random_uuid(), 'create_suppressions', 'Create Email Suppressions', 'suppressions', 'create'),
+    (gen_random_uuid(), 'delete_suppressions', 'Delete Email Suppressions', 'suppressions', 'delete')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name IN ('superadmin', 'admin')
+AND p.resource IN ('email_deliveries', 'suppressions')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE resource = 'api_clients';
+DROP TABLE IF EXISTS api_clients;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS api_clients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    previous_key_prefix VARCHAR(20),
+    previous_key_hash VARCHAR(64),
+    previous_key_expires_at TIMESTAMP,
+    allowed_endpoints JSONB NOT NULL DEFAULT '[]',
+    allowed_apps JSONB NOT NULL DEFAULT '[]',
+    require_signature BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_used_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_clients_key_prefix ON api_clients(key_prefix);
+CREATE INDEX IF NOT EXISTS idx_api_clients_previous_key_prefix ON api_clients(previous_key_prefix) WHERE previous_key_prefix IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_clients_name ON api_clients(name) WHERE deleted_at IS NULL;
+
+INSERT INTO permissions (id, name, display_name, resource, action) VALUES
+    (gen_random_uuid(), 'list_api_clients', 'List API Clients', 'api_clients', 'list'),
+    (gen_random_uuid(), 'view_api_clients', 'View API Client Detail', 'api_clients', 'view'),
+    (gen_random_uuid(), 'create_api_clients', 'Create API Clients', 'api_clients', 'create'),
+    (gen_random_uuid(), 'update_api_clients', 'Update API Clients', 'api_clients', 'update'),
+    (gen_random_uuid(), 'delete_api_clients', 'Delete API Clients', 'api_clients', 'delete'),
+    (gen_random_uuid(), 'rotate_api_clients', 'Rotate API Client Keys', 'api_clients', 'rotate')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name = 'superadmin'
+AND p.resource = 'api_clients'
+ON CONFLICT DO NOTHING;
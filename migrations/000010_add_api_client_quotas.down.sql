@@ -0,0 +1,3 @@
+ALTER TABLE api_clients DROP COLUMN IF EXISTS monthly_quota;
+ALTER TABLE api_clients DROP COLUMN IF EXISTS daily_quota;
+ALTER TABLE api_clients DROP COLUMN IF EXISTS burst_per_minute;
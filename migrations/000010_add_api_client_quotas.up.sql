@@ -0,0 +1,3 @@
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS burst_per_minute INT NOT NULL DEFAULT 0;
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS daily_quota INT NOT NULL DEFAULT 0;
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS monthly_quota INT NOT NULL DEFAULT 0;
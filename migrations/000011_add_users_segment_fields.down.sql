@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_attributes;
+DROP INDEX IF EXISTS idx_users_created_at;
+ALTER TABLE users DROP COLUMN IF EXISTS attributes;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
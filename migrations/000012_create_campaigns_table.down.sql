@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE resource = 'campaigns';
+DROP TABLE IF EXISTS campaigns;
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(150) NOT NULL,
+    app_name VARCHAR(100),
+    sender VARCHAR(100),
+    subject VARCHAR(200),
+    template_key VARCHAR(100),
+    template_data JSONB,
+    text_body TEXT,
+    html_body TEXT,
+    markdown_body TEXT,
+    segment JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    rate_per_minute INT NOT NULL DEFAULT 60,
+    total_count INT NOT NULL DEFAULT 0,
+    sent_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    skipped_count INT NOT NULL DEFAULT 0,
+    cursor_id UUID,
+    last_error TEXT,
+    lease_until TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_status ON campaigns(status) WHERE deleted_at IS NULL;
+
+INSERT INTO permissions (id, name, display_name, resource, action) VALUES
+    (gen_random_uuid(), 'list_campaigns', 'List Campaigns', 'campaigns', 'list'),
+    (gen_random_uuid(), 'view_campaigns', 'View Campaign Detail', 'campaigns', 'view'),
+    (gen_random_uuid(), 'create_campaigns', 'Create Campaigns', 'campaigns', 'create'),
+    (gen_random_uuid(), 'update_campaigns', 'Update Campaigns', 'campaigns', 'update'),
+    (gen_random_uuid(), 'delete_campaigns', 'Delete Campaigns', 'campaigns', 'delete'),
+    (gen_random_uuid(), 'control_campaigns', 'Start, Pause, Resume and Cancel Campaigns', 'campaigns', 'control')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE r.name IN ('superadmin', 'admin')
+AND p.resource = 'campaigns'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (user_id, category, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_preferences_opt_out ON notification_preferences(category, channel) WHERE enabled = FALSE;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_endpoint_event;
+DROP TABLE IF EXISTS outbox_processed;
+DROP TABLE IF EXISTS outbox_consumers;
+DROP TABLE IF EXISTS outbox;
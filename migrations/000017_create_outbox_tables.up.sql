@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(100) NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_created_at ON outbox(created_at, id);
+
+CREATE TABLE IF NOT EXISTS outbox_consumers (
+    name VARCHAR(100) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS outbox_processed (
+    consumer VARCHAR(100) NOT NULL REFERENCES outbox_consumers(name) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_processed_event_id ON outbox_processed(event_id);
+
+-- Relayed events may be handed to the webhook consumer again after a crash;
+-- this keeps each endpoint to one delivery per event.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_event ON webhook_deliveries(endpoint_id, event_id);
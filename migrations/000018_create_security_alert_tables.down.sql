@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_devices;
+DROP TABLE IF EXISTS security_alerts;
+ALTER TABLE users DROP COLUMN IF EXISTS locked_at;
@@ -0,0 +1,34 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS security_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID,
+    type VARCHAR(50) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    app_name VARCHAR(100),
+    lock_token_hash VARCHAR(64),
+    lock_expires_at TIMESTAMP,
+    metadata JSONB,
+    sent_at TIMESTAMP,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Relayed events may be handed over again after a crash; one alert is kept
+-- per event.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_security_alerts_event_id ON security_alerts(event_id) WHERE event_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_security_alerts_lock_token_hash ON security_alerts(lock_token_hash) WHERE lock_token_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_security_alerts_user_id ON security_alerts(user_id);
+
+CREATE TABLE IF NOT EXISTS user_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(500),
+    ip VARCHAR(64),
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_devices_user_fingerprint ON user_devices(user_id, fingerprint);
@@ -0,0 +1,13 @@
+-- Accounts that predate the column count their password age from now, so
+-- turning PASSWORD_MAX_AGE on does not expire them all at once.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
+UPDATE users SET password_changed_at = CURRENT_TIMESTAMP WHERE password_changed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS password_histories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_histories_user_created ON password_histories(user_id, created_at DESC);
@@ -0,0 +1,10 @@
+UPDATE webhook_endpoints
+SET events = (
+    SELECT COALESCE(jsonb_agg(DISTINCT CASE e
+        WHEN 'password.reset.requested' THEN 'password_reset.requested'
+        WHEN 'password.reset.verified' THEN 'password_reset.verified'
+        WHEN 'password.reset.completed' THEN 'user.password_reset'
+        ELSE e END), '[]'::jsonb)
+    FROM jsonb_array_elements_text(events) AS e
+)
+WHERE events ?| ARRAY['password.reset.requested', 'password.reset.verified', 'password.reset.completed'];
@@ -0,0 +1,10 @@
+UPDATE webhook_endpoints
+SET events = (
+    SELECT COALESCE(jsonb_agg(DISTINCT CASE e
+        WHEN 'password_reset.requested' THEN 'password.reset.requested'
+        WHEN 'password_reset.verified' THEN 'password.reset.verified'
+        WHEN 'user.password_reset' THEN 'password.reset.completed'
+        ELSE e END), '[]'::jsonb)
+    FROM jsonb_array_elements_text(events) AS e
+)
+WHERE events ?| ARRAY['password_reset.requested', 'password_reset.verified', 'user.password_reset'];
@@ -0,0 +1,2 @@
+ALTER TABLE api_clients DROP COLUMN IF EXISTS previous_signing_secret;
+ALTER TABLE api_clients DROP COLUMN IF EXISTS signing_secret;
@@ -0,0 +1,5 @@
+-- Requests were signed with the API key, which travels in X-API-Key. They are
+-- now signed with a secret of their own, issued when the client is created or
+-- its key rotated. Existing clients get one on their next rotation.
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS signing_secret VARCHAR(100);
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS previous_signing_secret VARCHAR(100);
@@ -0,0 +1,2 @@
+-- The backfilled timestamps cannot be told apart from real ones, so they
+-- are left in place.
@@ -0,0 +1,5 @@
+-- email_verified_at was only ever set by admins, so verified-only segments
+-- matched almost nobody. Registration OTP checks now set it; accounts from
+-- before that have no record either way and count as verified from when
+-- they were created.
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
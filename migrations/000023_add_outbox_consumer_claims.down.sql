@@ -0,0 +1,2 @@
+ALTER TABLE outbox_consumers DROP COLUMN IF EXISTS claimed_until;
+ALTER TABLE outbox_consumers DROP COLUMN IF EXISTS claimed_by;
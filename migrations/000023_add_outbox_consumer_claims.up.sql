@@ -0,0 +1,5 @@
+-- The relay used to hold an advisory lock in an open transaction while a
+-- consumer did its I/O. It now claims the consumer for a while instead and
+-- commits before handing events over.
+ALTER TABLE outbox_consumers ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(100);
+ALTER TABLE outbox_consumers ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
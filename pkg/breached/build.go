@@ -0,0 +1,88 @@
+package breached
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// BuildFile builds a filter at false-positive rate fpRate from the hashes in
+// src and writes it to dst. src is read twice, once to size the filter. It
+// holds one SHA-1 per line as 40 hex digits, optionally followed by ":" and a
+// count, as in the Have I Been Pwned "ordered by hash" download. Lines that
+// are not hashes are skipped and counted.
+func BuildFile(src, dst string, fpRate float64) (added, skipped uint64, err error) {
+	n, _, err := scanFile(src, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	filter, err := New(n, fpRate)
+	if err != nil {
+		return 0, 0, err
+	}
+	added, skipped, err = scanFile(src, filter.AddHash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, 0, err
+	}
+	w := bufio.NewWriter(out)
+	if _, err := filter.WriteTo(w); err != nil {
+		out.Close()
+		return 0, 0, fmt.Errorf("write filter: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return 0, 0, fmt.Errorf("write filter: %w", err)
+	}
+	return added, skipped, out.Close()
+}
+
+// scanFile hands every hash in path to add, if set, and counts the lines
+// that are and are not hashes.
+func scanFile(path string, add func([sha1.Size]byte)) (hashes, skipped uint64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	hashes, skipped, err = scan(f, add)
+	if err != nil {
+		return 0, 0, fmt.Errorf("read %s: %w", path, err)
+	}
+	return hashes, skipped, nil
+}
+
+func scan(r io.Reader, add func([sha1.Size]byte)) (hashes, skipped uint64, err error) {
+	scanner := bufio.NewScanner(r)
+	var sum [sha1.Size]byte
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		digest, _, _ := strings.Cut(line, ":")
+		if len(digest) != hex.EncodedLen(sha1.Size) {
+			skipped++
+			continue
+		}
+		if _, err := hex.Decode(sum[:], []byte(digest)); err != nil {
+			skipped++
+			continue
+		}
+		if add != nil {
+			add(sum)
+		}
+		hashes++
+	}
+	return hashes, skipped, scanner.Err()
+}
@@ -0,0 +1,134 @@
+package breached
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// File layout: magic, version, hash count, two reserved bytes, then the bit
+// count and entry count as little-endian uint64s and the bits themselves.
+const (
+	magic      = "PWBF"
+	version    = 1
+	headerSize = 24
+)
+
+var ErrInvalidFilter = errors.New("invalid breached password filter file")
+
+// Filter is a Bloom filter over the SHA-1 hashes of breached passwords. A
+// password it does not contain has certainly not been seen; one it contains
+// has been, up to the false-positive rate it was built for.
+type Filter struct {
+	bits    []byte
+	m       uint64
+	k       uint8
+	entries uint64
+}
+
+// New sizes an empty filter for n entries at false-positive rate fpRate.
+func New(n uint64, fpRate float64) (*Filter, error) {
+	if fpRate <= 0 || fpRate >= 1 {
+		return nil, fmt.Errorf("false-positive rate %v must be between 0 and 1", fpRate)
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = (m + 7) &^ 7
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	k = max(1, min(k, 30))
+
+	return &Filter{bits: make([]byte, m/8), m: m, k: uint8(k)}, nil
+}
+
+// Load reads a filter written by WriteTo. The whole file stays in memory for
+// the life of the filter: about 1.8 bytes per hash at a 0.001 rate, or some
+// 1.7 GB for the full Have I Been Pwned list.
+func Load(path string) (*Filter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize || string(data[:4]) != magic || data[4] != version {
+		return nil, ErrInvalidFilter
+	}
+
+	f := &Filter{
+		k:       data[5],
+		m:       binary.LittleEndian.Uint64(data[8:16]),
+		entries: binary.LittleEndian.Uint64(data[16:24]),
+		bits:    data[headerSize:],
+	}
+	if f.k == 0 || f.m == 0 || f.m%8 != 0 || uint64(len(f.bits)) != f.m/8 {
+		return nil, ErrInvalidFilter
+	}
+	return f, nil
+}
+
+// WriteTo writes the filter in the format Load reads.
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[4] = version
+	header[5] = f.k
+	binary.LittleEndian.PutUint64(header[8:16], f.m)
+	binary.LittleEndian.PutUint64(header[16:24], f.entries)
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	written, err := w.Write(f.bits)
+	return int64(n + written), err
+}
+
+// AddHash adds the SHA-1 hash of a password.
+func (f *Filter) AddHash(sum [sha1.Size]byte) {
+	h1, h2 := split(sum)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/8] |= 1 << (bit % 8)
+	}
+	f.entries++
+}
+
+// ContainsHash reports whether the SHA-1 hash of a password may be in the
+// filter.
+func (f *Filter) ContainsHash(sum [sha1.Size]byte) bool {
+	h1, h2 := split(sum)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether password may have appeared in a breach.
+func (f *Filter) Contains(password string) bool {
+	return f.ContainsHash(sha1.Sum([]byte(password)))
+}
+
+// Entries is the number of hashes the filter was built from.
+func (f *Filter) Entries() uint64 {
+	return f.entries
+}
+
+// FalsePositiveRate estimates the chance that a password never breached is
+// reported as breached, given how full the filter is.
+func (f *Filter) FalsePositiveRate() float64 {
+	return math.Pow(1-math.Exp(-float64(f.k)*float64(f.entries)/float64(f.m)), float64(f.k))
+}
+
+// split derives the two hashes of double hashing from a SHA-1 sum, which is
+// already uniform. h2 is odd so it never repeats the same bit.
+func split(sum [sha1.Size]byte) (uint64, uint64) {
+	return binary.LittleEndian.Uint64(sum[0:8]), binary.LittleEndian.Uint64(sum[8:16]) | 1
+}
@@ -0,0 +1,81 @@
+package breached
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildFileLoadContains(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "hashes.txt")
+	dst := filepath.Join(dir, "breached.bf")
+
+	sum := sha1.Sum([]byte("hunter2"))
+	lines := []string{
+		strings.ToUpper(hex.EncodeToString(sum[:])) + ":17",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3",
+		"not a hash",
+	}
+	if err := os.WriteFile(src, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	added, skipped, err := BuildFile(src, dst, 0.001)
+	if err != nil {
+		t.Fatalf("BuildFile() error = %v", err)
+	}
+	if added != 2 || skipped != 1 {
+		t.Errorf("BuildFile() = %d added, %d skipped, want 2 and 1", added, skipped)
+	}
+
+	filter, err := Load(dst)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !filter.Contains("hunter2") || !filter.Contains("password") {
+		t.Error("filter should contain the hashes it was built from")
+	}
+	if filter.Contains("correct horse battery staple") {
+		t.Error("filter should not contain a password it was not built from")
+	}
+	if filter.Entries() != 2 {
+		t.Errorf("Entries() = %d, want 2", filter.Entries())
+	}
+}
+
+func TestLoadRejectsCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	filter, err := New(10, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if _, err := filter.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.String()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"bad magic", "XXXX" + valid[4:]},
+		{"bad version", valid[:4] + "\x09" + valid[5:]},
+		{"truncated header", valid[:headerSize-1]},
+		{"truncated bits", valid[:len(valid)-1]},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_"))
+		if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: Load() error = %v, want ErrInvalidFilter", tt.name, err)
+		}
+	}
+}
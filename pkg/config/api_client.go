@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+type ApiClientConfig struct {
+	// Enabled turns on API key checks for the sender routes.
+	Enabled            bool
+	SignatureTolerance time.Duration
+	RotationGrace      time.Duration
+	// MaxBodyBytes caps how much of a signed request body is read to check
+	// its signature.
+	MaxBodyBytes int64
+}
+
+func LoadApiClientConfig() ApiClientConfig {
+	tolerance := time.Duration(utils.GetEnv("API_CLIENT_SIGNATURE_TOLERANCE_SECONDS", 300).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("API_CLIENT_SIGNATURE_TOLERANCE", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			tolerance = d
+		}
+	}
+
+	grace := time.Duration(utils.GetEnv("API_CLIENT_ROTATION_GRACE_SECONDS", 86400).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("API_CLIENT_ROTATION_GRACE", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			grace = d
+		}
+	}
+
+	maxBody := int64(utils.GetEnv("API_CLIENT_MAX_BODY_BYTES", 1<<20).(int))
+	if maxBody <= 0 {
+		maxBody = 1 << 20
+	}
+
+	return ApiClientConfig{
+		Enabled:            utils.GetEnv("API_CLIENT_AUTH_ENABLED", true).(bool),
+		SignatureTolerance: tolerance,
+		RotationGrace:      grace,
+		MaxBodyBytes:       maxBody,
+	}
+}
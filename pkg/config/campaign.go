@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+type CampaignConfig struct {
+	// WorkerEnabled runs the campaign worker in this instance. Several
+	// instances may run it; a campaign is leased to one of them at a time.
+	WorkerEnabled  bool
+	WorkerInterval time.Duration
+	DefaultRate    int
+	MaxRate        int
+}
+
+func LoadCampaignConfig() CampaignConfig {
+	interval := time.Duration(utils.GetEnv("CAMPAIGN_WORKER_INTERVAL_SECONDS", 5).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("CAMPAIGN_WORKER_INTERVAL", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			interval = d
+		}
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	defaultRate := utils.GetEnv("CAMPAIGN_DEFAULT_RATE_PER_MINUTE", 60).(int)
+	maxRate := utils.GetEnv("CAMPAIGN_MAX_RATE_PER_MINUTE", 600).(int)
+	if maxRate <= 0 {
+		maxRate = 600
+	}
+	if defaultRate <= 0 || defaultRate > maxRate {
+		defaultRate = maxRate
+	}
+
+	return CampaignConfig{
+		WorkerEnabled:  utils.GetEnv("CAMPAIGN_WORKER_ENABLED", true).(bool),
+		WorkerInterval: interval,
+		DefaultRate:    defaultRate,
+		MaxRate:        maxRate,
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+type EmailTemplateConfig struct {
+	DefaultLocale string
+	CacheTTL      time.Duration
+}
+
+func LoadEmailTemplateConfig() EmailTemplateConfig {
+	cacheTTL := time.Duration(utils.GetEnv("EMAIL_TEMPLATE_CACHE_TTL_SECONDS", 60).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("EMAIL_TEMPLATE_CACHE_TTL", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cacheTTL = d
+		}
+	}
+
+	locale := strings.ToLower(strings.TrimSpace(utils.GetEnv("EMAIL_DEFAULT_LOCALE", "id").(string)))
+	if locale == "" {
+		locale = "id"
+	}
+
+	return EmailTemplateConfig{
+		DefaultLocale: locale,
+		CacheTTL:      cacheTTL,
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+type EmailWebhookConfig struct {
+	Secret    string
+	Tolerance time.Duration
+}
+
+func LoadEmailWebhookConfig() EmailWebhookConfig {
+	tolerance := time.Duration(utils.GetEnv("EMAIL_WEBHOOK_TOLERANCE_SECONDS", 300).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("EMAIL_WEBHOOK_TOLERANCE", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			tolerance = d
+		}
+	}
+
+	return EmailWebhookConfig{
+		Secret:    strings.TrimSpace(utils.GetEnv("EMAIL_WEBHOOK_SECRET", "").(string)),
+		Tolerance: tolerance,
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+// NATSConfig controls publishing domain events to NATS JetStream. An empty
+// URL disables it.
+type NATSConfig struct {
+	URL       string
+	Name      string
+	Token     string
+	User      string
+	Password  string
+	CredsFile string
+	// Stream is the JetStream stream events are published to. With
+	// CreateStream set it is created, or updated to cover the event
+	// subjects, on connect.
+	Stream       string
+	CreateStream bool
+	// SubjectPrefix is prepended to the event type to make its subject,
+	// e.g. "service-sender.events.user.registered". Subjects overrides the
+	// subject of single events.
+	SubjectPrefix string
+	Subjects      map[string]string
+	// Source is carried in every envelope so consumers can tell publishers
+	// apart.
+	Source  string
+	Timeout time.Duration
+}
+
+func LoadNATSConfig() NATSConfig {
+	timeout := durationEnv("NATS_TIMEOUT", "NATS_TIMEOUT_SECONDS", 5)
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	subjects := map[string]string{}
+	if v := strings.TrimSpace(utils.GetEnv("NATS_SUBJECTS", "").(string)); v != "" {
+		for _, pair := range strings.Split(v, ",") {
+			event, subject, ok := strings.Cut(pair, "=")
+			event, subject = strings.TrimSpace(event), strings.TrimSpace(subject)
+			if ok && event != "" && subject != "" {
+				subjects[event] = subject
+			}
+		}
+	}
+
+	return NATSConfig{
+		URL:           strings.TrimSpace(utils.GetEnv("NATS_URL", "").(string)),
+		Name:          utils.GetEnv("NATS_CLIENT_NAME", "service-sender").(string),
+		Token:         utils.GetEnv("NATS_TOKEN", "").(string),
+		User:          utils.GetEnv("NATS_USER", "").(string),
+		Password:      utils.GetEnv("NATS_PASSWORD", "").(string),
+		CredsFile:     utils.GetEnv("NATS_CREDS_FILE", "").(string),
+		Stream:        utils.GetEnv("NATS_STREAM", "SERVICE_SENDER_EVENTS").(string),
+		CreateStream:  utils.GetEnv("NATS_CREATE_STREAM", true).(bool),
+		SubjectPrefix: strings.Trim(utils.GetEnv("NATS_SUBJECT_PREFIX", "service-sender.events").(string), ". "),
+		Subjects:      subjects,
+		Source:        utils.GetEnv("NATS_SOURCE", "service-sender").(string),
+		Timeout:       timeout,
+	}
+}
+
+// Subject is where events of eventType are published.
+func (c NATSConfig) Subject(eventType string) string {
+	if subject, ok := c.Subjects[eventType]; ok {
+		return subject
+	}
+	if c.SubjectPrefix == "" {
+		return eventType
+	}
+	return c.SubjectPrefix + "." + eventType
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+type NotificationConfig struct {
+	// StreamHeartbeat is how often an idle inbox stream sends a comment line,
+	// keeping proxies from closing it.
+	StreamHeartbeat time.Duration
+	// StreamTokenTTL is how long a token for opening the stream lasts. It
+	// only needs to outlive the connect, as an open stream is not checked
+	// again.
+	StreamTokenTTL time.Duration
+}
+
+func LoadNotificationConfig() NotificationConfig {
+	heartbeat := time.Duration(utils.GetEnv("NOTIFICATION_STREAM_HEARTBEAT_SECONDS", 25).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("NOTIFICATION_STREAM_HEARTBEAT", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			heartbeat = d
+		}
+	}
+	if heartbeat <= 0 {
+		heartbeat = 25 * time.Second
+	}
+
+	tokenTTL := time.Duration(utils.GetEnv("NOTIFICATION_STREAM_TOKEN_TTL_SECONDS", 60).(int)) * time.Second
+	if tokenTTL <= 0 {
+		tokenTTL = time.Minute
+	}
+
+	return NotificationConfig{StreamHeartbeat: heartbeat, StreamTokenTTL: tokenTTL}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"time"
+
+	"service-sender/utils"
+)
+
+type OutboxConfig struct {
+	// RelayEnabled runs the outbox relay in this instance. Several instances
+	// may run it; each consumer is served by one relay at a time.
+	RelayEnabled  bool
+	RelayInterval time.Duration
+	BatchSize     int
+	// Retention is how long relayed events are kept. Events older than this
+	// are deleted once every consumer has handled them.
+	Retention time.Duration
+	// ClaimTTL is how long a relay may hold a consumer for one batch before
+	// another relay may take it over.
+	ClaimTTL time.Duration
+}
+
+func LoadOutboxConfig() OutboxConfig {
+	interval := durationEnv("OUTBOX_RELAY_INTERVAL", "OUTBOX_RELAY_INTERVAL_SECONDS", 1)
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	retention := durationEnv("OUTBOX_RETENTION", "OUTBOX_RETENTION_SECONDS", 604800)
+	if retention <= 0 {
+		retention = 7 * 24 * time.Hour
+	}
+
+	claimTTL := durationEnv("OUTBOX_CLAIM_TTL", "OUTBOX_CLAIM_TTL_SECONDS", 300)
+	if claimTTL <= 0 {
+		claimTTL = 5 * time.Minute
+	}
+
+	batch := utils.GetEnv("OUTBOX_BATCH_SIZE", 100).(int)
+	if batch <= 0 {
+		batch = 100
+	}
+
+	return OutboxConfig{
+		RelayEnabled:  utils.GetEnv("OUTBOX_RELAY_ENABLED", true).(bool),
+		RelayInterval: interval,
+		BatchSize:     batch,
+		Retention:     retention,
+		ClaimTTL:      claimTTL,
+	}
+}
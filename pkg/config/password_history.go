@@ -0,0 +1,43 @@
+package config
+
+import (
+	"time"
+
+	"service-sender/utils"
+)
+
+// PasswordHistoryConfig controls password reuse and expiry.
+type PasswordHistoryConfig struct {
+	// Size is how many of a user's recent passwords, the current one
+	// included, cannot be set again. Zero turns the history off.
+	Size int
+	// MaxAge is how long a password lasts before the user must change it on
+	// their next login. Zero means passwords never expire.
+	MaxAge time.Duration
+	// ChangeTicketTTL is how long the ticket a login with an expired
+	// password gets can be used to change it.
+	ChangeTicketTTL time.Duration
+}
+
+func LoadPasswordHistoryConfig() PasswordHistoryConfig {
+	size := utils.GetEnv("PASSWORD_HISTORY_SIZE", 5).(int)
+	if size < 0 {
+		size = 0
+	}
+
+	maxAge := durationEnv("PASSWORD_MAX_AGE", "PASSWORD_MAX_AGE_SECONDS", 0)
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	ticketTTL := durationEnv("PASSWORD_CHANGE_TICKET_TTL", "PASSWORD_CHANGE_TICKET_TTL_SECONDS", 600)
+	if ticketTTL <= 0 {
+		ticketTTL = 10 * time.Minute
+	}
+
+	return PasswordHistoryConfig{
+		Size:            size,
+		MaxAge:          maxAge,
+		ChangeTicketTTL: ticketTTL,
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"service-sender/utils"
+)
+
+// bcryptMaxLength is the most bytes bcrypt hashes. No password can pass the
+// byte limit with more characters than that, so MaxLength is capped to it.
+const bcryptMaxLength = 72
+
+// Character classes a password policy can require.
+const (
+	PasswordClassLower  = "lower"
+	PasswordClassUpper  = "upper"
+	PasswordClassDigit  = "digit"
+	PasswordClassSymbol = "symbol"
+)
+
+// PasswordPolicy is what a new password must satisfy. Lengths are counted
+// in characters; pkg/passwordpolicy also rejects passwords over the 72 bytes
+// bcrypt hashes. MinEntropy is in bits, as estimated by pkg/passwordpolicy; zero
+// turns the guessability check off.
+type PasswordPolicy struct {
+	MinLength        int      `json:"min_length"`
+	MaxLength        int      `json:"max_length"`
+	RequiredClasses  []string `json:"required_classes"`
+	DisallowPersonal bool     `json:"disallow_personal"`
+	CheckCommon      bool     `json:"check_common"`
+	CheckBreached    bool     `json:"check_breached"`
+	MinEntropy       float64  `json:"min_entropy"`
+}
+
+// PasswordPolicyConfig holds the default policy and the policies of roles
+// that need more. CommonFile lists extra common passwords, one per line,
+// on top of the built-in list. BreachedFile is the breached-password filter
+// built with -build-breached-filter; BreachedFPRate is the false-positive
+// rate it is built for and the most it may have once loaded.
+type PasswordPolicyConfig struct {
+	Default        PasswordPolicy
+	Roles          map[string]PasswordPolicy
+	CommonFile     string
+	BreachedFile   string
+	BreachedFPRate float64
+}
+
+// DefaultBreachedFPRate lets one password in a thousand that was never
+// breached be rejected as breached.
+const DefaultBreachedFPRate = 0.001
+
+// defaultRolePolicies makes admin passwords longer and harder to guess
+// unless PASSWORD_ROLE_POLICIES says otherwise.
+const defaultRolePolicies = `{"admin":{"min_length":12,"min_entropy":60},"superadmin":{"min_length":14,"min_entropy":70}}`
+
+// LoadPasswordPolicyConfig reads the default policy from PASSWORD_* and the
+// role policies from PASSWORD_ROLE_POLICIES, a JSON object keyed by role. A
+// role policy can only tighten the default. The error reports a malformed
+// PASSWORD_ROLE_POLICIES; the default policy is still returned with it.
+func LoadPasswordPolicyConfig() (PasswordPolicyConfig, error) {
+	classes := []string{}
+	for _, class := range strings.Split(utils.GetEnv("PASSWORD_REQUIRED_CLASSES", "lower,upper,digit,symbol").(string), ",") {
+		switch class = strings.ToLower(strings.TrimSpace(class)); class {
+		case PasswordClassLower, PasswordClassUpper, PasswordClassDigit, PasswordClassSymbol:
+			classes = append(classes, class)
+		}
+	}
+
+	def := PasswordPolicy{
+		MinLength:        utils.GetEnv("PASSWORD_MIN_LENGTH", 8).(int),
+		MaxLength:        utils.GetEnv("PASSWORD_MAX_LENGTH", bcryptMaxLength).(int),
+		RequiredClasses:  classes,
+		DisallowPersonal: utils.GetEnv("PASSWORD_DISALLOW_PERSONAL", true).(bool),
+		CheckCommon:      utils.GetEnv("PASSWORD_CHECK_COMMON", true).(bool),
+		CheckBreached:    utils.GetEnv("PASSWORD_CHECK_BREACHED", true).(bool),
+		MinEntropy:       float64(utils.GetEnv("PASSWORD_MIN_ENTROPY", 0).(int)),
+	}
+	if def.MinLength < 1 {
+		def.MinLength = 1
+	}
+	if def.MaxLength <= 0 || def.MaxLength > bcryptMaxLength {
+		def.MaxLength = bcryptMaxLength
+	}
+	if def.MaxLength < def.MinLength {
+		def.MaxLength = def.MinLength
+	}
+
+	fpRate, err := strconv.ParseFloat(strings.TrimSpace(utils.GetEnv("PASSWORD_BREACHED_FP_RATE", "").(string)), 64)
+	if err != nil || fpRate <= 0 || fpRate >= 1 {
+		fpRate = DefaultBreachedFPRate
+	}
+
+	cfg := PasswordPolicyConfig{
+		Default:        def,
+		Roles:          map[string]PasswordPolicy{},
+		CommonFile:     strings.TrimSpace(utils.GetEnv("PASSWORD_COMMON_FILE", "").(string)),
+		BreachedFile:   strings.TrimSpace(utils.GetEnv("PASSWORD_BREACHED_FILE", "").(string)),
+		BreachedFPRate: fpRate,
+	}
+
+	raw := strings.TrimSpace(utils.GetEnv("PASSWORD_ROLE_POLICIES", defaultRolePolicies).(string))
+	if raw == "" {
+		return cfg, nil
+	}
+	var roles map[string]PasswordPolicy
+	if err := json.Unmarshal([]byte(raw), &roles); err != nil {
+		return cfg, fmt.Errorf("PASSWORD_ROLE_POLICIES: %w", err)
+	}
+	for role, policy := range roles {
+		cfg.Roles[strings.ToLower(strings.TrimSpace(role))] = def.Tighten(policy)
+	}
+	return cfg, nil
+}
+
+// For returns the policy of role.
+func (c PasswordPolicyConfig) For(role string) PasswordPolicy {
+	if policy, ok := c.Roles[strings.ToLower(strings.TrimSpace(role))]; ok {
+		return policy
+	}
+	return c.Default
+}
+
+// Tighten combines p with o, keeping the stricter of each rule. Zero values
+// in o leave p's rule as it is.
+func (p PasswordPolicy) Tighten(o PasswordPolicy) PasswordPolicy {
+	out := p
+	if o.MinLength > out.MinLength {
+		out.MinLength = o.MinLength
+	}
+	if o.MaxLength > 0 && o.MaxLength < out.MaxLength {
+		out.MaxLength = o.MaxLength
+	}
+	if out.MaxLength < out.MinLength {
+		out.MaxLength = out.MinLength
+	}
+
+	out.RequiredClasses = append([]string{}, p.RequiredClasses...)
+	for _, class := range o.RequiredClasses {
+		if !slices.Contains(out.RequiredClasses, class) {
+			out.RequiredClasses = append(out.RequiredClasses, class)
+		}
+	}
+
+	out.DisallowPersonal = p.DisallowPersonal || o.DisallowPersonal
+	out.CheckCommon = p.CheckCommon || o.CheckCommon
+	out.CheckBreached = p.CheckBreached || o.CheckBreached
+	if o.MinEntropy > out.MinEntropy {
+		out.MinEntropy = o.MinEntropy
+	}
+	return out
+}
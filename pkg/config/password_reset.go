@@ -7,6 +7,14 @@ import (
 	"service-sender/utils"
 )
 
+// Reset modes say how a user proves they own the email: by following an
+// emailed link, by entering an emailed code, or either.
+const (
+	ResetModeLink = "link"
+	ResetModeCode = "code"
+	ResetModeBoth = "both"
+)
+
 type PasswordResetConfig struct {
 	TTL         time.Duration
 	Cooldown    time.Duration
@@ -14,6 +22,13 @@ type PasswordResetConfig struct {
 	RateLimit   int
 	Secret      string
 	URLTemplate string
+	// Mode applies to apps without an entry in AppModes, which is keyed by
+	// lower-cased X-App-Name.
+	Mode     string
+	AppModes map[string]string
+	// TicketTTL is how long the ticket a verified code is exchanged for
+	// can be used to set the new password.
+	TicketTTL time.Duration
 }
 
 func LoadPasswordResetConfig() PasswordResetConfig {
@@ -46,6 +61,27 @@ func LoadPasswordResetConfig() PasswordResetConfig {
 		urlTemplate = strings.TrimSpace(utils.GetEnv("RESET_URL", "").(string))
 	}
 
+	mode := normalizeResetMode(utils.GetEnv("RESET_MODE", ResetModeLink).(string))
+	if mode == "" {
+		mode = ResetModeLink
+	}
+
+	appModes := map[string]string{}
+	if v := strings.TrimSpace(utils.GetEnv("RESET_APP_MODES", "").(string)); v != "" {
+		for _, pair := range strings.Split(v, ",") {
+			app, appMode, ok := strings.Cut(pair, "=")
+			app = strings.ToLower(strings.TrimSpace(app))
+			if appMode = normalizeResetMode(appMode); ok && app != "" && appMode != "" {
+				appModes[app] = appMode
+			}
+		}
+	}
+
+	ticketTTL := durationEnv("RESET_TICKET_TTL", "RESET_TICKET_TTL_SECONDS", 600)
+	if ticketTTL <= 0 {
+		ticketTTL = 10 * time.Minute
+	}
+
 	return PasswordResetConfig{
 		TTL:         ttl,
 		Cooldown:    cooldown,
@@ -53,5 +89,34 @@ func LoadPasswordResetConfig() PasswordResetConfig {
 		RateLimit:   rateLimit,
 		Secret:      secret,
 		URLTemplate: urlTemplate,
+		Mode:        mode,
+		AppModes:    appModes,
+		TicketTTL:   ticketTTL,
+	}
+}
+
+// ModeFor returns the reset mode configured for appName.
+func (c PasswordResetConfig) ModeFor(appName string) string {
+	if mode, ok := c.AppModes[strings.ToLower(strings.TrimSpace(appName))]; ok {
+		return mode
+	}
+	if c.Mode == "" {
+		return ResetModeLink
+	}
+	return c.Mode
+}
+
+// Allows reports whether appName may reset passwords with mode, which is
+// ResetModeLink or ResetModeCode.
+func (c PasswordResetConfig) Allows(appName, mode string) bool {
+	configured := c.ModeFor(appName)
+	return configured == ResetModeBoth || configured == mode
+}
+
+func normalizeResetMode(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case ResetModeLink, ResetModeCode, ResetModeBoth:
+		return mode
 	}
+	return ""
 }
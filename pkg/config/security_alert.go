@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+// SecurityAlertConfig controls the emails telling users about changes to
+// their account.
+type SecurityAlertConfig struct {
+	Enabled bool
+	// NewDevice alerts users to logins from a User-Agent they have not
+	// logged in with before. Their first login never raises one.
+	NewDevice bool
+	// AppName brands alerts raised outside a request, such as those for
+	// relayed account changes.
+	AppName string
+	// LockURLTemplate is the frontend page behind the "it wasn't me" link;
+	// {token} is replaced with the lock token. Without it alerts carry no
+	// link.
+	LockURLTemplate string
+	LockTTL         time.Duration
+	Secret          string
+}
+
+func LoadSecurityAlertConfig() SecurityAlertConfig {
+	lockTTL := durationEnv("SECURITY_LOCK_TTL", "SECURITY_LOCK_TTL_SECONDS", 7*24*3600)
+	if lockTTL <= 0 {
+		lockTTL = 7 * 24 * time.Hour
+	}
+
+	appName := strings.TrimSpace(utils.GetEnv("SECURITY_ALERT_APP_NAME", "").(string))
+	if appName == "" {
+		appName = strings.TrimSpace(utils.GetEnv("OTP_APP_NAME", "Account Verification").(string))
+	}
+
+	secret := strings.TrimSpace(utils.GetEnv("SECURITY_LOCK_SECRET", "").(string))
+	if secret == "" {
+		secret = strings.TrimSpace(utils.GetEnv("RESET_SECRET", "reset-secret").(string))
+	}
+
+	return SecurityAlertConfig{
+		Enabled:         utils.GetEnv("SECURITY_ALERT_ENABLED", true).(bool),
+		NewDevice:       utils.GetEnv("SECURITY_ALERT_NEW_DEVICE", true).(bool),
+		AppName:         appName,
+		LockURLTemplate: strings.TrimSpace(utils.GetEnv("SECURITY_LOCK_URL_TEMPLATE", "").(string)),
+		LockTTL:         lockTTL,
+		Secret:          secret,
+	}
+}
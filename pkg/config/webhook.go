@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+// WebhookConfig controls outbound webhook deliveries to subscriber endpoints.
+type WebhookConfig struct {
+	// WorkerEnabled runs the delivery worker in this instance. Several
+	// instances may run it; deliveries are leased to one at a time.
+	WorkerEnabled  bool
+	WorkerInterval time.Duration
+	BatchSize      int
+	Timeout        time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed. Retries wait RetryBackoff, doubling each time up to MaxBackoff.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+}
+
+func LoadWebhookConfig() WebhookConfig {
+	interval := durationEnv("WEBHOOK_WORKER_INTERVAL", "WEBHOOK_WORKER_INTERVAL_SECONDS", 5)
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	timeout := durationEnv("WEBHOOK_TIMEOUT", "WEBHOOK_TIMEOUT_SECONDS", 10)
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	backoff := durationEnv("WEBHOOK_RETRY_BACKOFF", "WEBHOOK_RETRY_BACKOFF_SECONDS", 30)
+	if backoff <= 0 {
+		backoff = 30 * time.Second
+	}
+
+	maxBackoff := durationEnv("WEBHOOK_MAX_BACKOFF", "WEBHOOK_MAX_BACKOFF_SECONDS", 21600)
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+
+	batch := utils.GetEnv("WEBHOOK_BATCH_SIZE", 50).(int)
+	if batch <= 0 {
+		batch = 50
+	}
+
+	attempts := utils.GetEnv("WEBHOOK_MAX_ATTEMPTS", 8).(int)
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	return WebhookConfig{
+		WorkerEnabled:  utils.GetEnv("WEBHOOK_WORKER_ENABLED", true).(bool),
+		WorkerInterval: interval,
+		BatchSize:      batch,
+		Timeout:        timeout,
+		MaxAttempts:    attempts,
+		RetryBackoff:   backoff,
+		MaxBackoff:     maxBackoff,
+	}
+}
+
+// durationEnv reads key as a duration string such as "90s", falling back to
+// secondsKey as a whole number of seconds.
+func durationEnv(key, secondsKey string, seconds int) time.Duration {
+	if v := strings.TrimSpace(utils.GetEnv(key, "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return time.Duration(utils.GetEnv(secondsKey, seconds).(int)) * time.Second
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"service-sender/utils"
+)
+
+// defaultPushHosts are the push services of the major browsers. Endpoints
+// are matched on the host or any subdomain of it.
+var defaultPushHosts = []string{
+	"fcm.googleapis.com",
+	"updates.push.services.mozilla.com",
+	"notify.windows.com",
+	"push.apple.com",
+}
+
+type WebPushConfig struct {
+	TTL          time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// AllowedHosts limits the endpoints users may register, since the
+	// service posts to them. AllowHTTP admits plain http endpoints, which
+	// only a local push service stub should need.
+	AllowedHosts []string
+	AllowHTTP    bool
+}
+
+func LoadWebPushConfig() WebPushConfig {
+	ttl := time.Duration(utils.GetEnv("WEBPUSH_TTL_SECONDS", 86400).(int)) * time.Second
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	backoff := time.Duration(utils.GetEnv("WEBPUSH_RETRY_BACKOFF_SECONDS", 1).(int)) * time.Second
+	if v := strings.TrimSpace(utils.GetEnv("WEBPUSH_RETRY_BACKOFF", "").(string)); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			backoff = d
+		}
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	retries := utils.GetEnv("WEBPUSH_MAX_RETRIES", 2).(int)
+	if retries < 0 {
+		retries = 0
+	}
+
+	hosts := defaultPushHosts
+	if v := strings.TrimSpace(utils.GetEnv("WEBPUSH_ALLOWED_HOSTS", "").(string)); v != "" {
+		hosts = nil
+		for _, host := range strings.Split(v, ",") {
+			if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	}
+
+	return WebPushConfig{
+		TTL:          ttl,
+		MaxRetries:   retries,
+		RetryBackoff: backoff,
+		AllowedHosts: hosts,
+		AllowHTTP:    utils.GetEnv("WEBPUSH_ALLOW_HTTP", false).(bool),
+	}
+}
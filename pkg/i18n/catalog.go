@@ -0,0 +1,339 @@
+package i18n
+
+// Email copy, keyed by message id.
+
+var catalogID = map[string]string{
+	"common.hello":       "Halo",
+	"common.user":        "Pengguna",
+	"common.greeting":    "Halo,",
+	"common.thanks":      "Terima kasih,",
+	"common.regards":     "Salam,",
+	"common.team":        "%s Team",
+	"common.view_detail": "Lihat Detail",
+	"layout.support":     "Butuh bantuan?",
+	"layout.rights":      "© %d %s. All rights reserved.",
+
+	"otp.subject":     "Kode OTP Pendaftaran Anda",
+	"otp.title":       "Kode Verifikasi - %s",
+	"otp.heading":     "Verifikasi Akun Anda",
+	"otp.instruction": "Gunakan kode verifikasi berikut untuk menyelesaikan pendaftaran akun Anda:",
+	"otp.code_label":  "Kode Verifikasi",
+	"otp.expiry":      "⏱️ Kode ini akan kadaluarsa dalam %d menit",
+	"otp.warning":     "🔒 Jangan bagikan kode ini kepada siapapun termasuk pihak yang mengaku dari %s.",
+	"otp.ignore":      "Jika Anda tidak merasa mendaftar di %s, abaikan email ini.",
+	"otp.text":        "Kode verifikasi pendaftaran kamu: %s\nKode ini akan kadaluarsa dalam %d menit.\nJika kamu tidak merasa mendaftar, abaikan email ini.\n",
+
+	"reset.subject":      "Reset Password Anda",
+	"reset.title":        "Reset Password - %s",
+	"reset.heading":      "Reset Password",
+	"reset.intro":        "Kami menerima permintaan reset password untuk akun Anda.",
+	"reset.button":       "Reset Password",
+	"reset.link_hint":    "Jika tombol di atas tidak berfungsi, salin link berikut ke browser Anda:",
+	"reset.token_label":  "Token Reset",
+	"reset.expiry_link":  "⏱️ Link ini kadaluarsa dalam %d menit",
+	"reset.expiry_token": "⏱️ Token ini kadaluarsa dalam %d menit",
+	"reset.ignore":       "🔒 Jika Anda tidak meminta reset password, abaikan email ini.",
+	"reset.text_intro":   "Kami menerima permintaan reset password untuk akun %s.\n",
+	"reset.text_link":    "Link reset: %s\n",
+	"reset.text_token":   "Token reset: %s\n",
+	"reset.text_expiry":  "%s kadaluarsa dalam %d menit.\nJika kamu tidak meminta reset, abaikan email ini.\n",
+	"reset.label_link":   "Link ini",
+	"reset.label_token":  "Token ini",
+
+	"security.password_changed.subject": "Password akun %s Anda telah diubah",
+	"security.password_changed.heading": "Password Anda Telah Diubah",
+	"security.password_changed.intro":   "Password akun %s Anda baru saja diubah.",
+	"security.password_reset.subject":   "Password akun %s Anda telah direset",
+	"security.password_reset.heading":   "Password Anda Telah Direset",
+	"security.password_reset.intro":     "Password akun %s Anda baru saja direset dan semua sesi login telah diakhiri.",
+	"security.email_changed.subject":    "Email akun %s Anda telah diubah",
+	"security.email_changed.heading":    "Email Anda Telah Diubah",
+	"security.email_changed.intro":      "Alamat email akun %s Anda baru saja diubah. Email ini dikirim ke alamat lama Anda.",
+	"security.role_changed.subject":     "Peran akun %s Anda telah diubah",
+	"security.role_changed.heading":     "Peran Anda Telah Diubah",
+	"security.role_changed.intro":       "Peran akun %s Anda baru saja diubah.",
+	"security.account_deleted.subject":  "Akun %s Anda telah dihapus",
+	"security.account_deleted.heading":  "Akun Anda Telah Dihapus",
+	"security.account_deleted.intro":    "Akun %s Anda baru saja dihapus.",
+	"security.new_device.subject":       "Login baru ke akun %s Anda",
+	"security.new_device.heading":       "Login dari Perangkat Baru",
+	"security.new_device.intro":         "Akun %s Anda baru saja digunakan untuk login dari perangkat yang belum pernah dipakai sebelumnya.",
+	"security.detail_time":              "Waktu",
+	"security.detail_ip":                "Alamat IP",
+	"security.detail_device":            "Perangkat",
+	"security.detail_previous_email":    "Email sebelumnya",
+	"security.detail_new_email":         "Email baru",
+	"security.detail_previous_role":     "Peran sebelumnya",
+	"security.detail_new_role":          "Peran baru",
+	"security.lock_intro":               "Bukan Anda? Kunci akun Anda sekarang. Semua sesi login akan diakhiri dan link reset password dikirim ke email ini.",
+	"security.lock_button":              "Bukan Saya, Kunci Akun",
+	"security.lock_expiry":              "⏱️ Link ini berlaku selama %d jam",
+	"security.contact_support":          "🔒 Jika ini bukan Anda, segera hubungi tim dukungan kami.",
+	"security.ignore":                   "Jika ini memang Anda, tidak ada yang perlu dilakukan.",
+	"security.text_lock":                "Jika ini bukan Anda, kunci akun Anda di: %s\nLink ini berlaku selama %d jam.\n",
+
+	"campaign.label":            "Campaign",
+	"campaign.fallback_subject": "Campaign Update",
+	"campaign.fallback_message": "Kami punya update terbaru untuk Anda.",
+	"campaign.read_more":        "Lihat selengkapnya:",
+	"campaign.link_hint":        "Jika tombol tidak berfungsi, buka link ini:",
+
+	"info.label":            "Informasi",
+	"info.fallback_subject": "Informasi Penting",
+	"info.fallback_title":   "Informasi Penting",
+	"info.fallback_message": "Ada informasi penting untuk Anda.",
+	"info.reference":        "Referensi",
+
+	"notification.label":            "Notification",
+	"notification.fallback_subject": "Notifikasi",
+	"notification.fallback_title":   "Notifikasi Baru",
+	"notification.fallback_message": "Anda menerima notifikasi baru.",
+	"notification.follow_up":        "Tindak lanjuti di:",
+	"notification.time":             "Waktu",
+	"notification.now":              "Sekarang",
+
+	"calendar.label":           "Undangan Acara",
+	"calendar.invite_subject":  "Undangan: %s",
+	"calendar.cancel_subject":  "Dibatalkan: %s",
+	"calendar.invite_intro":    "Anda diundang ke acara berikut.",
+	"calendar.cancel_intro":    "Acara berikut telah dibatalkan.",
+	"calendar.when":            "Waktu",
+	"calendar.where":           "Lokasi",
+	"calendar.organizer":       "Penyelenggara",
+	"calendar.details":         "Lihat Detail",
+	"calendar.attachment_hint": "Undangan kalender terlampir. Buka lampiran untuk menambahkan acara ke kalender Anda.",
+}
+
+var catalogEN = map[string]string{
+	"common.hello":       "Hello",
+	"common.user":        "there",
+	"common.greeting":    "Hello,",
+	"common.thanks":      "Thank you,",
+	"common.regards":     "Regards,",
+	"common.team":        "%s Team",
+	"common.view_detail": "View Details",
+	"layout.support":     "Need help?",
+	"layout.rights":      "© %d %s. All rights reserved.",
+
+	"otp.subject":     "Your Registration OTP",
+	"otp.title":       "Verification Code - %s",
+	"otp.heading":     "Verify Your Account",
+	"otp.instruction": "Use the following verification code to finish creating your account:",
+	"otp.code_label":  "Verification Code",
+	"otp.expiry":      "⏱️ This code expires in %d minutes",
+	"otp.warning":     "🔒 Never share this code with anyone, including people claiming to be from %s.",
+	"otp.ignore":      "If you did not sign up for %s, please ignore this email.",
+	"otp.text":        "Your registration verification code: %s\nThis code expires in %d minutes.\nIf you did not sign up, please ignore this email.\n",
+
+	"reset.subject":      "Reset Your Password",
+	"reset.title":        "Reset Password - %s",
+	"reset.heading":      "Reset Password",
+	"reset.intro":        "We received a request to reset the password for your account.",
+	"reset.button":       "Reset Password",
+	"reset.link_hint":    "If the button above does not work, copy this link into your browser:",
+	"reset.token_label":  "Reset Token",
+	"reset.expiry_link":  "⏱️ This link expires in %d minutes",
+	"reset.expiry_token": "⏱️ This token expires in %d minutes",
+	"reset.ignore":       "🔒 If you did not request a password reset, please ignore this email.",
+	"reset.text_intro":   "We received a request to reset the password for your %s account.\n",
+	"reset.text_link":    "Reset link: %s\n",
+	"reset.text_token":   "Reset token: %s\n",
+	"reset.text_expiry":  "%s expires in %d minutes.\nIf you did not request a reset, please ignore this email.\n",
+	"reset.label_link":   "This link",
+	"reset.label_token":  "This token",
+
+	"security.password_changed.subject": "Your %s password was changed",
+	"security.password_changed.heading": "Your Password Was Changed",
+	"security.password_changed.intro":   "The password of your %s account was just changed.",
+	"security.password_reset.subject":   "Your %s password was reset",
+	"security.password_reset.heading":   "Your Password Was Reset",
+	"security.password_reset.intro":     "The password of your %s account was just reset and every login session was signed out.",
+	"security.email_changed.subject":    "Your %s email address was changed",
+	"security.email_changed.heading":    "Your Email Address Was Changed",
+	"security.email_changed.intro":      "The email address of your %s account was just changed. This notice is sent to your previous address.",
+	"security.role_changed.subject":     "Your %s role was changed",
+	"security.role_changed.heading":     "Your Role Was Changed",
+	"security.role_changed.intro":       "The role of your %s account was just changed.",
+	"security.account_deleted.subject":  "Your %s account was deleted",
+	"security.account_deleted.heading":  "Your Account Was Deleted",
+	"security.account_deleted.intro":    "Your %s account was just deleted.",
+	"security.new_device.subject":       "New login to your %s account",
+	"security.new_device.heading":       "Login from a New Device",
+	"security.new_device.intro":         "Your %s account was just used to log in from a device it has not been used on before.",
+	"security.detail_time":              "Time",
+	"security.detail_ip":                "IP address",
+	"security.detail_device":            "Device",
+	"security.detail_previous_email":    "Previous email",
+	"security.detail_new_email":         "New email",
+	"security.detail_previous_role":     "Previous role",
+	"security.detail_new_role":          "New role",
+	"security.lock_intro":               "Wasn't you? Lock your account now. Every login session will be signed out and a password reset link sent to this address.",
+	"security.lock_button":              "It Wasn't Me, Lock My Account",
+	"security.lock_expiry":              "⏱️ This link works for %d hours",
+	"security.contact_support":          "🔒 If this wasn't you, contact our support team right away.",
+	"security.ignore":                   "If this was you, no action is needed.",
+	"security.text_lock":                "If this wasn't you, lock your account at: %s\nThis link works for %d hours.\n",
+
+	"campaign.label":            "Campaign",
+	"campaign.fallback_subject": "Campaign Update",
+	"campaign.fallback_message": "We have a new update for you.",
+	"campaign.read_more":        "Read more:",
+	"campaign.link_hint":        "If the button does not work, open this link:",
+
+	"info.label":            "Information",
+	"info.fallback_subject": "Important Information",
+	"info.fallback_title":   "Important Information",
+	"info.fallback_message": "There is important information for you.",
+	"info.reference":        "Reference",
+
+	"notification.label":            "Notification",
+	"notification.fallback_subject": "Notification",
+	"notification.fallback_title":   "New Notification",
+	"notification.fallback_message": "You have a new notification.",
+	"notification.follow_up":        "Follow up at:",
+	"notification.time":             "Time",
+	"notification.now":              "Now",
+
+	"calendar.label":           "Event Invitation",
+	"calendar.invite_subject":  "Invitation: %s",
+	"calendar.cancel_subject":  "Cancelled: %s",
+	"calendar.invite_intro":    "You are invited to the following event.",
+	"calendar.cancel_intro":    "The following event has been cancelled.",
+	"calendar.when":            "When",
+	"calendar.where":           "Where",
+	"calendar.organizer":       "Organizer",
+	"calendar.details":         "View Details",
+	"calendar.attachment_hint": "The calendar invite is attached. Open it to add the event to your calendar.",
+}
+
+// API messages, keyed by their SourceLocale text.
+
+var apiMessages = map[string]map[string]string{
+	LocaleID: {
+		"Invalid request format. Please ensure the structure is correct and matches the expected data format.": "Format permintaan tidak valid. Pastikan struktur sesuai dengan format data yang diharapkan.",
+		"Invalid header format. Please ensure the structure is correct and matches the expected data format.":  "Format header tidak valid. Pastikan struktur sesuai dengan format data yang diharapkan.",
+		"Something Went Wrong": "Terjadi Kesalahan",
+		"Access Denied":        "Akses Ditolak",
+		"Forbidden":            "Dilarang",
+		"Invalid Credentials. Please input the correct credentials and try again.": "Kredensial tidak valid. Masukkan kredensial yang benar lalu coba lagi.",
+		"Already exists.": "Sudah ada.",
+		"Data Not Found":  "Data Tidak Ditemukan",
+		"The requested resource could not be found": "Sumber daya yang diminta tidak ditemukan",
+		"Success": "Berhasil",
+		"Updated": "Diperbarui",
+		"No properties to update has been provided in request. Please specify at least one property which needs to be updated.": "Tidak ada properti yang diperbarui. Tentukan minimal satu properti yang perlu diperbarui.",
+		"Invalid email or password":                                "Email atau password salah",
+		"Access denied. You do not have the required permissions.": "Akses ditolak. Anda tidak memiliki izin yang diperlukan.",
+
+		"Email sender is not available":                                               "Layanan pengirim email tidak tersedia",
+		"Failed to send email":                                                        "Gagal mengirim email",
+		"Failed to send reset email":                                                  "Gagal mengirim email reset",
+		"Invalid or expired token":                                                    "Token tidak valid atau sudah kadaluarsa",
+		"OTP service is not available":                                                "Layanan OTP tidak tersedia",
+		"OTP verification failed":                                                     "Verifikasi OTP gagal",
+		"Password reset service is not available":                                     "Layanan reset password tidak tersedia",
+		"quota metering is not available":                                             "pengukuran kuota tidak tersedia",
+		"Quota exceeded for this API client":                                          "Kuota klien API ini sudah habis",
+		"Please wait before requesting another OTP":                                   "Mohon tunggu sebelum meminta OTP lagi",
+		"Please wait before requesting another reset email":                           "Mohon tunggu sebelum meminta email reset lagi",
+		"Rate limit exceeded for this endpoint":                                       "Batas permintaan untuk endpoint ini terlampaui",
+		"Too many requests from this IP, please try again later":                      "Terlalu banyak permintaan dari IP ini, coba lagi nanti",
+		"Unable to process OTP request":                                               "Permintaan OTP tidak dapat diproses",
+		"Unable to process reset request":                                             "Permintaan reset tidak dapat diproses",
+		"Password reset successfully":                                                 "Password berhasil direset",
+		"Password was changed but existing sessions could not be signed out":          "Password sudah diubah tetapi sesi yang ada tidak dapat dikeluarkan",
+		"Unable to reset password":                                                    "Password tidak dapat direset",
+		"password must be at least 8 characters long":                                 "password minimal 8 karakter",
+		"password must contain at least 1 lowercase letter (a-z)":                     "password harus mengandung minimal 1 huruf kecil (a-z)",
+		"password must contain at least 1 uppercase letter (A-Z)":                     "password harus mengandung minimal 1 huruf besar (A-Z)",
+		"password must contain at least 1 number (0-9)":                               "password harus mengandung minimal 1 angka (0-9)",
+		"password must contain at least 1 symbol (!@#$%^&*...)":                       "password harus mengandung minimal 1 simbol (!@#$%^&*...)",
+		"password must be at least 12 characters long":                                "password minimal 12 karakter",
+		"password must be at least 14 characters long":                                "password minimal 14 karakter",
+		"password must be at most 72 bytes long; some characters take more than one":  "password maksimal 72 byte; beberapa karakter memakan lebih dari satu byte",
+		"password must be at most 72 characters long":                                 "password maksimal 72 karakter",
+		"password must not contain your email or name":                                "password tidak boleh mengandung email atau nama Anda",
+		"password is too common":                                                      "password terlalu umum",
+		"password is too easy to guess":                                               "password terlalu mudah ditebak",
+		"password has appeared in a data breach":                                      "password pernah muncul dalam kebocoran data",
+		"password was used recently, choose a different one":                          "password ini baru saja digunakan, pilih password lain",
+		"Password expired. Change it to continue":                                     "Password kedaluwarsa. Ubah password untuk melanjutkan",
+		"Password change required":                                                    "Password harus diubah terlebih dahulu",
+		"Invalid or expired ticket":                                                   "Tiket tidak valid atau kedaluwarsa",
+		"Unable to change password":                                                   "Tidak dapat mengubah password",
+		"Invalid or expired code":                                                     "Kode tidak valid atau sudah kadaluarsa",
+		"Reset mode is not allowed for this app":                                      "Mode reset tidak diizinkan untuk aplikasi ini",
+		"Too many attempts, please request a new code":                                "Terlalu banyak percobaan, silakan minta kode baru",
+		"Unable to verify code":                                                       "Kode tidak dapat diverifikasi",
+		"Account locked. Check your email to reset your password":                     "Akun dikunci. Periksa email Anda untuk mereset password",
+		"Account locked and password reset sent":                                      "Akun dikunci dan reset password telah dikirim",
+		"Account locked. Request a password reset to unlock it":                       "Akun dikunci. Minta reset password untuk membukanya",
+		"Your previous email address now belongs to another account. Contact support": "Alamat email Anda sebelumnya kini dimiliki akun lain. Hubungi dukungan",
+		"Invalid or expired link":                                                     "Link tidak valid atau sudah kadaluarsa",
+		"Security alerts are not available":                                           "Peringatan keamanan tidak tersedia",
+		"Unable to lock account":                                                      "Akun tidak dapat dikunci",
+		"Unable to verify token":                                                      "Token tidak dapat diverifikasi",
+		"current password is incorrect":                                               "password saat ini salah",
+		"email or phone already exists":                                               "email atau nomor telepon sudah terdaftar",
+		"email template not found":                                                    "template email tidak ditemukan",
+		"sender is not allowed for this type or app":                                  "pengirim tidak diizinkan untuk tipe atau aplikasi ini",
+		"sender is not registered":                                                    "pengirim tidak terdaftar",
+		"subject is required when template_key is empty":                              "subject wajib diisi jika template_key kosong",
+		"template_key is not registered":                                              "template_key tidak terdaftar",
+		"event is required for calendar emails":                                       "event wajib diisi untuk email kalender",
+		"event.timezone is not a valid IANA timezone":                                 "event.timezone bukan zona waktu IANA yang valid",
+		"text_body, html_body or markdown_body is required":                           "text_body, html_body atau markdown_body wajib diisi",
+		"API client authentication is not available":                                  "autentikasi klien API tidak tersedia",
+		"X-API-Key header is required":                                                "header X-API-Key wajib diisi",
+		"api client is inactive":                                                      "klien API tidak aktif",
+		"api client is not allowed to call this endpoint":                             "klien API tidak diizinkan memanggil endpoint ini",
+		"api client is not allowed to send for this app":                              "klien API tidak diizinkan mengirim untuk aplikasi ini",
+		"api client not found":                                                        "klien API tidak ditemukan",
+		"api client with this name already exists":                                    "klien API dengan nama ini sudah ada",
+		"api key is invalid":                                                          "API key tidak valid",
+		"request nonce has already been used":                                         "nonce permintaan sudah pernah digunakan",
+		"request signing is not available":                                            "penandatanganan permintaan tidak tersedia",
+		"api client has no signing secret; rotate its key to get one":                 "klien API belum memiliki secret penandatanganan; rotasi kuncinya untuk mendapatkannya",
+		"request signature headers are missing":                                       "header tanda tangan permintaan tidak lengkap",
+		"request body is too large":                                                   "body permintaan terlalu besar",
+		"request signature is invalid":                                                "tanda tangan permintaan tidak valid",
+		"request timestamp outside tolerance":                                         "timestamp permintaan di luar toleransi",
+		"usage metering is not available":                                             "pencatatan pemakaian tidak tersedia",
+		"all recipients are on the suppression list":                                  "semua penerima ada di daftar supresi",
+		"email suppression not found":                                                 "supresi email tidak ditemukan",
+		"email webhooks are not configured":                                           "webhook email belum dikonfigurasi",
+		"unknown webhook provider":                                                    "penyedia webhook tidak dikenal",
+		"webhook authentication failed":                                               "autentikasi webhook gagal",
+		"all recipients opted out of this category":                                   "semua penerima memilih berhenti menerima kategori ini",
+		"private_to cannot be combined with cc or bcc":                                "private_to tidak dapat digabung dengan cc atau bcc",
+		"Email sent to some recipients":                                               "Email terkirim ke sebagian penerima",
+		"campaigns cannot be sent in the security category":                           "kampanye tidak dapat dikirim dalam kategori keamanan",
+		"security notifications cannot be turned off":                                 "notifikasi keamanan tidak dapat dinonaktifkan",
+		"user not found":                                                              "pengguna tidak ditemukan",
+		"unsupported locale":                                                          "bahasa tidak didukung",
+		"notification rule not found":                                                 "aturan notifikasi tidak ditemukan",
+		"user_id targets require the database":                                        "target user_id memerlukan database",
+		"push subscription not found":                                                 "langganan push tidak ditemukan",
+		"web push is not configured":                                                  "web push belum dikonfigurasi",
+		"push endpoint is not an allowed push service":                                "endpoint push bukan layanan push yang diizinkan",
+		"push subscription keys are invalid":                                          "kunci langganan push tidak valid",
+		"webhook endpoint not found":                                                  "endpoint webhook tidak ditemukan",
+		"webhook delivery not found":                                                  "pengiriman webhook tidak ditemukan",
+		"webhook url must be an absolute http or https url":                           "url webhook harus berupa url http atau https absolut",
+		"unknown webhook event":                                                       "event webhook tidak dikenal",
+		"notification not found":                                                      "notifikasi tidak ditemukan",
+		"campaign not found":                                                          "kampanye tidak ditemukan",
+		"campaign status does not allow this action":                                  "status kampanye tidak mengizinkan tindakan ini",
+		"one of template_key, text_body, html_body or markdown_body must be provided": "salah satu dari template_key, text_body, html_body atau markdown_body wajib diisi",
+		"only draft campaigns can be edited":                                          "hanya kampanye draf yang dapat diubah",
+		"rate_per_minute exceeds the configured maximum":                              "rate_per_minute melebihi batas maksimum yang dikonfigurasi",
+		"running or paused campaigns must be cancelled before they are deleted":       "kampanye yang berjalan atau dijeda harus dibatalkan sebelum dihapus",
+		"your account has no email address to send the test to":                       "akun Anda tidak memiliki alamat email untuk pengiriman uji",
+
+		"This field is required": "Kolom ini wajib diisi",
+		"Invalid email":          "Email tidak valid",
+		"Invalid value":          "Nilai tidak valid",
+		"Invalid URL":            "URL tidak valid",
+		"Unknown variable":       "Variabel tidak dikenal",
+	},
+}
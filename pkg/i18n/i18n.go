@@ -0,0 +1,137 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LocaleID = "id"
+	LocaleEN = "en"
+)
+
+// SourceLocale is the language pkg/messages and handler error strings are
+// written in; API message catalogs translate from it.
+const SourceLocale = LocaleEN
+
+var catalogs = map[string]map[string]string{
+	LocaleID: catalogID,
+	LocaleEN: catalogEN,
+}
+
+// Supported reports whether a catalog exists for locale.
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Normalize maps a tag such as "en-US" or "ID" to a supported locale, or ""
+// when neither the tag nor its base language is supported.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, "_", "-")))
+	if tag == "" {
+		return ""
+	}
+	if Supported(tag) {
+		return tag
+	}
+	if base, _, ok := strings.Cut(tag, "-"); ok && Supported(base) {
+		return base
+	}
+	return ""
+}
+
+// DefaultLocale is the last step of the resolution chain for API messages,
+// read from APP_DEFAULT_LOCALE.
+func DefaultLocale() string {
+	if v := Normalize(os.Getenv("APP_DEFAULT_LOCALE")); v != "" {
+		return v
+	}
+	return SourceLocale
+}
+
+// EmailDefaultLocale is the last step of the resolution chain for email copy,
+// read from EMAIL_DEFAULT_LOCALE so existing deployments keep Indonesian mail.
+func EmailDefaultLocale() string {
+	if v := Normalize(os.Getenv("EMAIL_DEFAULT_LOCALE")); v != "" {
+		return v
+	}
+	return LocaleID
+}
+
+// First returns the first candidate that normalizes to a supported locale,
+// or "" when none does.
+func First(candidates ...string) string {
+	for _, c := range candidates {
+		if v := Normalize(c); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header
+// ordered by quality, dropping q=0 entries.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 || strings.TrimSpace(tag) == "*" {
+			continue
+		}
+		items = append(items, weighted{tag: strings.TrimSpace(tag), q: q})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].q > items[j].q })
+	tags := make([]string, 0, len(items))
+	for _, item := range items {
+		tags = append(tags, item.tag)
+	}
+	return tags
+}
+
+// T looks key up in the catalog for locale, falling back to the email
+// default locale, then the source locale. args are applied to the catalog
+// entry with fmt.Sprintf. Unknown keys are returned as-is.
+func T(locale, key string, args ...interface{}) string {
+	for _, l := range []string{Normalize(locale), EmailDefaultLocale(), SourceLocale} {
+		if msg, ok := catalogs[l][key]; ok {
+			if len(args) > 0 {
+				return fmt.Sprintf(msg, args...)
+			}
+			return msg
+		}
+	}
+	return key
+}
+
+// Message translates an API message written in SourceLocale. Unknown
+// messages are returned unchanged.
+func Message(locale, msg string) string {
+	locale = Normalize(locale)
+	if locale == "" || locale == SourceLocale {
+		return msg
+	}
+	if translated, ok := apiMessages[locale][msg]; ok {
+		return translated
+	}
+	return msg
+}
@@ -153,7 +153,7 @@ func mapLevelToSlog(level int) slog.Level {
 }
 
 type stringHandler struct {
-	mu     sync.Mutex
+	mu     *sync.Mutex
 	writer io.Writer
 	level  slog.Leveler
 	attrs  []slog.Attr
@@ -165,6 +165,7 @@ func newStringHandler(writer io.Writer, level slog.Leveler) slog.Handler {
 		level = slog.LevelInfo
 	}
 	return &stringHandler{
+		mu:     &sync.Mutex{},
 		writer: writer,
 		level:  level,
 	}
@@ -0,0 +1,106 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const defaultPrimaryColor = "#1a1a2e"
+
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Brand holds the per-app look injected into every rendered email as .Brand.
+type Brand struct {
+	AppName      string `json:"app_name"`
+	LogoURL      string `json:"logo_url"`
+	PrimaryColor string `json:"primary_color"`
+	FooterText   string `json:"footer_text"`
+	SupportEmail string `json:"support_email"`
+}
+
+// BrandRegistry resolves brand profiles by X-App-Name.
+type BrandRegistry struct {
+	brands []Brand
+}
+
+func NewBrandRegistry(brands []Brand) (*BrandRegistry, error) {
+	seen := make(map[string]struct{}, len(brands))
+	cleaned := make([]Brand, 0, len(brands))
+	for _, brand := range brands {
+		brand.AppName = strings.TrimSpace(brand.AppName)
+		if brand.AppName == "" {
+			return nil, fmt.Errorf("brand app_name is required")
+		}
+		key := strings.ToLower(brand.AppName)
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("duplicate brand profile: %s", brand.AppName)
+		}
+		if brand.PrimaryColor != "" && !hexColorPattern.MatchString(brand.PrimaryColor) {
+			return nil, fmt.Errorf("brand %s: primary_color must be a hex colour", brand.AppName)
+		}
+		if brand.LogoURL != "" {
+			if u, err := url.Parse(brand.LogoURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+				return nil, fmt.Errorf("brand %s: invalid logo_url", brand.AppName)
+			}
+		}
+		if brand.SupportEmail != "" {
+			if _, err := mail.ParseAddress(brand.SupportEmail); err != nil {
+				return nil, fmt.Errorf("brand %s: invalid support_email: %w", brand.AppName, err)
+			}
+		}
+		seen[key] = struct{}{}
+		cleaned = append(cleaned, brand)
+	}
+	return &BrandRegistry{brands: cleaned}, nil
+}
+
+// LoadBrandRegistryFromEnv reads EMAIL_BRAND_PROFILES as a JSON array of
+// Brand. An empty variable yields an empty registry.
+func LoadBrandRegistryFromEnv() (*BrandRegistry, error) {
+	raw := strings.TrimSpace(os.Getenv("EMAIL_BRAND_PROFILES"))
+	if raw == "" {
+		return &BrandRegistry{}, nil
+	}
+
+	var brands []Brand
+	if err := json.Unmarshal([]byte(raw), &brands); err != nil {
+		return nil, fmt.Errorf("parse EMAIL_BRAND_PROFILES: %w", err)
+	}
+	return NewBrandRegistry(brands)
+}
+
+// Resolve returns the profile for appName. A profile registered as "*" is
+// used when there is no exact match, and a plain brand with the default
+// colour otherwise. Fallback brands carry the requested app name.
+func (r *BrandRegistry) Resolve(appName string) Brand {
+	appName = strings.TrimSpace(appName)
+	brand := Brand{AppName: appName}
+	if r != nil {
+		var wildcard *Brand
+		matched := false
+		for i := range r.brands {
+			if strings.EqualFold(r.brands[i].AppName, appName) {
+				brand = r.brands[i]
+				matched = true
+				break
+			}
+			if r.brands[i].AppName == identityWildcard && wildcard == nil {
+				wildcard = &r.brands[i]
+			}
+		}
+		if !matched && wildcard != nil {
+			brand = *wildcard
+			brand.AppName = appName
+		}
+	}
+
+	if brand.PrimaryColor == "" {
+		brand.PrimaryColor = defaultPrimaryColor
+	}
+	return brand
+}
@@ -2,13 +2,20 @@ package mailer
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html"
+	"net/mail"
 	"net/smtp"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+
+	"service-sender/pkg/i18n"
+	"service-sender/pkg/logger"
 )
 
 const defaultSMTPPort = 587
@@ -24,6 +31,8 @@ type BrevoSender struct {
 	ResetSubject string
 	TTL          time.Duration
 	AppName      string
+	Identities   *IdentityRegistry
+	Brands       *BrandRegistry
 }
 
 func NewBrevoSenderFromEnv() (*BrevoSender, error) {
@@ -46,15 +55,10 @@ func NewBrevoSenderFromEnv() (*BrevoSender, error) {
 		user = "apikey"
 	}
 
+	// Subjects are optional overrides; when empty the localized catalog
+	// subject is used.
 	subject := os.Getenv("SMTP_SUBJECT")
-	if subject == "" {
-		subject = "Your Registration OTP"
-	}
-
 	resetSubject := os.Getenv("RESET_SUBJECT")
-	if resetSubject == "" {
-		resetSubject = "Reset Your Password"
-	}
 
 	appName := os.Getenv("OTP_APP_NAME")
 	if appName == "" {
@@ -63,6 +67,20 @@ func NewBrevoSenderFromEnv() (*BrevoSender, error) {
 
 	ttl := parseDurationEnv([]string{"OTP_TTL"}, 5*time.Minute)
 
+	// A broken identity list must not take email down with it; mail keeps
+	// going out from SMTP_FROM until it is fixed.
+	identities, err := LoadIdentityRegistryFromEnv()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelWarn, "Email sender identities ignored, sending from SMTP_FROM: "+err.Error())
+		identities = &IdentityRegistry{}
+	}
+
+	brands, err := LoadBrandRegistryFromEnv()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelWarn, "Email brand profiles ignored, using the default brand: "+err.Error())
+		brands = &BrandRegistry{}
+	}
+
 	return &BrevoSender{
 		Host:         host,
 		Port:         port,
@@ -73,108 +91,58 @@ func NewBrevoSenderFromEnv() (*BrevoSender, error) {
 		ResetSubject: resetSubject,
 		TTL:          ttl,
 		AppName:      appName,
+		Identities:   identities,
+		Brands:       brands,
 	}, nil
 }
 
-func (s *BrevoSender) SendOTP(to, code, appName string) error {
+func (s *BrevoSender) SendOTP(to, code, appName, locale string) error {
 	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
 	auth := smtp.PlainAuth("", s.User, s.Pass, s.Host)
 
 	if strings.TrimSpace(appName) == "" {
 		appName = s.AppName
 	}
-	msg := buildOTPMessage(s.From, to, s.Subject, appName, code, s.TTL)
+	from := s.fromFor("otp", appName)
+	locale = resolveEmailLocale(locale)
+	subject := s.Subject
+	if subject == "" {
+		subject = i18n.T(locale, "otp.subject")
+	}
+	msg, err := buildOTPMessage(from, to, subject, locale, s.Brands.Resolve(appName), code, s.TTL)
+	if err != nil {
+		return err
+	}
 
-	return smtp.SendMail(addr, auth, extractEmail(s.From), []string{to}, msg)
+	return smtp.SendMail(addr, auth, extractEmail(from), []string{to}, msg)
 }
 
-func buildOTPMessage(from, to, subject, appName, code string, ttl time.Duration) []byte {
+// fromFor returns the From header for an email category, falling back to
+// SMTP_FROM when no default identity is configured for the type and app.
+func (s *BrevoSender) fromFor(emailType, appName string) string {
+	if identity, ok := s.Identities.Resolve(emailType, appName); ok {
+		return identity.From()
+	}
+	return s.From
+}
+
+func buildOTPMessage(from, to, subject, locale string, brand Brand, code string, ttl time.Duration) ([]byte, error) {
 	minutes := int(ttl.Minutes())
 	if minutes <= 0 {
 		minutes = 5
 	}
 
-	safeAppName := html.EscapeString(appName)
-
-	textBody := fmt.Sprintf(
-		"Kode verifikasi pendaftaran kamu: %s\nKode ini akan kadaluarsa dalam %d menit.\nJika kamu tidak merasa mendaftar, abaikan email ini.\n",
-		code,
-		minutes,
-	)
-	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
-<html lang="id">
-<head>
-  <meta charset="UTF-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
-  <title>Kode Verifikasi - %s</title>
-</head>
-<body style="margin: 0; padding: 0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f8f9fa;">
-  <div style="padding: 40px 20px; min-height: 100%%;">
-    <div style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
-      
-      <div style="background-color: #1a1a2e; padding: 32px 24px; text-align: center;">
-        <h1 style="color: #ffffff; font-size: 24px; font-weight: 600; margin: 0; letter-spacing: 0.5px;">
-          %s
-        </h1>
-      </div>
-
-      <div style="padding: 40px 32px;">
-        <h2 style="color: #1a1a2e; font-size: 20px; font-weight: 600; margin: 0 0 16px 0;">
-          Verifikasi Akun Anda
-        </h2>
-
-        <p style="color: #4a5568; font-size: 15px; line-height: 1.6; margin: 0 0 24px 0;">
-          Halo <strong>Pengguna</strong>,
-        </p>
-
-        <p style="color: #4a5568; font-size: 15px; line-height: 1.6; margin: 0 0 32px 0;">
-          Gunakan kode verifikasi berikut untuk menyelesaikan pendaftaran akun Anda:
-        </p>
-
-        <div style="background-color: #f7f7f9; border-radius: 8px; padding: 24px; text-align: center; margin-bottom: 32px; border: 1px dashed #e2e8f0;">
-          <p style="color: #718096; font-size: 12px; text-transform: uppercase; letter-spacing: 1px; margin: 0 0 12px 0;">
-            Kode Verifikasi
-          </p>
-          <p style="color: #1a1a2e; font-size: 36px; font-weight: 700; letter-spacing: 8px; margin: 0; font-family: 'Courier New', monospace;">
-            %s
-          </p>
-        </div>
-
-        <div style="background-color: #fff8e6; border-radius: 6px; padding: 12px 16px; margin-bottom: 24px; border-left: 3px solid #f6ad55;">
-          <p style="color: #744210; font-size: 13px; margin: 0; line-height: 1.5;">
-            ⏱️ Kode ini akan kadaluarsa dalam <strong>%d menit</strong>
-          </p>
-        </div>
-
-        <div style="background-color: #f0f9ff; border-radius: 6px; padding: 12px 16px; border-left: 3px solid #63b3ed;">
-          <p style="color: #2b6cb0; font-size: 13px; margin: 0; line-height: 1.5;">
-            🔒 Jangan bagikan kode ini kepada siapapun termasuk pihak yang mengaku dari %s.
-          </p>
-        </div>
-      </div>
-
-      <div style="background-color: #f8f9fa; padding: 24px 32px; border-top: 1px solid #e2e8f0;">
-        <p style="color: #a0aec0; font-size: 12px; text-align: center; margin: 0 0 8px 0; line-height: 1.5;">
-          Jika Anda tidak merasa mendaftar di %s, abaikan email ini.
-        </p>
-        <p style="color: #a0aec0; font-size: 12px; text-align: center; margin: 0;">
-          © %d %s. All rights reserved.
-        </p>
-      </div>
-
-    </div>
-  </div>
-</body>
-</html>`,
-		safeAppName,
-		safeAppName,
-		code,
-		minutes,
-		safeAppName,
-		safeAppName,
-		time.Now().Year(),
-		safeAppName,
-	)
+	textBody := i18n.T(locale, "otp.text", code, minutes)
+	htmlBody, err := RenderHTML("otp", otpContentTemplate, map[string]interface{}{
+		"Brand":   brand,
+		"AppName": brand.AppName,
+		"Locale":  locale,
+		"Code":    code,
+		"Minutes": minutes,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	boundary := "otp-boundary"
 
@@ -193,130 +161,162 @@ func buildOTPMessage(from, to, subject, appName, code string, ttl time.Duration)
 	buf.WriteString(htmlBody + "\r\n")
 	buf.WriteString("--" + boundary + "--")
 
-	return buf.Bytes()
+	return buf.Bytes(), nil
 }
 
-func (s *BrevoSender) SendPasswordReset(to, token, appName, resetURL string, ttl time.Duration) error {
+func (s *BrevoSender) SendPasswordReset(to, token, appName, locale, resetURL string, ttl time.Duration) error {
 	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
 	auth := smtp.PlainAuth("", s.User, s.Pass, s.Host)
 
 	if strings.TrimSpace(appName) == "" {
 		appName = s.AppName
 	}
+	locale = resolveEmailLocale(locale)
 	subject := s.ResetSubject
 	if subject == "" {
-		subject = "Reset Your Password"
+		subject = i18n.T(locale, "reset.subject")
 	}
 
-	msg := buildPasswordResetMessage(s.From, to, subject, appName, token, resetURL, ttl)
-	return smtp.SendMail(addr, auth, extractEmail(s.From), []string{to}, msg)
+	from := s.fromFor("reset", appName)
+	msg, err := buildPasswordResetMessage(from, to, subject, locale, s.Brands.Resolve(appName), token, resetURL, ttl)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(addr, auth, extractEmail(from), []string{to}, msg)
 }
 
-func buildPasswordResetMessage(from, to, subject, appName, token, resetURL string, ttl time.Duration) []byte {
+func buildPasswordResetMessage(from, to, subject, locale string, brand Brand, token, resetURL string, ttl time.Duration) ([]byte, error) {
 	minutes := int(ttl.Minutes())
 	if minutes <= 0 {
 		minutes = 15
 	}
 
-	safeAppName := html.EscapeString(appName)
-	safeURL := html.EscapeString(resetURL)
-
-	textBody := fmt.Sprintf(
-		"Kami menerima permintaan reset password untuk akun %s.\\n",
-		safeAppName,
-	)
+	textBody := i18n.T(locale, "reset.text_intro", brand.AppName)
+	expiryLabel := i18n.T(locale, "reset.label_token")
 	if resetURL != "" {
-		textBody += fmt.Sprintf("Link reset: %s\\n", resetURL)
+		textBody += i18n.T(locale, "reset.text_link", resetURL)
+		expiryLabel = i18n.T(locale, "reset.label_link")
 	} else {
-		textBody += fmt.Sprintf("Token reset: %s\\n", token)
+		textBody += i18n.T(locale, "reset.text_token", token)
 	}
-	expiryLabel := "Token ini"
-	if resetURL != "" {
-		expiryLabel = "Link ini"
+	textBody += i18n.T(locale, "reset.text_expiry", expiryLabel, minutes)
+
+	htmlBody, err := RenderHTML("reset", resetContentTemplate, map[string]interface{}{
+		"Brand":    brand,
+		"AppName":  brand.AppName,
+		"Locale":   locale,
+		"ResetURL": resetURL,
+		"Token":    token,
+		"Minutes":  minutes,
+	})
+	if err != nil {
+		return nil, err
 	}
-	textBody += fmt.Sprintf("%s kadaluarsa dalam %d menit.\\nJika kamu tidak meminta reset, abaikan email ini.\\n", expiryLabel, minutes)
 
-	linkSection := ""
-	linkText := ""
-	tokenSection := ""
-	if resetURL != "" {
-		linkSection = fmt.Sprintf(`<div style="margin: 18px 0 16px 0; text-align:center;">
-  <a href="%s" style="background:#2563eb;color:#ffffff;text-decoration:none;padding:12px 18px;border-radius:10px;font-weight:600;display:inline-block;">
-    Reset Password
-  </a>
-</div>`, safeURL)
-		linkText = fmt.Sprintf(`<p style="color:#64748b;font-size:12px;line-height:1.5;margin:0 0 18px 0;">
-Jika tombol di atas tidak berfungsi, salin link berikut ke browser Anda:
-<br><a href="%s" style="color:#2563eb;word-break:break-all;">%s</a>
-</p>`, safeURL, safeURL)
+	boundary := "reset-boundary"
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + to + "\r\n")
+	buf.WriteString("Subject: " + subject + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
+
+	buf.WriteString("--" + boundary + "\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(textBody + "\r\n")
+	buf.WriteString("--" + boundary + "\r\n")
+	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	buf.WriteString(htmlBody + "\r\n")
+	buf.WriteString("--" + boundary + "--")
+
+	return buf.Bytes(), nil
+}
+
+func (s *BrevoSender) SendSecurityAlert(to, appName, locale string, alert SecurityAlert) error {
+	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+	auth := smtp.PlainAuth("", s.User, s.Pass, s.Host)
+
+	if strings.TrimSpace(appName) == "" {
+		appName = s.AppName
+	}
+	locale = resolveEmailLocale(locale)
+	brand := s.Brands.Resolve(appName)
+	subject := i18n.T(locale, "security."+alert.Type+".subject", brand.AppName)
+
+	from := s.fromFor("security", appName)
+	msg, err := buildSecurityAlertMessage(from, to, subject, locale, brand, alert)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(addr, auth, extractEmail(from), []string{to}, msg)
+}
+
+// securityAlertDetail is one labelled row of the alert's summary table.
+type securityAlertDetail struct {
+	Label string
+	Value string
+}
+
+func buildSecurityAlertMessage(from, to, subject, locale string, brand Brand, alert SecurityAlert) ([]byte, error) {
+	hours := int(alert.LockTTL.Hours())
+	if hours <= 0 {
+		hours = 1
+	}
+
+	occurredAt := alert.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	details := []securityAlertDetail{{Label: i18n.T(locale, "security.detail_time"), Value: occurredAt.Format("2006-01-02 15:04 MST")}}
+	switch alert.Type {
+	case "email_changed":
+		details = append(details,
+			securityAlertDetail{Label: i18n.T(locale, "security.detail_previous_email"), Value: alert.Previous},
+			securityAlertDetail{Label: i18n.T(locale, "security.detail_new_email"), Value: alert.Current},
+		)
+	case "role_changed":
+		details = append(details,
+			securityAlertDetail{Label: i18n.T(locale, "security.detail_previous_role"), Value: alert.Previous},
+			securityAlertDetail{Label: i18n.T(locale, "security.detail_new_role"), Value: alert.Current},
+		)
+	}
+	if alert.IP != "" {
+		details = append(details, securityAlertDetail{Label: i18n.T(locale, "security.detail_ip"), Value: alert.IP})
+	}
+	if alert.Device != "" {
+		details = append(details, securityAlertDetail{Label: i18n.T(locale, "security.detail_device"), Value: alert.Device})
+	}
+
+	heading := i18n.T(locale, "security."+alert.Type+".heading")
+	intro := i18n.T(locale, "security."+alert.Type+".intro", brand.AppName)
+
+	textBody := intro + "\n\n"
+	for _, detail := range details {
+		textBody += detail.Label + ": " + detail.Value + "\n"
+	}
+	if alert.LockURL != "" {
+		textBody += "\n" + i18n.T(locale, "security.text_lock", alert.LockURL, hours)
 	} else {
-		tokenSection = fmt.Sprintf(`<div style="background-color: #f7f7f9; border-radius: 8px; padding: 18px; text-align: center; margin-bottom: 20px; border: 1px dashed #e2e8f0;">
-          <p style="color: #718096; font-size: 12px; text-transform: uppercase; letter-spacing: 1px; margin: 0 0 10px 0;">
-            Token Reset
-          </p>
-          <p style="color: #1a1a2e; font-size: 20px; font-weight: 700; letter-spacing: 2px; margin: 0; font-family: 'Courier New', monospace;">
-            %s
-          </p>
-        </div>`, token)
-	}
-
-	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
-<html lang="id">
-<head>
-  <meta charset="UTF-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
-  <title>Reset Password - %s</title>
-</head>
-<body style="margin: 0; padding: 0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f8f9fa;">
-  <div style="padding: 40px 20px; min-height: 100%%;">
-    <div style="max-width: 480px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.05);">
-      <div style="background-color: #1a1a2e; padding: 32px 24px; text-align: center;">
-        <h1 style="color: #ffffff; font-size: 24px; font-weight: 600; margin: 0; letter-spacing: 0.5px;">
-          %s
-        </h1>
-      </div>
-      <div style="padding: 36px 32px;">
-        <h2 style="color: #1a1a2e; font-size: 20px; font-weight: 600; margin: 0 0 12px 0;">
-          Reset Password
-        </h2>
-        <p style="color: #4a5568; font-size: 14px; line-height: 1.6; margin: 0 0 16px 0;">
-          Kami menerima permintaan reset password untuk akun Anda.
-        </p>
-        %s
-        %s
-        %s
-        <div style="background-color: #fff8e6; border-radius: 6px; padding: 12px 16px; margin-bottom: 18px; border-left: 3px solid #f6ad55;">
-          <p style="color: #744210; font-size: 13px; margin: 0; line-height: 1.5;">
-            ⏱️ %s kadaluarsa dalam <strong>%d menit</strong>
-          </p>
-        </div>
-        <div style="background-color: #f0f9ff; border-radius: 6px; padding: 12px 16px; border-left: 3px solid #63b3ed;">
-          <p style="color: #2b6cb0; font-size: 13px; margin: 0; line-height: 1.5;">
-            🔒 Jika Anda tidak meminta reset password, abaikan email ini.
-          </p>
-        </div>
-      </div>
-      <div style="background-color: #f8f9fa; padding: 24px 32px; border-top: 1px solid #e2e8f0;">
-        <p style="color: #a0aec0; font-size: 12px; text-align: center; margin: 0;">
-          © %d %s. All rights reserved.
-        </p>
-      </div>
-    </div>
-  </div>
-</body>
-</html>`,
-		safeAppName,
-		safeAppName,
-		linkSection,
-		linkText,
-		tokenSection,
-		expiryLabel,
-		minutes,
-		time.Now().Year(),
-		safeAppName,
-	)
+		textBody += "\n" + i18n.T(locale, "security.contact_support") + "\n"
+	}
 
-	boundary := "reset-boundary"
+	htmlBody, err := RenderHTML("security_alert", securityAlertContentTemplate, map[string]interface{}{
+		"Brand":   brand,
+		"AppName": brand.AppName,
+		"Locale":  locale,
+		"Name":    alert.Name,
+		"Heading": heading,
+		"Intro":   intro,
+		"Details": details,
+		"LockURL": alert.LockURL,
+		"Hours":   hours,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	boundary := "security-boundary"
 
 	var buf bytes.Buffer
 	buf.WriteString("From: " + from + "\r\n")
@@ -333,10 +333,10 @@ Jika tombol di atas tidak berfungsi, salin link berikut ke browser Anda:
 	buf.WriteString(htmlBody + "\r\n")
 	buf.WriteString("--" + boundary + "--")
 
-	return buf.Bytes()
+	return buf.Bytes(), nil
 }
 
-func (s *BrevoSender) SendEmail(payload EmailPayload) error {
+func (s *BrevoSender) SendEmail(payload EmailPayload) ([]SentMessage, error) {
 	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
 	auth := smtp.PlainAuth("", s.User, s.Pass, s.Host)
 
@@ -359,24 +359,69 @@ func (s *BrevoSender) SendEmail(payload EmailPayload) error {
 	textBody := strings.TrimSpace(payload.TextBody)
 	htmlBody := strings.TrimSpace(payload.HTMLBody)
 	if textBody == "" && htmlBody != "" {
-		textBody = "Please open this email in HTML mode."
+		textBody = HTMLToText(htmlBody)
 	}
 
-	for _, recipient := range payload.To {
-		to := strings.TrimSpace(recipient)
-		if to == "" {
-			continue
+	from := strings.TrimSpace(payload.From)
+	if from == "" {
+		from = s.fromFor(payload.Type, appName)
+	}
+
+	var invite *calendarPart
+	if payload.Calendar != nil {
+		var err error
+		invite, err = buildCalendarPart(*payload.Calendar, from, appName)
+		if err != nil {
+			return nil, err
 		}
-		msg := buildGeneralMessage(s.From, to, payload.ReplyTo, finalSubject, appName, textBody, htmlBody, payload.IdempotencyKey)
-		if err := smtp.SendMail(addr, auth, extractEmail(s.From), []string{to}, msg); err != nil {
-			return fmt.Errorf("send to %s: %w", to, err)
+	}
+
+	// PrivateTo gives every To recipient a copy of their own, so they never
+	// see each other. A failure part way leaves the earlier copies sent.
+	if payload.PrivateTo {
+		sent := make([]SentMessage, 0, len(payload.To))
+		for _, recipient := range payload.To {
+			to := strings.TrimSpace(recipient)
+			if to == "" {
+				continue
+			}
+			messageID := newMessageID(from)
+			msg := buildGeneralMessage(from, []string{to}, nil, payload.ReplyTo, finalSubject, appName, textBody, htmlBody, payload.IdempotencyKey, messageID, invite)
+			if err := smtp.SendMail(addr, auth, extractEmail(from), []string{to}, msg); err != nil {
+				return sent, fmt.Errorf("send to %s: %w", to, err)
+			}
+			sent = append(sent, SentMessage{MessageID: messageID, Recipients: []string{to}})
 		}
+		return sent, nil
+	}
+
+	// Otherwise one message goes out, so everyone can reply to all; BCC
+	// addresses are only in the envelope.
+	envelope := make([]string, 0, len(payload.To)+len(payload.Cc)+len(payload.Bcc))
+	envelope = append(envelope, payload.To...)
+	envelope = append(envelope, payload.Cc...)
+	envelope = append(envelope, payload.Bcc...)
+
+	messageID := newMessageID(from)
+	msg := buildGeneralMessage(from, payload.To, payload.Cc, payload.ReplyTo, finalSubject, appName, textBody, htmlBody, payload.IdempotencyKey, messageID, invite)
+	if err := smtp.SendMail(addr, auth, extractEmail(from), envelope, msg); err != nil {
+		return nil, fmt.Errorf("send to %d recipients: %w", len(envelope), err)
 	}
 
-	return nil
+	return []SentMessage{{MessageID: messageID, Recipients: envelope}}, nil
+}
+
+// newMessageID returns a Message-ID (without angle brackets) on the sender's
+// domain.
+func newMessageID(from string) string {
+	domain := "localhost"
+	if _, d, ok := strings.Cut(extractEmail(from), "@"); ok && d != "" {
+		domain = d
+	}
+	return uuid.NewString() + "@" + domain
 }
 
-func buildGeneralMessage(from, to, replyTo, subject, appName, textBody, htmlBody, idempotencyKey string) []byte {
+func buildGeneralMessage(from string, to, cc []string, replyTo, subject, appName, textBody, htmlBody, idempotencyKey, messageID string, invite *calendarPart) []byte {
 	if textBody == "" {
 		textBody = "No text content provided."
 	}
@@ -385,10 +430,14 @@ func buildGeneralMessage(from, to, replyTo, subject, appName, textBody, htmlBody
 	}
 
 	boundary := "general-boundary"
+	mixedBoundary := "general-mixed-boundary"
 
 	var buf bytes.Buffer
 	buf.WriteString("From: " + from + "\r\n")
-	buf.WriteString("To: " + to + "\r\n")
+	buf.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	if len(cc) > 0 {
+		buf.WriteString("Cc: " + strings.Join(cc, ", ") + "\r\n")
+	}
 	if strings.TrimSpace(replyTo) != "" {
 		buf.WriteString("Reply-To: " + replyTo + "\r\n")
 	}
@@ -398,8 +447,22 @@ func buildGeneralMessage(from, to, replyTo, subject, appName, textBody, htmlBody
 	if strings.TrimSpace(appName) != "" {
 		buf.WriteString("X-App-Name: " + appName + "\r\n")
 	}
+	if messageID != "" {
+		// Brevo echoes X-Mailin-custom in webhooks even when it rewrites
+		// the Message-ID.
+		buf.WriteString("Message-ID: <" + messageID + ">\r\n")
+		buf.WriteString("X-Mailin-custom: " + messageID + "\r\n")
+	}
 	buf.WriteString("Subject: " + subject + "\r\n")
 	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	// Invites follow the layout most clients expect: the text/calendar part
+	// inside multipart/alternative, plus the same data as an .ics attachment
+	// for clients that only look at attachments.
+	if invite != nil {
+		buf.WriteString("Content-Type: multipart/mixed; boundary=" + mixedBoundary + "\r\n\r\n")
+		buf.WriteString("--" + mixedBoundary + "\r\n")
+	}
 	buf.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
 
 	buf.WriteString("--" + boundary + "\r\n")
@@ -408,11 +471,79 @@ func buildGeneralMessage(from, to, replyTo, subject, appName, textBody, htmlBody
 	buf.WriteString("--" + boundary + "\r\n")
 	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
 	buf.WriteString(htmlBody + "\r\n")
+	if invite != nil {
+		buf.WriteString("--" + boundary + "\r\n")
+		buf.WriteString("Content-Type: text/calendar; charset=UTF-8; method=" + invite.method + "\r\n")
+		buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+		buf.WriteString(invite.content)
+	}
 	buf.WriteString("--" + boundary + "--")
 
+	if invite != nil {
+		buf.WriteString("\r\n--" + mixedBoundary + "\r\n")
+		buf.WriteString("Content-Type: application/ics; name=\"" + invite.filename + "\"\r\n")
+		buf.WriteString("Content-Disposition: attachment; filename=\"" + invite.filename + "\"\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		buf.WriteString(wrapBase64(invite.content) + "\r\n")
+		buf.WriteString("--" + mixedBoundary + "--")
+	}
+
 	return buf.Bytes()
 }
 
+type calendarPart struct {
+	method   string
+	filename string
+	content  string
+}
+
+// buildCalendarPart renders the invite once for every copy of the message.
+// The organizer defaults to the From address.
+func buildCalendarPart(event CalendarEvent, from, appName string) (*calendarPart, error) {
+	if strings.TrimSpace(event.OrganizerEmail) == "" {
+		event.OrganizerEmail = extractEmail(from)
+		if addr, err := mail.ParseAddress(from); err == nil {
+			event.OrganizerName = addr.Name
+		}
+	}
+	if strings.TrimSpace(event.Method) == "" {
+		event.Method = CalendarMethodRequest
+	}
+	event.Method = strings.ToUpper(event.Method)
+
+	prodID := "-//" + strings.ReplaceAll(strings.TrimSpace(appName), "//", "/") + "//Service Sender//EN"
+	content, err := BuildICS(event, prodID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	filename := "invite.ics"
+	if event.Cancelled() {
+		filename = "cancel.ics"
+	}
+	return &calendarPart{method: event.Method, filename: filename, content: content}, nil
+}
+
+func wrapBase64(content string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	return b.String()
+}
+
+// resolveEmailLocale applies the default step of the locale chain; callers
+// have already tried the request, the user profile and Accept-Language.
+func resolveEmailLocale(locale string) string {
+	if v := i18n.Normalize(locale); v != "" {
+		return v
+	}
+	return i18n.EmailDefaultLocale()
+}
+
 func extractEmail(from string) string {
 	start := strings.IndexByte(from, '<')
 	end := strings.IndexByte(from, '>')
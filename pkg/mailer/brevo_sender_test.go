@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildGeneralMessageHeaders(t *testing.T) {
+	msg := string(buildGeneralMessage("App <noreply@example.test>", []string{"a@example.test", "b@example.test"}, []string{"c@example.test"}, "", "Hi", "App", "hello", "", "", "id@example.test", nil))
+	if !strings.Contains(msg, "To: a@example.test, b@example.test\r\n") || !strings.Contains(msg, "Cc: c@example.test\r\n") {
+		t.Fatalf("headers should name To and Cc:\n%s", msg)
+	}
+	if strings.Contains(msg, "Bcc:") {
+		t.Fatalf("Bcc must stay out of the headers:\n%s", msg)
+	}
+}
+
+func TestNewBrevoSenderFromEnvIgnoresMalformedIdentities(t *testing.T) {
+	t.Setenv("SMTP_HOST", "smtp.example.test")
+	t.Setenv("SMTP_PASS", "secret")
+	t.Setenv("SMTP_FROM", "App <noreply@example.test>")
+	t.Setenv("EMAIL_SENDER_IDENTITIES", "[{")
+
+	sender, err := NewBrevoSenderFromEnv()
+	if err != nil {
+		t.Fatalf("NewBrevoSenderFromEnv() error = %v", err)
+	}
+	if got := sender.fromFor("info", "App"); got != "App <noreply@example.test>" {
+		t.Errorf("fromFor() = %q, want SMTP_FROM", got)
+	}
+}
+
+func TestNewBrevoSenderFromEnvIgnoresMalformedBrands(t *testing.T) {
+	t.Setenv("SMTP_HOST", "smtp.example.test")
+	t.Setenv("SMTP_PASS", "secret")
+	t.Setenv("SMTP_FROM", "App <noreply@example.test>")
+	t.Setenv("EMAIL_BRAND_PROFILES", `[{"app_name":"App","primary_color":"blue"}]`)
+
+	sender, err := NewBrevoSenderFromEnv()
+	if err != nil {
+		t.Fatalf("NewBrevoSenderFromEnv() error = %v", err)
+	}
+	if got := sender.Brands.Resolve("App"); got.PrimaryColor != defaultPrimaryColor {
+		t.Errorf("Resolve() colour = %q, want the default", got.PrimaryColor)
+	}
+}
@@ -0,0 +1,345 @@
+package mailer
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	cssCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	simpleSelector    = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*|\*)?((?:[.#][a-zA-Z_][a-zA-Z0-9_-]*)*)$`)
+	selectorPart      = regexp.MustCompile(`[.#][a-zA-Z_][a-zA-Z0-9_-]*`)
+)
+
+// InlineCSS moves the rules of <style> blocks into style attributes, which
+// is what most mail clients honour. Supported selectors are type, class, id,
+// their compounds and descendant chains; rules that cannot be inlined, such
+// as @media queries and pseudo-classes, stay in the <style> block. Existing
+// style attributes win over stylesheet rules unless the rule is !important.
+// Content without a <style> block is returned unchanged.
+func InlineCSS(content string) (string, error) {
+	if !strings.Contains(strings.ToLower(content), "<style") {
+		return content, nil
+	}
+
+	fragment := !strings.Contains(strings.ToLower(content), "<html")
+	var roots []*html.Node
+	if fragment {
+		body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+		nodes, err := html.ParseFragment(strings.NewReader(content), body)
+		if err != nil {
+			return "", err
+		}
+		for _, n := range nodes {
+			body.AppendChild(n)
+		}
+		roots = []*html.Node{body}
+	} else {
+		doc, err := html.Parse(strings.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+		roots = []*html.Node{doc}
+	}
+
+	var styles []*html.Node
+	for _, root := range roots {
+		collectStyles(root, &styles)
+	}
+
+	var rules []cssRule
+	for _, style := range styles {
+		if m := strings.TrimSpace(attr(style, "media")); m != "" && m != "all" && m != "screen" {
+			continue
+		}
+		css := ""
+		if style.FirstChild != nil {
+			css = style.FirstChild.Data
+		}
+		inlinable, rest := parseStylesheet(css, len(rules))
+		rules = append(rules, inlinable...)
+
+		if strings.TrimSpace(rest) == "" {
+			style.Parent.RemoveChild(style)
+		} else {
+			for c := style.FirstChild; c != nil; c = style.FirstChild {
+				style.RemoveChild(c)
+			}
+			style.AppendChild(&html.Node{Type: html.TextNode, Data: rest})
+		}
+	}
+
+	if len(rules) > 0 {
+		for _, root := range roots {
+			applyRules(root, rules)
+		}
+	}
+
+	var buf bytes.Buffer
+	if fragment {
+		for c := roots[0].FirstChild; c != nil; c = c.NextSibling {
+			if err := html.Render(&buf, c); err != nil {
+				return "", err
+			}
+		}
+	} else if err := html.Render(&buf, roots[0]); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type cssDeclaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+type cssRule struct {
+	selector    [][]string
+	specificity [3]int
+	order       int
+	decls       []cssDeclaration
+}
+
+func collectStyles(n *html.Node, out *[]*html.Node) {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Style {
+		*out = append(*out, n)
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		collectStyles(c, out)
+	}
+}
+
+// parseStylesheet splits css into inlinable rules and the text of the
+// remaining rules that have to stay in a <style> block.
+func parseStylesheet(css string, order int) ([]cssRule, string) {
+	css = cssCommentPattern.ReplaceAllString(css, "")
+
+	var rules []cssRule
+	var rest strings.Builder
+	for {
+		css = strings.TrimSpace(css)
+		if css == "" {
+			break
+		}
+
+		open := strings.Index(css, "{")
+		if open < 0 {
+			break
+		}
+		prelude := strings.TrimSpace(css[:open])
+
+		end := matchingBrace(css, open)
+		if end < 0 {
+			rest.WriteString(css)
+			break
+		}
+		block := css[open+1 : end]
+		raw := css[:end+1]
+		css = css[end+1:]
+
+		if strings.HasPrefix(prelude, "@") {
+			rest.WriteString(raw + "\n")
+			continue
+		}
+
+		decls := parseDeclarations(block)
+		var kept []string
+		for _, sel := range strings.Split(prelude, ",") {
+			sel = strings.TrimSpace(sel)
+			chain, spec, ok := parseSelector(sel)
+			if !ok {
+				kept = append(kept, sel)
+				continue
+			}
+			rules = append(rules, cssRule{selector: chain, specificity: spec, order: order, decls: decls})
+			order++
+		}
+		if len(kept) > 0 {
+			rest.WriteString(strings.Join(kept, ", ") + " {" + block + "}\n")
+		}
+	}
+	return rules, rest.String()
+}
+
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseDeclarations(block string) []cssDeclaration {
+	var decls []cssDeclaration
+	for _, part := range strings.Split(block, ";") {
+		prop, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		prop = strings.ToLower(strings.TrimSpace(prop))
+		value = strings.TrimSpace(value)
+		important := false
+		if idx := strings.Index(strings.ToLower(value), "!important"); idx >= 0 {
+			important = true
+			value = strings.TrimSpace(value[:idx])
+		}
+		if prop == "" || value == "" {
+			continue
+		}
+		decls = append(decls, cssDeclaration{property: prop, value: value, important: important})
+	}
+	return decls
+}
+
+// parseSelector accepts descendant chains of compound selectors such as
+// "table.main td .note" and returns them with their specificity.
+func parseSelector(sel string) ([][]string, [3]int, bool) {
+	var spec [3]int
+	parts := strings.Fields(sel)
+	if len(parts) == 0 {
+		return nil, spec, false
+	}
+
+	chain := make([][]string, 0, len(parts))
+	for _, part := range parts {
+		m := simpleSelector.FindStringSubmatch(part)
+		if m == nil {
+			return nil, spec, false
+		}
+		var compound []string
+		if m[1] != "" && m[1] != "*" {
+			compound = append(compound, strings.ToLower(m[1]))
+			spec[2]++
+		}
+		for _, p := range selectorPart.FindAllString(m[2], -1) {
+			compound = append(compound, p)
+			if p[0] == '#' {
+				spec[0]++
+			} else {
+				spec[1]++
+			}
+		}
+		chain = append(chain, compound)
+	}
+	return chain, spec, true
+}
+
+func applyRules(n *html.Node, rules []cssRule) {
+	if n.Type == html.ElementNode && n.DataAtom != atom.Style && n.DataAtom != atom.Head {
+		var matched []cssRule
+		for _, r := range rules {
+			if matchesChain(n, r.selector) {
+				matched = append(matched, r)
+			}
+		}
+		if len(matched) > 0 {
+			setStyle(n, matched)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		applyRules(c, rules)
+	}
+}
+
+func matchesChain(n *html.Node, chain [][]string) bool {
+	if !matchesCompound(n, chain[len(chain)-1]) {
+		return false
+	}
+	rest := chain[:len(chain)-1]
+	for p := n.Parent; p != nil && len(rest) > 0; p = p.Parent {
+		if p.Type == html.ElementNode && matchesCompound(p, rest[len(rest)-1]) {
+			rest = rest[:len(rest)-1]
+		}
+	}
+	return len(rest) == 0
+}
+
+func matchesCompound(n *html.Node, compound []string) bool {
+	for _, part := range compound {
+		switch part[0] {
+		case '#':
+			if attr(n, "id") != part[1:] {
+				return false
+			}
+		case '.':
+			found := false
+			for _, class := range strings.Fields(attr(n, "class")) {
+				if class == part[1:] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		default:
+			if n.Data != part {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// setStyle merges matched rules in cascade order with the element's own
+// style attribute.
+func setStyle(n *html.Node, matched []cssRule) {
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i].specificity, matched[j].specificity
+		if a != b {
+			return a[0] < b[0] || (a[0] == b[0] && (a[1] < b[1] || (a[1] == b[1] && a[2] < b[2])))
+		}
+		return matched[i].order < matched[j].order
+	})
+
+	var names []string
+	values := make(map[string]cssDeclaration)
+	set := func(d cssDeclaration) {
+		if current, ok := values[d.property]; ok && current.important && !d.important {
+			return
+		}
+		if _, ok := values[d.property]; !ok {
+			names = append(names, d.property)
+		}
+		values[d.property] = d
+	}
+
+	for _, r := range matched {
+		for _, d := range r.decls {
+			set(d)
+		}
+	}
+	for _, d := range parseDeclarations(attr(n, "style")) {
+		set(d)
+	}
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		d := values[name]
+		parts = append(parts, name+": "+d.value)
+	}
+	style := strings.Join(parts, "; ") + ";"
+
+	for i := range n.Attr {
+		if n.Attr[i].Key == "style" {
+			n.Attr[i].Val = style
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: style})
+}
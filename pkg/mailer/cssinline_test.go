@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlineCSS(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "rules inlined in specificity order",
+			html: `<html><head><style>p { color: red; } .x { font-weight: bold } #y { color: blue }</style></head><body><p class="x">a</p><p id="y">b</p></body></html>`,
+			want: []string{
+				`<p class="x" style="color: red; font-weight: bold;">a</p>`,
+				`<p id="y" style="color: blue;">b</p>`,
+			},
+			notWant: []string{"<style>"},
+		},
+		{
+			name: "inline style wins",
+			html: `<style>p { color: red; margin: 4px }</style><p style="color: green">a</p>`,
+			want: []string{`style="color: green; margin: 4px;"`},
+		},
+		{
+			name:    "descendant selector",
+			html:    `<style>div p { color: green }</style><div><p>in</p></div><p>out</p>`,
+			want:    []string{`<p style="color: green;">in</p>`, `<p>out</p>`},
+			notWant: []string{"<style>"},
+		},
+		{
+			name: "media queries and pseudo-classes kept in a style block",
+			html: `<style>@media (max-width: 600px) { p { color: red } } a:hover { color: red }</style><p>x</p>`,
+			want: []string{"<style>@media (max-width: 600px)", "a:hover", "<p>x</p>"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := InlineCSS(tt.html)
+		if err != nil {
+			t.Fatalf("%s: InlineCSS() error = %v", tt.name, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: InlineCSS() = %q, want it to contain %q", tt.name, got, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(got, notWant) {
+				t.Errorf("%s: InlineCSS() = %q, must not contain %q", tt.name, got, notWant)
+			}
+		}
+	}
+}
@@ -2,15 +2,34 @@ package mailer
 
 type EmailPayload struct {
 	Type           string
+	From           string
 	To             []string
+	Cc             []string
+	Bcc            []string
 	Subject        string
 	TextBody       string
 	HTMLBody       string
 	ReplyTo        string
 	AppName        string
 	IdempotencyKey string
+	// PrivateTo sends every To recipient a copy of their own instead of one
+	// message naming them all. It cannot be combined with Cc or Bcc.
+	PrivateTo bool
+	// Calendar, when set, is attached as a text/calendar part and as an
+	// .ics file.
+	Calendar *CalendarEvent
 }
 
+// SentMessage is one message accepted by the SMTP relay. MessageID is the
+// Message-ID header without angle brackets, which providers echo back in
+// their delivery webhooks.
+type SentMessage struct {
+	MessageID  string
+	Recipients []string
+}
+
+// EmailSender delivers a payload. On error the returned slice still lists
+// the messages accepted before the failure.
 type EmailSender interface {
-	SendEmail(payload EmailPayload) error
+	SendEmail(payload EmailPayload) ([]SentMessage, error)
 }
@@ -0,0 +1,308 @@
+package mailer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+// HTMLToText builds the plain-text alternative for an HTML email. Links are
+// kept as numbered footnotes, list items get bullets, table rows are
+// flattened into "cell | cell" lines and non-content elements such as
+// <head>, <style> and <script> are dropped.
+func HTMLToText(content string) string {
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return strings.TrimSpace(content)
+	}
+
+	links := &footnotes{index: make(map[string]int)}
+	w := newTextWriter(links)
+	w.walk(doc)
+
+	out := w.String()
+	if len(links.urls) > 0 {
+		var b strings.Builder
+		b.WriteString(out)
+		b.WriteString("\n\n")
+		for i, u := range links.urls {
+			fmt.Fprintf(&b, "[%d] %s\n", i+1, u)
+		}
+		out = b.String()
+	}
+	return strings.TrimSpace(out)
+}
+
+type footnotes struct {
+	urls  []string
+	index map[string]int
+}
+
+func (f *footnotes) add(href string) int {
+	if n, ok := f.index[href]; ok {
+		return n
+	}
+	f.urls = append(f.urls, href)
+	f.index[href] = len(f.urls)
+	return len(f.urls)
+}
+
+type textWriter struct {
+	buf    strings.Builder
+	breaks int
+	pre    bool
+	links  *footnotes
+}
+
+func newTextWriter(links *footnotes) *textWriter {
+	return &textWriter{links: links}
+}
+
+// String returns the rendered text with trailing spaces and runs of blank
+// lines removed.
+func (w *textWriter) String() string {
+	lines := strings.Split(w.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// lineBreak asks for n newlines before the next text, without ever adding
+// them at the start of the output.
+func (w *textWriter) lineBreak(n int) {
+	if n > w.breaks {
+		w.breaks = n
+	}
+}
+
+func (w *textWriter) write(s string) {
+	tail := w.buf.String()
+	if !w.pre && (tail == "" || w.breaks > 0 || strings.HasSuffix(tail, " ") || strings.HasSuffix(tail, "\n")) {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	if tail != "" && w.breaks > 0 {
+		w.buf.WriteString(strings.Repeat("\n", w.breaks))
+	}
+	w.breaks = 0
+	w.buf.WriteString(s)
+}
+
+func (w *textWriter) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+}
+
+// sub renders n into a separate writer sharing the footnote list, for
+// content that is post-processed as a block (cells, list items, quotes).
+func (w *textWriter) sub(n *html.Node) string {
+	inner := newTextWriter(w.links)
+	inner.pre = w.pre
+	inner.walkChildren(n)
+	return inner.String()
+}
+
+func (w *textWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if w.pre {
+			w.write(n.Data)
+			return
+		}
+		w.write(collapseSpace(n.Data))
+		return
+	case html.DocumentNode:
+		w.walkChildren(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Style, atom.Script, atom.Title, atom.Noscript, atom.Template:
+		return
+	case atom.Br:
+		w.buf.WriteString("\n")
+		w.breaks = 0
+		return
+	case atom.Hr:
+		w.lineBreak(2)
+		w.write("--------")
+		w.lineBreak(2)
+		return
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			w.write(alt)
+		}
+		return
+	case atom.A:
+		w.writeLink(n)
+		return
+	case atom.Pre:
+		w.lineBreak(2)
+		w.pre = true
+		w.walkChildren(n)
+		w.pre = false
+		w.lineBreak(2)
+		return
+	case atom.Li:
+		w.writeListItem(n)
+		return
+	case atom.Blockquote:
+		w.lineBreak(2)
+		w.write(prefixLines(w.sub(n), "> ", "> "))
+		w.lineBreak(2)
+		return
+	case atom.Tr:
+		w.writeRow(n)
+		return
+	case atom.Ul, atom.Ol:
+		gap := 2
+		if n.Parent != nil && n.Parent.DataAtom == atom.Li {
+			gap = 1
+		}
+		w.lineBreak(gap)
+		w.walkChildren(n)
+		w.lineBreak(gap)
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.P, atom.Table:
+		w.lineBreak(2)
+		w.walkChildren(n)
+		w.lineBreak(2)
+		return
+	case atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Main, atom.Nav, atom.Aside, atom.Center, atom.Tbody, atom.Thead, atom.Tfoot:
+		w.lineBreak(1)
+		w.walkChildren(n)
+		w.lineBreak(1)
+		return
+	}
+
+	w.walkChildren(n)
+}
+
+// writeLink writes the link text followed by a footnote marker. Links whose
+// text already is the URL, and non-navigational hrefs, are left inline.
+func (w *textWriter) writeLink(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	label := strings.Join(strings.Fields(w.sub(n)), " ")
+
+	lower := strings.ToLower(href)
+	linkable := strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:")
+	switch {
+	case !linkable:
+		w.write(label)
+	case label == "" || label == href || "mailto:"+label == href:
+		w.write(strings.TrimPrefix(href, "mailto:"))
+	default:
+		w.write(fmt.Sprintf("%s [%d]", label, w.links.add(href)))
+	}
+}
+
+func (w *textWriter) writeListItem(n *html.Node) {
+	marker := "- "
+	if p := n.Parent; p != nil && p.DataAtom == atom.Ol {
+		pos := 1
+		for s := p.FirstChild; s != nil && s != n; s = s.NextSibling {
+			if s.Type == html.ElementNode && s.DataAtom == atom.Li {
+				pos++
+			}
+		}
+		marker = fmt.Sprintf("%d. ", pos)
+	}
+
+	w.lineBreak(1)
+	w.write(prefixLines(w.sub(n), marker, strings.Repeat(" ", len(marker))))
+	w.lineBreak(1)
+}
+
+// writeRow flattens a table row. Rows of short cells become one
+// "a | b | c" line; rows holding block content (typical of layout tables)
+// are written cell after cell instead.
+func (w *textWriter) writeRow(n *html.Node) {
+	var cells []string
+	multiline := false
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+			continue
+		}
+		text := w.sub(c)
+		if text == "" {
+			continue
+		}
+		if strings.Contains(text, "\n") {
+			multiline = true
+		}
+		cells = append(cells, text)
+	}
+	if len(cells) == 0 {
+		return
+	}
+
+	if multiline {
+		w.lineBreak(2)
+		w.write(strings.Join(cells, "\n\n"))
+		w.lineBreak(2)
+		return
+	}
+	w.lineBreak(1)
+	w.write(strings.Join(cells, " | "))
+	w.lineBreak(1)
+}
+
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + line
+		case line == "":
+			lines[i] = strings.TrimRight(rest, " ")
+		default:
+			lines[i] = rest + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func collapseSpace(s string) string {
+	if s == "" {
+		return ""
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return " "
+	}
+	out := strings.Join(fields, " ")
+	if strings.TrimLeft(s, " \t\r\n\f") != s {
+		out = " " + out
+	}
+	if strings.TrimRight(s, " \t\r\n\f") != s {
+		out += " "
+	}
+	return out
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import "testing"
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "links become footnotes",
+			html: `<p>Hello <a href="https://example.test">site</a> and <a href="https://other.test">other</a>.</p>`,
+			want: "Hello site [1] and other [2].\n\n[1] https://example.test\n[2] https://other.test",
+		},
+		{
+			name: "table flattened",
+			html: `<table><tr><th>Name</th><th>Qty</th></tr><tr><td>Apple</td><td>2</td></tr></table>`,
+			want: "Name | Qty\nApple | 2",
+		},
+		{
+			name: "headings and lists, scripts and styles dropped",
+			html: `<h1>Title</h1><ul><li>one</li><li>two</li></ul><style>p{}</style><script>x()</script>`,
+			want: "Title\n\n- one\n- two",
+		},
+		{
+			name: "entities and line breaks",
+			html: `<p>a &amp; b<br>c</p>`,
+			want: "a & b\nc",
+		},
+	}
+	for _, tt := range tests {
+		if got := HTMLToText(tt.html); got != tt.want {
+			t.Errorf("%s: HTMLToText() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	CalendarMethodRequest = "REQUEST"
+	CalendarMethodCancel  = "CANCEL"
+
+	icsDateTime    = "20060102T150405"
+	icsDateTimeUTC = "20060102T150405Z"
+	icsLineOctets  = 75
+)
+
+type CalendarAttendee struct {
+	Name  string
+	Email string
+	Role  string
+	RSVP  bool
+}
+
+// CalendarEvent is the invite attached to a "calendar" email. Updates and
+// cancellations reuse the UID with a higher Sequence so clients replace the
+// event they already have instead of adding a new one.
+type CalendarEvent struct {
+	UID            string
+	Method         string
+	Sequence       int
+	Summary        string
+	Description    string
+	Location       string
+	URL            string
+	Start          time.Time
+	End            time.Time
+	Timezone       *time.Location
+	OrganizerName  string
+	OrganizerEmail string
+	Attendees      []CalendarAttendee
+}
+
+// Cancelled reports whether the event is sent as a cancellation.
+func (e CalendarEvent) Cancelled() bool {
+	return strings.EqualFold(e.Method, CalendarMethodCancel)
+}
+
+// BuildICS renders the event as an RFC 5545 VCALENDAR object with CRLF line
+// endings and folded lines. Times are written with a TZID and a matching
+// VTIMEZONE when the event has a timezone other than UTC, and in UTC form
+// otherwise.
+func BuildICS(e CalendarEvent, prodID string, stamp time.Time) (string, error) {
+	if strings.TrimSpace(e.UID) == "" {
+		return "", fmt.Errorf("calendar event uid is required")
+	}
+	if !e.End.After(e.Start) {
+		return "", fmt.Errorf("calendar event must end after it starts")
+	}
+	if strings.TrimSpace(e.OrganizerEmail) == "" {
+		return "", fmt.Errorf("calendar event organizer is required")
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(e.Method))
+	if method == "" {
+		method = CalendarMethodRequest
+	}
+	if method != CalendarMethodRequest && method != CalendarMethodCancel {
+		return "", fmt.Errorf("unsupported calendar method: %s", e.Method)
+	}
+
+	loc := e.Timezone
+	if loc == nil {
+		loc = time.UTC
+	}
+	zoned := loc != time.UTC && loc.String() != "UTC"
+
+	var lines []string
+	add := func(line string) { lines = append(lines, line) }
+
+	add("BEGIN:VCALENDAR")
+	add("PRODID:" + prodID)
+	add("VERSION:2.0")
+	add("CALSCALE:GREGORIAN")
+	add("METHOD:" + method)
+	if zoned {
+		lines = append(lines, vtimezone(loc, e.Start, e.End)...)
+	}
+
+	add("BEGIN:VEVENT")
+	add("UID:" + escapeICSText(e.UID))
+	add("DTSTAMP:" + stamp.UTC().Format(icsDateTimeUTC))
+	add(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+	if zoned {
+		add("DTSTART;TZID=" + loc.String() + ":" + e.Start.In(loc).Format(icsDateTime))
+		add("DTEND;TZID=" + loc.String() + ":" + e.End.In(loc).Format(icsDateTime))
+	} else {
+		add("DTSTART:" + e.Start.UTC().Format(icsDateTimeUTC))
+		add("DTEND:" + e.End.UTC().Format(icsDateTimeUTC))
+	}
+	add("SUMMARY:" + escapeICSText(e.Summary))
+	if e.Description != "" {
+		add("DESCRIPTION:" + escapeICSText(e.Description))
+	}
+	if e.Location != "" {
+		add("LOCATION:" + escapeICSText(e.Location))
+	}
+	if e.URL != "" {
+		add("URL:" + e.URL)
+	}
+	add("ORGANIZER" + icsCommonName(e.OrganizerName) + ":mailto:" + e.OrganizerEmail)
+	for _, a := range e.Attendees {
+		role := strings.ToUpper(strings.TrimSpace(a.Role))
+		if role == "" {
+			role = "REQ-PARTICIPANT"
+		}
+		rsvp := "FALSE"
+		if a.RSVP && method == CalendarMethodRequest {
+			rsvp = "TRUE"
+		}
+		add(fmt.Sprintf("ATTENDEE%s;ROLE=%s;PARTSTAT=NEEDS-ACTION;RSVP=%s:mailto:%s", icsCommonName(a.Name), role, rsvp, a.Email))
+	}
+	if method == CalendarMethodCancel {
+		add("STATUS:CANCELLED")
+	} else {
+		add("STATUS:CONFIRMED")
+	}
+	add("TRANSP:OPAQUE")
+	add("END:VEVENT")
+	add("END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICSLine(line))
+		b.WriteString("\r\n")
+	}
+	return b.String(), nil
+}
+
+// vtimezone describes loc for the years the event spans. Each offset change
+// inside that window becomes its own observance; zones without changes get
+// a single STANDARD observance.
+func vtimezone(loc *time.Location, start, end time.Time) []string {
+	from := time.Date(start.In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+	to := time.Date(end.In(loc).Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+	observance := func(onset string, at time.Time, fromOffset int) {
+		name, offset := at.Zone()
+		kind := "STANDARD"
+		if at.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		lines = append(lines,
+			"BEGIN:"+kind,
+			"DTSTART:"+onset,
+			"TZOFFSETFROM:"+icsOffset(fromOffset),
+			"TZOFFSETTO:"+icsOffset(offset),
+			"TZNAME:"+name,
+			"END:"+kind,
+		)
+	}
+
+	_, firstOffset := from.Zone()
+	observance("19700101T000000", from, firstOffset)
+
+	prev := from
+	_, prevOffset := prev.Zone()
+	for t := from.Add(24 * time.Hour); t.Before(to); t = t.Add(24 * time.Hour) {
+		if _, offset := t.Zone(); offset != prevOffset {
+			lo, hi := prev, t
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == prevOffset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			onset := hi.UTC().Add(time.Duration(prevOffset) * time.Second)
+			observance(onset.Format(icsDateTime), hi, prevOffset)
+			_, prevOffset = hi.Zone()
+		}
+		prev = t
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	h, m, s := seconds/3600, seconds%3600/60, seconds%60
+	if s != 0 {
+		return fmt.Sprintf("%s%02d%02d%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, h, m)
+}
+
+// icsCommonName returns the CN parameter for name. Parameter values cannot
+// be escaped (RFC 5545 section 3.2), so control characters, which could
+// start a new property, become spaces and the characters a value may not
+// hold, DQUOTE, ";", ":" and ",", are dropped.
+func icsCommonName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return ' '
+		case r == '"' || r == ';' || r == ':' || r == ',':
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + name + `"`
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// foldICSLine splits lines longer than 75 octets as RFC 5545 section 3.1
+// requires, never inside a multi-byte character.
+func foldICSLine(line string) string {
+	if len(line) <= icsLineOctets {
+		return line
+	}
+
+	var b strings.Builder
+	limit := icsLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICSCommonName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Jane Doe", `;CN="Jane Doe"`},
+		{"  ", ""},
+		{`"Doe, Jane"`, `;CN="Doe Jane"`},
+		{"Jane\r\nATTENDEE:mailto:evil@example.test", `;CN="Jane ATTENDEEmailtoevil@example.test"`},
+		{"Jane;ROLE=CHAIR", `;CN="JaneROLE=CHAIR"`},
+		{"Jane\tDoe\x00\x7f", `;CN="Jane Doe"`},
+	}
+	for _, tt := range tests {
+		if got := icsCommonName(tt.name); got != tt.want {
+			t.Errorf("icsCommonName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildICSKeepsNamesInTheirParameter(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ics, err := BuildICS(CalendarEvent{
+		UID:            "evt-1@example.test",
+		Summary:        "Planning",
+		Start:          start,
+		End:            start.Add(time.Hour),
+		OrganizerName:  "Ops\r\nMETHOD:CANCEL",
+		OrganizerEmail: "ops@example.test",
+		Attendees: []CalendarAttendee{
+			{Name: "Jane\nATTENDEE;ROLE=CHAIR:mailto:evil@example.test", Email: "jane@example.test", RSVP: true},
+		},
+	}, "-//service-sender//test//EN", start)
+	if err != nil {
+		t.Fatalf("BuildICS: %v", err)
+	}
+
+	for _, line := range strings.Split(ics, "\r\n") {
+		if strings.HasPrefix(line, "METHOD:CANCEL") || strings.Contains(line, "mailto:evil@") {
+			t.Errorf("injected line %q", line)
+		}
+	}
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	if !strings.Contains(unfolded, `ORGANIZER;CN="Ops METHODCANCEL":mailto:ops@example.test`) {
+		t.Errorf("organizer line missing from\n%s", ics)
+	}
+}
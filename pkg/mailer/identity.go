@@ -0,0 +1,150 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+)
+
+const identityWildcard = "*"
+
+// SenderIdentity describes a From/Reply-To pair that may be used for a set of
+// email types and calling applications.
+type SenderIdentity struct {
+	Key         string   `json:"key"`
+	FromName    string   `json:"from_name"`
+	FromAddress string   `json:"from_address"`
+	ReplyTo     string   `json:"reply_to"`
+	Types       []string `json:"types"`
+	Apps        []string `json:"apps"`
+	Default     bool     `json:"default"`
+}
+
+// From returns the RFC 5322 formatted From header value.
+func (i SenderIdentity) From() string {
+	addr := mail.Address{Name: strings.TrimSpace(i.FromName), Address: strings.TrimSpace(i.FromAddress)}
+	return addr.String()
+}
+
+func (i SenderIdentity) allowsType(emailType string) bool {
+	return matchesList(i.Types, emailType)
+}
+
+func (i SenderIdentity) allowsApp(appName string) bool {
+	return matchesList(i.Apps, appName)
+}
+
+// IdentityRegistry resolves sender identities by key, email type and app name.
+type IdentityRegistry struct {
+	identities []SenderIdentity
+}
+
+func NewIdentityRegistry(identities []SenderIdentity) (*IdentityRegistry, error) {
+	seen := make(map[string]struct{}, len(identities))
+	cleaned := make([]SenderIdentity, 0, len(identities))
+	for _, identity := range identities {
+		identity.Key = strings.TrimSpace(identity.Key)
+		if identity.Key == "" {
+			return nil, fmt.Errorf("sender identity key is required")
+		}
+		if _, ok := seen[identity.Key]; ok {
+			return nil, fmt.Errorf("duplicate sender identity key: %s", identity.Key)
+		}
+		if _, err := mail.ParseAddress(identity.FromAddress); err != nil {
+			return nil, fmt.Errorf("sender identity %s: invalid from_address: %w", identity.Key, err)
+		}
+		if strings.TrimSpace(identity.ReplyTo) != "" {
+			if _, err := mail.ParseAddress(identity.ReplyTo); err != nil {
+				return nil, fmt.Errorf("sender identity %s: invalid reply_to: %w", identity.Key, err)
+			}
+		}
+		seen[identity.Key] = struct{}{}
+		cleaned = append(cleaned, identity)
+	}
+	return &IdentityRegistry{identities: cleaned}, nil
+}
+
+// LoadIdentityRegistryFromEnv reads EMAIL_SENDER_IDENTITIES as a JSON array of
+// SenderIdentity. An empty variable yields an empty registry.
+func LoadIdentityRegistryFromEnv() (*IdentityRegistry, error) {
+	raw := strings.TrimSpace(os.Getenv("EMAIL_SENDER_IDENTITIES"))
+	if raw == "" {
+		return &IdentityRegistry{}, nil
+	}
+
+	var identities []SenderIdentity
+	if err := json.Unmarshal([]byte(raw), &identities); err != nil {
+		return nil, fmt.Errorf("parse EMAIL_SENDER_IDENTITIES: %w", err)
+	}
+	return NewIdentityRegistry(identities)
+}
+
+// Get returns the identity registered under key.
+func (r *IdentityRegistry) Get(key string) (SenderIdentity, bool) {
+	if r == nil {
+		return SenderIdentity{}, false
+	}
+	key = strings.TrimSpace(key)
+	for _, identity := range r.identities {
+		if identity.Key == key {
+			return identity, true
+		}
+	}
+	return SenderIdentity{}, false
+}
+
+// Allowed reports whether identity may be used for the given email type and app.
+func (r *IdentityRegistry) Allowed(identity SenderIdentity, emailType, appName string) bool {
+	return identity.allowsType(emailType) && identity.allowsApp(appName)
+}
+
+// Resolve picks the default identity for an email type and app. Identities
+// bound to the exact app win over wildcard ones; the first match in
+// configuration order is used within the same specificity.
+func (r *IdentityRegistry) Resolve(emailType, appName string) (SenderIdentity, bool) {
+	if r == nil {
+		return SenderIdentity{}, false
+	}
+
+	var wildcard *SenderIdentity
+	for i := range r.identities {
+		identity := r.identities[i]
+		if !identity.Default || !r.Allowed(identity, emailType, appName) {
+			continue
+		}
+		if containsExact(identity.Apps, appName) {
+			return identity, true
+		}
+		if wildcard == nil {
+			wildcard = &r.identities[i]
+		}
+	}
+	if wildcard != nil {
+		return *wildcard, true
+	}
+	return SenderIdentity{}, false
+}
+
+func matchesList(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if item == identityWildcard || strings.EqualFold(item, strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsExact(list []string, value string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) != identityWildcard && strings.EqualFold(strings.TrimSpace(item), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"time"
+
+	"service-sender/pkg/i18n"
+)
+
+// TemplateFuncs is available to every email template, including the layout
+// partials. Template validation must parse with the same set.
+var TemplateFuncs = htmltemplate.FuncMap{
+	"dict": dict,
+	"year": func() int { return time.Now().Year() },
+	"t":    i18n.T,
+}
+
+// baseLayout is the shared shell for HTML emails. Content templates opt in by
+// defining a "content" block and may override "title", "lang", "header" and
+// "footer". Partials: brand_header, brand_footer, button and notice. Copy is
+// looked up with {{t .Locale "key"}}.
+const baseLayout = `{{define "layout"}}<!DOCTYPE html>
+<html lang="{{block "lang" .}}{{or .Locale "id"}}{{end}}">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>{{block "title" .}}{{.Brand.AppName}}{{end}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f8f9fa; color: #1f2937;">
+  <div style="padding: 40px 20px;">
+    <div style="max-width: 600px; margin: 0 auto; background-color: #ffffff; border-radius: 12px; overflow: hidden; border: 1px solid #e2e8f0;">
+      {{block "header" .}}{{template "brand_header" .}}{{end}}
+      <div style="padding: 32px;">
+        {{block "content" .}}{{end}}
+      </div>
+      {{block "footer" .}}{{template "brand_footer" .}}{{end}}
+    </div>
+  </div>
+</body>
+</html>{{end}}
+
+{{define "brand_header"}}<div style="background-color: {{.Brand.PrimaryColor}}; padding: 28px 24px; text-align: center;">
+  {{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.AppName}}" style="max-height: 48px; border: 0;">{{else}}<h1 style="color: #ffffff; font-size: 24px; font-weight: 600; margin: 0; letter-spacing: 0.5px;">{{.Brand.AppName}}</h1>{{end}}
+</div>{{end}}
+
+{{define "brand_footer"}}<div style="background-color: #f8f9fa; padding: 24px 32px; border-top: 1px solid #e2e8f0;">
+  {{if .Brand.SupportEmail}}<p style="color: #a0aec0; font-size: 12px; text-align: center; margin: 0 0 8px 0;">{{t .Locale "layout.support"}} <a href="mailto:{{.Brand.SupportEmail}}" style="color: {{.Brand.PrimaryColor}};">{{.Brand.SupportEmail}}</a></p>{{end}}
+  <p style="color: #a0aec0; font-size: 12px; text-align: center; margin: 0;">{{if .Brand.FooterText}}{{.Brand.FooterText}}{{else}}{{t .Locale "layout.rights" year .Brand.AppName}}{{end}}</p>
+</div>{{end}}
+
+{{define "button"}}<div style="margin: 18px 0 16px 0; text-align: center;">
+  <a href="{{.URL}}" style="background: {{.Color}}; color: #ffffff; text-decoration: none; padding: 12px 18px; border-radius: 10px; font-weight: 600; display: inline-block;">{{.Label}}</a>
+</div>{{end}}
+
+{{define "notice"}}{{if eq .Tone "warning"}}<div style="background-color: #fff8e6; border-radius: 6px; padding: 12px 16px; margin-bottom: 18px; border-left: 3px solid #f6ad55;">
+  <p style="color: #744210; font-size: 13px; margin: 0; line-height: 1.5;">{{.Text}}</p>
+</div>{{else}}<div style="background-color: #f0f9ff; border-radius: 6px; padding: 12px 16px; margin-bottom: 18px; border-left: 3px solid #63b3ed;">
+  <p style="color: #2b6cb0; font-size: 13px; margin: 0; line-height: 1.5;">{{.Text}}</p>
+</div>{{end}}{{end}}`
+
+var layoutTemplates = htmltemplate.Must(htmltemplate.New("base").Funcs(TemplateFuncs).Parse(baseLayout))
+
+// RenderHTML renders content as an HTML email. When content defines a
+// "content" block it is wrapped in the base layout; otherwise it is rendered
+// as a standalone document with the partials still available. data should
+// carry the resolved Brand under "Brand" and the locale under "Locale".
+func RenderHTML(name, content string, data map[string]interface{}) (string, error) {
+	probe, err := htmltemplate.New(name).Funcs(TemplateFuncs).Parse(content)
+	if err != nil {
+		return "", err
+	}
+	useLayout := probe.Lookup("content") != nil
+
+	tpl, err := layoutTemplates.Clone()
+	if err != nil {
+		return "", err
+	}
+	if _, err := tpl.New(name).Parse(content); err != nil {
+		return "", err
+	}
+
+	entry := name
+	if useLayout {
+		entry = "layout"
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, entry, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func dict(values ...interface{}) (map[string]interface{}, error) {
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("dict expects key/value pairs")
+	}
+	out := make(map[string]interface{}, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		key, ok := values[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings")
+		}
+		out[key] = values[i+1]
+	}
+	return out, nil
+}
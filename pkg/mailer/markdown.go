@@ -0,0 +1,312 @@
+package mailer
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	mdHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRule        = regexp.MustCompile(`^ {0,3}([-*_])(?:\s*([-*_])){2,}\s*$`)
+	mdBullet      = regexp.MustCompile(`^ {0,3}[-*+]\s+`)
+	mdOrdered     = regexp.MustCompile(`^ {0,3}(\d{1,9})[.)]\s+`)
+	mdTableDivide = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+	mdCode   = regexp.MustCompile("`([^`]+)`")
+	mdImage  = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+&quot;[^)]*&quot;)?\)`)
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)(?:\s+&quot;[^)]*&quot;)?\)`)
+	mdStrong = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	mdEm     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*|(^|[^\w])_(\S(?:[^_]*?\S)?)_([^\w]|$)`)
+	mdStrike = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	mdToken  = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// MarkdownToHTML renders a practical Markdown subset (headings, paragraphs,
+// emphasis, code, links, images, lists, blockquotes, rules and pipe tables)
+// to HTML. Raw HTML in the source is escaped rather than passed through and
+// only http, https and mailto links are kept, so the output is safe to embed
+// in an email.
+func MarkdownToHTML(src string) string {
+	src = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\t", "    ")
+	return renderBlocks(strings.Split(src, "\n"))
+}
+
+func renderBlocks(lines []string) string {
+	var out strings.Builder
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence := trimmed[:3]
+			i++
+			var code []string
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			level := len(m[1])
+			fmt.Fprintf(&out, "<h%d>%s</h%d>\n", level, renderInline(m[2]), level)
+			i++
+
+		case mdRule.MatchString(line):
+			out.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quote []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+				i++
+			}
+			out.WriteString("<blockquote>\n" + renderBlocks(quote) + "</blockquote>\n")
+
+		case mdBullet.MatchString(line) || mdOrdered.MatchString(line):
+			var block string
+			block, i = renderList(lines, i)
+			out.WriteString(block)
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && mdTableDivide.MatchString(lines[i+1]):
+			var block string
+			block, i = renderTable(lines, i)
+			out.WriteString(block)
+
+		default:
+			var para []string
+			for i < len(lines) && startsParagraphLine(lines, i, len(para) == 0) {
+				para = append(para, lines[i])
+				i++
+			}
+			out.WriteString("<p>" + renderParagraph(para) + "</p>\n")
+		}
+	}
+	return out.String()
+}
+
+// startsParagraphLine reports whether lines[i] continues the current
+// paragraph rather than opening another block.
+func startsParagraphLine(lines []string, i int, first bool) bool {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	if first {
+		return true
+	}
+	return !mdHeading.MatchString(line) &&
+		!mdRule.MatchString(line) &&
+		!mdBullet.MatchString(line) &&
+		!mdOrdered.MatchString(line) &&
+		!strings.HasPrefix(trimmed, ">") &&
+		!strings.HasPrefix(trimmed, "```") &&
+		!strings.HasPrefix(trimmed, "~~~")
+}
+
+func renderParagraph(lines []string) string {
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		hardBreak := strings.HasSuffix(line, "  ") && i < len(lines)-1
+		parts[i] = renderInline(strings.TrimSpace(line))
+		if hardBreak {
+			parts[i] += "<br>"
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderList consumes one list starting at lines[start]. Lines indented past
+// the marker belong to the current item and are rendered as nested blocks,
+// which gives nested lists for free.
+func renderList(lines []string, start int) (string, int) {
+	ordered := mdOrdered.MatchString(lines[start])
+	marker := mdBullet
+	if ordered {
+		marker = mdOrdered
+	}
+
+	indent := leadingSpaces(lines[start])
+	var items [][]string
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if loc := marker.FindStringIndex(line); loc != nil && leadingSpaces(line) < indent+2 {
+			items = append(items, []string{line[loc[1]:]})
+			i++
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if i+1 < len(lines) && (marker.MatchString(lines[i+1]) || leadingSpaces(lines[i+1]) >= indent+2) {
+				items[len(items)-1] = append(items[len(items)-1], "")
+				i++
+				continue
+			}
+			break
+		}
+		if leadingSpaces(line) >= indent+2 || !startsBlock(line) {
+			items[len(items)-1] = append(items[len(items)-1], dedent(line, indent+2))
+			i++
+			continue
+		}
+		break
+	}
+
+	tag := "ul"
+	open := "<ul>"
+	if ordered {
+		tag = "ol"
+		open = "<ol>"
+		if n, err := strconv.Atoi(mdOrdered.FindStringSubmatch(lines[start])[1]); err == nil && n != 1 {
+			open = fmt.Sprintf(`<ol start="%d">`, n)
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(open + "\n")
+	for _, item := range items {
+		body := strings.TrimSpace(renderBlocks(item))
+		if strings.HasPrefix(body, "<p>") && strings.Count(body, "<p>") == 1 {
+			body = strings.Replace(strings.Replace(body, "<p>", "", 1), "</p>", "", 1)
+		}
+		out.WriteString("<li>" + body + "</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return out.String(), i
+}
+
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return mdHeading.MatchString(line) || mdRule.MatchString(line) ||
+		mdBullet.MatchString(line) || mdOrdered.MatchString(line) ||
+		strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "```")
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func dedent(line string, width int) string {
+	for n := 0; n < width && strings.HasPrefix(line, " "); n++ {
+		line = line[1:]
+	}
+	return line
+}
+
+func renderTable(lines []string, start int) (string, int) {
+	header := splitTableRow(lines[start])
+	i := start + 2
+
+	var out strings.Builder
+	out.WriteString("<table>\n<thead>\n<tr>")
+	for _, cell := range header {
+		out.WriteString("<th>" + renderInline(cell) + "</th>")
+	}
+	out.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != "" {
+		out.WriteString("<tr>")
+		for _, cell := range splitTableRow(lines[i]) {
+			out.WriteString("<td>" + renderInline(cell) + "</td>")
+		}
+		out.WriteString("</tr>\n")
+		i++
+	}
+	out.WriteString("</tbody>\n</table>\n")
+	return out.String(), i
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// renderInline escapes text and applies inline markup. Code spans, links and
+// images are swapped for placeholders first so emphasis rules never touch
+// their contents.
+func renderInline(text string) string {
+	var tokens []string
+	hold := func(s string) string {
+		tokens = append(tokens, s)
+		return fmt.Sprintf("\x00%d\x00", len(tokens)-1)
+	}
+
+	text = strings.ReplaceAll(text, "\x00", "")
+	text = mdCode.ReplaceAllStringFunc(text, func(m string) string {
+		return hold("<code>" + html.EscapeString(mdCode.FindStringSubmatch(m)[1]) + "</code>")
+	})
+	text = html.EscapeString(text)
+	text = mdImage.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mdImage.FindStringSubmatch(m)
+		if !safeURL(sub[2], false) {
+			return hold(sub[1])
+		}
+		return hold(`<img src="` + sub[2] + `" alt="` + sub[1] + `" style="max-width: 100%;">`)
+	})
+	text = mdLink.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mdLink.FindStringSubmatch(m)
+		label := applyEmphasis(sub[1])
+		if !safeURL(sub[2], true) {
+			return hold(label)
+		}
+		return hold(`<a href="` + sub[2] + `">` + label + `</a>`)
+	})
+
+	return restoreTokens(applyEmphasis(text), tokens)
+}
+
+func applyEmphasis(text string) string {
+	text = mdStrong.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mdStrong.FindStringSubmatch(m)
+		return "<strong>" + sub[1] + sub[2] + "</strong>"
+	})
+	text = mdEm.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mdEm.FindStringSubmatch(m)
+		if sub[1] != "" {
+			return "<em>" + sub[1] + "</em>"
+		}
+		return sub[2] + "<em>" + sub[3] + "</em>" + sub[4]
+	})
+	return mdStrike.ReplaceAllString(text, "<del>$1</del>")
+}
+
+// restoreTokens expands placeholders, including ones nested inside link
+// labels.
+func restoreTokens(text string, tokens []string) string {
+	for depth := 0; depth < 3 && strings.Contains(text, "\x00"); depth++ {
+		text = mdToken.ReplaceAllStringFunc(text, func(m string) string {
+			n, err := strconv.Atoi(mdToken.FindStringSubmatch(m)[1])
+			if err != nil || n >= len(tokens) {
+				return ""
+			}
+			return tokens[n]
+		})
+	}
+	return text
+}
+
+// safeURL checks an already HTML-escaped URL against the allowed schemes.
+func safeURL(escaped string, allowMailto bool) bool {
+	lower := strings.ToLower(html.UnescapeString(escaped))
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return true
+	}
+	return allowMailto && strings.HasPrefix(lower, "mailto:")
+}
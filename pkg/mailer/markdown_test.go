@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "javascript link dropped",
+			src:     "[click](javascript:void)",
+			want:    []string{"click"},
+			notWant: []string{"<a", "javascript:"},
+		},
+		{
+			name:    "javascript image dropped",
+			src:     "![pic](JavaScript:void)",
+			notWant: []string{"<img", "javascript:", "JavaScript:"},
+		},
+		{
+			name: "http link kept and escaped",
+			src:  "[ok](https://example.test/a?b=1&c=2)",
+			want: []string{`<a href="https://example.test/a?b=1&amp;c=2">ok</a>`},
+		},
+		{
+			name: "mailto link and image",
+			src:  "[m](mailto:a@example.test) ![p](https://example.test/p.png)",
+			want: []string{`<a href="mailto:a@example.test">m</a>`, `<img src="https://example.test/p.png" alt="p"`},
+		},
+		{
+			name:    "raw html escaped",
+			src:     `<script>alert(1)</script> <b onclick="x()">hi</b>`,
+			want:    []string{"&lt;script&gt;alert(1)&lt;/script&gt;", "&lt;b onclick="},
+			notWant: []string{"<script", "<b "},
+		},
+		{
+			name: "emphasis",
+			src:  "**bold** and _em_",
+			want: []string{"<strong>bold</strong>", "<em>em</em>"},
+		},
+		{
+			name: "lists",
+			src:  "- one\n- two\n\n1. first\n2. second",
+			want: []string{"<ul>\n<li>one</li>\n<li>two</li>\n</ul>", "<ol>\n<li>first</li>\n<li>second</li>\n</ol>"},
+		},
+		{
+			name: "table",
+			src:  "| A | B |\n|---|---|\n| 1 | 2 |",
+			want: []string{"<tr><th>A</th><th>B</th></tr>", "<tr><td>1</td><td>2</td></tr>"},
+		},
+	}
+	for _, tt := range tests {
+		got := MarkdownToHTML(tt.src)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: MarkdownToHTML(%q) = %q, want it to contain %q", tt.name, tt.src, got, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(got, notWant) {
+				t.Errorf("%s: MarkdownToHTML(%q) = %q, must not contain %q", tt.name, tt.src, got, notWant)
+			}
+		}
+	}
+}
@@ -3,5 +3,5 @@ package mailer
 import "time"
 
 type PasswordResetSender interface {
-	SendPasswordReset(to, token, appName, resetURL string, ttl time.Duration) error
+	SendPasswordReset(to, token, appName, locale, resetURL string, ttl time.Duration) error
 }
@@ -0,0 +1,23 @@
+package mailer
+
+import "time"
+
+// SecurityAlert describes an account change for the security alert email.
+// Type picks the copy ("security.<type>.*" in the catalog). Previous and
+// Current are the old and new email or role, IP and Device where a login
+// came from. LockURL is the "it wasn't me" link, valid for LockTTL.
+type SecurityAlert struct {
+	Type       string
+	Name       string
+	OccurredAt time.Time
+	Previous   string
+	Current    string
+	IP         string
+	Device     string
+	LockURL    string
+	LockTTL    time.Duration
+}
+
+type SecurityAlertSender interface {
+	SendSecurityAlert(to, appName, locale string, alert SecurityAlert) error
+}
@@ -1,5 +1,5 @@
 package mailer
 
 type Sender interface {
-	SendOTP(to, code, appName string) error
+	SendOTP(to, code, appName, locale string) error
 }
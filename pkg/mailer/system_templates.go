@@ -0,0 +1,40 @@
+package mailer
+
+const otpContentTemplate = `{{define "title"}}{{t .Locale "otp.title" .Brand.AppName}}{{end}}
+{{define "content"}}<h2 style="color: #1a1a2e; font-size: 20px; font-weight: 600; margin: 0 0 16px 0;">{{t .Locale "otp.heading"}}</h2>
+<p style="color: #4a5568; font-size: 15px; line-height: 1.6; margin: 0 0 24px 0;">{{t .Locale "common.hello"}} <strong>{{t .Locale "common.user"}}</strong>,</p>
+<p style="color: #4a5568; font-size: 15px; line-height: 1.6; margin: 0 0 32px 0;">{{t .Locale "otp.instruction"}}</p>
+<div style="background-color: #f7f7f9; border-radius: 8px; padding: 24px; text-align: center; margin-bottom: 32px; border: 1px dashed #e2e8f0;">
+  <p style="color: #718096; font-size: 12px; text-transform: uppercase; letter-spacing: 1px; margin: 0 0 12px 0;">{{t .Locale "otp.code_label"}}</p>
+  <p style="color: {{.Brand.PrimaryColor}}; font-size: 36px; font-weight: 700; letter-spacing: 8px; margin: 0; font-family: 'Courier New', monospace;">{{.Code}}</p>
+</div>
+{{template "notice" (dict "Tone" "warning" "Text" (t .Locale "otp.expiry" .Minutes))}}
+{{template "notice" (dict "Tone" "info" "Text" (t .Locale "otp.warning" .Brand.AppName))}}
+<p style="color: #a0aec0; font-size: 12px; text-align: center; margin: 0; line-height: 1.5;">{{t .Locale "otp.ignore" .Brand.AppName}}</p>{{end}}`
+
+const resetContentTemplate = `{{define "title"}}{{t .Locale "reset.title" .Brand.AppName}}{{end}}
+{{define "content"}}<h2 style="color: #1a1a2e; font-size: 20px; font-weight: 600; margin: 0 0 12px 0;">{{t .Locale "reset.heading"}}</h2>
+<p style="color: #4a5568; font-size: 14px; line-height: 1.6; margin: 0 0 16px 0;">{{t .Locale "reset.intro"}}</p>
+{{if .ResetURL}}{{template "button" (dict "URL" .ResetURL "Label" (t .Locale "reset.button") "Color" .Brand.PrimaryColor)}}
+<p style="color: #64748b; font-size: 12px; line-height: 1.5; margin: 0 0 18px 0;">{{t .Locale "reset.link_hint"}}<br><a href="{{.ResetURL}}" style="color: {{.Brand.PrimaryColor}}; word-break: break-all;">{{.ResetURL}}</a></p>
+{{template "notice" (dict "Tone" "warning" "Text" (t .Locale "reset.expiry_link" .Minutes))}}
+{{else}}<div style="background-color: #f7f7f9; border-radius: 8px; padding: 18px; text-align: center; margin-bottom: 20px; border: 1px dashed #e2e8f0;">
+  <p style="color: #718096; font-size: 12px; text-transform: uppercase; letter-spacing: 1px; margin: 0 0 10px 0;">{{t .Locale "reset.token_label"}}</p>
+  <p style="color: #1a1a2e; font-size: 20px; font-weight: 700; letter-spacing: 2px; margin: 0; font-family: 'Courier New', monospace;">{{.Token}}</p>
+</div>
+{{template "notice" (dict "Tone" "warning" "Text" (t .Locale "reset.expiry_token" .Minutes))}}
+{{end}}{{template "notice" (dict "Tone" "info" "Text" (t .Locale "reset.ignore"))}}{{end}}`
+
+const securityAlertContentTemplate = `{{define "title"}}{{.Heading}} - {{.Brand.AppName}}{{end}}
+{{define "content"}}<h2 style="color: #1a1a2e; font-size: 20px; font-weight: 600; margin: 0 0 12px 0;">{{.Heading}}</h2>
+<p style="color: #4a5568; font-size: 15px; line-height: 1.6; margin: 0 0 16px 0;">{{t .Locale "common.hello"}} <strong>{{if .Name}}{{.Name}}{{else}}{{t .Locale "common.user"}}{{end}}</strong>,</p>
+<p style="color: #4a5568; font-size: 14px; line-height: 1.6; margin: 0 0 20px 0;">{{.Intro}}</p>
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="background-color: #f7f7f9; border-radius: 8px; margin-bottom: 24px;">
+{{range .Details}}<tr><td style="padding: 8px 16px; color: #718096; font-size: 13px; width: 40%;">{{.Label}}</td><td style="padding: 8px 16px; color: #1a1a2e; font-size: 13px; word-break: break-all;">{{.Value}}</td></tr>
+{{end}}</table>
+{{if .LockURL}}<p style="color: #4a5568; font-size: 14px; line-height: 1.6; margin: 0 0 8px 0;">{{t .Locale "security.lock_intro"}}</p>
+{{template "button" (dict "URL" .LockURL "Label" (t .Locale "security.lock_button") "Color" "#c53030")}}
+<p style="color: #64748b; font-size: 12px; line-height: 1.5; margin: 0 0 18px 0;">{{t .Locale "reset.link_hint"}}<br><a href="{{.LockURL}}" style="color: #c53030; word-break: break-all;">{{.LockURL}}</a></p>
+{{template "notice" (dict "Tone" "warning" "Text" (t .Locale "security.lock_expiry" .Hours))}}
+{{else}}{{template "notice" (dict "Tone" "warning" "Text" (t .Locale "security.contact_support"))}}
+{{end}}{{template "notice" (dict "Tone" "info" "Text" (t .Locale "security.ignore"))}}{{end}}`
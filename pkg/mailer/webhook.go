@@ -0,0 +1,148 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrUnknownWebhookProvider = errors.New("unknown webhook provider")
+
+// Delivery event kinds, normalized across providers.
+const (
+	EventSent         = "sent"
+	EventDelivered    = "delivered"
+	EventDeferred     = "deferred"
+	EventSoftBounce   = "soft_bounce"
+	EventHardBounce   = "hard_bounce"
+	EventBlocked      = "blocked"
+	EventComplaint    = "complaint"
+	EventOpened       = "opened"
+	EventClicked      = "clicked"
+	EventUnsubscribed = "unsubscribed"
+	EventFailed       = "failed"
+)
+
+// DeliveryEvent is one provider notification about one recipient of a sent
+// message.
+type DeliveryEvent struct {
+	Provider   string
+	Kind       string
+	MessageID  string
+	Email      string
+	Reason     string
+	OccurredAt time.Time
+	// RawEvent is the provider's own event name, kept for the status detail.
+	RawEvent string
+}
+
+// Suppresses reports whether the recipient must not be mailed again: hard
+// bounces, invalid addresses and spam complaints.
+func (e DeliveryEvent) Suppresses() bool {
+	return e.Kind == EventHardBounce || e.Kind == EventComplaint
+}
+
+var webhookParsers = map[string]func([]byte) ([]DeliveryEvent, error){
+	"brevo": ParseBrevoWebhook,
+}
+
+// ParseWebhook decodes a webhook body from provider.
+func ParseWebhook(provider string, body []byte) ([]DeliveryEvent, error) {
+	parse, ok := webhookParsers[strings.ToLower(strings.TrimSpace(provider))]
+	if !ok {
+		return nil, ErrUnknownWebhookProvider
+	}
+	return parse(body)
+}
+
+type brevoEvent struct {
+	Event        string `json:"event"`
+	Email        string `json:"email"`
+	MessageID    string `json:"message-id"`
+	MailinCustom string `json:"X-Mailin-custom"`
+	Reason       string `json:"reason"`
+	Ts           int64  `json:"ts"`
+	TsEvent      int64  `json:"ts_event"`
+	TsEpoch      int64  `json:"ts_epoch"`
+}
+
+var brevoKinds = map[string]string{
+	"request":           EventSent,
+	"delivered":         EventDelivered,
+	"deferred":          EventDeferred,
+	"soft_bounce":       EventSoftBounce,
+	"hard_bounce":       EventHardBounce,
+	"invalid_email":     EventHardBounce,
+	"blocked":           EventBlocked,
+	"spam":              EventComplaint,
+	"complaint":         EventComplaint,
+	"opened":            EventOpened,
+	"unique_opened":     EventOpened,
+	"proxy_open":        EventOpened,
+	"unique_proxy_open": EventOpened,
+	"click":             EventClicked,
+	"unsubscribed":      EventUnsubscribed,
+	"error":             EventFailed,
+}
+
+// ParseBrevoWebhook decodes a Brevo transactional webhook, which posts either
+// a single event object or an array of them. Unknown event names are
+// skipped rather than rejected so new provider events do not cause retries.
+func ParseBrevoWebhook(body []byte) ([]DeliveryEvent, error) {
+	body = bytes.TrimSpace(body)
+	var raw []brevoEvent
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, fmt.Errorf("decode brevo webhook: %w", err)
+		}
+	} else {
+		var single brevoEvent
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, fmt.Errorf("decode brevo webhook: %w", err)
+		}
+		raw = []brevoEvent{single}
+	}
+
+	events := make([]DeliveryEvent, 0, len(raw))
+	for _, r := range raw {
+		kind, ok := brevoKinds[strings.ToLower(strings.TrimSpace(r.Event))]
+		if !ok {
+			continue
+		}
+
+		messageID := NormalizeMessageID(r.MailinCustom)
+		if messageID == "" {
+			messageID = NormalizeMessageID(r.MessageID)
+		}
+
+		occurred := time.Now()
+		switch {
+		case r.TsEpoch > 0:
+			occurred = time.UnixMilli(r.TsEpoch)
+		case r.TsEvent > 0:
+			occurred = time.Unix(r.TsEvent, 0)
+		case r.Ts > 0:
+			occurred = time.Unix(r.Ts, 0)
+		}
+
+		events = append(events, DeliveryEvent{
+			Provider:   "brevo",
+			Kind:       kind,
+			MessageID:  messageID,
+			Email:      strings.ToLower(strings.TrimSpace(r.Email)),
+			Reason:     strings.TrimSpace(r.Reason),
+			OccurredAt: occurred.UTC(),
+			RawEvent:   r.Event,
+		})
+	}
+	return events, nil
+}
+
+// NormalizeMessageID strips whitespace and angle brackets so stored IDs and
+// webhook IDs compare equal.
+func NormalizeMessageID(id string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+}
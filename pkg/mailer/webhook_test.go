@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "brevo", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func TestParseBrevoWebhookSingleEvent(t *testing.T) {
+	events, err := ParseWebhook("Brevo", readFixture(t, "delivered.json"))
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+
+	got := events[0]
+	want := DeliveryEvent{
+		Provider: "brevo",
+		Kind:     EventDelivered,
+		// X-Mailin-custom wins over the Message-ID Brevo rewrote.
+		MessageID:  "0c6a7d0e-5b7e-4d8c-9f41-3f0a2b1e6c55@service-sender",
+		Email:      "jane.doe@example.com",
+		OccurredAt: time.UnixMilli(1715674867312).UTC(),
+		RawEvent:   "delivered",
+	}
+	if got != want {
+		t.Errorf("event = %+v, want %+v", got, want)
+	}
+	if got.Suppresses() {
+		t.Error("delivered event suppresses the recipient")
+	}
+}
+
+func TestParseBrevoWebhookHardBounce(t *testing.T) {
+	events, err := ParseBrevoWebhook(readFixture(t, "hard_bounce.json"))
+	if err != nil {
+		t.Fatalf("ParseBrevoWebhook: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+
+	got := events[0]
+	if got.Kind != EventHardBounce || !got.Suppresses() {
+		t.Errorf("kind = %q, suppresses = %v", got.Kind, got.Suppresses())
+	}
+	// Without X-Mailin-custom the brackets come off Brevo's Message-ID.
+	if got.MessageID != "202405140823.12834401117@smtp-relay.mailin.fr" {
+		t.Errorf("message id = %q", got.MessageID)
+	}
+	if got.Reason == "" {
+		t.Error("bounce reason is empty")
+	}
+}
+
+func TestParseBrevoWebhookBatch(t *testing.T) {
+	events, err := ParseBrevoWebhook(readFixture(t, "batch.json"))
+	if err != nil {
+		t.Fatalf("ParseBrevoWebhook: %v", err)
+	}
+
+	// loadedByProxy is not a known event and is skipped.
+	want := []struct {
+		kind     string
+		occurred time.Time
+	}{
+		{EventSent, time.UnixMilli(1715674865020)},
+		{EventOpened, time.UnixMilli(1715675152871)},
+		{EventComplaint, time.Unix(1715677333, 0)},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, w := range want {
+		if events[i].Kind != w.kind || !events[i].OccurredAt.Equal(w.occurred) {
+			t.Errorf("event %d = %s at %s, want %s at %s", i, events[i].Kind, events[i].OccurredAt, w.kind, w.occurred.UTC())
+		}
+	}
+}
+
+func TestParseWebhookErrors(t *testing.T) {
+	if _, err := ParseWebhook("sendgrid", readFixture(t, "delivered.json")); !errors.Is(err, ErrUnknownWebhookProvider) {
+		t.Errorf("unknown provider error = %v", err)
+	}
+	if _, err := ParseWebhook("brevo", []byte(`{"event":`)); err == nil {
+		t.Error("truncated body parsed")
+	}
+}
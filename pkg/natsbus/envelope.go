@@ -0,0 +1,66 @@
+package natsbus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SpecVersion is the version of the envelope itself. It changes only when an
+// envelope field is removed or changes meaning.
+const SpecVersion = 1
+
+// Versions lists the events published to NATS with the version of each one's
+// data schema. A version is bumped when a data field is removed or changes
+// meaning; new fields do not make a new version. Each schema is described in
+// schemas/<type>.v<version>.json, next to envelope.v<SpecVersion>.json.
+var Versions = map[string]int{
+	"user.registered":          1,
+	"user.deleted":             1,
+	"otp.sent":                 1,
+	"otp.verified":             1,
+	"password.reset.requested": 1,
+	"password.reset.completed": 1,
+	"session.revoked":          1,
+}
+
+var ErrNotPublished = errors.New("event type is not published to nats")
+
+// Envelope is the JSON body of every message. Consumers should dispatch on
+// Type and Version and ignore data fields they do not know.
+type Envelope struct {
+	SpecVersion int                    `json:"specversion"`
+	Id          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Version     int                    `json:"version"`
+	Schema      string                 `json:"schema"`
+	Source      string                 `json:"source"`
+	OccurredAt  time.Time              `json:"occurred_at"`
+	Data        map[string]interface{} `json:"data"`
+}
+
+// Publishes reports whether events of eventType go to NATS.
+func Publishes(eventType string) bool {
+	_, ok := Versions[eventType]
+	return ok
+}
+
+func NewEnvelope(id, eventType, source string, occurredAt time.Time, data map[string]interface{}) (Envelope, error) {
+	version, ok := Versions[eventType]
+	if !ok {
+		return Envelope{}, fmt.Errorf("%w: %s", ErrNotPublished, eventType)
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return Envelope{
+		SpecVersion: SpecVersion,
+		Id:          id,
+		Type:        eventType,
+		Version:     version,
+		Schema:      fmt.Sprintf("%s.v%d", eventType, version),
+		Source:      source,
+		OccurredAt:  occurredAt.UTC(),
+		Data:        data,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package natsbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"service-sender/pkg/config"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, subject string, env Envelope) error
+}
+
+// JetStreamPublisher publishes envelopes to a JetStream stream, waiting for
+// the stream's acknowledgement. The envelope id is sent as Nats-Msg-Id, so a
+// message published twice within the stream's duplicate window is stored
+// once.
+type JetStreamPublisher struct {
+	Conn   *nats.Conn
+	JS     jetstream.JetStream
+	Config config.NATSConfig
+
+	mu          sync.Mutex
+	streamReady bool
+}
+
+// NewJetStreamPublisher connects to cfg.URL. The connection is retried in
+// the background if the server is unreachable, and the stream is set up on
+// the first publish that finds it missing.
+func NewJetStreamPublisher(cfg config.NATSConfig) (*JetStreamPublisher, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("nats not configured")
+	}
+
+	opts := []nats.Option{
+		nats.Name(cfg.Name),
+		nats.Timeout(cfg.Timeout),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+	if cfg.User != "" {
+		opts = append(opts, nats.UserInfo(cfg.User, cfg.Password))
+	}
+	if cfg.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("connect nats: %w", err)
+	}
+	js, err := jetstream.New(conn, jetstream.WithDefaultTimeout(cfg.Timeout))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("jetstream: %w", err)
+	}
+
+	p := &JetStreamPublisher{Conn: conn, JS: js, Config: cfg}
+	if conn.IsConnected() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		defer cancel()
+		if err := p.ensureStream(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *JetStreamPublisher) Publish(ctx context.Context, subject string, env Envelope) error {
+	if err := p.ensureStream(ctx); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = body
+	msg.Header.Set("Content-Type", "application/json")
+	msg.Header.Set("Event-Type", env.Type)
+	msg.Header.Set("Event-Schema", env.Schema)
+
+	opts := []jetstream.PublishOpt{jetstream.WithMsgID(env.Id)}
+	if p.Config.Stream != "" {
+		opts = append(opts, jetstream.WithExpectStream(p.Config.Stream))
+	}
+	if _, err := p.JS.PublishMsg(ctx, msg, opts...); err != nil {
+		return fmt.Errorf("publish %s: %w", subject, err)
+	}
+	return nil
+}
+
+func (p *JetStreamPublisher) Close() {
+	p.Conn.Close()
+}
+
+// ensureStream creates the stream when CreateStream is set and it does not
+// exist yet. An existing stream is left as it is, so operators may tune its
+// limits and subjects.
+func (p *JetStreamPublisher) ensureStream(ctx context.Context) error {
+	if !p.Config.CreateStream || p.Config.Stream == "" {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.streamReady {
+		return nil
+	}
+
+	_, err := p.JS.Stream(ctx, p.Config.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = p.JS.CreateStream(ctx, jetstream.StreamConfig{
+			Name:       p.Config.Stream,
+			Subjects:   p.subjects(),
+			Storage:    jetstream.FileStorage,
+			Duplicates: 2 * time.Minute,
+		})
+		if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("stream %s: %w", p.Config.Stream, err)
+	}
+	p.streamReady = true
+	return nil
+}
+
+// subjects are those of every published event: the prefix wildcard plus any
+// override outside it.
+func (p *JetStreamPublisher) subjects() []string {
+	var subjects []string
+	seen := map[string]bool{}
+	add := func(subject string) {
+		if !seen[subject] {
+			seen[subject] = true
+			subjects = append(subjects, subject)
+		}
+	}
+
+	prefix := p.Config.SubjectPrefix
+	if prefix != "" {
+		add(prefix + ".>")
+	}
+	types := make([]string, 0, len(Versions))
+	for eventType := range Versions {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	for _, eventType := range types {
+		subject := p.Config.Subject(eventType)
+		if prefix == "" || !strings.HasPrefix(subject, prefix+".") {
+			add(subject)
+		}
+	}
+	return subjects
+}
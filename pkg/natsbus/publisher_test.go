@@ -0,0 +1,196 @@
+package natsbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"service-sender/pkg/config"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func runJetStream(t *testing.T) *server.Server {
+	t.Helper()
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natsserver.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func newTestPublisher(t *testing.T, srv *server.Server) *JetStreamPublisher {
+	t.Helper()
+	p, err := NewJetStreamPublisher(config.NATSConfig{
+		URL:           srv.ClientURL(),
+		Name:          "natsbus-test",
+		Stream:        "TEST_EVENTS",
+		CreateStream:  true,
+		SubjectPrefix: "test.events",
+		Source:        "natsbus-test",
+		Timeout:       2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewJetStreamPublisher: %v", err)
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+func newTestConsumer(t *testing.T, p *JetStreamPublisher, ackWait time.Duration) jetstream.Consumer {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	consumer, err := p.JS.CreateOrUpdateConsumer(ctx, "TEST_EVENTS", jetstream.ConsumerConfig{
+		Durable:       "test-consumer",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		FilterSubject: "test.events.>",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateConsumer: %v", err)
+	}
+	return consumer
+}
+
+func publish(t *testing.T, p *JetStreamPublisher, id, eventType string, data map[string]interface{}) {
+	t.Helper()
+	env, err := NewEnvelope(id, eventType, "natsbus-test", time.Now(), data)
+	if err != nil {
+		t.Fatalf("NewEnvelope: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := p.Publish(ctx, p.Config.Subject(eventType), env); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+}
+
+func fetchOne(t *testing.T, consumer jetstream.Consumer, wait time.Duration) jetstream.Msg {
+	t.Helper()
+	batch, err := consumer.Fetch(1, jetstream.FetchMaxWait(wait))
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	for msg := range batch.Messages() {
+		return msg
+	}
+	if err := batch.Error(); err != nil && !errors.Is(err, jetstream.ErrNoMessages) {
+		t.Fatalf("Fetch: %v", err)
+	}
+	return nil
+}
+
+func TestPublishStoresEnvelope(t *testing.T) {
+	p := newTestPublisher(t, runJetStream(t))
+	consumer := newTestConsumer(t, p, time.Second)
+
+	publish(t, p, "evt-1", "user.registered", map[string]interface{}{"id": "u-1", "email": "a@example.test"})
+
+	msg := fetchOne(t, consumer, time.Second)
+	if msg == nil {
+		t.Fatal("no message delivered")
+	}
+	if msg.Subject() != "test.events.user.registered" {
+		t.Errorf("subject = %q", msg.Subject())
+	}
+	if got := msg.Headers().Get("Nats-Msg-Id"); got != "evt-1" {
+		t.Errorf("Nats-Msg-Id = %q", got)
+	}
+	if got := msg.Headers().Get("Event-Schema"); got != "user.registered.v1" {
+		t.Errorf("Event-Schema = %q", got)
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(msg.Data(), &env); err != nil {
+		t.Fatalf("unmarshal envelope: %v", err)
+	}
+	if env.Id != "evt-1" || env.Type != "user.registered" || env.Version != 1 || env.SpecVersion != SpecVersion {
+		t.Errorf("envelope = %+v", env)
+	}
+	if env.Data["email"] != "a@example.test" {
+		t.Errorf("data = %v", env.Data)
+	}
+	if err := msg.Ack(); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+}
+
+func TestPublishDropsDuplicateId(t *testing.T) {
+	p := newTestPublisher(t, runJetStream(t))
+
+	publish(t, p, "evt-dup", "otp.sent", map[string]interface{}{"email": "a@example.test"})
+	publish(t, p, "evt-dup", "otp.sent", map[string]interface{}{"email": "a@example.test"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := p.JS.Stream(ctx, "TEST_EVENTS")
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.State.Msgs != 1 {
+		t.Errorf("stream holds %d messages, want 1", info.State.Msgs)
+	}
+}
+
+func TestDurableConsumerRedeliversUntilAcked(t *testing.T) {
+	p := newTestPublisher(t, runJetStream(t))
+	ackWait := 300 * time.Millisecond
+	consumer := newTestConsumer(t, p, ackWait)
+
+	publish(t, p, "evt-2", "session.revoked", map[string]interface{}{"user_id": "u-1"})
+
+	first := fetchOne(t, consumer, time.Second)
+	if first == nil {
+		t.Fatal("no message delivered")
+	}
+	// Not acked: the server hands it out again once AckWait passes.
+	second := fetchOne(t, consumer, ackWait+time.Second)
+	if second == nil {
+		t.Fatal("unacked message was not redelivered")
+	}
+	meta, err := second.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata: %v", err)
+	}
+	if meta.NumDelivered != 2 {
+		t.Errorf("NumDelivered = %d, want 2", meta.NumDelivered)
+	}
+	if err := second.DoubleAck(context.Background()); err != nil {
+		t.Fatalf("DoubleAck: %v", err)
+	}
+
+	// The durable consumer, looked up again by name, has nothing left.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	again, err := p.JS.Consumer(ctx, "TEST_EVENTS", "test-consumer")
+	if err != nil {
+		t.Fatalf("Consumer: %v", err)
+	}
+	if msg := fetchOne(t, again, ackWait+500*time.Millisecond); msg != nil {
+		t.Errorf("acked message was redelivered: %s", msg.Subject())
+	}
+	info, err := again.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.NumAckPending != 0 || info.AckFloor.Stream != 1 {
+		t.Errorf("ack pending = %d, ack floor = %d", info.NumAckPending, info.AckFloor.Stream)
+	}
+}
+
+func TestPublishRejectsUnknownType(t *testing.T) {
+	if _, err := NewEnvelope("evt-3", "user.updated", "natsbus-test", time.Now(), nil); !errors.Is(err, ErrNotPublished) {
+		t.Errorf("NewEnvelope error = %v, want ErrNotPublished", err)
+	}
+}
@@ -0,0 +1,361 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"service-sender/pkg/breached"
+	"service-sender/pkg/config"
+)
+
+// Codes of the reasons a password is rejected.
+const (
+	ReasonTooShort      = "too_short"
+	ReasonTooLong       = "too_long"
+	ReasonMissingLower  = "missing_lowercase"
+	ReasonMissingUpper  = "missing_uppercase"
+	ReasonMissingDigit  = "missing_number"
+	ReasonMissingSymbol = "missing_symbol"
+	ReasonPersonalInfo  = "contains_personal_info"
+	ReasonCommon        = "common_password"
+	ReasonBreached      = "breached_password"
+	ReasonTooWeak       = "too_weak"
+)
+
+// missingClass is the reason given for each required class a password lacks.
+var missingClass = map[string]Reason{
+	config.PasswordClassLower:  {Code: ReasonMissingLower, Message: "password must contain at least 1 lowercase letter (a-z)"},
+	config.PasswordClassUpper:  {Code: ReasonMissingUpper, Message: "password must contain at least 1 uppercase letter (A-Z)"},
+	config.PasswordClassDigit:  {Code: ReasonMissingDigit, Message: "password must contain at least 1 number (0-9)"},
+	config.PasswordClassSymbol: {Code: ReasonMissingSymbol, Message: "password must contain at least 1 symbol (!@#$%^&*...)"},
+}
+
+// bcryptMaxBytes is the most bytes bcrypt hashes. Longer passwords are
+// rejected rather than silently truncated, whatever the policy allows.
+const bcryptMaxBytes = 72
+
+//go:embed common.txt
+var builtinCommon string
+
+// Subject is who a password is for, so it can be checked for their details.
+type Subject struct {
+	Email string
+	Name  string
+}
+
+type Reason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Result is the outcome of checking a password. Score runs from 0 (trivial
+// to guess) to 4 (very hard), and Entropy is the estimate it is based on in
+// bits.
+type Result struct {
+	Valid   bool     `json:"valid"`
+	Score   int      `json:"score"`
+	Entropy float64  `json:"entropy"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// Error rejects a password for one or more reasons. Its message is that of
+// the first reason.
+type Error struct {
+	Reasons []Reason
+}
+
+func (e *Error) Error() string {
+	if e == nil || len(e.Reasons) == 0 {
+		return "password does not meet the password policy"
+	}
+	return e.Reasons[0].Message
+}
+
+// Engine checks passwords against the policy of the role they are for.
+type Engine struct {
+	Config config.PasswordPolicyConfig
+	// Breached holds the passwords seen in breaches. Without it, breaches
+	// are not checked.
+	Breached *breached.Filter
+	common   map[string]struct{}
+}
+
+// NewEngine loads the built-in common-password list, cfg.CommonFile on top
+// of it and the breached-password filter in cfg.BreachedFile, if set. The
+// engine is usable even with an error; it then lacks what failed to load.
+func NewEngine(cfg config.PasswordPolicyConfig) (*Engine, error) {
+	e := &Engine{Config: cfg, common: map[string]struct{}{}}
+	e.addCommon(bufio.NewScanner(strings.NewReader(builtinCommon)))
+
+	var errs []error
+	if cfg.CommonFile != "" {
+		if err := e.loadCommon(cfg.CommonFile); err != nil {
+			errs = append(errs, fmt.Errorf("common password file: %w", err))
+		}
+	}
+
+	if cfg.BreachedFile != "" {
+		filter, err := breached.Load(cfg.BreachedFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("breached password filter: %w", err))
+		} else {
+			e.Breached = filter
+			// The filter still works, only it turns away more good passwords.
+			// Rounding the hash count alone puts a filter slightly over the
+			// rate it was built for, hence the slack.
+			if rate := filter.FalsePositiveRate(); rate > cfg.BreachedFPRate*1.5 {
+				errs = append(errs, fmt.Errorf("breached password filter false-positive rate %.6f exceeds %.6f; rebuild it", rate, cfg.BreachedFPRate))
+			}
+		}
+	}
+	return e, errors.Join(errs...)
+}
+
+func (e *Engine) loadCommon(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.addCommon(bufio.NewScanner(f))
+}
+
+func (e *Engine) addCommon(scanner *bufio.Scanner) error {
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e.common[line] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// For returns the policy of role. A nil Engine has the default policy.
+func (e *Engine) For(role string) config.PasswordPolicy {
+	if e == nil {
+		return defaultPolicy
+	}
+	return e.Config.For(role)
+}
+
+// defaultPolicy applies when no engine is configured.
+var defaultPolicy = config.PasswordPolicy{
+	MinLength: 8,
+	MaxLength: 72,
+	RequiredClasses: []string{
+		config.PasswordClassLower,
+		config.PasswordClassUpper,
+		config.PasswordClassDigit,
+		config.PasswordClassSymbol,
+	},
+	DisallowPersonal: true,
+	CheckCommon:      true,
+	CheckBreached:    true,
+}
+
+// Validate returns an *Error when password breaks the policy of role.
+func (e *Engine) Validate(password, role string, subject Subject) error {
+	if result := e.Check(password, role, subject); !result.Valid {
+		return &Error{Reasons: result.Reasons}
+	}
+	return nil
+}
+
+// Check scores password and lists every rule of role's policy it breaks.
+func (e *Engine) Check(password, role string, subject Subject) Result {
+	policy := e.For(role)
+	reasons := []Reason{}
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		reasons = append(reasons, Reason{Code: ReasonTooShort, Message: fmt.Sprintf("password must be at least %d characters long", policy.MinLength)})
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		reasons = append(reasons, Reason{Code: ReasonTooLong, Message: fmt.Sprintf("password must be at most %d characters long", policy.MaxLength)})
+	} else if len(password) > bcryptMaxBytes {
+		reasons = append(reasons, Reason{Code: ReasonTooLong, Message: fmt.Sprintf("password must be at most %d bytes long; some characters take more than one", bcryptMaxBytes)})
+	}
+
+	classes := charClasses(password)
+	for _, class := range policy.RequiredClasses {
+		if !slices.Contains(classes, class) {
+			reasons = append(reasons, missingClass[class])
+		}
+	}
+
+	lower := strings.ToLower(password)
+	entropy := estimateEntropy(password)
+
+	personal := personalTokens(subject)
+	if containsAny(lower, personal) {
+		entropy = math.Min(entropy, 20)
+		if policy.DisallowPersonal {
+			reasons = append(reasons, Reason{Code: ReasonPersonalInfo, Message: "password must not contain your email or name"})
+		}
+	}
+
+	if e.isCommon(lower) {
+		entropy = math.Min(entropy, 10)
+		if policy.CheckCommon {
+			reasons = append(reasons, Reason{Code: ReasonCommon, Message: "password is too common"})
+		}
+	}
+
+	if policy.CheckBreached && e != nil && e.Breached != nil && e.Breached.Contains(password) {
+		entropy = math.Min(entropy, 10)
+		reasons = append(reasons, Reason{Code: ReasonBreached, Message: "password has appeared in a data breach"})
+	}
+
+	if policy.MinEntropy > 0 && entropy < policy.MinEntropy {
+		reasons = append(reasons, Reason{Code: ReasonTooWeak, Message: "password is too easy to guess"})
+	}
+
+	return Result{
+		Valid:   len(reasons) == 0,
+		Score:   score(entropy),
+		Entropy: math.Round(entropy*10) / 10,
+		Reasons: reasons,
+	}
+}
+
+// isCommon reports whether password, lowercased, is on the common list as is
+// or once the digits and symbols people tack on the end are removed.
+func (e *Engine) isCommon(lower string) bool {
+	if e == nil {
+		return false
+	}
+	if _, ok := e.common[lower]; ok {
+		return true
+	}
+	stem := strings.TrimRightFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if stem == "" || stem == lower {
+		return false
+	}
+	_, ok := e.common[stem]
+	return ok
+}
+
+func charClasses(password string) []string {
+	var classes []string
+	add := func(class string) {
+		if !slices.Contains(classes, class) {
+			classes = append(classes, class)
+		}
+	}
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			add(config.PasswordClassLower)
+		case r >= 'A' && r <= 'Z':
+			add(config.PasswordClassUpper)
+		case r >= '0' && r <= '9':
+			add(config.PasswordClassDigit)
+		default:
+			add(config.PasswordClassSymbol)
+		}
+	}
+	return classes
+}
+
+// estimateEntropy is the length of password times the bits of the character
+// pool it draws from. Characters repeating or continuing a run from the one
+// before, as in "aaaa" or "1234", count for a quarter.
+func estimateEntropy(password string) float64 {
+	pool := 0
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	for _, set := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if set.used {
+			pool += set.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	effective := 0.0
+	var prev rune
+	for i, r := range []rune(password) {
+		if i > 0 && (r == prev || r == prev+1 || r == prev-1) {
+			effective += 0.25
+		} else {
+			effective++
+		}
+		prev = r
+	}
+	return effective * math.Log2(float64(pool))
+}
+
+func score(entropy float64) int {
+	switch {
+	case entropy < 28:
+		return 0
+	case entropy < 36:
+		return 1
+	case entropy < 60:
+		return 2
+	case entropy < 80:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// personalTokens are the parts of the subject's email and name too personal
+// to appear in their password. Parts shorter than 3 letters are left out.
+func personalTokens(subject Subject) []string {
+	var tokens []string
+	add := func(value string) {
+		for _, token := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(token) >= 3 && !slices.Contains(tokens, token) {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	local, _, _ := strings.Cut(strings.TrimSpace(subject.Email), "@")
+	if len(local) >= 3 {
+		tokens = append(tokens, strings.ToLower(local))
+	}
+	add(local)
+	add(subject.Name)
+	return tokens
+}
+
+func containsAny(s string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(s, token) {
+			return true
+		}
+	}
+	return false
+}
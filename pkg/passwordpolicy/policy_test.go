@@ -0,0 +1,76 @@
+package passwordpolicy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"service-sender/pkg/config"
+)
+
+func testEngine(t *testing.T) *Engine {
+	t.Helper()
+	def := config.PasswordPolicy{
+		MinLength:        8,
+		MaxLength:        72,
+		RequiredClasses:  []string{config.PasswordClassLower, config.PasswordClassUpper, config.PasswordClassDigit, config.PasswordClassSymbol},
+		DisallowPersonal: true,
+		CheckCommon:      true,
+	}
+	e, err := NewEngine(config.PasswordPolicyConfig{
+		Default: def,
+		Roles:   map[string]config.PasswordPolicy{"admin": def.Tighten(config.PasswordPolicy{MinLength: 12})},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	return e
+}
+
+func reasonCodes(result Result) []string {
+	codes := make([]string, 0, len(result.Reasons))
+	for _, reason := range result.Reasons {
+		codes = append(codes, reason.Code)
+	}
+	return codes
+}
+
+func TestCheckReasons(t *testing.T) {
+	e := testEngine(t)
+	subject := Subject{Email: "jane.doe@example.test", Name: "Jane Doe"}
+
+	tests := []struct {
+		name     string
+		password string
+		role     string
+		want     []string
+	}{
+		{"valid user password", "Tr4vel!ng-Owl", "user", nil},
+		{"too short for admin", "Tr4vel!ng-O", "admin", []string{ReasonTooShort}},
+		{"long enough for user", "Tr4vel!ng-O", "user", nil},
+		{"missing classes", "travelingowls", "user", []string{ReasonMissingUpper, ReasonMissingDigit, ReasonMissingSymbol}},
+		{"personal info", "Jane!Doe-2024", "user", []string{ReasonPersonalInfo}},
+		{"common with suffix", "Password123!", "user", []string{ReasonCommon}},
+		{"short in characters", "Ab1!ééé", "user", []string{ReasonTooShort}},
+		{"long enough in characters", "Ab1!éééé", "user", nil},
+		{"over the bcrypt bytes", "Aa1!" + strings.Repeat("é", 40), "user", []string{ReasonTooLong}},
+		{"over max length", "Aa1!" + strings.Repeat("x", 70), "user", []string{ReasonTooLong}},
+	}
+	for _, tt := range tests {
+		got := reasonCodes(e.Check(tt.password, tt.role, subject))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Check(%q, %q) reasons = %v, want %v", tt.name, tt.password, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestValidateReturnsError(t *testing.T) {
+	err := testEngine(t).Validate("short", "user", Subject{})
+	perr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Validate() error = %v, want *Error", err)
+	}
+	if perr.Reasons[0].Code != ReasonTooShort {
+		t.Errorf("first reason = %q, want %q", perr.Reasons[0].Code, ReasonTooShort)
+	}
+}
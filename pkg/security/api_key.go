@@ -0,0 +1,61 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+const apiKeyScheme = "sk"
+
+// GenerateApiKey returns a new key of the form sk_<prefix>_<secret> along
+// with its prefix. The prefix is safe to store and display; the key itself
+// is shown once and only its hash is kept.
+func GenerateApiKey() (prefix, key string, err error) {
+	id := make([]byte, 6)
+	if _, err = rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(id)
+	key = apiKeyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return prefix, key, nil
+}
+
+// GenerateSigningSecret returns a new secret for signing an API client's
+// requests. Unlike the key it is stored as is, since signatures are checked
+// against it.
+func GenerateSigningSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "ss_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// ParseApiKey returns the prefix of a well-formed key.
+func ParseApiKey(key string) (string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(key), "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != 12 || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// HashApiKey returns the hex SHA-256 of key. Keys carry 256 bits of
+// randomness, so a fast hash is sufficient.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ApiKeyMatches compares key against a stored hash in constant time.
+func ApiKeyMatches(key, hash string) bool {
+	return hash != "" && constantTimeEqual(HashApiKey(key), hash)
+}
@@ -0,0 +1,124 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+// DefaultWebhookTolerance bounds the age of a signed webhook when no
+// tolerance is configured.
+const DefaultWebhookTolerance = 5 * time.Minute
+
+var (
+	ErrWebhookUnauthorized = errors.New("webhook authentication failed")
+	ErrWebhookExpired      = errors.New("webhook timestamp outside tolerance")
+)
+
+// VerifyWebhook authenticates an inbound webhook against secret. Three forms
+// are accepted, checked in this order:
+//
+//   - X-Webhook-Signature: sha256=<hex HMAC-SHA256>, computed over
+//     "<timestamp>.<body>". X-Webhook-Timestamp is required and must be
+//     within tolerance, or DefaultWebhookTolerance when that is not positive;
+//   - Authorization: Bearer <secret>;
+//   - HTTP Basic auth with the secret as password, for providers that only
+//     support credentials in the webhook URL.
+func VerifyWebhook(header http.Header, body []byte, secret string, tolerance time.Duration, now time.Time) error {
+	if secret == "" {
+		return ErrWebhookUnauthorized
+	}
+
+	if sig := strings.TrimSpace(header.Get(WebhookSignatureHeader)); sig != "" {
+		return verifyWebhookSignature(sig, header.Get(WebhookTimestampHeader), body, secret, tolerance, now)
+	}
+
+	auth := strings.TrimSpace(header.Get("Authorization"))
+	if token, ok := cutPrefixFold(auth, "Bearer "); ok {
+		if constantTimeEqual(strings.TrimSpace(token), secret) {
+			return nil
+		}
+		return ErrWebhookUnauthorized
+	}
+	if encoded, ok := cutPrefixFold(auth, "Basic "); ok {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return ErrWebhookUnauthorized
+		}
+		if _, password, ok := strings.Cut(string(decoded), ":"); ok && constantTimeEqual(password, secret) {
+			return nil
+		}
+	}
+	return ErrWebhookUnauthorized
+}
+
+// SignWebhook returns the X-Webhook-Signature value for body sent at
+// timestamp, matching what VerifyWebhook expects.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateWebhookSecret returns a new signing secret for an outbound webhook
+// endpoint. Unlike API keys it is stored as is, since deliveries are signed
+// with it.
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func verifyWebhookSignature(signature, timestamp string, body []byte, secret string, tolerance time.Duration, now time.Time) error {
+	timestamp = strings.TrimSpace(timestamp)
+	if timestamp == "" {
+		return ErrWebhookUnauthorized
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookUnauthorized
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	age := now.Sub(time.Unix(ts, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrWebhookExpired
+	}
+
+	expected := SignWebhook(secret, timestamp, body)
+	if !strings.HasPrefix(signature, "sha256=") {
+		signature = "sha256=" + signature
+	}
+	if hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return nil
+	}
+	return ErrWebhookUnauthorized
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return "", false
+}
+
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
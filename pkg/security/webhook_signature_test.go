@@ -0,0 +1,154 @@
+package security
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "whsec_test"
+
+func brevoFixture(t *testing.T) []byte {
+	t.Helper()
+	body, err := os.ReadFile("../mailer/testdata/brevo/delivered.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func signedHeader(body []byte, sentAt time.Time) http.Header {
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	header := http.Header{}
+	header.Set(WebhookTimestampHeader, timestamp)
+	header.Set(WebhookSignatureHeader, SignWebhook(testWebhookSecret, timestamp, body))
+	return header
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := brevoFixture(t)
+	now := time.Unix(1715674900, 0)
+
+	tests := []struct {
+		name      string
+		header    func() http.Header
+		body      []byte
+		tolerance time.Duration
+		want      error
+	}{
+		{
+			name:   "valid",
+			header: func() http.Header { return signedHeader(body, now.Add(-time.Minute)) },
+			body:   body,
+		},
+		{
+			name: "bare hex signature",
+			header: func() http.Header {
+				h := signedHeader(body, now)
+				h.Set(WebhookSignatureHeader, h.Get(WebhookSignatureHeader)[len("sha256="):])
+				return h
+			},
+			body: body,
+		},
+		{
+			name:   "tampered body",
+			header: func() http.Header { return signedHeader(body, now) },
+			body:   append([]byte(" "), body...),
+			want:   ErrWebhookUnauthorized,
+		},
+		{
+			name: "missing timestamp",
+			header: func() http.Header {
+				h := http.Header{}
+				mac := SignWebhook(testWebhookSecret, "", body)
+				h.Set(WebhookSignatureHeader, mac)
+				return h
+			},
+			body: body,
+			want: ErrWebhookUnauthorized,
+		},
+		{
+			name: "malformed timestamp",
+			header: func() http.Header {
+				h := signedHeader(body, now)
+				h.Set(WebhookTimestampHeader, "yesterday")
+				return h
+			},
+			body: body,
+			want: ErrWebhookUnauthorized,
+		},
+		{
+			name:   "too old",
+			header: func() http.Header { return signedHeader(body, now.Add(-10*time.Minute)) },
+			body:   body,
+			want:   ErrWebhookExpired,
+		},
+		{
+			name:   "from the future",
+			header: func() http.Header { return signedHeader(body, now.Add(10*time.Minute)) },
+			body:   body,
+			want:   ErrWebhookExpired,
+		},
+		{
+			name:      "no tolerance falls back to the default",
+			header:    func() http.Header { return signedHeader(body, now.Add(-DefaultWebhookTolerance-time.Second)) },
+			body:      body,
+			tolerance: -1,
+			want:      ErrWebhookExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tolerance := 5 * time.Minute
+			if tt.tolerance != 0 {
+				tolerance = tt.tolerance
+			}
+			err := VerifyWebhook(tt.header(), tt.body, testWebhookSecret, tolerance, now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyWebhook = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhookCredentials(t *testing.T) {
+	body := brevoFixture(t)
+	now := time.Now()
+	basic := func(user, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+
+	tests := []struct {
+		name string
+		auth string
+		want error
+	}{
+		{"bearer", "Bearer " + testWebhookSecret, nil},
+		{"bearer any case", "bearer " + testWebhookSecret, nil},
+		{"wrong bearer", "Bearer whsec_other", ErrWebhookUnauthorized},
+		{"basic", basic("brevo", testWebhookSecret), nil},
+		{"wrong basic", basic("brevo", "whsec_other"), ErrWebhookUnauthorized},
+		{"malformed basic", "Basic !!!", ErrWebhookUnauthorized},
+		{"none", "", ErrWebhookUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.auth != "" {
+				header.Set("Authorization", tt.auth)
+			}
+			if err := VerifyWebhook(header, body, testWebhookSecret, time.Minute, now); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyWebhook = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := VerifyWebhook(http.Header{"Authorization": {"Bearer "}}, body, "", time.Minute, now); !errors.Is(err, ErrWebhookUnauthorized) {
+		t.Errorf("empty secret accepted: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type Sender interface {
+	Send(ctx context.Context, to, text, appName string) error
+}
+
+// HTTPSender posts messages to an SMS gateway as JSON:
+//
+//	{"to": "+6281234567890", "text": "...", "app_name": "YourApp"}
+//
+// with the token, if any, as a bearer Authorization header. Any 2xx response
+// counts as accepted.
+type HTTPSender struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+func NewHTTPSenderFromEnv() (*HTTPSender, error) {
+	url := strings.TrimSpace(os.Getenv("SMS_GATEWAY_URL"))
+	if url == "" {
+		return nil, fmt.Errorf("sms gateway not configured")
+	}
+	return &HTTPSender{
+		URL:    url,
+		Token:  strings.TrimSpace(os.Getenv("SMS_GATEWAY_TOKEN")),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *HTTPSender) Send(ctx context.Context, to, text, appName string) error {
+	payload, err := json.Marshal(map[string]string{"to": to, "text": text, "app_name": appName})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms gateway returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Urgency values of RFC 8030 section 5.3.
+const (
+	UrgencyVeryLow = "very-low"
+	UrgencyLow     = "low"
+	UrgencyNormal  = "normal"
+	UrgencyHigh    = "high"
+)
+
+// maxRetryAfter caps how long a Retry-After header can put a retry off.
+const maxRetryAfter = 30 * time.Second
+
+// ErrSubscriptionGone is returned when the push service answers 404 or 410:
+// the subscription expired or was revoked and should be forgotten.
+var ErrSubscriptionGone = errors.New("push subscription is gone")
+
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// StatusError is a push service refusal. RetryAfter is the delay the push
+// service asked for, if any.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("push service returned %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether a send that failed with err may succeed later:
+// network errors, 429 and 5xx.
+func Retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+type Client struct {
+	VAPID *VAPID
+	HTTP  *http.Client
+	// TTL is how long the push service keeps an undelivered message.
+	TTL time.Duration
+}
+
+func NewClient(vapid *VAPID, ttl time.Duration) *Client {
+	return &Client{
+		VAPID: vapid,
+		HTTP:  &http.Client{Timeout: 10 * time.Second},
+		TTL:   ttl,
+	}
+}
+
+// Send encrypts payload for the subscription and posts it to its endpoint
+// once. Whether to try again is up to the caller; see Retryable.
+func (c *Client) Send(ctx context.Context, sub Subscription, payload []byte, urgency string) error {
+	body, err := Encrypt(sub.P256dh, sub.Auth, payload)
+	if err != nil {
+		return err
+	}
+	if urgency == "" {
+		urgency = UrgencyNormal
+	}
+
+	authorization, err := c.VAPID.authorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(c.TTL/time.Second)))
+	req.Header.Set("Urgency", urgency)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	}
+
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(text)),
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func retryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return min(wait, maxRetryAfter)
+}
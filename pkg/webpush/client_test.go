@@ -0,0 +1,153 @@
+package webpush
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSubscription holds the user agent keys of the RFC 8291 example.
+var testSubscription = Subscription{
+	P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+	Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+}
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	public, private, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys: %v", err)
+	}
+	vapid, err := NewVAPID(public, private, "mailto:ops@example.test")
+	if err != nil {
+		t.Fatalf("NewVAPID: %v", err)
+	}
+	return NewClient(vapid, time.Hour)
+}
+
+func TestSendCreated(t *testing.T) {
+	client := newTestClient(t)
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	sub := testSubscription
+	sub.Endpoint = srv.URL + "/push/abc"
+	if err := client.Send(context.Background(), sub, []byte(`{"title":"hi"}`), UrgencyHigh); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if got.Method != http.MethodPost || got.URL.Path != "/push/abc" {
+		t.Errorf("request = %s %s", got.Method, got.URL.Path)
+	}
+	for header, want := range map[string]string{
+		"Content-Encoding": "aes128gcm",
+		"Content-Type":     "application/octet-stream",
+		"TTL":              "3600",
+		"Urgency":          UrgencyHigh,
+	} {
+		if v := got.Header.Get(header); v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+	if auth := got.Header.Get("Authorization"); !strings.HasPrefix(auth, "vapid t=") || !strings.HasSuffix(auth, ", k="+client.VAPID.PublicKey) {
+		t.Errorf("Authorization = %q", auth)
+	}
+	// Header, the 14-byte plaintext, the delimiter and the GCM tag.
+	if want := headerLength + 14 + 1 + 16; len(body) != want {
+		t.Errorf("body is %d bytes, want %d", len(body), want)
+	}
+}
+
+func TestSendGone(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		client := newTestClient(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		sub := testSubscription
+		sub.Endpoint = srv.URL
+		err := client.Send(context.Background(), sub, nil, "")
+		if !errors.Is(err, ErrSubscriptionGone) {
+			t.Errorf("%d: error = %v, want ErrSubscriptionGone", status, err)
+		}
+		if Retryable(err) {
+			t.Errorf("%d: gone subscription is retryable", status)
+		}
+		srv.Close()
+	}
+}
+
+func TestSendTooManyRequests(t *testing.T) {
+	client := newTestClient(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, "slow down\n")
+	}))
+	defer srv.Close()
+
+	sub := testSubscription
+	sub.Endpoint = srv.URL
+	err := client.Send(context.Background(), sub, nil, "")
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("error = %v, want *StatusError", err)
+	}
+	if statusErr.StatusCode != http.StatusTooManyRequests || statusErr.Body != "slow down" || statusErr.RetryAfter != 7*time.Second {
+		t.Errorf("StatusError = %+v", statusErr)
+	}
+	if !Retryable(err) {
+		t.Error("429 is not retryable")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&StatusError{StatusCode: http.StatusBadRequest}, false},
+		{&StatusError{StatusCode: http.StatusRequestEntityTooLarge}, false},
+		{ErrPayloadTooLarge, false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	// Nothing listens on a closed server's address.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	sub := testSubscription
+	sub.Endpoint = srv.URL
+	if err := newTestClient(t).Send(context.Background(), sub, nil, ""); !Retryable(err) {
+		t.Errorf("network error %v is not retryable", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter("120"); got != maxRetryAfter {
+		t.Errorf("retryAfter(120) = %s, want the %s cap", got, maxRetryAfter)
+	}
+	if got := retryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)); got != 0 {
+		t.Errorf("past date = %s", got)
+	}
+	if got := retryAfter("soon"); got != 0 {
+		t.Errorf("retryAfter(soon) = %s", got)
+	}
+}
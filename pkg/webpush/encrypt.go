@@ -0,0 +1,114 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// recordSize is the aes128gcm record size announced in the header. The
+	// whole message is sent as one record.
+	recordSize = 4096
+	saltLength = 16
+	// headerLength is salt, record size, key id length and the 65-byte
+	// uncompressed application server key.
+	headerLength = saltLength + 4 + 1 + 65
+	// MaxPayload is the largest plaintext that keeps the encrypted body
+	// within the 4096 bytes every push service must accept.
+	MaxPayload = recordSize - headerLength - 16 - 1
+)
+
+var ErrPayloadTooLarge = errors.New("push payload too large")
+
+// Encrypt encrypts plaintext for a subscription as RFC 8291 describes, using
+// the aes128gcm content coding of RFC 8188. p256dh and auth are the
+// subscription's keys, base64url encoded as the browser reports them.
+func Encrypt(p256dh, auth string, plaintext []byte) ([]byte, error) {
+	if len(plaintext) > MaxPayload {
+		return nil, ErrPayloadTooLarge
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encrypt(p256dh, auth, plaintext, asPrivate, salt)
+}
+
+// encrypt takes the ephemeral key and salt as parameters so the RFC 8291
+// example can be reproduced.
+func encrypt(p256dh, auth string, plaintext []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublicRaw, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("decode p256dh: %w", err)
+	}
+	authSecret, err := decodeKey(auth)
+	if err != nil {
+		return nil, fmt.Errorf("decode auth: %w", err)
+	}
+	if len(authSecret) != 16 {
+		return nil, fmt.Errorf("auth secret must be 16 bytes")
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parse p256dh: %w", err)
+	}
+
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	// IKM binds the shared secret to both public keys and the auth secret.
+	keyInfo := make([]byte, 0, 14+65+65)
+	keyInfo = append(keyInfo, "WebPush: info\x00"...)
+	keyInfo = append(keyInfo, uaPublicRaw...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single record ends with the 0x02 last-record delimiter and no
+	// padding.
+	record := make([]byte, 0, len(plaintext)+1)
+	record = append(record, plaintext...)
+	record = append(record, 0x02)
+
+	body := make([]byte, headerLength, headerLength+len(record)+gcm.Overhead())
+	copy(body, salt)
+	binary.BigEndian.PutUint32(body[saltLength:], recordSize)
+	body[saltLength+4] = byte(len(asPublic))
+	copy(body[saltLength+5:], asPublic)
+	return gcm.Seal(body, nonce, record, nil), nil
+}
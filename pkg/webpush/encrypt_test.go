@@ -0,0 +1,67 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeKey(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+// TestEncryptRFC8291Example reproduces the example of RFC 8291 Appendix A.
+func TestEncryptRFC8291Example(t *testing.T) {
+	const (
+		plaintext  = "When I grow up, I want to be a watermelon"
+		asPublic   = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+		asPrivate  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+		uaPublic   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+		salt       = "DGv6ra1nlYgDCS1FRnbzlw"
+		authSecret = "BTBZMqHH6r4Tts7J_aSIgg"
+		want       = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	)
+
+	key, err := ecdh.P256().NewPrivateKey(mustDecode(t, asPrivate))
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	if !bytes.Equal(key.PublicKey().Bytes(), mustDecode(t, asPublic)) {
+		t.Fatal("application server key pair does not match the example")
+	}
+
+	body, err := encrypt(uaPublic, authSecret, []byte(plaintext), key, mustDecode(t, salt))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(body); got != want {
+		t.Errorf("body =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncryptRejectsBadInput(t *testing.T) {
+	const (
+		uaPublic   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+		authSecret = "BTBZMqHH6r4Tts7J_aSIgg"
+	)
+
+	if _, err := Encrypt(uaPublic, authSecret, make([]byte, MaxPayload+1)); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("oversized payload error = %v", err)
+	}
+	if _, err := Encrypt(uaPublic, authSecret, make([]byte, MaxPayload)); err != nil {
+		t.Errorf("largest payload rejected: %v", err)
+	}
+	if _, err := Encrypt(uaPublic, "c2hvcnQ", nil); err == nil {
+		t.Error("short auth secret accepted")
+	}
+	if _, err := Encrypt("BAAA", authSecret, nil); err == nil {
+		t.Error("invalid p256dh accepted")
+	}
+}
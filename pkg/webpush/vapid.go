@@ -0,0 +1,110 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenTTL is how long a VAPID token is valid. RFC 8292 caps it at 24
+// hours; a fresh token is signed for every request anyway.
+const vapidTokenTTL = 12 * time.Hour
+
+// VAPID identifies this server to push services (RFC 8292). Keys use the
+// encoding browsers and the usual web-push tools expect: the public key is
+// the uncompressed P-256 point and the private key the raw 32-byte scalar,
+// both unpadded base64url.
+type VAPID struct {
+	PublicKey  string
+	PrivateKey *ecdsa.PrivateKey
+	// Subject is a mailto: or https: contact for the push service operator.
+	Subject string
+}
+
+func LoadVAPIDFromEnv() (*VAPID, error) {
+	public := strings.TrimSpace(os.Getenv("WEBPUSH_VAPID_PUBLIC_KEY"))
+	private := strings.TrimSpace(os.Getenv("WEBPUSH_VAPID_PRIVATE_KEY"))
+	subject := strings.TrimSpace(os.Getenv("WEBPUSH_VAPID_SUBJECT"))
+	if public == "" || private == "" || subject == "" {
+		return nil, fmt.Errorf("vapid keys not configured")
+	}
+	return NewVAPID(public, private, subject)
+}
+
+func NewVAPID(publicKey, privateKey, subject string) (*VAPID, error) {
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https:") {
+		return nil, fmt.Errorf("vapid subject must be a mailto: or https: URL")
+	}
+
+	raw, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode vapid private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse vapid private key: %w", err)
+	}
+
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if encoded := base64.RawURLEncoding.EncodeToString(public); encoded != strings.TrimRight(publicKey, "=") {
+		return nil, fmt.Errorf("vapid public key does not match the private key")
+	}
+
+	return &VAPID{PublicKey: base64.RawURLEncoding.EncodeToString(public), PrivateKey: key, Subject: subject}, nil
+}
+
+// GenerateVAPIDKeys returns a new key pair in the encoding NewVAPID reads.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", "", err
+	}
+	private, err := key.Bytes()
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(public), base64.RawURLEncoding.EncodeToString(private), nil
+}
+
+// authorization builds the "vapid" Authorization header for a request to
+// endpoint. The token audience is the endpoint's origin.
+func (v *VAPID) authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{u.Scheme + "://" + u.Host},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(vapidTokenTTL)),
+		Subject:   v.Subject,
+	})
+	signed, err := token.SignedString(v.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + signed + ", k=" + v.PublicKey, nil
+}
+
+// decodeKey accepts base64url with or without padding, which is how browsers
+// and key generators variously hand keys out.
+func decodeKey(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
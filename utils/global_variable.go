@@ -1,8 +1,13 @@
 package utils
 
 const (
-	CtxKeyId       = "CTX_ID"
-	CtxKeyAuthData = "auth_data"
+	CtxKeyId        = "CTX_ID"
+	CtxKeyAuthData  = "auth_data"
+	CtxKeyLocale    = "locale"
+	CtxKeyApiClient = "api_client"
+	// CtxKeyQuotaUsed holds how many metered sends a handler actually made,
+	// so the quota middleware refunds only the rest.
+	CtxKeyQuotaUsed = "quota_used"
 )
 
 const (
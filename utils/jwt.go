@@ -12,10 +12,20 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ScopePasswordChange limits a token to changing an expired password.
+const ScopePasswordChange = "password_change"
+
+// ScopeNotificationStream limits a token to opening the inbox stream, which
+// browsers connect to without an Authorization header.
+const ScopeNotificationStream = "notification_stream"
+
 type AppClaims struct {
 	UserId   string `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// Scope, when set, limits what the token is good for; AuthMiddleware
+	// only accepts tokens without one.
+	Scope string `json:"scope,omitempty"`
 	*jwt.RegisteredClaims
 }
 
@@ -41,6 +51,25 @@ func GenerateJwt(user *domainuser.Users, logId string) (string, error) {
 	return signedToken, nil
 }
 
+// GenerateScopedJwt issues a token for user that is only good for scope and
+// expires after ttl.
+func GenerateScopedJwt(user *domainuser.Users, logId, scope string, ttl time.Duration) (string, error) {
+	claims := AppClaims{
+		UserId:   user.Id,
+		Username: user.Name,
+		Role:     user.Role,
+		Scope:    scope,
+		RegisteredClaims: &jwt.RegisteredClaims{
+			ID:        logId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
+	return token.SignedString([]byte(os.Getenv("JWT_KEY")))
+}
+
 func GetAuthToken(ctx *gin.Context) string {
 	bearerToken := ctx.Request.Header.Get("Authorization")
 	return strings.ReplaceAll(bearerToken, "Bearer ", "")
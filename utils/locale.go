@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"service-sender/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLocale returns the first supported locale from the request's own
+// locale field and then Accept-Language, or "" when neither matches so the
+// caller can continue with the user profile and default.
+func RequestLocale(ctx *gin.Context, requested string) string {
+	candidates := append([]string{requested}, i18n.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))...)
+	return i18n.First(candidates...)
+}
+
+// HeaderLocale returns the first supported locale from Accept-Language, or
+// "". Callers that consult the user profile first use it instead of
+// RequestLocale.
+func HeaderLocale(ctx *gin.Context) string {
+	return i18n.First(i18n.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))...)
+}